@@ -0,0 +1,73 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestRemindCommand(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	rs1 := Client.Must(Client.Command(channel.Id, "/remind in 2h check the build")).Data.(*model.CommandResponse)
+	if !strings.Contains(rs1.Text, "2h") {
+		t.Fatal("should have confirmed the reminder", rs1.Text)
+	}
+
+	rs2 := Client.Must(Client.Command(channel.Id, "/remind every weekday stand up")).Data.(*model.CommandResponse)
+	if rs2.Text == "" {
+		t.Fatal("should have confirmed the recurring reminder")
+	}
+
+	scheduledPosts, err := app.GetScheduledPostsForUserPage(th.BasicUser.Id, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(scheduledPosts) != 2 {
+		t.Fatal("should have scheduled two posts")
+	}
+
+	for _, scheduledPost := range scheduledPosts {
+		if scheduledPost.ChannelId != channel.Id {
+			t.Fatal("should be posted in the channel the command was run in")
+		}
+
+		if scheduledPost.Message == "check the build" && scheduledPost.Recurrence != model.SCHEDULED_POST_RECURRENCE_NONE {
+			t.Fatal("the first reminder shouldn't recur")
+		} else if scheduledPost.Message == "stand up" && scheduledPost.Recurrence != model.SCHEDULED_POST_RECURRENCE_WEEKDAYS {
+			t.Fatal("the second reminder should recur on weekdays")
+		} else if scheduledPost.Message == "stand up" && scheduledPost.Timezone != "UTC" {
+			t.Fatal("the second reminder should be in UTC since the client didn't give a timezone")
+		}
+	}
+
+	rs3 := Client.Must(Client.Command(channel.Id, "/remind list")).Data.(*model.CommandResponse)
+	if !strings.Contains(rs3.Text, scheduledPosts[0].Id) || !strings.Contains(rs3.Text, scheduledPosts[1].Id) {
+		t.Fatal("should have listed the reminders", rs3.Text)
+	}
+
+	Client.Must(Client.Command(channel.Id, "/remind cancel "+scheduledPosts[0].Id))
+	if scheduledPosts, err := app.GetScheduledPostsForUserPage(th.BasicUser.Id, 0, 10); err != nil {
+		t.Fatal(err)
+	} else if len(scheduledPosts) != 1 {
+		t.Fatal("should have cancelled a reminder")
+	}
+
+	rs4 := Client.Must(Client.Command(channel.Id, "/remind in soon check the build")).Data.(*model.CommandResponse)
+	if rs4.Text != "Couldn't understand when to post the message. Use a delay such as 30m, 2h or 1d, followed by the message." {
+		t.Fatal("should have failed to parse the delay", rs4.Text)
+	}
+
+	// users can't cancel each other's reminders
+	Client.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+	Client.Must(Client.Command(channel.Id, "/remind cancel "+scheduledPosts[1].Id))
+	if _, err := app.GetScheduledPost(scheduledPosts[1].Id); err != nil {
+		t.Fatal("shouldn't have cancelled another user's reminder")
+	}
+}
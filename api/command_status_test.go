@@ -0,0 +1,46 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestStatusCommand(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	Client.Must(Client.Command(channel.Id, "/status set :calendar: In a meeting"))
+
+	if user, err := app.GetUser(th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if user.CustomStatus == nil || user.CustomStatus.Emoji != "calendar" || user.CustomStatus.Text != "In a meeting" || user.CustomStatus.ExpiresAt != 0 {
+		t.Fatal("should have set the custom status")
+	}
+
+	Client.Must(Client.Command(channel.Id, "/status for 1h Out to lunch"))
+
+	if user, err := app.GetUser(th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if user.CustomStatus == nil || user.CustomStatus.Emoji != "" || user.CustomStatus.Text != "Out to lunch" || user.CustomStatus.ExpiresAt <= model.GetMillis() {
+		t.Fatal("should have set the custom status with an expiry")
+	}
+
+	rs1 := Client.Must(Client.Command(channel.Id, "/status for soon Out to lunch")).Data.(*model.CommandResponse)
+	if rs1.Text != "Couldn't understand how long to show the status for. Use a duration such as 30m, 2h or 1d, followed by the status." {
+		t.Fatal("should have failed to parse the duration", rs1.Text)
+	}
+
+	Client.Must(Client.Command(channel.Id, "/status clear"))
+
+	if user, err := app.GetUser(th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if user.CustomStatus != nil {
+		t.Fatal("should have cleared the custom status")
+	}
+}
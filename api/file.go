@@ -4,6 +4,7 @@
 package api
 
 import (
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -15,6 +16,10 @@ import (
 	"github.com/mattermost/platform/utils"
 )
 
+const (
+	MAX_UPLOAD_MEMORY = 10 * 1024 * 1024
+)
+
 func InitFile() {
 	l4g.Debug(utils.T("api.file.init.debug"))
 
@@ -37,7 +42,8 @@ func uploadFile(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := r.ParseMultipartForm(*utils.Cfg.FileSettings.MaxFileSize); err != nil {
+	// Anything beyond the first few megabytes of the form is spooled to disk instead of being held in memory
+	if err := r.ParseMultipartForm(MAX_UPLOAD_MEMORY); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -60,7 +66,23 @@ func uploadFile(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resStruct, err := app.UploadFiles(c.TeamId, channelId, c.Session.UserId, m.File["files"], m.Value["client_ids"])
+	files := make([]io.ReadCloser, 0, len(m.File["files"]))
+	filenames := make([]string, 0, len(m.File["files"]))
+	for _, fileHeader := range m.File["files"] {
+		file, fileErr := fileHeader.Open()
+		if fileErr != nil {
+			for _, opened := range files {
+				opened.Close()
+			}
+			c.Err = model.NewAppError("uploadFile", "api.file.upload_file.bad_parse.app_error", nil, fileErr.Error(), http.StatusBadRequest)
+			return
+		}
+
+		files = append(files, file)
+		filenames = append(filenames, fileHeader.Filename)
+	}
+
+	resStruct, err := app.UploadFiles(c.TeamId, channelId, c.Session.UserId, files, filenames, m.Value["client_ids"])
 	if err != nil {
 		c.Err = err
 		return
@@ -19,7 +19,6 @@ import (
 	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
-	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
 )
 
@@ -67,23 +66,16 @@ func registerOAuthApp(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	secret := model.NewId()
-
-	oauthApp.ClientSecret = secret
 	oauthApp.CreatorId = c.Session.UserId
 
-	if result := <-app.Srv.Store.OAuth().SaveApp(oauthApp); result.Err != nil {
-		c.Err = result.Err
-		return
-	} else {
-		oauthApp = result.Data.(*model.OAuthApp)
-
-		c.LogAudit("client_id=" + oauthApp.Id)
-
-		w.Write([]byte(oauthApp.ToJson()))
+	rapp, err := app.CreateOAuthApp(oauthApp)
+	if err != nil {
+		c.Err = err
 		return
 	}
 
+	c.LogAudit("client_id=" + rapp.Id)
+	w.Write([]byte(rapp.ToJson()))
 }
 
 func getOAuthApps(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -99,21 +91,20 @@ func getOAuthApps(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var ochan store.StoreChannel
+	var apps []*model.OAuthApp
+	var err *model.AppError
 	if app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM_WIDE_OAUTH) {
-		ochan = app.Srv.Store.OAuth().GetApps()
+		apps, err = app.GetOAuthApps()
 	} else {
-		c.Err = nil
-		ochan = app.Srv.Store.OAuth().GetAppByUser(c.Session.UserId)
+		apps, err = app.GetOAuthAppsByCreator(c.Session.UserId)
 	}
 
-	if result := <-ochan; result.Err != nil {
-		c.Err = result.Err
+	if err != nil {
+		c.Err = err
 		return
-	} else {
-		apps := result.Data.([]*model.OAuthApp)
-		w.Write([]byte(model.OAuthAppListToJson(apps)))
 	}
+
+	w.Write([]byte(model.OAuthAppListToJson(apps)))
 }
 
 func getOAuthAppInfo(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -235,17 +226,10 @@ func getAuthorizedApps(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ochan := app.Srv.Store.OAuth().GetAuthorizedApps(c.Session.UserId)
-	if result := <-ochan; result.Err != nil {
-		c.Err = result.Err
+	if apps, err := app.GetAuthorizedAppsForUser(c.Session.UserId); err != nil {
+		c.Err = err
 		return
 	} else {
-		apps := result.Data.([]*model.OAuthApp)
-		for k, a := range apps {
-			a.Sanitize()
-			apps[k] = a
-		}
-
 		w.Write([]byte(model.OAuthAppListToJson(apps)))
 	}
 }
@@ -882,7 +866,7 @@ func deleteOAuthApp(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := (<-app.Srv.Store.OAuth().DeleteApp(id)).Err; err != nil {
+	if err := app.DeleteOAuthApp(id); err != nil {
 		c.Err = err
 		return
 	}
@@ -906,28 +890,7 @@ func deauthorizeOAuthApp(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// revoke app sessions
-	if result := <-app.Srv.Store.OAuth().GetAccessDataByUserForApp(c.Session.UserId, id); result.Err != nil {
-		c.Err = result.Err
-		return
-	} else {
-		accessData := result.Data.([]*model.AccessData)
-
-		for _, a := range accessData {
-			if err := app.RevokeAccessToken(a.Token); err != nil {
-				c.Err = err
-				return
-			}
-
-			if rad := <-app.Srv.Store.OAuth().RemoveAccessData(a.Token); rad.Err != nil {
-				c.Err = rad.Err
-				return
-			}
-		}
-	}
-
-	// Deauthorize the app
-	if err := (<-app.Srv.Store.Preference().Delete(c.Session.UserId, model.PREFERENCE_CATEGORY_AUTHORIZED_OAUTH_APP, id)).Err; err != nil {
+	if err := app.DeauthorizeOAuthAppForUser(c.Session.UserId, id); err != nil {
 		c.Err = err
 		return
 	}
@@ -964,14 +927,12 @@ func regenerateOAuthSecret(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		oauthApp.ClientSecret = model.NewId()
-		if update := <-app.Srv.Store.OAuth().UpdateApp(oauthApp); update.Err != nil {
-			c.Err = update.Err
+		if rapp, err := app.RegenerateOAuthAppSecret(oauthApp); err != nil {
+			c.Err = err
 			return
+		} else {
+			w.Write([]byte(rapp.ToJson()))
 		}
-
-		w.Write([]byte(oauthApp.ToJson()))
-		return
 	}
 }
 
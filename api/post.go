@@ -60,6 +60,13 @@ func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
 		post.CreateAt = 0
 	}
 
+	if botUserId, err := app.GetBotUserIdForOAuthSession(&c.Session); err != nil {
+		c.Err = err
+		return
+	} else if botUserId != "" {
+		post.UserId = botUserId
+	}
+
 	rp, err := app.CreatePostAsUser(post)
 	if err != nil {
 		c.Err = err
@@ -84,7 +91,7 @@ func updatePost(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	post.UserId = c.Session.UserId
 
-	rpost, err := app.UpdatePost(post)
+	rpost, err := app.UpdatePost(post, true)
 	if err != nil {
 		c.Err = err
 		return
@@ -53,28 +53,20 @@ func saveReaction(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var post *model.Post
-
-	if result := <-app.Srv.Store.Post().Get(reaction.PostId); result.Err != nil {
-		c.Err = result.Err
+	if post, err := app.GetSinglePost(postId); err != nil {
+		c.Err = err
 		return
-	} else if post = result.Data.(*model.PostList).Posts[postId]; post.ChannelId != channelId {
+	} else if post.ChannelId != channelId {
 		c.Err = model.NewLocAppError("saveReaction", "api.reaction.save_reaction.mismatched_channel_id.app_error",
 			nil, "channelId="+channelId+", post.ChannelId="+post.ChannelId+", postId="+postId)
 		c.Err.StatusCode = http.StatusBadRequest
 		return
 	}
 
-	if result := <-app.Srv.Store.Reaction().Save(reaction); result.Err != nil {
-		c.Err = result.Err
+	if reaction, err := app.SaveReactionForPost(reaction); err != nil {
+		c.Err = err
 		return
 	} else {
-		go sendReactionEvent(model.WEBSOCKET_EVENT_REACTION_ADDED, channelId, reaction, post)
-
-		reaction := result.Data.(*model.Reaction)
-
-		app.InvalidateCacheForReactions(reaction.PostId)
-
 		w.Write([]byte(reaction.ToJson()))
 	}
 }
@@ -111,45 +103,22 @@ func deleteReaction(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var post *model.Post
-
-	if result := <-app.Srv.Store.Post().Get(reaction.PostId); result.Err != nil {
-		c.Err = result.Err
+	if post, err := app.GetSinglePost(postId); err != nil {
+		c.Err = err
 		return
-	} else if post = result.Data.(*model.PostList).Posts[postId]; post.ChannelId != channelId {
+	} else if post.ChannelId != channelId {
 		c.Err = model.NewLocAppError("deleteReaction", "api.reaction.delete_reaction.mismatched_channel_id.app_error",
 			nil, "channelId="+channelId+", post.ChannelId="+post.ChannelId+", postId="+postId)
 		c.Err.StatusCode = http.StatusBadRequest
 		return
 	}
 
-	if result := <-app.Srv.Store.Reaction().Delete(reaction); result.Err != nil {
-		c.Err = result.Err
+	if err := app.DeleteReactionForPost(reaction); err != nil {
+		c.Err = err
 		return
-	} else {
-		go sendReactionEvent(model.WEBSOCKET_EVENT_REACTION_REMOVED, channelId, reaction, post)
-
-		app.InvalidateCacheForReactions(reaction.PostId)
-
-		ReturnStatusOK(w)
 	}
-}
-
-func sendReactionEvent(event string, channelId string, reaction *model.Reaction, post *model.Post) {
-	// send out that a reaction has been added/removed
-
-	message := model.NewWebSocketEvent(event, "", channelId, "", nil)
-	message.Add("reaction", reaction.ToJson())
-	app.Publish(message)
-
-	// THe post is always modified since the UpdateAt always changes
-	app.InvalidateCacheForChannelPosts(post.ChannelId)
-	post.HasReactions = true
-	post.UpdateAt = model.GetMillis()
-	umessage := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_EDITED, "", channelId, "", nil)
-	umessage.Add("post", post.ToJson())
-	app.Publish(umessage)
 
+	ReturnStatusOK(w)
 }
 
 func listReactions(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -184,12 +153,10 @@ func listReactions(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if result := <-app.Srv.Store.Reaction().GetForPost(postId, true); result.Err != nil {
-		c.Err = result.Err
+	if reactions, err := app.GetReactionsForPost(postId); err != nil {
+		c.Err = err
 		return
 	} else {
-		reactions := result.Data.([]*model.Reaction)
-
 		w.Write([]byte(model.ReactionsToJson(reactions)))
 	}
 }
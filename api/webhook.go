@@ -34,6 +34,8 @@ func InitWebhook() {
 	// Old route. Remove eventually.
 	mr := app.Srv.Router
 	mr.Handle("/hooks/{id:[A-Za-z0-9]+}", ApiAppHandler(incomingWebhook)).Methods("POST")
+
+	mr.Handle("/hooks/commands/{id:[A-Za-z0-9]+}", ApiAppHandler(commandWebhook)).Methods("POST")
 }
 
 func createIncomingHook(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -348,10 +350,45 @@ func incomingWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
 
 	var payload io.Reader
+	var files []io.ReadCloser
+	var filenames []string
 	contentType := r.Header.Get("Content-Type")
-	if strings.Split(contentType, "; ")[0] == "application/x-www-form-urlencoded" {
+	switch strings.Split(contentType, ";")[0] {
+	case "application/x-www-form-urlencoded":
 		payload = strings.NewReader(r.FormValue("payload"))
-	} else {
+	case "multipart/form-data":
+		if r.ContentLength > *utils.Cfg.FileSettings.MaxFileSize {
+			c.Err = model.NewAppError("incomingWebhook", "api.file.upload_file.too_large.app_error", nil, "", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, *utils.Cfg.FileSettings.MaxFileSize)
+		if err := r.ParseMultipartForm(MAX_UPLOAD_MEMORY); err != nil {
+			c.Err = model.NewAppError("incomingWebhook", "api.webhook.incoming.multipart.app_error", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		// the files are normally closed once they've been uploaded, but not if the request fails before then
+		defer func() {
+			for _, file := range files {
+				file.Close()
+			}
+		}()
+
+		for _, fileHeader := range r.MultipartForm.File["files"] {
+			file, fileErr := fileHeader.Open()
+			if fileErr != nil {
+				c.Err = model.NewAppError("incomingWebhook", "api.webhook.incoming.multipart.app_error", nil, fileErr.Error(), http.StatusBadRequest)
+				return
+			}
+
+			files = append(files, file)
+			filenames = append(filenames, fileHeader.Filename)
+		}
+
+		payload = strings.NewReader(r.FormValue("payload"))
+	default:
 		payload = r.Body
 	}
 
@@ -372,14 +409,30 @@ func incomingWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	parsedRequest := model.IncomingWebhookRequestFromJson(payload)
+	if err := app.HandleIncomingWebhook(id, payload, files, filenames); err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok"))
+}
+
+func commandWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id := params["id"]
 
-	err := app.HandleIncomingWebhook(id, parsedRequest)
+	response, err := model.CommandResponseFromHTTPBody(r.Body)
 	if err != nil {
 		c.Err = err
 		return
 	}
 
+	if err := app.HandleCommandWebhook(id, r.URL.Query(), response); err != nil {
+		c.Err = err
+		return
+	}
+
 	w.Header().Set("Content-Type", "text/plain")
 	w.Write([]byte("ok"))
 }
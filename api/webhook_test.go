@@ -4,9 +4,14 @@
 package api
 
 import (
+	"bytes"
 	"fmt"
+	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
@@ -1091,9 +1096,246 @@ func TestIncomingWebhooks(t *testing.T) {
 		t.Fatal("should have failed with bad request - attachment too long")
 	}
 
+	if _, err := Client.DoPost(url, `{"text": "this is a test", "attachments": ["not an attachment"]}`, "application/json"); err == nil || err.StatusCode != http.StatusBadRequest {
+		t.Fatal("should have failed with bad request - malformed attachment")
+	}
+
+	if _, err := Client.DoPost(url, `{"text": "this is a test", "attachments": [{"fields": [{"title": "Priority", "value": ["High"]}]}]}`, "application/json"); err == nil || err.StatusCode != http.StatusBadRequest {
+		t.Fatal("should have failed with bad request - malformed attachment field")
+	}
+
 	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = false
 
 	if _, err := Client.DoPost(url, "{\"text\":\"this is a test\"}", "application/json"); err == nil {
 		t.Fatal("should have failed - webhooks turned off")
 	}
 }
+
+func TestIncomingWebhookChannelLockedAndRateLimit(t *testing.T) {
+	th := Setup().InitSystemAdmin()
+	Client := th.SystemAdminClient
+	team := th.SystemAdminTeam
+	channel1 := th.CreateChannel(Client, team)
+	channel2 := th.CreateChannel(Client, team)
+	user2 := th.CreateUser(Client)
+	LinkUserToTeam(user2, team)
+
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+	}()
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+
+	hook := &model.IncomingWebhook{ChannelId: channel1.Id, ChannelLocked: true}
+	hook = Client.Must(Client.CreateIncomingWebhook(hook)).Data.(*model.IncomingWebhook)
+
+	url := "/hooks/" + hook.Id
+
+	if _, err := Client.DoPost(url, fmt.Sprintf(`{"text": "this is a test", "channel": "%s"}`, channel1.Name), "application/json"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Client.DoPost(url, fmt.Sprintf(`{"text": "this is a test", "channel": "%s"}`, channel2.Name), "application/json"); err == nil || err.StatusCode != http.StatusForbidden {
+		t.Fatal("should have failed - hook is locked to its channel")
+	}
+
+	if _, err := Client.DoPost(url, fmt.Sprintf(`{"text": "this is a test", "channel": "@%s"}`, user2.Username), "application/json"); err == nil || err.StatusCode != http.StatusForbidden {
+		t.Fatal("should have failed - hook is locked to its channel")
+	}
+
+	limitedHook := &model.IncomingWebhook{ChannelId: channel1.Id, RateLimit: 2}
+	limitedHook = Client.Must(Client.CreateIncomingWebhook(limitedHook)).Data.(*model.IncomingWebhook)
+
+	url = "/hooks/" + limitedHook.Id
+
+	for i := 0; i < 2; i++ {
+		if _, err := Client.DoPost(url, `{"text": "this is a test"}`, "application/json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := Client.DoPost(url, `{"text": "this is a test"}`, "application/json"); err == nil || err.StatusCode != http.StatusTooManyRequests {
+		t.Fatal("should have failed - rate limit exceeded")
+	}
+
+	// other hooks aren't affected
+	if _, err := Client.DoPost("/hooks/"+hook.Id, `{"text": "this is a test"}`, "application/json"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIncomingWebhookFiles(t *testing.T) {
+	th := Setup().InitSystemAdmin()
+
+	if utils.Cfg.FileSettings.DriverName == "" {
+		t.Logf("skipping because no file driver is enabled")
+		return
+	}
+
+	Client := th.SystemAdminClient
+	team := th.SystemAdminTeam
+	channel1 := th.CreateChannel(Client, team)
+
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+	}()
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+
+	hook := &model.IncomingWebhook{ChannelId: channel1.Id}
+	hook = Client.Must(Client.CreateIncomingWebhook(hook)).Data.(*model.IncomingWebhook)
+
+	data, err := readTestFile("test.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("payload", `{"text": "this has a file"}`)
+	if part, err := writer.CreateFormFile("files", "test.png"); err != nil {
+		t.Fatal(err)
+	} else {
+		part.Write(data)
+	}
+	writer.Close()
+
+	if _, err := Client.DoPost("/hooks/"+hook.Id, body.String(), writer.FormDataContentType()); err != nil {
+		t.Fatal(err)
+	}
+
+	posts := Client.Must(Client.GetPosts(channel1.Id, 0, 1, "")).Data.(*model.PostList)
+	post := posts.Posts[posts.Order[0]]
+	if post.Message != "this has a file" || len(post.FileIds) != 1 {
+		t.Fatal("should have attached the file to the post")
+	}
+
+	if info, err := Client.GetFileInfo(post.FileIds[0]); err != nil {
+		t.Fatal(err)
+	} else if info.Name != "test.png" || info.PostId != post.Id {
+		t.Fatal("should have uploaded the file")
+	}
+
+	// files can be sent without any text
+	body.Reset()
+	writer = multipart.NewWriter(body)
+	writer.WriteField("payload", `{}`)
+	if part, err := writer.CreateFormFile("files", "test.png"); err != nil {
+		t.Fatal(err)
+	} else {
+		part.Write(data)
+	}
+	writer.Close()
+
+	if _, err := Client.DoPost("/hooks/"+hook.Id, body.String(), writer.FormDataContentType()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommandWebhooks(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	enableCommands := *utils.Cfg.ServiceSettings.EnableCommands
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableCommands = &enableCommands
+	}()
+	*utils.Cfg.ServiceSettings.EnableCommands = true
+
+	responseURLs := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		responseURLs <- r.FormValue("response_url")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	cmd := &model.Command{
+		URL:     ts.URL,
+		Method:  model.COMMAND_METHOD_POST,
+		Trigger: "delayed",
+	}
+	th.SystemAdminClient.Must(th.SystemAdminClient.CreateCommand(cmd))
+
+	Client.Must(Client.Command(channel.Id, "/delayed"))
+
+	responseURL := <-responseURLs
+	index := strings.Index(responseURL, "/hooks/commands/")
+	if index == -1 {
+		t.Fatal("should have sent a response url, got " + responseURL)
+	}
+	path := responseURL[index:]
+
+	if _, err := Client.DoPost(path, `{"response_type": "in_channel", "text": "delayed response"}`, "application/json"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	posts := Client.Must(Client.GetPosts(channel.Id, 0, 1, "")).Data.(*model.PostList)
+	if post := posts.Posts[posts.Order[0]]; post.Message != "delayed response" || post.UserId != th.BasicUser.Id {
+		t.Fatal("should have posted the delayed response as the user that ran the command")
+	}
+
+	if _, err := Client.DoPost(path, "junk", "application/json"); err == nil || err.StatusCode != http.StatusBadRequest {
+		t.Fatal("should have failed - bad response")
+	}
+
+	for i := 1; i < model.COMMAND_WEBHOOK_MAX_USES; i++ {
+		if _, err := Client.DoPost(path, `{"text": "ephemeral response"}`, "application/json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := Client.DoPost(path, `{"text": "one too many"}`, "application/json"); err == nil || err.StatusCode != http.StatusBadRequest {
+		t.Fatal("should have failed - too many uses")
+	}
+
+	if _, err := Client.DoPost("/hooks/commands/"+model.NewId(), `{"text": "unsigned"}`, "application/json"); err == nil || err.StatusCode != http.StatusUnauthorized {
+		t.Fatal("should have failed - unsigned hook")
+	}
+
+	if _, err := Client.DoPost(strings.Replace(path, channel.Id, model.NewId(), 1), `{"text": "tampered"}`, "application/json"); err == nil || err.StatusCode != http.StatusUnauthorized {
+		t.Fatal("should have failed - changed hook")
+	}
+}
+
+func TestIncomingWebhookMessageTemplate(t *testing.T) {
+	th := Setup().InitSystemAdmin()
+	Client := th.SystemAdminClient
+	team := th.SystemAdminTeam
+	channel1 := th.CreateChannel(Client, team)
+
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+	}()
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+
+	hook := &model.IncomingWebhook{
+		ChannelId:       channel1.Id,
+		MessageTemplate: `{"text": "Build {{.number}} {{.status}}", "attachments": [{"title": "{{.commit.message}}"}]}`,
+	}
+	hook = Client.Must(Client.CreateIncomingWebhook(hook)).Data.(*model.IncomingWebhook)
+
+	if _, err := Client.DoPost("/hooks/"+hook.Id, `{"number": 42, "status": "passed", "commit": {"message": "Fix the tests"}}`, "application/json"); err != nil {
+		t.Fatal(err)
+	}
+
+	posts := Client.Must(Client.GetPosts(channel1.Id, 0, 1, "")).Data.(*model.PostList)
+	post := posts.Posts[posts.Order[0]]
+	if post.Message != "Build 42 passed" {
+		t.Fatal("should have posted the rendered text", post.Message)
+	}
+
+	if attachments := post.Attachments(); len(attachments) != 1 || attachments[0].Title != "Fix the tests" {
+		t.Fatal("should have posted the rendered attachments")
+	}
+
+	if _, err := Client.DoPost("/hooks/"+hook.Id, `not json`, "application/json"); err == nil || err.StatusCode != http.StatusBadRequest {
+		t.Fatal("should have failed - the payload isn't JSON")
+	}
+}
@@ -5,6 +5,7 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/gorilla/websocket"
@@ -35,6 +36,11 @@ func connect(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	wc := app.NewWebConn(ws, c.Session, c.T, c.Locale)
 
+	if connectionId := r.URL.Query().Get(model.WEBSOCKET_PARAM_CONNECTION_ID); len(connectionId) > 0 {
+		sequence, _ := strconv.ParseInt(r.URL.Query().Get(model.WEBSOCKET_PARAM_SEQUENCE_NUMBER), 10, 64)
+		wc.SetResume(connectionId, sequence)
+	}
+
 	if len(c.Session.UserId) > 0 {
 		app.HubRegister(wc)
 	}
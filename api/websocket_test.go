@@ -343,3 +343,72 @@ func TestZZWebSocketTearDown(t *testing.T) {
 	time.Sleep(2 * time.Second)
 	TearDown()
 }
+
+func TestWebSocketResume(t *testing.T) {
+	th := Setup().InitBasic()
+	WebSocketClient, err := th.CreateWebSocketClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer WebSocketClient.Close()
+
+	WebSocketClient.Listen()
+
+	time.Sleep(300 * time.Millisecond)
+	if resp := <-WebSocketClient.ResponseChannel; resp.Status != model.STATUS_OK {
+		t.Fatal("should have responded OK to authentication challenge")
+	}
+
+	if hello := <-WebSocketClient.EventChannel; hello.Event != model.WEBSOCKET_EVENT_HELLO {
+		t.Fatal("should have received hello")
+	} else if len(WebSocketClient.ConnectionId) != 26 {
+		t.Fatal("should have been given a connection id")
+	}
+
+	connectionId := WebSocketClient.ConnectionId
+
+	evt1 := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_TYPING, "", "", th.BasicUser.Id, nil)
+	app.Publish(evt1)
+
+	if resp := <-WebSocketClient.EventChannel; resp.Event != model.WEBSOCKET_EVENT_TYPING || resp.Seq != 1 {
+		t.Fatal("should have received the first event", resp.Seq)
+	}
+
+	WebSocketClient.Close()
+	time.Sleep(300 * time.Millisecond)
+
+	evt2 := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_TYPING, "", "", th.BasicUser.Id, nil)
+	evt2.Add("missed", "1")
+	app.Publish(evt2)
+	time.Sleep(300 * time.Millisecond)
+
+	if err := WebSocketClient.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	WebSocketClient.Listen()
+
+	if hello := <-WebSocketClient.EventChannel; hello.Event != model.WEBSOCKET_EVENT_HELLO || WebSocketClient.ConnectionId != connectionId {
+		t.Fatal("should have resumed the connection")
+	}
+
+	if resp := <-WebSocketClient.EventChannel; resp.Event != model.WEBSOCKET_EVENT_TYPING || resp.Seq != 2 || resp.Data["missed"] != "1" {
+		t.Fatal("should have replayed the missed event")
+	}
+
+	WebSocketClient.Close()
+	time.Sleep(300 * time.Millisecond)
+
+	WebSocketClient.ConnectionId = model.NewId()
+	if err := WebSocketClient.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	WebSocketClient.Listen()
+
+	if hello := <-WebSocketClient.EventChannel; hello.Event != model.WEBSOCKET_EVENT_HELLO || WebSocketClient.EventSequence != 0 {
+		t.Fatal("should have started a new connection")
+	}
+
+	if resp := <-WebSocketClient.EventChannel; resp.Event != model.WEBSOCKET_EVENT_RESYNC_REQUIRED {
+		t.Fatal("should have been told to resync")
+	}
+}
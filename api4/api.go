@@ -25,6 +25,9 @@ type Routes struct {
 	UserByUsername *mux.Router // 'api/v4/users/username/{username:[A-Za-z0-9_-\.]+}'
 	UserByEmail    *mux.Router // 'api/v4/users/email/{email}'
 
+	Bots *mux.Router // 'api/v4/bots'
+	Bot  *mux.Router // 'api/v4/bots/{bot_user_id:[A-Za-z0-9]+}'
+
 	Teams        *mux.Router // 'api/v4/teams'
 	TeamsForUser *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/teams'
 	Team         *mux.Router // 'api/v4/teams/{team_id:[A-Za-z0-9]+}'
@@ -45,6 +48,11 @@ type Routes struct {
 	Posts           *mux.Router // 'api/v4/posts'
 	Post            *mux.Router // 'api/v4/posts/{post_id:[A-Za-z0-9]+}'
 	PostsForChannel *mux.Router // 'api/v4/channels/{channel_id:[A-Za-z0-9]+}/posts'
+	PostsForUser    *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/posts'
+
+	ScheduledPosts        *mux.Router // 'api/v4/scheduled_posts'
+	ScheduledPost         *mux.Router // 'api/v4/scheduled_posts/{scheduled_post_id:[A-Za-z0-9]+}'
+	ScheduledPostsForUser *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/scheduled_posts'
 
 	Files *mux.Router // 'api/v4/files'
 	File  *mux.Router // 'api/v4/files/{file_id:[A-Za-z0-9]+}'
@@ -63,6 +71,8 @@ type Routes struct {
 
 	Admin      *mux.Router // 'api/v4/admin'
 	OAuth      *mux.Router // 'api/v4/oauth'
+	OAuthApps  *mux.Router // 'api/v4/oauth/apps'
+	OAuthApp   *mux.Router // 'api/v4/oauth/apps/{app_id:[A-Za-z0-9]+}'
 	SAML       *mux.Router // 'api/v4/saml'
 	Compliance *mux.Router // 'api/v4/compliance'
 	Cluster    *mux.Router // 'api/v4/cluster'
@@ -82,7 +92,11 @@ type Routes struct {
 	Emojis *mux.Router // 'api/v4/emoji'
 	Emoji  *mux.Router // 'api/v4/emoji/{emoji_id:[A-Za-z0-9]+}'
 
+	Reactions *mux.Router // 'api/v4/reactions'
+
 	Webrtc *mux.Router // 'api/v4/webrtc'
+
+	Jobs *mux.Router // 'api/v4/jobs'
 }
 
 var BaseRoutes *Routes
@@ -103,6 +117,9 @@ func InitApi(full bool) {
 	BaseRoutes.UserByUsername = BaseRoutes.Users.PathPrefix("/username/{username:[A-Za-z0-9\\_\\-\\.]+}").Subrouter()
 	BaseRoutes.UserByEmail = BaseRoutes.Users.PathPrefix("/email/{email}").Subrouter()
 
+	BaseRoutes.Bots = BaseRoutes.ApiRoot.PathPrefix("/bots").Subrouter()
+	BaseRoutes.Bot = BaseRoutes.Bots.PathPrefix("/{bot_user_id:[A-Za-z0-9]+}").Subrouter()
+
 	BaseRoutes.Teams = BaseRoutes.ApiRoot.PathPrefix("/teams").Subrouter()
 	BaseRoutes.TeamsForUser = BaseRoutes.User.PathPrefix("/teams").Subrouter()
 	BaseRoutes.Team = BaseRoutes.Teams.PathPrefix("/{team_id:[A-Za-z0-9]+}").Subrouter()
@@ -123,6 +140,11 @@ func InitApi(full bool) {
 	BaseRoutes.Posts = BaseRoutes.ApiRoot.PathPrefix("/posts").Subrouter()
 	BaseRoutes.Post = BaseRoutes.Posts.PathPrefix("/{post_id:[A-Za-z0-9]+}").Subrouter()
 	BaseRoutes.PostsForChannel = BaseRoutes.Channel.PathPrefix("/posts").Subrouter()
+	BaseRoutes.PostsForUser = BaseRoutes.User.PathPrefix("/posts").Subrouter()
+
+	BaseRoutes.ScheduledPosts = BaseRoutes.ApiRoot.PathPrefix("/scheduled_posts").Subrouter()
+	BaseRoutes.ScheduledPost = BaseRoutes.ScheduledPosts.PathPrefix("/{scheduled_post_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.ScheduledPostsForUser = BaseRoutes.User.PathPrefix("/scheduled_posts").Subrouter()
 
 	BaseRoutes.Files = BaseRoutes.ApiRoot.PathPrefix("/files").Subrouter()
 	BaseRoutes.File = BaseRoutes.Files.PathPrefix("/{file_id:[A-Za-z0-9]+}").Subrouter()
@@ -140,6 +162,8 @@ func InitApi(full bool) {
 
 	BaseRoutes.SAML = BaseRoutes.ApiRoot.PathPrefix("/saml").Subrouter()
 	BaseRoutes.OAuth = BaseRoutes.ApiRoot.PathPrefix("/oauth").Subrouter()
+	BaseRoutes.OAuthApps = BaseRoutes.OAuth.PathPrefix("/apps").Subrouter()
+	BaseRoutes.OAuthApp = BaseRoutes.OAuthApps.PathPrefix("/{app_id:[A-Za-z0-9]+}").Subrouter()
 	BaseRoutes.Admin = BaseRoutes.ApiRoot.PathPrefix("/admin").Subrouter()
 	BaseRoutes.Compliance = BaseRoutes.ApiRoot.PathPrefix("/compliance").Subrouter()
 	BaseRoutes.Cluster = BaseRoutes.ApiRoot.PathPrefix("/cluster").Subrouter()
@@ -153,21 +177,33 @@ func InitApi(full bool) {
 	BaseRoutes.Emojis = BaseRoutes.ApiRoot.PathPrefix("/emoji").Subrouter()
 	BaseRoutes.Emoji = BaseRoutes.Emojis.PathPrefix("/{emoji_id:[A-Za-z0-9]+}").Subrouter()
 
+	BaseRoutes.Reactions = BaseRoutes.ApiRoot.PathPrefix("/reactions").Subrouter()
+
 	BaseRoutes.Webrtc = BaseRoutes.ApiRoot.PathPrefix("/webrtc").Subrouter()
 
+	BaseRoutes.Jobs = BaseRoutes.ApiRoot.PathPrefix("/jobs").Subrouter()
+
 	InitUser()
+	InitBot()
 	InitTeam()
 	InitChannel()
 	InitPost()
+	InitScheduledPost()
 	InitFile()
 	InitSystem()
 	InitWebhook()
 	InitPreference()
+	InitCommand()
+	InitEmoji()
+	InitReaction()
+	InitOAuth()
+	InitStatus()
 	InitSaml()
 	InitCompliance()
 	InitCluster()
 	InitLdap()
 	InitBrand()
+	InitJob()
 
 	app.Srv.Router.Handle("/api/v4/{anything:.*}", http.HandlerFunc(Handle404))
 
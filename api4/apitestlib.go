@@ -20,8 +20,6 @@ import (
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
-
-	s3 "github.com/minio/minio-go"
 )
 
 type TestHelper struct {
@@ -354,6 +352,10 @@ func GenerateTestChannelName() string {
 	return "fakechannel" + model.NewRandomString(10)
 }
 
+func GenerateTestAppName() string {
+	return "fakeoauthapp" + model.NewRandomString(10)
+}
+
 func GenerateTestId() string {
 	return model.NewId()
 }
@@ -397,6 +399,15 @@ func CheckNoError(t *testing.T, resp *model.Response) {
 	}
 }
 
+func CheckCreatedStatus(t *testing.T, resp *model.Response) {
+	if resp.StatusCode != http.StatusCreated {
+		debug.PrintStack()
+		t.Log("actual: " + strconv.Itoa(resp.StatusCode))
+		t.Log("expected: " + strconv.Itoa(http.StatusCreated))
+		t.Fatal("wrong status code")
+	}
+}
+
 func CheckForbiddenStatus(t *testing.T, resp *model.Response) {
 	if resp.Error == nil {
 		debug.PrintStack()
@@ -527,46 +538,19 @@ func readTestFile(name string) ([]byte, error) {
 }
 
 func cleanupTestFile(info *model.FileInfo) error {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
-		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
-		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
-		secure := *utils.Cfg.FileSettings.AmazonS3SSL
-		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
-		if err != nil {
-			return err
-		}
-		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
-		if err := s3Clnt.RemoveObject(bucket, info.Path); err != nil {
-			return err
-		}
-
-		if info.ThumbnailPath != "" {
-			if err := s3Clnt.RemoveObject(bucket, info.ThumbnailPath); err != nil {
-				return err
-			}
-		}
+	if err := app.RemoveFile(info.Path); err != nil {
+		return err
+	}
 
-		if info.PreviewPath != "" {
-			if err := s3Clnt.RemoveObject(bucket, info.PreviewPath); err != nil {
-				return err
-			}
-		}
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if err := os.Remove(utils.Cfg.FileSettings.Directory + info.Path); err != nil {
+	if info.ThumbnailPath != "" {
+		if err := app.RemoveFile(info.ThumbnailPath); err != nil {
 			return err
 		}
+	}
 
-		if info.ThumbnailPath != "" {
-			if err := os.Remove(utils.Cfg.FileSettings.Directory + info.ThumbnailPath); err != nil {
-				return err
-			}
-		}
-
-		if info.PreviewPath != "" {
-			if err := os.Remove(utils.Cfg.FileSettings.Directory + info.PreviewPath); err != nil {
-				return err
-			}
+	if info.PreviewPath != "" {
+		if err := app.RemoveFile(info.PreviewPath); err != nil {
+			return err
 		}
 	}
 
@@ -0,0 +1,151 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitBot() {
+	l4g.Debug(utils.T("api.bot.init.debug"))
+
+	BaseRoutes.Bots.Handle("", ApiSessionRequired(createBot)).Methods("POST")
+	BaseRoutes.Bots.Handle("", ApiSessionRequired(getBots)).Methods("GET")
+	BaseRoutes.Bot.Handle("", ApiSessionRequired(getBot)).Methods("GET")
+	BaseRoutes.Bot.Handle("/disable", ApiSessionRequired(disableBot)).Methods("POST")
+	BaseRoutes.Bot.Handle("/enable", ApiSessionRequired(enableBot)).Methods("POST")
+	BaseRoutes.Bot.Handle("/assign/{user_id:[A-Za-z0-9]+}", ApiSessionRequired(assignBot)).Methods("POST")
+}
+
+func createBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	bot := model.UserFromJson(r.Body)
+	if bot == nil {
+		c.SetInvalidParam("bot")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_CREATE_BOT) {
+		c.SetPermissionError(model.PERMISSION_CREATE_BOT)
+		return
+	}
+
+	if rbot, err := app.CreateBot(bot, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("bot_user_id=" + rbot.Id)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rbot.ToJson()))
+	}
+}
+
+func getBots(c *Context, w http.ResponseWriter, r *http.Request) {
+	includeDeleted := r.URL.Query().Get("include_deleted") == "true"
+
+	// users that can't manage every bot only see the ones they own
+	ownerId := ""
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_OTHERS_BOTS) {
+		if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_BOTS) {
+			c.SetPermissionError(model.PERMISSION_MANAGE_BOTS)
+			return
+		}
+		ownerId = c.Session.UserId
+	}
+
+	if bots, err := app.GetBotsPage(ownerId, includeDeleted, c.Params.Page, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		for _, bot := range bots {
+			app.SanitizeProfile(bot, c.IsSystemAdmin())
+		}
+		w.Write([]byte(model.UserListToJson(bots)))
+	}
+}
+
+func getBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	bot := getBotForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	app.SanitizeProfile(bot, c.IsSystemAdmin())
+	w.Write([]byte(bot.ToJson()))
+}
+
+func disableBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	updateBotActive(c, w, false)
+}
+
+func enableBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	updateBotActive(c, w, true)
+}
+
+func updateBotActive(c *Context, w http.ResponseWriter, active bool) {
+	bot := getBotForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if rbot, err := app.UpdateBotActive(bot, active); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("bot_user_id=" + rbot.Id)
+		app.SanitizeProfile(rbot, c.IsSystemAdmin())
+		w.Write([]byte(rbot.ToJson()))
+	}
+}
+
+func assignBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	bot := getBotForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	// giving a bot away to somebody else requires being able to manage every bot
+	if c.Params.UserId != c.Session.UserId && !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_OTHERS_BOTS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_OTHERS_BOTS)
+		return
+	}
+
+	if rbot, err := app.AssignBot(bot, c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("bot_user_id=" + rbot.Id + " owner_id=" + rbot.BotOwnerId)
+		w.Write([]byte(rbot.ToJson()))
+	}
+}
+
+// getBotForSession loads the bot in the URL and checks that the session can manage it.
+func getBotForSession(c *Context) *model.User {
+	c.RequireBotUserId()
+	if c.Err != nil {
+		return nil
+	}
+
+	bot, err := app.GetBot(c.Params.BotUserId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if !app.SessionHasPermissionToBot(c.Session, bot) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_BOTS)
+		return nil
+	}
+
+	return bot
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestCreateBot(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableOnlyAdminIntegrations := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = enableOnlyAdminIntegrations
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = true
+	utils.SetDefaultRolesBasedOnConfig()
+
+	bot := &model.User{Username: "bot" + model.NewId()}
+
+	_, resp := Client.CreateBot(bot)
+	CheckForbiddenStatus(t, resp)
+
+	rbot, resp := th.SystemAdminClient.CreateBot(bot)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if !rbot.IsBot {
+		t.Fatal("should be a bot")
+	}
+
+	if rbot.BotOwnerId != th.SystemAdminUser.Id {
+		t.Fatal("bot should be owned by its creator")
+	}
+
+	_, resp = th.SystemAdminClient.CreateBot(bot)
+	CheckBadRequestStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	rbot, resp = Client.CreateBot(&model.User{Username: "bot" + model.NewId()})
+	CheckNoError(t, resp)
+
+	if rbot.BotOwnerId != th.BasicUser.Id {
+		t.Fatal("bot should be owned by its creator")
+	}
+
+	_, resp = Client.Login(rbot.Username, "password")
+	CheckUnauthorizedStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.CreateBot(&model.User{Username: "bot" + model.NewId()})
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetBots(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableOnlyAdminIntegrations := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = enableOnlyAdminIntegrations
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	bot1, resp := Client.CreateBot(&model.User{Username: "bot" + model.NewId()})
+	CheckNoError(t, resp)
+
+	bot2, resp := th.SystemAdminClient.CreateBot(&model.User{Username: "bot" + model.NewId()})
+	CheckNoError(t, resp)
+
+	bots, resp := Client.GetBots(0, 100, false)
+	CheckNoError(t, resp)
+
+	if len(bots) != 1 || bots[0].Id != bot1.Id {
+		t.Fatal("should only get the bots the user owns")
+	}
+
+	bots, resp = th.SystemAdminClient.GetBots(0, 100, false)
+	CheckNoError(t, resp)
+
+	found := map[string]bool{}
+	for _, bot := range bots {
+		found[bot.Id] = true
+	}
+
+	if !found[bot1.Id] || !found[bot2.Id] {
+		t.Fatal("system admin should get every bot")
+	}
+
+	_, resp = Client.GetBot(bot1.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetBot(bot2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetBot(th.BasicUser.Id)
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.GetBot("junk")
+	CheckBadRequestStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = true
+	utils.SetDefaultRolesBasedOnConfig()
+
+	_, resp = Client.GetBots(0, 100, false)
+	CheckForbiddenStatus(t, resp)
+}
+
+func TestDisableBot(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableOnlyAdminIntegrations := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = enableOnlyAdminIntegrations
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	bot, resp := Client.CreateBot(&model.User{Username: "bot" + model.NewId()})
+	CheckNoError(t, resp)
+
+	th.LoginBasic2()
+	_, resp = Client.DisableBot(bot.Id)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginBasic()
+	rbot, resp := Client.DisableBot(bot.Id)
+	CheckNoError(t, resp)
+
+	if rbot.DeleteAt == 0 {
+		t.Fatal("bot should be disabled")
+	}
+
+	bots, resp := Client.GetBots(0, 100, false)
+	CheckNoError(t, resp)
+
+	if len(bots) != 0 {
+		t.Fatal("disabled bots shouldn't be returned")
+	}
+
+	bots, resp = Client.GetBots(0, 100, true)
+	CheckNoError(t, resp)
+
+	if len(bots) != 1 {
+		t.Fatal("disabled bots should be returned when asked for")
+	}
+
+	hook := &model.IncomingWebhook{ChannelId: th.BasicChannel.Id, BotUserId: bot.Id}
+	_, resp = Client.CreateIncomingWebhook(hook)
+	CheckBadRequestStatus(t, resp)
+
+	rbot, resp = Client.EnableBot(bot.Id)
+	CheckNoError(t, resp)
+
+	if rbot.DeleteAt != 0 {
+		t.Fatal("bot should be enabled")
+	}
+
+	_, resp = th.SystemAdminClient.DisableBot(bot.Id)
+	CheckNoError(t, resp)
+}
+
+func TestAssignBot(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableOnlyAdminIntegrations := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = enableOnlyAdminIntegrations
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	bot, resp := Client.CreateBot(&model.User{Username: "bot" + model.NewId()})
+	CheckNoError(t, resp)
+
+	_, resp = Client.AssignBot(bot.Id, th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	rbot, resp := th.SystemAdminClient.AssignBot(bot.Id, th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	if rbot.BotOwnerId != th.BasicUser2.Id {
+		t.Fatal("bot should have a new owner")
+	}
+
+	_, resp = Client.GetBot(bot.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.AssignBot(bot.Id, bot.Id)
+	CheckBadRequestStatus(t, resp)
+}
+
+func TestIncomingWebhookPostsAsBot(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	enableOnlyAdminIntegrations := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+		*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = enableOnlyAdminIntegrations
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	bot, resp := th.SystemAdminClient.CreateBot(&model.User{Username: "bot" + model.NewId()})
+	CheckNoError(t, resp)
+
+	hook := &model.IncomingWebhook{ChannelId: th.BasicChannel.Id, BotUserId: bot.Id}
+	_, resp = Client.CreateIncomingWebhook(hook)
+	CheckForbiddenStatus(t, resp)
+
+	rhook, resp := th.SystemAdminClient.CreateIncomingWebhook(hook)
+	CheckNoError(t, resp)
+
+	if rhook.BotUserId != bot.Id {
+		t.Fatal("hook should post as the bot")
+	}
+}
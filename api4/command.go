@@ -0,0 +1,271 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitCommand() {
+	l4g.Debug(utils.T("api.command.init.debug"))
+
+	BaseRoutes.Commands.Handle("", ApiSessionRequired(createCommand)).Methods("POST")
+	BaseRoutes.Commands.Handle("/execute", ApiSessionRequired(executeCommand)).Methods("POST")
+
+	BaseRoutes.Command.Handle("", ApiSessionRequired(updateCommand)).Methods("PUT")
+	BaseRoutes.Command.Handle("", ApiSessionRequired(deleteCommand)).Methods("DELETE")
+	BaseRoutes.Command.Handle("/regen_token", ApiSessionRequired(regenCommandToken)).Methods("PUT")
+
+	BaseRoutes.CommandsForTeam.Handle("", ApiSessionRequired(listCommands)).Methods("GET")
+	BaseRoutes.CommandsForTeam.Handle("/autocomplete_suggestions", ApiSessionRequired(listCommandAutocompleteSuggestions)).Methods("GET")
+}
+
+func createCommand(c *Context, w http.ResponseWriter, r *http.Request) {
+	cmd := model.CommandFromJson(r.Body)
+	if cmd == nil {
+		c.SetInvalidParam("command")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionToTeam(c.Session, cmd.TeamId, model.PERMISSION_MANAGE_SLASH_COMMANDS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SLASH_COMMANDS)
+		return
+	}
+
+	cmd.CreatorId = c.Session.UserId
+
+	rcmd, err := app.CreateCommand(cmd)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rcmd.ToJson()))
+}
+
+func updateCommand(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireCommandId()
+	if c.Err != nil {
+		return
+	}
+
+	cmd := model.CommandFromJson(r.Body)
+	if cmd == nil || cmd.Id != c.Params.CommandId {
+		c.SetInvalidParam("command")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	oldCmd, err := app.GetCommand(c.Params.CommandId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !canManageCommand(c, oldCmd) {
+		return
+	}
+
+	rcmd, err := app.UpdateCommand(oldCmd, cmd)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	w.Write([]byte(rcmd.ToJson()))
+}
+
+func deleteCommand(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireCommandId()
+	if c.Err != nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	cmd, err := app.GetCommand(c.Params.CommandId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !canManageCommand(c, cmd) {
+		return
+	}
+
+	if err := app.DeleteCommand(cmd.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	ReturnStatusOK(w)
+}
+
+func regenCommandToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireCommandId()
+	if c.Err != nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	cmd, err := app.GetCommand(c.Params.CommandId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !canManageCommand(c, cmd) {
+		return
+	}
+
+	rcmd, err := app.RegenCommandToken(cmd)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	w.Write([]byte(model.MapToJson(map[string]string{"token": rcmd.Token})))
+}
+
+// canManageCommand sets a permission error on the context if the session can't change the command.
+func canManageCommand(c *Context, cmd *model.Command) bool {
+	if !app.SessionHasPermissionToTeam(c.Session, cmd.TeamId, model.PERMISSION_MANAGE_SLASH_COMMANDS) {
+		c.LogAudit("fail - inappropriate permissions")
+		c.SetPermissionError(model.PERMISSION_MANAGE_SLASH_COMMANDS)
+		return false
+	}
+
+	if c.Session.UserId != cmd.CreatorId && !app.SessionHasPermissionToTeam(c.Session, cmd.TeamId, model.PERMISSION_MANAGE_OTHERS_SLASH_COMMANDS) {
+		c.LogAudit("fail - inappropriate permissions")
+		c.SetPermissionError(model.PERMISSION_MANAGE_OTHERS_SLASH_COMMANDS)
+		return false
+	}
+
+	return true
+}
+
+func listCommands(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	customOnly := r.URL.Query().Get("custom_only") == "true"
+
+	var commands []*model.Command
+	var err *model.AppError
+	if customOnly {
+		if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_MANAGE_SLASH_COMMANDS) {
+			c.SetPermissionError(model.PERMISSION_MANAGE_SLASH_COMMANDS)
+			return
+		}
+
+		commands, err = app.ListTeamCommands(c.Params.TeamId)
+	} else {
+		if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_VIEW_TEAM) {
+			c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+			return
+		}
+
+		commands, err = app.ListCommands(c.Params.TeamId, c.T)
+	}
+
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.CommandListToJson(commands)))
+}
+
+func listCommandAutocompleteSuggestions(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	userInput := r.URL.Query().Get("user_input")
+	if userInput == "" {
+		c.SetInvalidParam("user_input")
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_VIEW_TEAM) {
+		c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+		return
+	}
+
+	suggestions, err := app.GetCommandSuggestions(userInput, c.Params.TeamId, c.T)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.SuggestCommandListToJson(suggestions)))
+}
+
+func executeCommand(c *Context, w http.ResponseWriter, r *http.Request) {
+	commandArgs := model.CommandArgsFromJson(r.Body)
+	if commandArgs == nil {
+		c.SetInvalidParam("command_args")
+		return
+	}
+
+	if len(commandArgs.Command) <= 1 || strings.Index(commandArgs.Command, "/") != 0 {
+		c.SetInvalidParam("command")
+		return
+	}
+
+	if len(commandArgs.ChannelId) != 26 {
+		c.SetInvalidParam("channel_id")
+		return
+	}
+
+	if !app.SessionHasPermissionToChannel(c.Session, commandArgs.ChannelId, model.PERMISSION_USE_SLASH_COMMANDS) {
+		c.SetPermissionError(model.PERMISSION_USE_SLASH_COMMANDS)
+		return
+	}
+
+	channel, err := app.GetChannel(commandArgs.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	// direct and group channels don't belong to a team, so the client has to tell us which one it's in
+	if channel.TeamId != "" {
+		commandArgs.TeamId = channel.TeamId
+	} else if !app.SessionHasPermissionToTeam(c.Session, commandArgs.TeamId, model.PERMISSION_VIEW_TEAM) {
+		c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+		return
+	}
+
+	commandArgs.UserId = c.Session.UserId
+	commandArgs.T = c.T
+	commandArgs.Session = c.Session
+	commandArgs.SiteURL = c.GetSiteURL()
+
+	response, err := app.ExecuteCommand(commandArgs)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(response.ToJson()))
+}
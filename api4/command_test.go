@@ -0,0 +1,479 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestCreateCommand(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableCommands := *utils.Cfg.ServiceSettings.EnableCommands
+	enableAdminOnlyIntegrations := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableCommands = &enableCommands
+		utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = &enableAdminOnlyIntegrations
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	*utils.Cfg.ServiceSettings.EnableCommands = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = true
+	utils.SetDefaultRolesBasedOnConfig()
+
+	cmd := &model.Command{
+		TeamId:  th.BasicTeam.Id,
+		URL:     "http://nowhere.com",
+		Method:  model.COMMAND_METHOD_POST,
+		Trigger: "trigger"}
+
+	_, resp := Client.CreateCommand(cmd)
+	CheckForbiddenStatus(t, resp)
+
+	rcmd, resp := th.SystemAdminClient.CreateCommand(cmd)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rcmd.CreatorId != th.SystemAdminUser.Id {
+		t.Fatal("user ids didn't match")
+	}
+
+	if rcmd.TeamId != th.BasicTeam.Id {
+		t.Fatal("team ids didn't match")
+	}
+
+	if rcmd.Token == "" {
+		t.Fatal("should have generated a token")
+	}
+
+	_, resp = th.SystemAdminClient.CreateCommand(cmd)
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.command.duplicate_trigger.app_error")
+
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	cmd.Trigger = "othertrigger"
+	_, resp = Client.CreateCommand(cmd)
+	CheckNoError(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableCommands = false
+	cmd.Trigger = "yetanothertrigger"
+	_, resp = th.SystemAdminClient.CreateCommand(cmd)
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestUpdateCommand(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableCommands := *utils.Cfg.ServiceSettings.EnableCommands
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableCommands = &enableCommands
+	}()
+	*utils.Cfg.ServiceSettings.EnableCommands = true
+
+	cmd, resp := th.SystemAdminClient.CreateCommand(&model.Command{
+		TeamId:  th.BasicTeam.Id,
+		URL:     "http://nowhere.com",
+		Method:  model.COMMAND_METHOD_POST,
+		Trigger: "trigger"})
+	CheckNoError(t, resp)
+
+	cmd.Trigger = "newtrigger"
+	cmd.Token = "tokenthatshouldbeignored"
+
+	rcmd, resp := th.SystemAdminClient.UpdateCommand(cmd)
+	CheckNoError(t, resp)
+
+	if rcmd.Trigger != "newtrigger" {
+		t.Fatal("trigger should have been updated")
+	}
+
+	if rcmd.Token == cmd.Token {
+		t.Fatal("token should not have been updated")
+	}
+
+	_, resp = Client.UpdateCommand(cmd)
+	CheckForbiddenStatus(t, resp)
+
+	cmd.Id = model.NewId()
+	_, resp = th.SystemAdminClient.UpdateCommand(cmd)
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestDeleteCommand(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableCommands := *utils.Cfg.ServiceSettings.EnableCommands
+	enableAdminOnlyIntegrations := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableCommands = &enableCommands
+		utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = &enableAdminOnlyIntegrations
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	*utils.Cfg.ServiceSettings.EnableCommands = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	cmd, resp := th.SystemAdminClient.CreateCommand(&model.Command{
+		TeamId:  th.BasicTeam.Id,
+		URL:     "http://nowhere.com",
+		Method:  model.COMMAND_METHOD_POST,
+		Trigger: "trigger"})
+	CheckNoError(t, resp)
+
+	// a regular user can manage their own commands but not somebody else's
+	_, resp = Client.DeleteCommand(cmd.Id)
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := th.SystemAdminClient.DeleteCommand(cmd.Id)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have returned true")
+	}
+
+	_, resp = th.SystemAdminClient.DeleteCommand(cmd.Id)
+	CheckNotFoundStatus(t, resp)
+
+	cmd, resp = Client.CreateCommand(&model.Command{
+		TeamId:  th.BasicTeam.Id,
+		URL:     "http://nowhere.com",
+		Method:  model.COMMAND_METHOD_POST,
+		Trigger: "trigger2"})
+	CheckNoError(t, resp)
+
+	_, resp = Client.DeleteCommand(cmd.Id)
+	CheckNoError(t, resp)
+}
+
+func TestListCommands(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableCommands := *utils.Cfg.ServiceSettings.EnableCommands
+	enableAdminOnlyIntegrations := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableCommands = &enableCommands
+		utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = &enableAdminOnlyIntegrations
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	*utils.Cfg.ServiceSettings.EnableCommands = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = true
+	utils.SetDefaultRolesBasedOnConfig()
+
+	custom, resp := th.SystemAdminClient.CreateCommand(&model.Command{
+		TeamId:       th.BasicTeam.Id,
+		URL:          "http://nowhere.com",
+		Method:       model.COMMAND_METHOD_POST,
+		Trigger:      "custom_command",
+		AutoComplete: true})
+	CheckNoError(t, resp)
+
+	commands, resp := Client.ListCommands(th.BasicTeam.Id, false)
+	CheckNoError(t, resp)
+
+	foundEcho := false
+	foundCustom := false
+	for _, command := range commands {
+		if command.Trigger == "echo" {
+			foundEcho = true
+		}
+
+		if command.Id == custom.Id {
+			foundCustom = true
+
+			if command.Token != "" {
+				t.Fatal("token should have been sanitized")
+			}
+		}
+	}
+
+	if !foundEcho {
+		t.Fatal("couldn't find echo command")
+	}
+
+	if !foundCustom {
+		t.Fatal("couldn't find custom command")
+	}
+
+	_, resp = Client.ListCommands(th.BasicTeam.Id, true)
+	CheckForbiddenStatus(t, resp)
+
+	commands, resp = th.SystemAdminClient.ListCommands(th.BasicTeam.Id, true)
+	CheckNoError(t, resp)
+
+	if len(commands) != 1 || commands[0].Id != custom.Id {
+		t.Fatal("should only have returned the custom command")
+	}
+
+	otherTeam := th.CreateTeamWithClient(th.SystemAdminClient)
+	_, resp = Client.ListCommands(otherTeam.Id, false)
+	CheckForbiddenStatus(t, resp)
+}
+
+func TestRegenCommandToken(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableCommands := *utils.Cfg.ServiceSettings.EnableCommands
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableCommands = &enableCommands
+	}()
+	*utils.Cfg.ServiceSettings.EnableCommands = true
+
+	cmd, resp := th.SystemAdminClient.CreateCommand(&model.Command{
+		TeamId:  th.BasicTeam.Id,
+		URL:     "http://nowhere.com",
+		Method:  model.COMMAND_METHOD_POST,
+		Trigger: "trigger"})
+	CheckNoError(t, resp)
+
+	token, resp := th.SystemAdminClient.RegenCommandToken(cmd.Id)
+	CheckNoError(t, resp)
+
+	if token == "" || token == cmd.Token {
+		t.Fatal("should have returned a new token")
+	}
+
+	_, resp = Client.RegenCommandToken(cmd.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.RegenCommandToken(model.NewId())
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestExecuteCommand(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	response, resp := Client.ExecuteCommand(th.BasicChannel.Id, "/echo hello")
+	CheckNoError(t, resp)
+
+	if response == nil {
+		t.Fatal("should have returned a response")
+	}
+
+	_, resp = Client.ExecuteCommand(th.BasicChannel.Id, "echo hello")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.ExecuteCommand("junk", "/echo hello")
+	CheckBadRequestStatus(t, resp)
+
+	privateChannel := th.CreateChannelWithClient(th.SystemAdminClient, model.CHANNEL_PRIVATE)
+	_, resp = Client.ExecuteCommand(privateChannel.Id, "/echo hello")
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.ExecuteCommand(th.BasicChannel.Id, "/echo hello")
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestExecuteCustomCommand(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableCommands := *utils.Cfg.ServiceSettings.EnableCommands
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableCommands = &enableCommands
+	}()
+	*utils.Cfg.ServiceSettings.EnableCommands = true
+
+	signingSecret := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := <-signingSecret
+		signingSecret <- secret
+
+		if _, err := model.VerifyRequestSignature(r, secret); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text": "signed"}`))
+	}))
+	defer ts.Close()
+
+	cmd, resp := th.SystemAdminClient.CreateCommand(&model.Command{
+		TeamId:  th.BasicTeam.Id,
+		URL:     ts.URL,
+		Method:  model.COMMAND_METHOD_POST,
+		Trigger: "signed"})
+	CheckNoError(t, resp)
+
+	if len(cmd.SigningSecret) != model.SIGNING_SECRET_LENGTH {
+		t.Fatal("should have generated a signing secret")
+	}
+
+	signingSecret <- cmd.SigningSecret
+
+	_, resp = Client.ExecuteCommand(th.BasicChannel.Id, "/signed hello")
+	CheckNoError(t, resp)
+
+	// the integration rejects requests signed with the wrong secret
+	<-signingSecret
+	signingSecret <- model.NewSigningSecret()
+
+	_, resp = Client.ExecuteCommand(th.BasicChannel.Id, "/signed hello")
+	CheckInternalErrorStatus(t, resp)
+}
+
+func TestExecuteCustomCommandWithArguments(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableCommands := *utils.Cfg.ServiceSettings.EnableCommands
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableCommands = &enableCommands
+	}()
+	*utils.Cfg.ServiceSettings.EnableCommands = true
+
+	values := make(chan url.Values, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		values <- r.Form
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text": "parsed"}`))
+	}))
+	defer ts.Close()
+
+	_, resp := th.SystemAdminClient.CreateCommand(&model.Command{
+		TeamId:  th.BasicTeam.Id,
+		URL:     ts.URL,
+		Method:  model.COMMAND_METHOD_POST,
+		Trigger: "parsed",
+		AutocompleteData: &model.CommandAutocompleteData{
+			Subcommands: []*model.CommandAutocompleteData{
+				{
+					Trigger: "notify",
+					Arguments: []*model.CommandArgument{
+						{Name: "user", Type: model.COMMAND_ARGUMENT_TYPE_USER, Required: true},
+						{Name: "priority", Type: model.COMMAND_ARGUMENT_TYPE_ENUM, Options: []string{"low", "high"}},
+					},
+				},
+			},
+		}})
+	CheckNoError(t, resp)
+
+	_, resp = Client.ExecuteCommand(th.BasicChannel.Id, "/parsed notify @"+th.BasicUser2.Username+" high")
+	CheckNoError(t, resp)
+
+	form := <-values
+	if form.Get("subcommand") != "notify" || form.Get("arg_priority") != "high" {
+		t.Fatal("should have sent the parsed arguments", form)
+	}
+
+	if form.Get("arg_user") != th.BasicUser2.Username || form.Get("arg_user_id") != th.BasicUser2.Id {
+		t.Fatal("should have sent the user and their id", form)
+	}
+
+	// the integration isn't called when the arguments don't match
+	response, resp := Client.ExecuteCommand(th.BasicChannel.Id, "/parsed notify @"+th.BasicUser2.Username+" urgent")
+	CheckNoError(t, resp)
+
+	if response.ResponseType != model.COMMAND_RESPONSE_TYPE_EPHEMERAL || response.Text == "" {
+		t.Fatal("should have explained the error")
+	}
+
+	select {
+	case <-values:
+		t.Fatal("shouldn't have called the integration")
+	default:
+	}
+}
+
+func TestListCommandAutocompleteSuggestions(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableCommands := *utils.Cfg.ServiceSettings.EnableCommands
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableCommands = &enableCommands
+	}()
+	*utils.Cfg.ServiceSettings.EnableCommands = true
+
+	_, resp := th.SystemAdminClient.CreateCommand(&model.Command{
+		TeamId:       th.BasicTeam.Id,
+		URL:          "http://nowhere.com",
+		Method:       model.COMMAND_METHOD_POST,
+		Trigger:      "deploy",
+		AutoComplete: true,
+		AutocompleteData: &model.CommandAutocompleteData{
+			Arguments: []*model.CommandArgument{
+				{Name: "environment", Type: model.COMMAND_ARGUMENT_TYPE_ENUM, Required: true, Options: []string{"staging", "production"}},
+				{Name: "notes", Type: model.COMMAND_ARGUMENT_TYPE_TEXT, HelpText: "Release notes"},
+			},
+		}})
+	CheckNoError(t, resp)
+
+	suggestions, resp := Client.GetCommandAutocompleteSuggestions("/ms", th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	if len(suggestions) != 1 || suggestions[0].Suggestion != "/msg" {
+		t.Fatal("should have suggested /msg", suggestions)
+	}
+
+	suggestions, resp = Client.GetCommandAutocompleteSuggestions("/deploy pro", th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	if len(suggestions) != 1 || suggestions[0].Suggestion != "/deploy production" {
+		t.Fatal("should have suggested the production option", suggestions)
+	}
+
+	suggestions, resp = Client.GetCommandAutocompleteSuggestions("/deploy staging fix", th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	if len(suggestions) != 1 || suggestions[0].Description != "Release notes" {
+		t.Fatal("should have described the notes", suggestions)
+	}
+
+	suggestions, resp = Client.GetCommandAutocompleteSuggestions("/msg @"+th.BasicUser2.Username[:5], th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	found := false
+	for _, suggestion := range suggestions {
+		if suggestion.Suggestion == "/msg @"+th.BasicUser2.Username {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should have suggested the user", suggestions)
+	}
+
+	suggestions, resp = Client.GetCommandAutocompleteSuggestions("/join ~"+th.BasicChannel.Name, th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	if len(suggestions) == 0 || suggestions[0].Suggestion != "/join ~"+th.BasicChannel.Name {
+		t.Fatal("should have suggested the channel", suggestions)
+	}
+
+	_, resp = Client.GetCommandAutocompleteSuggestions("", th.BasicTeam.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetCommandAutocompleteSuggestions("/ms", model.NewId())
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetCommandAutocompleteSuggestions("/ms", th.BasicTeam.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -164,6 +164,10 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	c.Path = r.URL.Path
 
+	if c.Err == nil && c.Session.IsUserAccessToken() {
+		c.LogAudit("method=" + r.Method + " user_access_token_id=" + c.Session.Props[model.SESSION_PROP_USER_ACCESS_TOKEN_ID])
+	}
+
 	if c.Err == nil && h.requireSession {
 		c.SessionRequired()
 	}
@@ -259,8 +263,8 @@ func (c *Context) MfaRequired() {
 		return
 	}
 
-	// OAuth integrations are excepted
-	if c.Session.IsOAuth {
+	// OAuth integrations and user access tokens are excepted
+	if c.Session.IsOAuth || c.Session.IsUserAccessToken() {
 		return
 	}
 
@@ -407,6 +411,61 @@ func (c *Context) RequireReportId() *Context {
 	return c
 }
 
+func (c *Context) RequireJobId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.JobId) != 26 {
+		c.SetInvalidUrlParam("job_id")
+	}
+	return c
+}
+
+func (c *Context) RequireTokenId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.TokenId) != 26 {
+		c.SetInvalidUrlParam("token_id")
+	}
+	return c
+}
+
+func (c *Context) RequireBotUserId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.BotUserId) != 26 {
+		c.SetInvalidUrlParam("bot_user_id")
+	}
+	return c
+}
+
+func (c *Context) RequireScheduledPostId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.ScheduledPostId) != 26 {
+		c.SetInvalidUrlParam("scheduled_post_id")
+	}
+	return c
+}
+
+func (c *Context) RequireJobType() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.JobType) == 0 || len(c.Params.JobType) > 32 {
+		c.SetInvalidUrlParam("job_type")
+	}
+	return c
+}
+
 func (c *Context) RequireTeamName() *Context {
 	if c.Err != nil {
 		return c
@@ -478,3 +537,75 @@ func (c *Context) RequireHookId() *Context {
 
 	return c
 }
+
+func (c *Context) RequireDeliveryId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.DeliveryId) != 26 {
+		c.SetInvalidUrlParam("delivery_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireCommandId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.CommandId) != 26 {
+		c.SetInvalidUrlParam("command_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireEmojiId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.EmojiId) != 26 {
+		c.SetInvalidUrlParam("emoji_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireEmojiName() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.EmojiName) == 0 || len(c.Params.EmojiName) > model.EMOJI_NAME_MAX_LENGTH {
+		c.SetInvalidUrlParam("emoji_name")
+	}
+
+	return c
+}
+
+func (c *Context) RequireActionId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.ActionId) == 0 || len(c.Params.ActionId) > model.SLACK_ATTACHMENT_ACTION_ID_MAX_LENGTH {
+		c.SetInvalidUrlParam("action_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireAppId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.AppId) != 26 {
+		c.SetInvalidUrlParam("app_id")
+	}
+
+	return c
+}
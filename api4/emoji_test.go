@@ -0,0 +1,286 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestCreateEmoji(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	EnableCustomEmoji := *utils.Cfg.ServiceSettings.EnableCustomEmoji
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableCustomEmoji = EnableCustomEmoji
+	}()
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = false
+
+	emoji := &model.Emoji{
+		CreatorId: th.BasicUser.Id,
+		Name:      model.NewId(),
+	}
+
+	_, resp := Client.CreateEmoji(emoji, createTestGif(t, 10, 10), "image.gif")
+	CheckNotImplementedStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = true
+
+	newEmoji, resp := Client.CreateEmoji(emoji, createTestGif(t, 10, 10), "image.gif")
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if newEmoji.Name != emoji.Name {
+		t.Fatal("create with wrong name")
+	}
+
+	// try to create an emoji with a duplicate name
+	_, resp = Client.CreateEmoji(emoji, createTestGif(t, 10, 10), "image.gif")
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.emoji.create.duplicate.app_error")
+
+	// try to create a valid animated gif emoji
+	emoji = &model.Emoji{
+		CreatorId: th.BasicUser.Id,
+		Name:      model.NewId(),
+	}
+
+	_, resp = Client.CreateEmoji(emoji, createTestAnimatedGif(t, 10, 10, 10), "image.gif")
+	CheckNoError(t, resp)
+
+	// larger images are resized rather than rejected
+	emoji = &model.Emoji{
+		CreatorId: th.BasicUser.Id,
+		Name:      model.NewId(),
+	}
+
+	_, resp = Client.CreateEmoji(emoji, createTestGif(t, 1000, 10), "image.gif")
+	CheckNoError(t, resp)
+
+	// try to create an emoji that's too large
+	emoji = &model.Emoji{
+		CreatorId: th.BasicUser.Id,
+		Name:      model.NewId(),
+	}
+
+	_, resp = Client.CreateEmoji(emoji, createTestGif(t, 10000, 10000), "image.gif")
+	if resp.Error == nil {
+		t.Fatal("should fail - emoji is too big")
+	}
+
+	// try to create an emoji with data that isn't an image
+	emoji = &model.Emoji{
+		CreatorId: th.BasicUser.Id,
+		Name:      model.NewId(),
+	}
+
+	_, resp = Client.CreateEmoji(emoji, make([]byte, 100, 100), "image.gif")
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.emoji.upload.image.app_error")
+
+	// try to create an emoji as another user
+	emoji = &model.Emoji{
+		CreatorId: th.BasicUser2.Id,
+		Name:      model.NewId(),
+	}
+
+	_, resp = Client.CreateEmoji(emoji, createTestGif(t, 10, 10), "image.gif")
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetEmojiList(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	EnableCustomEmoji := *utils.Cfg.ServiceSettings.EnableCustomEmoji
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableCustomEmoji = EnableCustomEmoji
+	}()
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = true
+
+	emojis := []*model.Emoji{
+		{
+			CreatorId: th.BasicUser.Id,
+			Name:      model.NewId(),
+		},
+		{
+			CreatorId: th.BasicUser.Id,
+			Name:      model.NewId(),
+		},
+	}
+
+	for idx, emoji := range emojis {
+		newEmoji, resp := Client.CreateEmoji(emoji, createTestGif(t, 10, 10), "image.gif")
+		CheckNoError(t, resp)
+		emojis[idx] = newEmoji
+	}
+
+	_, resp := Client.DeleteEmoji(emojis[1].Id)
+	CheckNoError(t, resp)
+
+	listEmoji, resp := Client.GetEmojiList()
+	CheckNoError(t, resp)
+
+	found := map[string]bool{}
+	for _, emoji := range listEmoji {
+		found[emoji.Id] = true
+	}
+
+	if !found[emojis[0].Id] {
+		t.Fatal("failed to get emoji")
+	}
+
+	if found[emojis[1].Id] {
+		t.Fatal("should not get a deleted emoji")
+	}
+
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = false
+	_, resp = Client.GetEmojiList()
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestDeleteEmoji(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	EnableCustomEmoji := *utils.Cfg.ServiceSettings.EnableCustomEmoji
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableCustomEmoji = EnableCustomEmoji
+	}()
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = true
+
+	newEmoji, resp := Client.CreateEmoji(&model.Emoji{
+		CreatorId: th.BasicUser.Id,
+		Name:      model.NewId(),
+	}, createTestGif(t, 10, 10), "image.gif")
+	CheckNoError(t, resp)
+
+	th.LoginBasic2()
+	_, resp = Client.DeleteEmoji(newEmoji.Id)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginBasic()
+	ok, resp := Client.DeleteEmoji(newEmoji.Id)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have returned true")
+	}
+
+	_, resp = Client.GetEmoji(newEmoji.Id)
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.DeleteEmoji(newEmoji.Id)
+	CheckNotFoundStatus(t, resp)
+
+	// system admins can delete anybody's emoji
+	newEmoji, resp = Client.CreateEmoji(&model.Emoji{
+		CreatorId: th.BasicUser.Id,
+		Name:      model.NewId(),
+	}, createTestGif(t, 10, 10), "image.gif")
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteEmoji(newEmoji.Id)
+	CheckNoError(t, resp)
+}
+
+func TestGetEmoji(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	EnableCustomEmoji := *utils.Cfg.ServiceSettings.EnableCustomEmoji
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableCustomEmoji = EnableCustomEmoji
+	}()
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = true
+
+	newEmoji, resp := Client.CreateEmoji(&model.Emoji{
+		CreatorId: th.BasicUser.Id,
+		Name:      model.NewId(),
+	}, createTestGif(t, 10, 10), "image.gif")
+	CheckNoError(t, resp)
+
+	emoji, resp := Client.GetEmoji(newEmoji.Id)
+	CheckNoError(t, resp)
+
+	if emoji.Id != newEmoji.Id || emoji.Name != newEmoji.Name {
+		t.Fatal("wrong emoji was returned")
+	}
+
+	_, resp = Client.GetEmoji(model.NewId())
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestGetEmojiImage(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	EnableCustomEmoji := *utils.Cfg.ServiceSettings.EnableCustomEmoji
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableCustomEmoji = EnableCustomEmoji
+	}()
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = true
+
+	newEmoji, resp := Client.CreateEmoji(&model.Emoji{
+		CreatorId: th.BasicUser.Id,
+		Name:      model.NewId(),
+	}, createTestAnimatedGif(t, 10, 10, 10), "image.gif")
+	CheckNoError(t, resp)
+
+	data, resp := Client.GetEmojiImage(newEmoji.Id)
+	CheckNoError(t, resp)
+
+	if _, imageType, err := image.DecodeConfig(bytes.NewReader(data)); err != nil {
+		t.Fatalf("unable to identify received image: %v", err.Error())
+	} else if imageType != "gif" {
+		t.Fatal("should've received gif data")
+	}
+
+	_, resp = Client.GetEmojiImage(model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = false
+	_, resp = Client.GetEmojiImage(newEmoji.Id)
+	CheckNotImplementedStatus(t, resp)
+}
+
+func createTestGif(t *testing.T, width int, height int) []byte {
+	var buffer bytes.Buffer
+
+	if err := gif.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatalf("failed to create gif: %v", err.Error())
+	}
+
+	return buffer.Bytes()
+}
+
+func createTestAnimatedGif(t *testing.T, width int, height int, frames int) []byte {
+	var buffer bytes.Buffer
+
+	img := gif.GIF{
+		Image: make([]*image.Paletted, frames, frames),
+		Delay: make([]int, frames, frames),
+	}
+	for i := 0; i < frames; i++ {
+		img.Image[i] = image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.Black})
+		img.Delay[i] = 0
+	}
+	if err := gif.EncodeAll(&buffer, &img); err != nil {
+		t.Fatalf("failed to create animated gif: %v", err.Error())
+	}
+
+	return buffer.Bytes()
+}
@@ -4,8 +4,11 @@
 package api4
 
 import (
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
 	"strconv"
 
 	l4g "github.com/alecthomas/log4go"
@@ -33,43 +36,134 @@ func InitFile() {
 }
 
 func uploadFile(c *Context, w http.ResponseWriter, r *http.Request) {
-	if r.ContentLength > *utils.Cfg.FileSettings.MaxFileSize {
-		c.Err = model.NewLocAppError("uploadFile", "api.file.upload_file.too_large.app_error", nil, "")
-		c.Err.StatusCode = http.StatusRequestEntityTooLarge
+	if len(utils.Cfg.FileSettings.DriverName) == 0 {
+		c.Err = model.NewAppError("uploadFile", "api.file.upload_file.storage.app_error", nil, "", http.StatusNotImplemented)
 		return
 	}
 
-	if err := r.ParseMultipartForm(*utils.Cfg.FileSettings.MaxFileSize); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if r.ContentLength > *utils.Cfg.FileSettings.MaxFileSize {
+		c.Err = model.NewLocAppError("uploadFile", "api.file.upload_file.too_large.app_error", nil, "")
+		c.Err.StatusCode = http.StatusRequestEntityTooLarge
 		return
 	}
 
-	m := r.MultipartForm
+	r.Body = http.MaxBytesReader(w, r.Body, *utils.Cfg.FileSettings.MaxFileSize)
 
-	props := m.Value
-	if len(props["channel_id"]) == 0 {
-		c.SetInvalidParam("channel_id")
-		return
-	}
-	channelId := props["channel_id"][0]
-	if len(channelId) == 0 {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		c.Err = model.NewAppError("uploadFile", "api.file.upload_file.bad_parse.app_error", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resStruct := &model.FileUploadResponse{
+		FileInfos: []*model.FileInfo{},
+		ClientIds: []string{},
+	}
+
+	// Files are streamed straight to the file backend once the channel has been checked, but any that arrive before
+	// the channel_id field have to be spooled to disk until we know where they're going
+	channelId := ""
+	spooled := []*os.File{}
+	spooledNames := []string{}
+	defer func() {
+		for _, file := range spooled {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			c.Err = model.NewAppError("uploadFile", "api.file.upload_file.bad_parse.app_error", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch part.FormName() {
+		case "channel_id":
+			if channelId != "" {
+				c.SetInvalidParam("channel_id")
+				return
+			}
+
+			value, _ := ioutil.ReadAll(io.LimitReader(part, 64))
+			channelId = string(value)
+			if len(channelId) != 26 {
+				c.SetInvalidParam("channel_id")
+				return
+			}
+
+			if !app.SessionHasPermissionToChannel(c.Session, channelId, model.PERMISSION_UPLOAD_FILE) {
+				c.SetPermissionError(model.PERMISSION_UPLOAD_FILE)
+				return
+			}
+
+			for i, file := range spooled {
+				if _, err := file.Seek(0, io.SeekStart); err != nil {
+					c.Err = model.NewAppError("uploadFile", "api.file.upload_file.spool.app_error", nil, err.Error(), http.StatusInternalServerError)
+					return
+				}
+
+				info, err := app.DoUploadFile(FILE_TEAM_ID, channelId, c.Session.UserId, spooledNames[i], file)
+				if err != nil {
+					c.Err = err
+					return
+				}
+
+				resStruct.FileInfos = append(resStruct.FileInfos, info)
+			}
+		case "client_ids":
+			value, _ := ioutil.ReadAll(io.LimitReader(part, 1024))
+			resStruct.ClientIds = append(resStruct.ClientIds, string(value))
+		case "files":
+			if channelId == "" {
+				file, err := spoolFilePart(part)
+				if err != nil {
+					c.Err = err
+					return
+				}
+
+				spooled = append(spooled, file)
+				spooledNames = append(spooledNames, part.FileName())
+				continue
+			}
+
+			info, err := app.DoUploadFile(FILE_TEAM_ID, channelId, c.Session.UserId, part.FileName(), part)
+			if err != nil {
+				c.Err = err
+				return
+			}
+
+			resStruct.FileInfos = append(resStruct.FileInfos, info)
+		}
+	}
+
+	if channelId == "" {
 		c.SetInvalidParam("channel_id")
 		return
 	}
 
-	if !app.SessionHasPermissionToChannel(c.Session, channelId, model.PERMISSION_UPLOAD_FILE) {
-		c.SetPermissionError(model.PERMISSION_UPLOAD_FILE)
-		return
-	}
+	app.HandleImages(resStruct.FileInfos)
 
-	resStruct, err := app.UploadFiles(FILE_TEAM_ID, channelId, c.Session.UserId, m.File["files"], m.Value["client_ids"])
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(resStruct.ToJson()))
+}
+
+func spoolFilePart(part io.Reader) (*os.File, *model.AppError) {
+	file, err := ioutil.TempFile("", "mattermost-upload-")
 	if err != nil {
-		c.Err = err
-		return
+		return nil, model.NewAppError("uploadFile", "api.file.upload_file.spool.app_error", nil, err.Error(), http.StatusInternalServerError)
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(resStruct.ToJson()))
+	if _, err := io.Copy(file, part); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, model.NewAppError("uploadFile", "api.file.upload_file.spool.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return file, nil
 }
 
 func getFile(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -89,12 +183,15 @@ func getFile(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if data, err := app.ReadFile(info.Path); err != nil {
+	if fileReader, err := app.FileReader(info.Path); err != nil {
 		c.Err = err
 		c.Err.StatusCode = http.StatusNotFound
-	} else if err := writeFileResponse(info.Name, info.MimeType, data, w, r); err != nil {
-		c.Err = err
-		return
+	} else {
+		defer fileReader.Close()
+		if err := writeFileResponse(info.Name, info.MimeType, info.Size, fileReader, w, r); err != nil {
+			c.Err = err
+			return
+		}
 	}
 }
 
@@ -121,12 +218,15 @@ func getFileThumbnail(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if data, err := app.ReadFile(info.ThumbnailPath); err != nil {
+	if fileReader, err := app.FileReader(info.ThumbnailPath); err != nil {
 		c.Err = err
 		c.Err.StatusCode = http.StatusNotFound
-	} else if err := writeFileResponse(info.Name, info.MimeType, data, w, r); err != nil {
-		c.Err = err
-		return
+	} else {
+		defer fileReader.Close()
+		if err := writeFileResponse(info.Name, info.MimeType, 0, fileReader, w, r); err != nil {
+			c.Err = err
+			return
+		}
 	}
 }
 
@@ -188,12 +288,15 @@ func getFilePreview(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if data, err := app.ReadFile(info.PreviewPath); err != nil {
+	if fileReader, err := app.FileReader(info.PreviewPath); err != nil {
 		c.Err = err
 		c.Err.StatusCode = http.StatusNotFound
-	} else if err := writeFileResponse(info.Name, info.MimeType, data, w, r); err != nil {
-		c.Err = err
-		return
+	} else {
+		defer fileReader.Close()
+		if err := writeFileResponse(info.Name, info.MimeType, 0, fileReader, w, r); err != nil {
+			c.Err = err
+			return
+		}
 	}
 }
 
@@ -250,18 +353,24 @@ func getPublicFile(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if data, err := app.ReadFile(info.Path); err != nil {
+	if fileReader, err := app.FileReader(info.Path); err != nil {
 		c.Err = err
 		c.Err.StatusCode = http.StatusNotFound
-	} else if err := writeFileResponse(info.Name, info.MimeType, data, w, r); err != nil {
-		c.Err = err
-		return
+	} else {
+		defer fileReader.Close()
+		if err := writeFileResponse(info.Name, info.MimeType, info.Size, fileReader, w, r); err != nil {
+			c.Err = err
+			return
+		}
 	}
 }
 
-func writeFileResponse(filename string, contentType string, bytes []byte, w http.ResponseWriter, r *http.Request) *model.AppError {
+func writeFileResponse(filename string, contentType string, contentSize int64, fileReader io.Reader, w http.ResponseWriter, r *http.Request) *model.AppError {
 	w.Header().Set("Cache-Control", "max-age=2592000, public")
-	w.Header().Set("Content-Length", strconv.Itoa(len(bytes)))
+
+	if contentSize > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(contentSize, 10))
+	}
 
 	if contentType != "" {
 		w.Header().Set("Content-Type", contentType)
@@ -275,7 +384,7 @@ func writeFileResponse(filename string, contentType string, bytes []byte, w http
 	w.Header().Set("X-Frame-Options", "DENY")
 	w.Header().Set("Content-Security-Policy", "Frame-ancestors 'none'")
 
-	w.Write(bytes)
+	io.Copy(w, fileReader)
 
 	return nil
 }
@@ -4,7 +4,9 @@
 package api4
 
 import (
+	"bytes"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"strings"
 	"testing"
@@ -104,6 +106,76 @@ func TestUploadFile(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestUploadFileWithChannelIdLast(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+	channel := th.BasicChannel
+
+	data, err := readTestFile("test.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Files sent before the channel_id field have to be held until the channel can be checked
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, name := range []string{"test1.png", "test2.txt"} {
+		if part, err := writer.CreateFormFile("files", name); err != nil {
+			t.Fatal(err)
+		} else if _, err := part.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writer.WriteField("channel_id", channel.Id)
+	writer.Close()
+
+	fileResp, resp := Client.DoUploadFile(Client.GetFilesRoute(), body.Bytes(), writer.FormDataContentType())
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if len(fileResp.FileInfos) != 2 {
+		t.Fatal("should've returned two file infos")
+	} else if fileResp.FileInfos[0].Name != "test1.png" || fileResp.FileInfos[1].Name != "test2.txt" {
+		t.Fatal("file infos should be returned in the order that the files were sent")
+	}
+
+	for _, uploadInfo := range fileResp.FileInfos {
+		if uploadInfo.Size != int64(len(data)) {
+			t.Fatal("file size should match the uploaded data")
+		}
+
+		if received, resp := Client.GetFile(uploadInfo.Id); resp.Error != nil {
+			t.Fatal(resp.Error)
+		} else if !bytes.Equal(received, data) {
+			t.Fatal("received file didn't match sent one")
+		}
+	}
+
+	// Wait a bit for files to ready
+	time.Sleep(2 * time.Second)
+
+	for _, uploadInfo := range fileResp.FileInfos {
+		if result := <-app.Srv.Store.FileInfo().Get(uploadInfo.Id); result.Err != nil {
+			t.Fatal(result.Err)
+		} else if err := cleanupTestFile(result.Data.(*model.FileInfo)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	body = &bytes.Buffer{}
+	writer = multipart.NewWriter(body)
+	if part, err := writer.CreateFormFile("files", "test.png"); err != nil {
+		t.Fatal(err)
+	} else if _, err := part.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+
+	_, resp = Client.DoUploadFile(Client.GetFilesRoute(), body.Bytes(), writer.FormDataContentType())
+	CheckBadRequestStatus(t, resp)
+}
+
 func TestGetFile(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -0,0 +1,122 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitJob() {
+	l4g.Debug(utils.T("api.job.init.debug"))
+
+	BaseRoutes.Jobs.Handle("", ApiSessionRequired(getJobs)).Methods("GET")
+	BaseRoutes.Jobs.Handle("", ApiSessionRequired(createJob)).Methods("POST")
+	BaseRoutes.Jobs.Handle("/{job_id:[A-Za-z0-9]+}", ApiSessionRequired(getJob)).Methods("GET")
+	BaseRoutes.Jobs.Handle("/{job_id:[A-Za-z0-9]+}/cancel", ApiSessionRequired(cancelJob)).Methods("POST")
+	BaseRoutes.Jobs.Handle("/type/{job_type:[A-Za-z0-9_-]+}", ApiSessionRequired(getJobsByType)).Methods("GET")
+}
+
+func getJob(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireJobId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if job, err := app.GetJob(c.Params.JobId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(job.ToJson()))
+	}
+}
+
+func createJob(c *Context, w http.ResponseWriter, r *http.Request) {
+	job := model.JobFromJson(r.Body)
+	if job == nil {
+		c.SetInvalidParam("job")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if job, err := app.CreateJob(job); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("job_id=" + job.Id + " type=" + job.Type)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(job.ToJson()))
+	}
+}
+
+func getJobs(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if jobs, err := app.GetJobsPage(c.Params.Page, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.JobsToJson(jobs)))
+	}
+}
+
+func getJobsByType(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireJobType()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if jobs, err := app.GetJobsByTypePage(c.Params.JobType, c.Params.Page, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.JobsToJson(jobs)))
+	}
+}
+
+func cancelJob(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireJobId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if _, err := app.GetJob(c.Params.JobId); err != nil {
+		c.Err = err
+		return
+	}
+
+	if err := app.CancelJob(c.Params.JobId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("job_id=" + c.Params.JobId)
+	ReturnStatusOK(w)
+}
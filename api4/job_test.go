@@ -0,0 +1,145 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+)
+
+func TestCreateJob(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	job := &model.Job{
+		Type: model.JOB_TYPE_DATA_RETENTION,
+		Data: map[string]string{
+			"thing": "stuff",
+		},
+	}
+
+	received, resp := th.SystemAdminClient.CreateJob(job)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	defer app.Srv.Store.Job().Delete(received.Id)
+
+	if received.Status != model.JOB_STATUS_PENDING || received.Data["thing"] != "stuff" {
+		t.Fatal("should've created a pending job")
+	}
+
+	job = &model.Job{
+		Type: model.NewId(),
+	}
+
+	_, resp = th.SystemAdminClient.CreateJob(job)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.Client.CreateJob(job)
+	CheckForbiddenStatus(t, resp)
+}
+
+func TestGetJob(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	job := &model.Job{
+		Type: model.JOB_TYPE_DATA_RETENTION,
+	}
+	store.Must(app.Srv.Store.Job().Save(job))
+	defer app.Srv.Store.Job().Delete(job.Id)
+
+	if received, resp := th.SystemAdminClient.GetJob(job.Id); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if received.Id != job.Id || received.Status != job.Status {
+		t.Fatal("incorrect job received")
+	}
+
+	_, resp := th.SystemAdminClient.GetJob("1234")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.Client.GetJob(job.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetJob(model.NewId())
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestGetJobs(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	jobs := []*model.Job{
+		{
+			Type: model.JOB_TYPE_DATA_RETENTION,
+		},
+		{
+			Type: model.JOB_TYPE_DATA_RETENTION,
+		},
+	}
+
+	for _, job := range jobs {
+		store.Must(app.Srv.Store.Job().Save(job))
+		defer app.Srv.Store.Job().Delete(job.Id)
+	}
+
+	received, resp := th.SystemAdminClient.GetJobs(0, 2)
+	CheckNoError(t, resp)
+
+	if len(received) != 2 {
+		t.Fatal("received wrong number of jobs")
+	} else if received[0].CreateAt < received[1].CreateAt {
+		t.Fatal("should've received newest job first")
+	}
+
+	received, resp = th.SystemAdminClient.GetJobsByType(model.JOB_TYPE_DATA_RETENTION, 0, 1)
+	CheckNoError(t, resp)
+
+	if len(received) != 1 || received[0].Type != model.JOB_TYPE_DATA_RETENTION {
+		t.Fatal("received wrong jobs")
+	}
+
+	_, resp = th.Client.GetJobs(0, 60)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.Client.GetJobsByType(model.JOB_TYPE_DATA_RETENTION, 0, 60)
+	CheckForbiddenStatus(t, resp)
+}
+
+func TestCancelJob(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	pendingJob := &model.Job{
+		Type: model.JOB_TYPE_DATA_RETENTION,
+	}
+	store.Must(app.Srv.Store.Job().Save(pendingJob))
+	defer app.Srv.Store.Job().Delete(pendingJob.Id)
+
+	finishedJob := &model.Job{
+		Type:   model.JOB_TYPE_DATA_RETENTION,
+		Status: model.JOB_STATUS_SUCCESS,
+	}
+	store.Must(app.Srv.Store.Job().Save(finishedJob))
+	defer app.Srv.Store.Job().Delete(finishedJob.Id)
+
+	_, resp := th.Client.CancelJob(pendingJob.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CancelJob(pendingJob.Id)
+	CheckNoError(t, resp)
+
+	if received, _ := th.SystemAdminClient.GetJob(pendingJob.Id); received.Status != model.JOB_STATUS_CANCELED {
+		t.Fatal("pending job should've been canceled")
+	}
+
+	_, resp = th.SystemAdminClient.CancelJob(finishedJob.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CancelJob(model.NewId())
+	CheckNotFoundStatus(t, resp)
+}
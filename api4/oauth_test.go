@@ -0,0 +1,365 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestCreateOAuthApp(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	AdminClient := th.SystemAdminClient
+
+	enableOAuth := utils.Cfg.ServiceSettings.EnableOAuthServiceProvider
+	adminOnly := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = enableOAuth
+		*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = adminOnly
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = true
+	utils.SetDefaultRolesBasedOnConfig()
+
+	oapp := &model.OAuthApp{Name: GenerateTestAppName(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+
+	rapp, resp := AdminClient.CreateOAuthApp(oapp)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rapp.Name != oapp.Name {
+		t.Fatal("names did not match")
+	}
+
+	if rapp.CreatorId != th.SystemAdminUser.Id {
+		t.Fatal("creator id should be the session user")
+	}
+
+	if rapp.ClientSecret == "" {
+		t.Fatal("should have generated a client secret")
+	}
+
+	_, resp = Client.CreateOAuthApp(oapp)
+	CheckForbiddenStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+	_, resp = Client.CreateOAuthApp(oapp)
+	CheckNoError(t, resp)
+
+	oapp.Name = GenerateTestAppName()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = false
+	_, resp = AdminClient.CreateOAuthApp(oapp)
+	CheckNotImplementedStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.CreateOAuthApp(oapp)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetOAuthApps(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	AdminClient := th.SystemAdminClient
+
+	enableOAuth := utils.Cfg.ServiceSettings.EnableOAuthServiceProvider
+	adminOnly := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = enableOAuth
+		*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = adminOnly
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	oapp := &model.OAuthApp{Name: GenerateTestAppName(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+
+	adminApp, resp := AdminClient.CreateOAuthApp(oapp)
+	CheckNoError(t, resp)
+
+	oapp.Name = GenerateTestAppName()
+	userApp, resp := Client.CreateOAuthApp(oapp)
+	CheckNoError(t, resp)
+
+	apps, resp := AdminClient.GetOAuthApps()
+	CheckNoError(t, resp)
+
+	found1 := false
+	found2 := false
+	for _, a := range apps {
+		if a.Id == adminApp.Id {
+			found1 = true
+		}
+		if a.Id == userApp.Id {
+			found2 = true
+		}
+	}
+
+	if !found1 || !found2 {
+		t.Fatal("system admin should see all apps")
+	}
+
+	apps, resp = Client.GetOAuthApps()
+	CheckNoError(t, resp)
+
+	if len(apps) != 1 || apps[0].Id != userApp.Id {
+		t.Fatal("regular users should only see their own apps")
+	}
+
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = true
+	utils.SetDefaultRolesBasedOnConfig()
+
+	_, resp = Client.GetOAuthApps()
+	CheckForbiddenStatus(t, resp)
+
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = false
+	_, resp = AdminClient.GetOAuthApps()
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestGetOAuthApp(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	AdminClient := th.SystemAdminClient
+
+	enableOAuth := utils.Cfg.ServiceSettings.EnableOAuthServiceProvider
+	adminOnly := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = enableOAuth
+		*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = adminOnly
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	oapp := &model.OAuthApp{Name: GenerateTestAppName(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+
+	adminApp, resp := AdminClient.CreateOAuthApp(oapp)
+	CheckNoError(t, resp)
+
+	oapp.Name = GenerateTestAppName()
+	userApp, resp := Client.CreateOAuthApp(oapp)
+	CheckNoError(t, resp)
+
+	rapp, resp := AdminClient.GetOAuthApp(userApp.Id)
+	CheckNoError(t, resp)
+
+	if rapp.ClientSecret != userApp.ClientSecret {
+		t.Fatal("should have returned the client secret")
+	}
+
+	_, resp = Client.GetOAuthApp(userApp.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetOAuthApp(adminApp.Id)
+	CheckForbiddenStatus(t, resp)
+
+	// anybody can see the sanitized info needed to authorize an app
+	info, resp := Client.GetOAuthAppInfo(adminApp.Id)
+	CheckNoError(t, resp)
+
+	if info.ClientSecret != "" {
+		t.Fatal("client secret should have been sanitized")
+	}
+
+	_, resp = AdminClient.GetOAuthApp(model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = false
+	_, resp = AdminClient.GetOAuthApp(adminApp.Id)
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestUpdateOAuthApp(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	AdminClient := th.SystemAdminClient
+
+	enableOAuth := utils.Cfg.ServiceSettings.EnableOAuthServiceProvider
+	adminOnly := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = enableOAuth
+		*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = adminOnly
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	oapp := &model.OAuthApp{Name: GenerateTestAppName(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+
+	oapp, resp := AdminClient.CreateOAuthApp(oapp)
+	CheckNoError(t, resp)
+
+	secret := oapp.ClientSecret
+	oapp.Name = "newname"
+	oapp.Description = "newdescription"
+	oapp.ClientSecret = "newsecret"
+
+	rapp, resp := AdminClient.UpdateOAuthApp(oapp)
+	CheckNoError(t, resp)
+
+	if rapp.Name != "newname" || rapp.Description != "newdescription" {
+		t.Fatal("app was not updated")
+	}
+
+	if rapp.ClientSecret != secret {
+		t.Fatal("client secret should not have been changed")
+	}
+
+	_, resp = Client.UpdateOAuthApp(oapp)
+	CheckForbiddenStatus(t, resp)
+
+	oapp.Id = model.NewId()
+	_, resp = AdminClient.UpdateOAuthApp(oapp)
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestDeleteOAuthApp(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	AdminClient := th.SystemAdminClient
+
+	enableOAuth := utils.Cfg.ServiceSettings.EnableOAuthServiceProvider
+	adminOnly := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = enableOAuth
+		*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = adminOnly
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	oapp := &model.OAuthApp{Name: GenerateTestAppName(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+
+	adminApp, resp := AdminClient.CreateOAuthApp(oapp)
+	CheckNoError(t, resp)
+
+	oapp.Name = GenerateTestAppName()
+	userApp, resp := Client.CreateOAuthApp(oapp)
+	CheckNoError(t, resp)
+
+	_, resp = Client.DeleteOAuthApp(adminApp.Id)
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := Client.DeleteOAuthApp(userApp.Id)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have returned true")
+	}
+
+	_, resp = AdminClient.DeleteOAuthApp(adminApp.Id)
+	CheckNoError(t, resp)
+
+	_, resp = AdminClient.GetOAuthApp(adminApp.Id)
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestRegenerateOAuthAppSecret(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	AdminClient := th.SystemAdminClient
+
+	enableOAuth := utils.Cfg.ServiceSettings.EnableOAuthServiceProvider
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = enableOAuth
+	}()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+
+	oapp := &model.OAuthApp{Name: GenerateTestAppName(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+
+	oapp, resp := AdminClient.CreateOAuthApp(oapp)
+	CheckNoError(t, resp)
+
+	rapp, resp := AdminClient.RegenerateOAuthAppSecret(oapp.Id)
+	CheckNoError(t, resp)
+
+	if rapp.Id != oapp.Id {
+		t.Fatal("wrong app was returned")
+	}
+
+	if rapp.ClientSecret == oapp.ClientSecret {
+		t.Fatal("secret should have been regenerated")
+	}
+
+	_, resp = Client.RegenerateOAuthAppSecret(oapp.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = AdminClient.RegenerateOAuthAppSecret(model.NewId())
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestAuthorizedOAuthApps(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	AdminClient := th.SystemAdminClient
+
+	enableOAuth := utils.Cfg.ServiceSettings.EnableOAuthServiceProvider
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = enableOAuth
+	}()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+
+	oapp := &model.OAuthApp{Name: GenerateTestAppName(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+
+	oapp, resp := AdminClient.CreateOAuthApp(oapp)
+	CheckNoError(t, resp)
+
+	// authorizing an app stores it as one of the user's preferences
+	authorizedApp := model.Preference{
+		UserId:   th.BasicUser.Id,
+		Category: model.PREFERENCE_CATEGORY_AUTHORIZED_OAUTH_APP,
+		Name:     oapp.Id,
+		Value:    model.DEFAULT_SCOPE,
+	}
+	store.Must(app.Srv.Store.Preference().Save(&model.Preferences{authorizedApp}))
+
+	apps, resp := Client.GetAuthorizedOAuthAppsForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	if len(apps) != 1 || apps[0].Id != oapp.Id {
+		t.Fatal("should have returned the authorized app")
+	}
+
+	if apps[0].ClientSecret != "" {
+		t.Fatal("client secret should have been sanitized")
+	}
+
+	_, resp = Client.GetAuthorizedOAuthAppsForUser(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.DeauthorizeOAuthApp(th.BasicUser2.Id, oapp.Id)
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := Client.DeauthorizeOAuthApp(th.BasicUser.Id, oapp.Id)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have returned true")
+	}
+
+	apps, resp = Client.GetAuthorizedOAuthAppsForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	if len(apps) != 0 {
+		t.Fatal("app should have been deauthorized")
+	}
+}
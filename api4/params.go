@@ -17,23 +17,32 @@ const (
 )
 
 type ApiParams struct {
-	UserId         string
-	TeamId         string
-	ChannelId      string
-	PostId         string
-	FileId         string
-	CommandId      string
-	HookId         string
-	ReportId       string
-	EmojiId        string
-	Email          string
-	Username       string
-	TeamName       string
-	ChannelName    string
-	PreferenceName string
-	Category       string
-	Page           int
-	PerPage        int
+	UserId          string
+	TeamId          string
+	ChannelId       string
+	PostId          string
+	FileId          string
+	CommandId       string
+	HookId          string
+	DeliveryId      string
+	ReportId        string
+	EmojiId         string
+	EmojiName       string
+	AppId           string
+	ActionId        string
+	JobId           string
+	JobType         string
+	TokenId         string
+	BotUserId       string
+	ScheduledPostId string
+	Email           string
+	Username        string
+	TeamName        string
+	ChannelName     string
+	PreferenceName  string
+	Category        string
+	Page            int
+	PerPage         int
 }
 
 func ApiParamsFromRequest(r *http.Request) *ApiParams {
@@ -77,6 +86,42 @@ func ApiParamsFromRequest(r *http.Request) *ApiParams {
 		params.EmojiId = val
 	}
 
+	if val, ok := props["emoji_name"]; ok {
+		params.EmojiName = val
+	}
+
+	if val, ok := props["app_id"]; ok {
+		params.AppId = val
+	}
+
+	if val, ok := props["delivery_id"]; ok {
+		params.DeliveryId = val
+	}
+
+	if val, ok := props["action_id"]; ok {
+		params.ActionId = val
+	}
+
+	if val, ok := props["token_id"]; ok {
+		params.TokenId = val
+	}
+
+	if val, ok := props["bot_user_id"]; ok {
+		params.BotUserId = val
+	}
+
+	if val, ok := props["scheduled_post_id"]; ok {
+		params.ScheduledPostId = val
+	}
+
+	if val, ok := props["job_id"]; ok {
+		params.JobId = val
+	}
+
+	if val, ok := props["job_type"]; ok {
+		params.JobType = val
+	}
+
 	if val, ok := props["email"]; ok {
 		params.Email = val
 	}
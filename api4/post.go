@@ -20,11 +20,13 @@ func InitPost() {
 	BaseRoutes.Post.Handle("", ApiSessionRequired(getPost)).Methods("GET")
 	BaseRoutes.Post.Handle("", ApiSessionRequired(deletePost)).Methods("DELETE")
 	BaseRoutes.Post.Handle("/thread", ApiSessionRequired(getPostThread)).Methods("GET")
+	BaseRoutes.Post.Handle("/push_notification", ApiSessionRequired(getPostPushNotification)).Methods("GET")
 	BaseRoutes.Post.Handle("/files/info", ApiSessionRequired(getFileInfosForPost)).Methods("GET")
 	BaseRoutes.PostsForChannel.Handle("", ApiSessionRequired(getPostsForChannel)).Methods("GET")
 
 	BaseRoutes.Team.Handle("/posts/search", ApiSessionRequired(searchPosts)).Methods("POST")
 	BaseRoutes.Post.Handle("", ApiSessionRequired(updatePost)).Methods("PUT")
+	BaseRoutes.Post.Handle("/actions/{action_id:[A-Za-z0-9]+}", ApiSessionRequired(doPostAction)).Methods("POST")
 }
 
 func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -45,6 +47,13 @@ func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
 		post.CreateAt = 0
 	}
 
+	if botUserId, err := app.GetBotUserIdForOAuthSession(&c.Session); err != nil {
+		c.Err = err
+		return
+	} else if botUserId != "" {
+		post.UserId = botUserId
+	}
+
 	rp, err := app.CreatePostAsUser(post)
 	if err != nil {
 		c.Err = err
@@ -102,6 +111,25 @@ func getPost(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func getPostPushNotification(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if msg, err := app.GetPushNotificationForPost(c.Params.PostId, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(msg.ToJson()))
+	}
+}
+
 func deletePost(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequirePostId()
 	if c.Err != nil {
@@ -193,7 +221,7 @@ func updatePost(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	post.UserId = c.Session.UserId
 
-	rpost, err := app.UpdatePost(post)
+	rpost, err := app.UpdatePost(post, true)
 	if err != nil {
 		c.Err = err
 		return
@@ -202,6 +230,28 @@ func updatePost(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(rpost.ToJson()))
 }
 
+func doPostAction(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId().RequireActionId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	// the body is only needed to say which option of a select menu was picked
+	props := model.MapFromJson(r.Body)
+
+	if err := app.DoPostAction(c.Params.PostId, c.Params.ActionId, c.Session.UserId, props["selected_option"]); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
 func getFileInfosForPost(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequirePostId()
 	if c.Err != nil {
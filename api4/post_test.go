@@ -5,7 +5,9 @@ package api4
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -278,6 +280,44 @@ func TestGetPost(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestGetPostPushNotification(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := th.CreatePost()
+	privatePost := th.CreatePostWithClient(Client, th.CreatePrivateChannel())
+
+	Client.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	msg, resp := Client.GetPostPushNotification(post.Id)
+	CheckNoError(t, resp)
+
+	if msg.PostId != post.Id || msg.ChannelId != th.BasicChannel.Id || msg.TeamId != th.BasicTeam.Id {
+		t.Fatal("should have returned the notification for the post")
+	}
+
+	if msg.IsIdLoaded || !strings.Contains(msg.Message, th.BasicUser.Username) || !strings.Contains(msg.Message, post.Message) {
+		t.Fatal("should have returned the full contents of the notification", msg.Message)
+	}
+
+	_, resp = Client.GetPostPushNotification("junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetPostPushNotification(model.NewId())
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetPostPushNotification(privatePost.Id)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetPostPushNotification(post.Id)
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetPostPushNotification(post.Id)
+	CheckNoError(t, resp)
+}
+
 func TestDeletePost(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -633,3 +673,170 @@ func TestGetFileInfosForPost(t *testing.T) {
 	_, resp = th.SystemAdminClient.GetFileInfosForPost(th.BasicPost.Id, "")
 	CheckNoError(t, resp)
 }
+
+func TestDoPostAction(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	requests := make(chan *model.PostActionIntegrationRequest, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := model.PostActionIntegrationRequestFromJson(r.Body)
+		requests <- request
+
+		response := &model.PostActionIntegrationResponse{
+			Update:        &model.Post{Message: "updated by " + request.UserId},
+			EphemeralText: "clicked",
+		}
+		w.Write([]byte(response.ToJson()))
+	}))
+	defer ts.Close()
+
+	post := &model.Post{
+		UserId:    th.BasicUser.Id,
+		ChannelId: th.BasicChannel.Id,
+		Message:   "message",
+		Props: model.StringInterface{
+			"from_webhook": "true",
+			"attachments": []*model.SlackAttachment{
+				{
+					Text: "text",
+					Actions: []*model.SlackAttachmentAction{
+						{
+							Name:        "button",
+							Integration: &model.PostActionIntegration{URL: ts.URL, Context: model.StringInterface{"key": "value"}},
+						},
+						{
+							Name:        "select",
+							Type:        model.SLACK_ATTACHMENT_ACTION_TYPE_SELECT,
+							Options:     []*model.PostActionOptions{{Text: "One", Value: "1"}},
+							Integration: &model.PostActionIntegration{URL: ts.URL},
+						},
+					},
+				},
+			},
+		},
+	}
+	post.GenerateActionIds()
+
+	post, err := app.CreatePost(post, th.BasicTeam.Id, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attachments := post.Attachments()
+	buttonId := attachments[0].Actions[0].Id
+	selectId := attachments[0].Actions[1].Id
+
+	ok, resp := Client.DoPostAction(post.Id, buttonId, "")
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have returned ok")
+	}
+
+	request := <-requests
+	if request.UserId != th.BasicUser.Id || request.PostId != post.Id || request.ChannelId != th.BasicChannel.Id || request.TeamId != th.BasicTeam.Id {
+		t.Fatal("integration received the wrong request")
+	}
+
+	if request.Context["key"] != "value" {
+		t.Fatal("integration should have received the action's context")
+	}
+
+	if rpost, err := app.GetSinglePost(post.Id); err != nil {
+		t.Fatal(err)
+	} else if rpost.Message != "updated by "+th.BasicUser.Id {
+		t.Fatal("post should have been updated")
+	} else if rpost.Props["from_webhook"] != "true" {
+		t.Fatal("updated post should be marked as from a webhook")
+	}
+
+	_, resp = Client.DoPostAction(post.Id, selectId, "2")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.DoPostAction(post.Id, selectId, "1")
+	CheckNoError(t, resp)
+
+	if request := <-requests; request.Context["selected_option"] != "1" {
+		t.Fatal("integration should have received the selected option")
+	}
+
+	_, resp = Client.DoPostAction(post.Id, model.NewId(), "")
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.DoPostAction("junk", buttonId, "")
+	CheckBadRequestStatus(t, resp)
+
+	th.LoginBasic2()
+	privateChannel := th.CreatePrivateChannel()
+	privatePost := th.CreatePostWithClient(Client, privateChannel)
+
+	th.LoginBasic()
+	_, resp = Client.DoPostAction(privatePost.Id, buttonId, "")
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.DoPostAction(post.Id, buttonId, "")
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DoPostAction(post.Id, buttonId, "")
+	CheckNoError(t, resp)
+	<-requests
+
+	// users can't add actions that send requests to a URL of their choosing
+	th.LoginBasic()
+	userPost := &model.Post{
+		ChannelId: th.BasicChannel.Id,
+		Message:   "message",
+		Props: model.StringInterface{
+			"from_webhook": "true",
+			"attachments": []*model.SlackAttachment{
+				{
+					Text: "text",
+					Actions: []*model.SlackAttachmentAction{
+						{Id: "chosen", Name: "button", Integration: &model.PostActionIntegration{URL: ts.URL}},
+					},
+				},
+			},
+		},
+	}
+
+	userPost, resp = Client.CreatePost(userPost)
+	CheckNoError(t, resp)
+
+	action := userPost.Attachments()[0].Actions[0]
+	if action.Integration != nil {
+		t.Fatal("should have removed the integration from the action")
+	} else if action.Id == "chosen" {
+		t.Fatal("should have generated the action's id")
+	}
+
+	_, resp = Client.DoPostAction(userPost.Id, action.Id, "")
+	CheckNotFoundStatus(t, resp)
+
+	// nor run actions on posts that weren't made by integrations
+	plainPost := &model.Post{
+		UserId:    th.BasicUser.Id,
+		ChannelId: th.BasicChannel.Id,
+		Message:   "message",
+		Props: model.StringInterface{
+			"attachments": []*model.SlackAttachment{
+				{Text: "text", Actions: []*model.SlackAttachmentAction{{Name: "button", Integration: &model.PostActionIntegration{URL: ts.URL}}}},
+			},
+		},
+	}
+	plainPost.GenerateActionIds()
+
+	plainPost, err = app.CreatePost(plainPost, th.BasicTeam.Id, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp = Client.DoPostAction(plainPost.Id, plainPost.Attachments()[0].Actions[0].Id, "")
+	CheckNotFoundStatus(t, resp)
+
+	if _, err := app.UpdatePost(&model.Post{Id: plainPost.Id, UserId: plainPost.UserId, Message: "updated"}, false); err == nil {
+		t.Fatal("shouldn't replace the props of a post that wasn't made by an integration")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestSaveReaction(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	userId := th.BasicUser.Id
+	postId := th.BasicPost.Id
+
+	reaction := &model.Reaction{
+		UserId:    userId,
+		PostId:    postId,
+		EmojiName: "smile",
+	}
+
+	rr, resp := Client.SaveReaction(reaction)
+	CheckNoError(t, resp)
+
+	if rr.UserId != reaction.UserId || rr.PostId != reaction.PostId || rr.EmojiName != reaction.EmojiName {
+		t.Fatal("did not save the reaction correctly")
+	}
+
+	if rr.CreateAt == 0 {
+		t.Fatal("should have set create at")
+	}
+
+	reactions, resp := Client.GetReactions(postId)
+	CheckNoError(t, resp)
+
+	if len(reactions) != 1 {
+		t.Fatal("didn't save reaction correctly")
+	}
+
+	// saving the same reaction again should be a no-op
+	_, resp = Client.SaveReaction(reaction)
+	CheckNoError(t, resp)
+
+	reactions, _ = Client.GetReactions(postId)
+	if len(reactions) != 1 {
+		t.Fatal("should have not saved a duplicate reaction")
+	}
+
+	reaction.EmojiName = "sad"
+	_, resp = Client.SaveReaction(reaction)
+	CheckNoError(t, resp)
+
+	reactions, _ = Client.GetReactions(postId)
+	if len(reactions) != 2 {
+		t.Fatal("should have saved multiple reactions")
+	}
+
+	reaction.PostId = GenerateTestId()
+	_, resp = Client.SaveReaction(reaction)
+	CheckForbiddenStatus(t, resp)
+
+	reaction.PostId = "junk"
+	_, resp = Client.SaveReaction(reaction)
+	CheckBadRequestStatus(t, resp)
+
+	reaction.PostId = postId
+	reaction.UserId = GenerateTestId()
+	_, resp = Client.SaveReaction(reaction)
+	CheckForbiddenStatus(t, resp)
+
+	reaction.UserId = "junk"
+	_, resp = Client.SaveReaction(reaction)
+	CheckBadRequestStatus(t, resp)
+
+	reaction.UserId = userId
+	reaction.EmojiName = ""
+	_, resp = Client.SaveReaction(reaction)
+	CheckBadRequestStatus(t, resp)
+
+	reaction.EmojiName = "smile"
+	privatePost := th.CreatePostWithClient(th.SystemAdminClient, th.CreateChannelWithClient(th.SystemAdminClient, model.CHANNEL_PRIVATE))
+	reaction.PostId = privatePost.Id
+	_, resp = Client.SaveReaction(reaction)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.SaveReaction(reaction)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetReactions(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	postId := th.BasicPost.Id
+
+	for _, emojiName := range []string{"smile", "+1"} {
+		_, resp := Client.SaveReaction(&model.Reaction{
+			UserId:    th.BasicUser.Id,
+			PostId:    postId,
+			EmojiName: emojiName,
+		})
+		CheckNoError(t, resp)
+	}
+
+	reactions, resp := Client.GetReactions(postId)
+	CheckNoError(t, resp)
+
+	if len(reactions) != 2 {
+		t.Fatal("should have returned both reactions")
+	}
+
+	_, resp = Client.GetReactions("junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetReactions(GenerateTestId())
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetReactions(postId)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetReactions(postId)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestDeleteReaction(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	postId := th.BasicPost.Id
+
+	reaction := &model.Reaction{
+		UserId:    th.BasicUser.Id,
+		PostId:    postId,
+		EmojiName: "smile",
+	}
+
+	_, resp := Client.SaveReaction(reaction)
+	CheckNoError(t, resp)
+
+	// users can't remove each other's reactions
+	th.LoginBasic2()
+	_, resp = Client.DeleteReaction(reaction)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginBasic()
+	ok, resp := Client.DeleteReaction(reaction)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have returned true")
+	}
+
+	reactions, _ := Client.GetReactions(postId)
+	if len(reactions) != 0 {
+		t.Fatal("should have deleted the reaction")
+	}
+
+	_, resp = Client.SaveReaction(reaction)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteReaction(reaction)
+	CheckNoError(t, resp)
+
+	reaction.PostId = "junk"
+	_, resp = Client.DeleteReaction(reaction)
+	CheckBadRequestStatus(t, resp)
+
+	reaction.PostId = GenerateTestId()
+	_, resp = Client.DeleteReaction(reaction)
+	CheckForbiddenStatus(t, resp)
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitScheduledPost() {
+	l4g.Debug(utils.T("api.scheduled_post.init.debug"))
+
+	BaseRoutes.ScheduledPosts.Handle("", ApiSessionRequired(createScheduledPost)).Methods("POST")
+	BaseRoutes.ScheduledPost.Handle("", ApiSessionRequired(getScheduledPost)).Methods("GET")
+	BaseRoutes.ScheduledPost.Handle("", ApiSessionRequired(updateScheduledPost)).Methods("PUT")
+	BaseRoutes.ScheduledPost.Handle("", ApiSessionRequired(deleteScheduledPost)).Methods("DELETE")
+	BaseRoutes.ScheduledPostsForUser.Handle("", ApiSessionRequired(getScheduledPostsForUser)).Methods("GET")
+}
+
+func createScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	scheduledPost := model.ScheduledPostFromJson(r.Body)
+	if scheduledPost == nil {
+		c.SetInvalidParam("scheduled_post")
+		return
+	}
+
+	scheduledPost.UserId = c.Session.UserId
+
+	if !app.SessionHasPermissionToChannel(c.Session, scheduledPost.ChannelId, model.PERMISSION_CREATE_POST) {
+		c.SetPermissionError(model.PERMISSION_CREATE_POST)
+		return
+	}
+
+	if rscheduledPost, err := app.CreateScheduledPost(scheduledPost); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("scheduled_post_id=" + rscheduledPost.Id)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rscheduledPost.ToJson()))
+	}
+}
+
+// getScheduledPostForSession gets the scheduled post from the request's URL if the session's user can manage it.
+func getScheduledPostForSession(c *Context) *model.ScheduledPost {
+	c.RequireScheduledPostId()
+	if c.Err != nil {
+		return nil
+	}
+
+	scheduledPost, err := app.GetScheduledPost(c.Params.ScheduledPostId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, scheduledPost.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return nil
+	}
+
+	return scheduledPost
+}
+
+func getScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	scheduledPost := getScheduledPostForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	w.Write([]byte(scheduledPost.ToJson()))
+}
+
+func updateScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	updatedScheduledPost := model.ScheduledPostFromJson(r.Body)
+	if updatedScheduledPost == nil {
+		c.SetInvalidParam("scheduled_post")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	oldScheduledPost := getScheduledPostForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if updatedScheduledPost.Id != "" && updatedScheduledPost.Id != oldScheduledPost.Id {
+		c.SetInvalidParam("scheduled_post_id")
+		return
+	}
+
+	if rscheduledPost, err := app.UpdateScheduledPost(oldScheduledPost, updatedScheduledPost); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.Write([]byte(rscheduledPost.ToJson()))
+	}
+}
+
+func deleteScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.LogAudit("attempt")
+
+	scheduledPost := getScheduledPostForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := app.CancelScheduledPost(scheduledPost.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	ReturnStatusOK(w)
+}
+
+func getScheduledPostsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if scheduledPosts, err := app.GetScheduledPostsForUserPage(c.Params.UserId, c.Params.Page, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.ScheduledPostListToJson(scheduledPosts)))
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestCreateScheduledPost(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	scheduledPost := &model.ScheduledPost{
+		ChannelId:   th.BasicChannel.Id,
+		Message:     "later",
+		ScheduledAt: model.GetMillis() + 60*60*1000,
+		Recurrence:  model.SCHEDULED_POST_RECURRENCE_WEEKLY,
+	}
+
+	_, resp := Client.CreateScheduledPost(scheduledPost)
+	CheckBadRequestStatus(t, resp)
+
+	scheduledPost.Timezone = "America/Toronto"
+	rscheduledPost, resp := Client.CreateScheduledPost(scheduledPost)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rscheduledPost.UserId != th.BasicUser.Id {
+		t.Fatal("should have been scheduled for the current user")
+	}
+
+	scheduledPost.ScheduledAt = model.GetMillis() - 1000
+	_, resp = Client.CreateScheduledPost(scheduledPost)
+	CheckBadRequestStatus(t, resp)
+
+	scheduledPost.ScheduledAt = model.GetMillis() + 60*60*1000
+	scheduledPost.Recurrence = "hourly"
+	_, resp = Client.CreateScheduledPost(scheduledPost)
+	CheckBadRequestStatus(t, resp)
+
+	scheduledPost.Recurrence = model.SCHEDULED_POST_RECURRENCE_NONE
+	scheduledPost.ChannelId = th.CreatePrivateChannel().Id
+	_, resp = th.SystemAdminClient.CreateScheduledPost(scheduledPost)
+	CheckNoError(t, resp)
+
+	Client.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+	_, resp = Client.CreateScheduledPost(scheduledPost)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.CreateScheduledPost(scheduledPost)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetUpdateAndDeleteScheduledPost(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	scheduledPost, resp := Client.CreateScheduledPost(&model.ScheduledPost{
+		ChannelId:   th.BasicChannel.Id,
+		Message:     "later",
+		ScheduledAt: model.GetMillis() + 60*60*1000,
+	})
+	CheckNoError(t, resp)
+
+	rscheduledPost, resp := Client.GetScheduledPost(scheduledPost.Id)
+	CheckNoError(t, resp)
+
+	if rscheduledPost.Message != scheduledPost.Message {
+		t.Fatal("got the wrong scheduled post")
+	}
+
+	scheduledPosts, resp := Client.GetScheduledPostsForUser(th.BasicUser.Id, 0, 10)
+	CheckNoError(t, resp)
+
+	if len(scheduledPosts) != 1 || scheduledPosts[0].Id != scheduledPost.Id {
+		t.Fatal("should have listed the scheduled post")
+	}
+
+	scheduledPost.Message = "even later"
+	scheduledPost.ScheduledAt += 60 * 60 * 1000
+	scheduledPost.Recurrence = model.SCHEDULED_POST_RECURRENCE_DAILY
+	scheduledPost.Timezone = "UTC"
+	scheduledPost.ChannelId = th.BasicChannel2.Id
+	rscheduledPost, resp = Client.UpdateScheduledPost(scheduledPost)
+	CheckNoError(t, resp)
+
+	if rscheduledPost.Message != "even later" || rscheduledPost.Recurrence != model.SCHEDULED_POST_RECURRENCE_DAILY {
+		t.Fatal("should have updated the scheduled post")
+	}
+
+	if rscheduledPost.ChannelId != th.BasicChannel.Id {
+		t.Fatal("shouldn't have moved the scheduled post to another channel")
+	}
+
+	scheduledPost.ScheduledAt = model.GetMillis() - 1000
+	_, resp = Client.UpdateScheduledPost(scheduledPost)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetScheduledPost(model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	// admins can manage other users' scheduled posts
+	_, resp = th.SystemAdminClient.GetScheduledPost(scheduledPost.Id)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.GetScheduledPostsForUser(th.BasicUser.Id, 0, 10)
+	CheckNoError(t, resp)
+
+	Client.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	_, resp = Client.GetScheduledPost(scheduledPost.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetScheduledPostsForUser(th.BasicUser.Id, 0, 10)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.UpdateScheduledPost(scheduledPost)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.DeleteScheduledPost(scheduledPost.Id)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Login(th.BasicUser.Email, th.BasicUser.Password)
+
+	ok, resp := Client.DeleteScheduledPost(scheduledPost.Id)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have returned true")
+	}
+
+	_, resp = Client.GetScheduledPost(scheduledPost.Id)
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestSendDueScheduledPosts(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	privateChannel := th.CreatePrivateChannel()
+
+	scheduledPosts := []*model.ScheduledPost{
+		{UserId: th.BasicUser.Id, ChannelId: th.BasicChannel.Id, Message: "once " + model.NewId()},
+		{UserId: th.BasicUser.Id, ChannelId: th.BasicChannel.Id, Message: "daily " + model.NewId(), Recurrence: model.SCHEDULED_POST_RECURRENCE_DAILY, Timezone: "UTC"},
+		{UserId: th.BasicUser2.Id, ChannelId: privateChannel.Id, Message: "not allowed " + model.NewId()},
+	}
+
+	// these are saved straight to the store since they're already due
+	for _, scheduledPost := range scheduledPosts {
+		scheduledPost.ScheduledAt = model.GetMillis() - 1000
+		if result := <-app.Srv.Store.ScheduledPost().Save(scheduledPost); result.Err != nil {
+			t.Fatal(result.Err)
+		}
+	}
+
+	app.SendDueScheduledPosts()
+
+	// sending them again shouldn't post them twice
+	app.SendDueScheduledPosts()
+
+	posts, resp := Client.GetPostsForChannel(th.BasicChannel.Id, 0, 60, "")
+	CheckNoError(t, resp)
+
+	counts := map[string]int{}
+	for _, post := range posts.Posts {
+		counts[post.Message]++
+
+		if post.Message == scheduledPosts[0].Message && post.UserId != th.BasicUser.Id {
+			t.Fatal("should have been posted by the user that scheduled it")
+		}
+	}
+
+	if counts[scheduledPosts[0].Message] != 1 || counts[scheduledPosts[1].Message] != 1 {
+		t.Fatal("should have sent each due post exactly once", counts)
+	}
+
+	if _, err := app.GetScheduledPost(scheduledPosts[0].Id); err == nil || err.StatusCode != http.StatusNotFound {
+		t.Fatal("should have removed the post once it was sent")
+	}
+
+	if daily, err := app.GetScheduledPost(scheduledPosts[1].Id); err != nil {
+		t.Fatal(err)
+	} else if daily.ScheduledAt <= model.GetMillis() {
+		t.Fatal("should have moved the recurring post to its next time")
+	}
+
+	posts, resp = Client.GetPostsForChannel(privateChannel.Id, 0, 60, "")
+	CheckNoError(t, resp)
+
+	for _, post := range posts.Posts {
+		if post.Message == scheduledPosts[2].Message {
+			t.Fatal("shouldn't have posted in a channel that the user can't post in")
+		}
+	}
+}
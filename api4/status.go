@@ -0,0 +1,147 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitStatus() {
+	l4g.Debug(utils.T("api.status.init.debug"))
+
+	BaseRoutes.User.Handle("/status", ApiSessionRequired(getUserStatus)).Methods("GET")
+	BaseRoutes.Users.Handle("/status/ids", ApiSessionRequired(getUserStatusesByIds)).Methods("POST")
+	BaseRoutes.User.Handle("/status", ApiSessionRequired(updateUserStatus)).Methods("PUT")
+	BaseRoutes.User.Handle("/status/custom", ApiSessionRequired(updateUserCustomStatus)).Methods("PUT")
+	BaseRoutes.User.Handle("/status/custom", ApiSessionRequired(removeUserCustomStatus)).Methods("DELETE")
+}
+
+func getUserStatus(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	// users that have never logged in don't have a status yet
+	if status, err := app.GetStatus(c.Params.UserId); err != nil && err.Id != store.MISSING_STATUS_ERROR {
+		c.Err = err
+		return
+	} else if err != nil {
+		status = &model.Status{UserId: c.Params.UserId, Status: model.STATUS_OFFLINE}
+		w.Write([]byte(status.ToJson()))
+	} else {
+		w.Write([]byte(status.ToJson()))
+	}
+}
+
+func getUserStatusesByIds(c *Context, w http.ResponseWriter, r *http.Request) {
+	userIds := model.ArrayFromJson(r.Body)
+	if len(userIds) == 0 {
+		c.SetInvalidParam("user_ids")
+		return
+	}
+
+	for _, userId := range userIds {
+		if len(userId) != 26 {
+			c.SetInvalidParam("user_ids")
+			return
+		}
+	}
+
+	if statusMap, err := app.GetStatusesByIds(userIds); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.StringInterfaceToJson(statusMap)))
+	}
+}
+
+func updateUserStatus(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	status := model.StatusFromJson(r.Body)
+	if status == nil || status.UserId != c.Params.UserId {
+		c.SetInvalidParam("status")
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	switch status.Status {
+	case model.STATUS_ONLINE:
+		app.SetStatusOnline(c.Params.UserId, c.Session.Id, true)
+	case model.STATUS_AWAY:
+		app.SetStatusAwayIfNeeded(c.Params.UserId, true)
+	case model.STATUS_OFFLINE:
+		app.SetStatusOffline(c.Params.UserId, true)
+	case model.STATUS_DND:
+		if status.DNDEndTime != 0 && status.DNDEndTime <= model.GetMillis() {
+			c.SetInvalidParam("dnd_end_time")
+			return
+		}
+
+		app.SetStatusDoNotDisturb(c.Params.UserId, status.DNDEndTime)
+	default:
+		c.SetInvalidParam("status")
+		return
+	}
+
+	getUserStatus(c, w, r)
+}
+
+func updateUserCustomStatus(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	customStatus := model.CustomStatusFromJson(r.Body)
+	if customStatus == nil {
+		c.SetInvalidParam("custom_status")
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := app.SetCustomStatus(c.Params.UserId, customStatus); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func removeUserCustomStatus(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := app.RemoveCustomStatus(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestGetUserStatus(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	// users that have never connected are offline
+	userStatus, resp := Client.GetUserStatus(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	if userStatus.Status != model.STATUS_OFFLINE {
+		t.Fatal("should be offline")
+	}
+
+	app.SetStatusOnline(th.BasicUser.Id, "", true)
+	userStatus, resp = Client.GetUserStatus(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	if userStatus.Status != model.STATUS_ONLINE {
+		t.Fatal("should be online")
+	}
+
+	app.SetStatusAwayIfNeeded(th.BasicUser2.Id, true)
+	userStatus, resp = Client.GetUserStatus(th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	if userStatus.Status != model.STATUS_AWAY {
+		t.Fatal("should be away")
+	}
+
+	_, resp = Client.GetUserStatus("junk")
+	CheckBadRequestStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetUserStatus(th.BasicUser.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetUsersStatusesByIds(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	app.SetStatusOnline(th.BasicUser.Id, "", true)
+
+	statuses, resp := Client.GetUsersStatusesByIds([]string{th.BasicUser.Id, th.BasicUser2.Id})
+	CheckNoError(t, resp)
+
+	if statuses[th.BasicUser.Id] != model.STATUS_ONLINE {
+		t.Fatal("basic user should be online")
+	}
+
+	if statuses[th.BasicUser2.Id] != model.STATUS_OFFLINE {
+		t.Fatal("basic user 2 should be offline")
+	}
+
+	_, resp = Client.GetUsersStatusesByIds([]string{})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetUsersStatusesByIds([]string{"junk"})
+	CheckBadRequestStatus(t, resp)
+}
+
+func TestUpdateUserStatus(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	userStatus, resp := Client.UpdateUserStatus(th.BasicUser.Id, &model.Status{UserId: th.BasicUser.Id, Status: model.STATUS_AWAY})
+	CheckNoError(t, resp)
+
+	if userStatus.Status != model.STATUS_AWAY {
+		t.Fatal("should be away")
+	}
+
+	userStatus, resp = Client.UpdateUserStatus(th.BasicUser.Id, &model.Status{UserId: th.BasicUser.Id, Status: model.STATUS_OFFLINE})
+	CheckNoError(t, resp)
+
+	if userStatus.Status != model.STATUS_OFFLINE {
+		t.Fatal("should be offline")
+	}
+
+	userStatus, resp = Client.UpdateUserStatus(th.BasicUser.Id, &model.Status{UserId: th.BasicUser.Id, Status: model.STATUS_ONLINE})
+	CheckNoError(t, resp)
+
+	if userStatus.Status != model.STATUS_ONLINE {
+		t.Fatal("should be online")
+	}
+
+	endTime := model.GetMillis() + 2*60*60*1000
+	userStatus, resp = Client.UpdateUserStatus(th.BasicUser.Id, &model.Status{UserId: th.BasicUser.Id, Status: model.STATUS_DND, DNDEndTime: endTime})
+	CheckNoError(t, resp)
+
+	if userStatus.Status != model.STATUS_DND || userStatus.DNDEndTime != endTime {
+		t.Fatal("should be dnd until the end time")
+	}
+
+	_, resp = Client.UpdateUserStatus(th.BasicUser.Id, &model.Status{UserId: th.BasicUser.Id, Status: model.STATUS_DND, DNDEndTime: model.GetMillis() - 1000})
+	CheckBadRequestStatus(t, resp)
+
+	userStatus, resp = Client.UpdateUserStatus(th.BasicUser.Id, &model.Status{UserId: th.BasicUser.Id, Status: model.STATUS_ONLINE})
+	CheckNoError(t, resp)
+
+	if userStatus.Status != model.STATUS_ONLINE || userStatus.DNDEndTime != 0 {
+		t.Fatal("should be online")
+	}
+
+	_, resp = Client.UpdateUserStatus(th.BasicUser.Id, &model.Status{UserId: th.BasicUser.Id, Status: "junk"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateUserStatus(th.BasicUser.Id, &model.Status{UserId: th.BasicUser2.Id, Status: model.STATUS_AWAY})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateUserStatus(th.BasicUser2.Id, &model.Status{UserId: th.BasicUser2.Id, Status: model.STATUS_AWAY})
+	CheckForbiddenStatus(t, resp)
+
+	userStatus, resp = th.SystemAdminClient.UpdateUserStatus(th.BasicUser2.Id, &model.Status{UserId: th.BasicUser2.Id, Status: model.STATUS_OFFLINE})
+	CheckNoError(t, resp)
+
+	if userStatus.Status != model.STATUS_OFFLINE {
+		t.Fatal("should be offline")
+	}
+}
+
+func TestUpdateDNDStatuses(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	// do not disturb returns to the manually set status when it ends
+	_, resp := Client.UpdateUserStatus(th.BasicUser.Id, &model.Status{UserId: th.BasicUser.Id, Status: model.STATUS_AWAY})
+	CheckNoError(t, resp)
+
+	app.SetStatusDoNotDisturb(th.BasicUser.Id, model.GetMillis()+1000)
+
+	now := model.GetMillis()
+	app.UpdateDNDStatuses(now, now)
+
+	if status, _ := Client.GetUserStatus(th.BasicUser.Id); status.Status != model.STATUS_DND {
+		t.Fatal("should still be dnd")
+	}
+
+	now += 2000
+	app.UpdateDNDStatuses(now, now)
+
+	if status, _ := Client.GetUserStatus(th.BasicUser.Id); status.Status != model.STATUS_AWAY || !status.Manual {
+		t.Fatal("should be away again")
+	}
+
+	// quiet hours start do not disturb in the user's timezone
+	location, _ := time.LoadLocation("Asia/Tokyo")
+	start := time.Now().In(location).Add(time.Minute)
+	quietHours := &model.QuietHours{Start: start.Format(model.QUIET_HOURS_TIME_FORMAT), End: start.Add(time.Hour).Format(model.QUIET_HOURS_TIME_FORMAT), Timezone: "Asia/Tokyo"}
+
+	_, resp = Client.UpdatePreferences(th.BasicUser2.Id, &model.Preferences{{UserId: th.BasicUser2.Id, Category: model.PREFERENCE_CATEGORY_NOTIFICATIONS, Name: model.PREFERENCE_NAME_QUIET_HOURS, Value: quietHours.ToJson()}})
+	CheckNoError(t, resp)
+
+	now = model.GetMillis()
+	app.UpdateDNDStatuses(now-60*1000, now)
+
+	if status, _ := Client.GetUserStatus(th.BasicUser2.Id); status.Status == model.STATUS_DND {
+		t.Fatal("quiet hours shouldn't have started yet")
+	}
+
+	app.UpdateDNDStatuses(now, now+2*60*1000)
+
+	if status, _ := Client.GetUserStatus(th.BasicUser2.Id); status.Status != model.STATUS_DND || status.DNDEndTime == 0 {
+		t.Fatal("quiet hours should have started")
+	}
+
+	_, resp = Client.UpdatePreferences(th.BasicUser2.Id, &model.Preferences{{UserId: th.BasicUser2.Id, Category: model.PREFERENCE_CATEGORY_NOTIFICATIONS, Name: model.PREFERENCE_NAME_QUIET_HOURS, Value: `{"start": "junk"}`}})
+	if resp.Error == nil {
+		t.Fatal("should have failed with invalid quiet hours")
+	}
+}
+
+func TestUpdateUserCustomStatus(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	customStatus := &model.CustomStatus{Emoji: "calendar", Text: "In a meeting"}
+	ok, resp := Client.UpdateUserCustomStatus(th.BasicUser.Id, customStatus)
+	CheckNoError(t, resp)
+	if !ok {
+		t.Fatal("should have returned true")
+	}
+
+	user, resp := Client.GetUser(th.BasicUser.Id, "")
+	CheckNoError(t, resp)
+	if user.CustomStatus == nil || user.CustomStatus.Emoji != "calendar" || user.CustomStatus.Text != "In a meeting" {
+		t.Fatal("should have set the custom status")
+	}
+
+	_, resp = Client.UpdateUserCustomStatus(th.BasicUser.Id, &model.CustomStatus{})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateUserCustomStatus(th.BasicUser.Id, &model.CustomStatus{Text: "Out to lunch", ExpiresAt: model.GetMillis() - 1000})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateUserCustomStatus(th.BasicUser2.Id, customStatus)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.RemoveUserCustomStatus(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	// custom statuses are cleared once they expire
+	_, resp = th.SystemAdminClient.UpdateUserCustomStatus(th.BasicUser2.Id, &model.CustomStatus{Text: "Out to lunch", ExpiresAt: model.GetMillis() + 1000})
+	CheckNoError(t, resp)
+
+	time.Sleep(1500 * time.Millisecond)
+	app.ClearExpiredCustomStatuses()
+
+	if user, _ := Client.GetUser(th.BasicUser2.Id, ""); user.CustomStatus != nil {
+		t.Fatal("should have cleared the expired custom status")
+	}
+
+	if user, _ := Client.GetUser(th.BasicUser.Id, ""); user.CustomStatus == nil {
+		t.Fatal("shouldn't have cleared a custom status without an expiry")
+	}
+
+	ok, resp = Client.RemoveUserCustomStatus(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	if !ok {
+		t.Fatal("should have returned true")
+	}
+
+	if user, _ := Client.GetUser(th.BasicUser.Id, ""); user.CustomStatus != nil {
+		t.Fatal("should have removed the custom status")
+	}
+
+	Client.Logout()
+	_, resp = Client.UpdateUserCustomStatus(th.BasicUser.Id, customStatus)
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
 )
@@ -116,6 +117,24 @@ func TestEmailTest(t *testing.T) {
 	CheckInternalErrorStatus(t, resp)
 }
 
+func TestS3Test(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	config := app.GetConfig()
+
+	_, resp := Client.TestS3Connection(config)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.TestS3Connection(config)
+	CheckNoError(t, resp)
+
+	config.FileSettings.DriverName = "invalid"
+	_, resp = th.SystemAdminClient.TestS3Connection(config)
+	CheckErrorMessage(t, resp, "api.file.no_driver.app_error")
+}
+
 func TestDatabaseRecycle(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
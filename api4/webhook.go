@@ -19,9 +19,12 @@ func InitWebhook() {
 	BaseRoutes.IncomingHooks.Handle("", ApiSessionRequired(getIncomingHooks)).Methods("GET")
 	BaseRoutes.IncomingHook.Handle("", ApiSessionRequired(getIncomingHook)).Methods("GET")
 	BaseRoutes.IncomingHook.Handle("", ApiSessionRequired(deleteIncomingHook)).Methods("DELETE")
+	BaseRoutes.IncomingHook.Handle("/test", ApiSessionRequired(testIncomingHook)).Methods("POST")
 
 	BaseRoutes.OutgoingHooks.Handle("", ApiSessionRequired(createOutgoingHook)).Methods("POST")
 	BaseRoutes.OutgoingHooks.Handle("", ApiSessionRequired(getOutgoingHooks)).Methods("GET")
+	BaseRoutes.OutgoingHook.Handle("/deliveries", ApiSessionRequired(getOutgoingHookDeliveries)).Methods("GET")
+	BaseRoutes.OutgoingHook.Handle("/deliveries/{delivery_id:[A-Za-z0-9]+}/redeliver", ApiSessionRequired(redeliverOutgoingHook)).Methods("POST")
 }
 
 func createIncomingHook(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -124,6 +127,33 @@ func getIncomingHook(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// testIncomingHook shows what a payload sent to an incoming webhook would be posted as, without posting it.
+func testIncomingHook(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireHookId()
+	if c.Err != nil {
+		return
+	}
+
+	hook, err := app.GetIncomingWebhook(c.Params.HookId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, hook.TeamId, model.PERMISSION_MANAGE_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_WEBHOOKS)
+		return
+	}
+
+	req, err := app.ParseIncomingWebhookPayload(hook, r.Body)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(req.ToJson()))
+}
+
 func deleteIncomingHook(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireHookId()
 	if c.Err != nil {
@@ -226,3 +256,67 @@ func getOutgoingHooks(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	w.Write([]byte(model.OutgoingWebhookListToJson(hooks)))
 }
+
+func getOutgoingHookDeliveries(c *Context, w http.ResponseWriter, r *http.Request) {
+	hook := getOutgoingHookForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if deliveries, err := app.GetOutgoingWebhookDeliveriesPage(hook.Id, c.Params.Page, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.OutgoingWebhookDeliveryListToJson(deliveries)))
+	}
+}
+
+func redeliverOutgoingHook(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireDeliveryId()
+	if c.Err != nil {
+		return
+	}
+
+	hook := getOutgoingHookForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	delivery, err := app.RedeliverOutgoingWebhook(hook, c.Params.DeliveryId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(delivery.ToJson()))
+}
+
+// getOutgoingHookForSession loads the outgoing webhook in the URL and checks that the session can manage it.
+func getOutgoingHookForSession(c *Context) *model.OutgoingWebhook {
+	c.RequireHookId()
+	if c.Err != nil {
+		return nil
+	}
+
+	hook, err := app.GetOutgoingWebhook(c.Params.HookId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, hook.TeamId, model.PERMISSION_MANAGE_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_WEBHOOKS)
+		return nil
+	}
+
+	if hook.CreatorId != c.Session.UserId && !app.SessionHasPermissionToTeam(c.Session, hook.TeamId, model.PERMISSION_MANAGE_OTHERS_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_OTHERS_WEBHOOKS)
+		return nil
+	}
+
+	return hook
+}
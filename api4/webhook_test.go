@@ -4,8 +4,15 @@
 package api4
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
 )
@@ -197,6 +204,69 @@ func TestGetIncomingWebhook(t *testing.T) {
 	})
 }
 
+func TestTestIncomingWebhook(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.SystemAdminClient
+
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	enableAdminOnlyHooks := utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+		utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = enableAdminOnlyHooks
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = true
+	utils.SetDefaultRolesBasedOnConfig()
+
+	hook, resp := Client.CreateIncomingWebhook(&model.IncomingWebhook{
+		ChannelId:       th.BasicChannel.Id,
+		MessageTemplate: `{"text": "{{.alert.name}} is {{.alert.state}}", "attachments": [{"text": "{{.alert.details}}"}]}`,
+	})
+	CheckNoError(t, resp)
+
+	req, resp := Client.TestIncomingWebhook(hook.Id, `{"alert": {"name": "CPU", "state": "firing", "details": "90% for 5m"}}`)
+	CheckNoError(t, resp)
+
+	if req.Text != "CPU is firing" || len(req.Attachments) != 1 || req.Attachments[0].Text != "90% for 5m" {
+		t.Fatal("should have rendered the payload", req)
+	}
+
+	posts, resp := Client.GetPostsForChannel(th.BasicChannel.Id, 0, 60, "")
+	CheckNoError(t, resp)
+
+	for _, post := range posts.Posts {
+		if post.Message == req.Text {
+			t.Fatal("shouldn't have posted the payload")
+		}
+	}
+
+	_, resp = Client.TestIncomingWebhook(hook.Id, "not json")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.CreateIncomingWebhook(&model.IncomingWebhook{ChannelId: th.BasicChannel.Id, MessageTemplate: `{"text": "{{.alert"}`})
+	CheckBadRequestStatus(t, resp)
+
+	// hooks without a template show how a normal request would be posted
+	plainHook, resp := Client.CreateIncomingWebhook(&model.IncomingWebhook{ChannelId: th.BasicChannel.Id})
+	CheckNoError(t, resp)
+
+	req, resp = Client.TestIncomingWebhook(plainHook.Id, `{"text": "hello", "username": "alerts"}`)
+	CheckNoError(t, resp)
+
+	if req.Text != "hello" || req.Username != "alerts" {
+		t.Fatal("should have parsed the request", req)
+	}
+
+	_, resp = Client.TestIncomingWebhook(model.NewId(), "{}")
+	CheckNotFoundStatus(t, resp)
+
+	th.LoginBasic()
+	_, resp = th.Client.TestIncomingWebhook(hook.Id, "{}")
+	CheckForbiddenStatus(t, resp)
+}
+
 func TestDeleteIncomingWebhook(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -418,3 +488,221 @@ func TestGetOutgoingWebhooks(t *testing.T) {
 	_, resp = Client.GetOutgoingWebhooks(0, 1000, "")
 	CheckUnauthorizedStatus(t, resp)
 }
+
+func TestOutgoingWebhookDeliveries(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableOutgoingHooks := utils.Cfg.ServiceSettings.EnableOutgoingWebhooks
+	enableAdminOnlyHooks := utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOutgoingWebhooks = enableOutgoingHooks
+		utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = enableAdminOnlyHooks
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	utils.Cfg.ServiceSettings.EnableOutgoingWebhooks = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = true
+	utils.SetDefaultRolesBasedOnConfig()
+
+	// the integration fails the first request so that the delivery has to be retried
+	var requests int32
+	var signingSecret atomic.Value
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := model.VerifyRequestSignature(r, signingSecret.Load().(string)); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	hook := &model.OutgoingWebhook{ChannelId: th.BasicChannel.Id, TeamId: th.BasicChannel.TeamId, TriggerWords: []string{"trigger"}, CallbackURLs: []string{ts.URL}}
+	hook, resp := th.SystemAdminClient.CreateOutgoingWebhook(hook)
+	CheckNoError(t, resp)
+
+	if len(hook.SigningSecret) != model.SIGNING_SECRET_LENGTH {
+		t.Fatal("should have generated a signing secret")
+	}
+	signingSecret.Store(hook.SigningSecret)
+
+	th.CreateMessagePostWithClient(th.SystemAdminClient, th.BasicChannel, "trigger the hook")
+
+	delivery := waitForOutgoingWebhookDelivery(t, th.SystemAdminClient, hook.Id, model.OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS)
+	if delivery.Attempts != 2 {
+		t.Fatal("should have taken two attempts")
+	} else if delivery.StatusCode != http.StatusOK {
+		t.Fatal("should have recorded the status code")
+	} else if delivery.URL != ts.URL || delivery.ChannelId != th.BasicChannel.Id || !strings.Contains(delivery.Payload, "trigger+the+hook") {
+		t.Fatal("should have recorded what was sent")
+	}
+
+	redelivery, resp := th.SystemAdminClient.RedeliverOutgoingWebhook(hook.Id, delivery.Id)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if redelivery.Id == delivery.Id || redelivery.Payload != delivery.Payload {
+		t.Fatal("should have created a new delivery of the same payload")
+	}
+
+	if deliveries := waitForOutgoingWebhookDeliveries(t, th.SystemAdminClient, hook.Id, 2); deliveries[0].Id != redelivery.Id || deliveries[0].Attempts != 1 {
+		t.Fatal("redelivery should have been sent once")
+	}
+
+	_, resp = th.SystemAdminClient.RedeliverOutgoingWebhook(hook.Id, model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.RedeliverOutgoingWebhook(hook.Id, "junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetOutgoingWebhookDeliveries(model.NewId(), 0, 10)
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.GetOutgoingWebhookDeliveries(hook.Id, 0, 10)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.RedeliverOutgoingWebhook(hook.Id, delivery.Id)
+	CheckForbiddenStatus(t, resp)
+
+	// deliveries can't be sent again once their URL is no longer one of the hook's callback URLs
+	updatedHook := *hook
+	updatedHook.CallbackURLs = []string{"http://nowhere.com/"}
+	if _, err := app.UpdateOutgoingWebhook(hook, &updatedHook); err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp = th.SystemAdminClient.RedeliverOutgoingWebhook(hook.Id, delivery.Id)
+	CheckBadRequestStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetOutgoingWebhookDeliveries(hook.Id, 0, 10)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestOutgoingWebhookEvents(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	enableOutgoingHooks := utils.Cfg.ServiceSettings.EnableOutgoingWebhooks
+	enableAdminOnlyHooks := utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOutgoingWebhooks = enableOutgoingHooks
+		utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = enableAdminOnlyHooks
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	utils.Cfg.ServiceSettings.EnableOutgoingWebhooks = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = true
+	utils.SetDefaultRolesBasedOnConfig()
+
+	payloads := make(chan *model.OutgoingWebhookPayload, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload model.OutgoingWebhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		payloads <- &payload
+
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	channel := th.CreateChannelWithClient(th.SystemAdminClient, model.CHANNEL_PRIVATE)
+
+	hook := &model.OutgoingWebhook{
+		ChannelId:    channel.Id,
+		TeamId:       channel.TeamId,
+		CallbackURLs: []string{ts.URL},
+		ContentType:  "application/json",
+		Events:       []string{model.OUTGOING_WEBHOOK_EVENT_POSTED, model.OUTGOING_WEBHOOK_EVENT_POST_EDITED, model.OUTGOING_WEBHOOK_EVENT_REACTION_ADDED},
+	}
+
+	_, resp := th.SystemAdminClient.CreateOutgoingWebhook(hook)
+	CheckForbiddenStatus(t, resp)
+
+	hook.IncludePrivateChannels = true
+	hook, resp = th.SystemAdminClient.CreateOutgoingWebhook(hook)
+	CheckNoError(t, resp)
+
+	waitForPayload := func(event string) *model.OutgoingWebhookPayload {
+		select {
+		case payload := <-payloads:
+			if payload.Event != event {
+				t.Fatalf("expected %v event, got %v", event, payload.Event)
+			} else if payload.Version != model.OUTGOING_WEBHOOK_PAYLOAD_VERSION {
+				t.Fatal("should have sent the payload version")
+			} else if payload.ChannelId != channel.Id || payload.Token != hook.Token {
+				t.Fatal("should have sent the channel and token")
+			}
+			return payload
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for the %v event", event)
+		}
+		return nil
+	}
+
+	post := th.CreateMessagePostWithClient(th.SystemAdminClient, channel, "first message")
+	if payload := waitForPayload(model.OUTGOING_WEBHOOK_EVENT_POSTED); payload.PostId != post.Id || payload.Text != "first message" {
+		t.Fatal("should have sent the new post")
+	}
+
+	post.Message = "edited message"
+	_, resp = th.SystemAdminClient.UpdatePost(post.Id, post)
+	CheckNoError(t, resp)
+
+	if payload := waitForPayload(model.OUTGOING_WEBHOOK_EVENT_POST_EDITED); payload.PostId != post.Id || payload.Text != "edited message" {
+		t.Fatal("should have sent the edited post")
+	}
+
+	_, resp = th.SystemAdminClient.SaveReaction(&model.Reaction{UserId: th.SystemAdminUser.Id, PostId: post.Id, EmojiName: "smile"})
+	CheckNoError(t, resp)
+
+	if payload := waitForPayload(model.OUTGOING_WEBHOOK_EVENT_REACTION_ADDED); payload.EmojiName != "smile" || payload.UserId != th.SystemAdminUser.Id {
+		t.Fatal("should have sent the reaction")
+	}
+
+	// the hook isn't subscribed to deleted posts
+	_, resp = th.SystemAdminClient.DeletePost(post.Id)
+	CheckNoError(t, resp)
+
+	select {
+	case payload := <-payloads:
+		t.Fatalf("shouldn't have sent the %v event", payload.Event)
+	case <-time.After(time.Second):
+	}
+}
+
+func waitForOutgoingWebhookDelivery(t *testing.T, client *model.Client4, hookId string, status string) *model.OutgoingWebhookDelivery {
+	for i := 0; i < 100; i++ {
+		deliveries, resp := client.GetOutgoingWebhookDeliveries(hookId, 0, 10)
+		CheckNoError(t, resp)
+
+		if len(deliveries) > 0 && deliveries[0].Status == status {
+			return deliveries[0]
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	t.Fatal("timed out waiting for the delivery")
+	return nil
+}
+
+func waitForOutgoingWebhookDeliveries(t *testing.T, client *model.Client4, hookId string, count int) []*model.OutgoingWebhookDelivery {
+	for i := 0; i < 100; i++ {
+		deliveries, resp := client.GetOutgoingWebhookDeliveries(hookId, 0, 10)
+		CheckNoError(t, resp)
+
+		if len(deliveries) == count && deliveries[0].Status == model.OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS {
+			return deliveries
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	t.Fatal("timed out waiting for the deliveries")
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/jobs"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
@@ -156,6 +157,7 @@ func RecycleDatabaseConnection() {
 
 	l4g.Warn(utils.T("api.admin.recycle_db_start.warn"))
 	Srv.Store = store.NewSqlStore()
+	jobs.Srv.Store = Srv.Store
 
 	time.Sleep(20 * time.Second)
 	oldStore.Close()
@@ -89,6 +89,33 @@ func SessionHasPermissionToUser(session model.Session, userId string) bool {
 	return false
 }
 
+// SessionHasPermissionToBot checks whether the session can manage the given bot, either because the user owns it or
+// because they can manage every bot.
+func SessionHasPermissionToBot(session model.Session, bot *model.User) bool {
+	if !bot.IsBot {
+		return false
+	}
+
+	if bot.BotOwnerId == session.UserId && SessionHasPermissionTo(session, model.PERMISSION_MANAGE_BOTS) {
+		return true
+	}
+
+	return SessionHasPermissionTo(session, model.PERMISSION_MANAGE_OTHERS_BOTS)
+}
+
+// SessionHasPermissionToUserOrBot is like SessionHasPermissionToUser, but also lets bot owners act on their bots.
+func SessionHasPermissionToUserOrBot(session model.Session, userId string) bool {
+	if SessionHasPermissionToUser(session, userId) {
+		return true
+	}
+
+	if bot, err := GetBot(userId); err == nil {
+		return SessionHasPermissionToBot(session, bot)
+	}
+
+	return false
+}
+
 func SessionHasPermissionToPost(session model.Session, postId string, permission *model.Permission) bool {
 	post, err := GetSinglePost(postId)
 	if err != nil {
@@ -184,6 +211,18 @@ func HasPermissionToUser(askingUserId string, userId string) bool {
 	return false
 }
 
+func HasPermissionToBot(askingUserId string, bot *model.User) bool {
+	if !bot.IsBot {
+		return false
+	}
+
+	if bot.BotOwnerId == askingUserId && HasPermissionTo(askingUserId, model.PERMISSION_MANAGE_BOTS) {
+		return true
+	}
+
+	return HasPermissionTo(askingUserId, model.PERMISSION_MANAGE_OTHERS_BOTS)
+}
+
 func CheckIfRolesGrantPermission(roles []string, permissionId string) bool {
 	for _, roleId := range roles {
 		if role, ok := model.BuiltInRoles[roleId]; !ok {
@@ -0,0 +1,169 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	BOT_EMAIL_DOMAIN = "@localhost"
+)
+
+// CreateBot creates a bot account owned by the given user. Bots are users that can't log in, so they use user
+// access tokens or integrations to post.
+func CreateBot(bot *model.User, ownerId string) (*model.User, *model.AppError) {
+	if _, err := GetUser(ownerId); err != nil {
+		return nil, err
+	}
+
+	bot.IsBot = true
+	bot.BotOwnerId = ownerId
+	bot.Password = ""
+	bot.AuthData = nil
+	bot.AuthService = ""
+	bot.EmailVerified = true
+	bot.Roles = model.ROLE_SYSTEM_USER.Id
+	bot.Locale = *utils.Cfg.LocalizationSettings.DefaultClientLocale
+
+	// bots never receive email, but the address still has to be valid and unique
+	bot.Email = model.NewId() + BOT_EMAIL_DOMAIN
+
+	if ruser, err := saveNewUser(bot); err != nil {
+		return nil, err
+	} else {
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_NEW_USER, "", "", "", nil)
+		message.Add("user_id", ruser.Id)
+		go Publish(message)
+
+		return ruser, nil
+	}
+}
+
+func GetBot(botUserId string) (*model.User, *model.AppError) {
+	if user, err := GetUser(botUserId); err != nil {
+		return nil, err
+	} else if !user.IsBot {
+		return nil, model.NewAppError("GetBot", "app.bot.get.not_found.app_error", nil, "user_id="+botUserId, http.StatusNotFound)
+	} else {
+		return user, nil
+	}
+}
+
+func GetBotsPage(ownerId string, includeDeleted bool, page, perPage int) ([]*model.User, *model.AppError) {
+	if result := <-Srv.Store.User().GetBots(ownerId, includeDeleted, page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.User), nil
+	}
+}
+
+func UpdateBotActive(bot *model.User, active bool) (*model.User, *model.AppError) {
+	if ruser, err := UpdateActive(bot, active); err != nil {
+		return nil, err
+	} else {
+		sendUpdatedUserEvent(ruser)
+		return ruser, nil
+	}
+}
+
+// AssignBot transfers the ownership of a bot to another active user.
+func AssignBot(bot *model.User, ownerId string) (*model.User, *model.AppError) {
+	if owner, err := GetUser(ownerId); err != nil {
+		return nil, err
+	} else if owner.DeleteAt != 0 || owner.IsBot {
+		return nil, model.NewAppError("AssignBot", "app.bot.assign.owner.app_error", nil, "owner_id="+ownerId, http.StatusBadRequest)
+	}
+
+	bot.BotOwnerId = ownerId
+
+	if result := <-Srv.Store.User().Update(bot, true); result.Err != nil {
+		return nil, result.Err
+	} else {
+		ruser := result.Data.([2]*model.User)[0]
+		InvalidateCacheForUser(ruser.Id)
+		ruser.Sanitize(map[string]bool{})
+		sendUpdatedUserEvent(ruser)
+		return ruser, nil
+	}
+}
+
+// ValidateBotForIntegration checks that an integration created by the given user may post as the bot.
+func ValidateBotForIntegration(botUserId, creatorId string) *model.AppError {
+	if botUserId == "" {
+		return nil
+	}
+
+	bot, err := getActiveBot(botUserId)
+	if err != nil {
+		return err
+	}
+
+	if !HasPermissionToBot(creatorId, bot) {
+		return model.NewAppError("ValidateBotForIntegration", "app.bot.integration.permissions.app_error", nil, "bot_user_id="+botUserId+" creator_id="+creatorId, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+// getPostingUserId returns the id of the user that an integration should post as, which is its bot if it has one.
+func getPostingUserId(userId, botUserId string) (string, *model.AppError) {
+	if botUserId == "" {
+		return userId, nil
+	}
+
+	if _, err := getActiveBot(botUserId); err != nil {
+		return "", err
+	}
+
+	return botUserId, nil
+}
+
+func getActiveBot(botUserId string) (*model.User, *model.AppError) {
+	bot, err := GetBot(botUserId)
+	if err != nil {
+		return nil, model.NewAppError("getActiveBot", "app.bot.inactive.app_error", nil, "bot_user_id="+botUserId+", "+err.Error(), http.StatusBadRequest)
+	}
+
+	if bot.DeleteAt != 0 {
+		return nil, model.NewAppError("getActiveBot", "app.bot.inactive.app_error", nil, "bot_user_id="+botUserId, http.StatusBadRequest)
+	}
+
+	return bot, nil
+}
+
+// GetBotUserIdForOAuthSession returns the bot that posts made with an OAuth access token should be attributed to,
+// or an empty string if the OAuth app doesn't post as a bot.
+func GetBotUserIdForOAuthSession(session *model.Session) (string, *model.AppError) {
+	if !session.IsOAuth {
+		return "", nil
+	}
+
+	var accessData *model.AccessData
+	if result := <-Srv.Store.OAuth().GetAccessData(session.Token); result.Err != nil {
+		return "", result.Err
+	} else {
+		accessData = result.Data.(*model.AccessData)
+	}
+
+	var oauthApp *model.OAuthApp
+	if result := <-Srv.Store.OAuth().GetApp(accessData.ClientId); result.Err != nil {
+		return "", result.Err
+	} else {
+		oauthApp = result.Data.(*model.OAuthApp)
+	}
+
+	if oauthApp.BotUserId == "" {
+		return "", nil
+	}
+
+	if _, err := getActiveBot(oauthApp.BotUserId); err != nil {
+		return "", err
+	}
+
+	return oauthApp.BotUserId, nil
+}
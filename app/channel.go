@@ -134,6 +134,11 @@ func CreateChannel(channel *model.Channel, addMember bool) (*model.Channel, *mod
 			InvalidateCacheForUser(channel.CreatorId)
 		}
 
+		// channels created along with a team or by an import don't have a creator
+		if len(sc.CreatorId) > 0 {
+			go handleWebhookEvent(model.OUTGOING_WEBHOOK_EVENT_CHANNEL_CREATED, sc.Id, sc.CreatorId, nil, "")
+		}
+
 		return sc, nil
 	}
 }
@@ -443,6 +448,8 @@ func AddUserToChannel(user *model.User, channel *model.Channel) (*model.ChannelM
 	message.Add("team_id", channel.TeamId)
 	Publish(message)
 
+	go handleWebhookEvent(model.OUTGOING_WEBHOOK_EVENT_USER_JOINED_CHANNEL, channel.Id, user.Id, nil, "")
+
 	return newMember, nil
 }
 
@@ -873,6 +880,8 @@ func removeUserFromChannel(userIdToRemove string, removerUserId string, channel
 	userMsg.Add("remover_id", removerUserId)
 	go Publish(userMsg)
 
+	go handleWebhookEvent(model.OUTGOING_WEBHOOK_EVENT_USER_LEFT_CHANNEL, channel.Id, userIdToRemove, nil, "")
+
 	return nil
 }
 
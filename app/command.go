@@ -42,7 +42,7 @@ func CreateCommandPost(post *model.Post, teamId string, response *model.CommandR
 	post.Message = parseSlackLinksToMarkdown(response.Text)
 	post.CreateAt = model.GetMillis()
 
-	if response.Attachments != nil {
+	if len(response.Attachments) > 0 {
 		parseSlackAttachment(post, response.Attachments)
 	}
 
@@ -111,8 +111,13 @@ func ExecuteCommand(args *model.CommandArgs) (*model.CommandResponse, *model.App
 	provider := GetCommandProvider(trigger)
 
 	if provider != nil {
+		cmd := provider.GetCommand(args.T)
+		if response := parseCommand(cmd, args, message); response != nil {
+			return HandleCommandResponse(cmd, args, response, true)
+		}
+
 		response := provider.DoCommand(args, message)
-		return HandleCommandResponse(provider.GetCommand(args.T), args, response, true)
+		return HandleCommandResponse(cmd, args, response, true)
 	} else {
 		if !*utils.Cfg.ServiceSettings.EnableCommands {
 			return nil, model.NewAppError("ExecuteCommand", "api.command.disabled.app_error", nil, "", http.StatusNotImplemented)
@@ -152,6 +157,10 @@ func ExecuteCommand(args *model.CommandArgs) (*model.CommandResponse, *model.App
 				if trigger == cmd.Trigger {
 					l4g.Debug(fmt.Sprintf(utils.T("api.command.execute_command.debug"), trigger, args.UserId))
 
+					if response := parseCommand(cmd, args, message); response != nil {
+						return HandleCommandResponse(cmd, args, response, false)
+					}
+
 					p := url.Values{}
 					p.Set("token", cmd.Token)
 
@@ -166,7 +175,22 @@ func ExecuteCommand(args *model.CommandArgs) (*model.CommandResponse, *model.App
 
 					p.Set("command", "/"+trigger)
 					p.Set("text", message)
-					p.Set("response_url", "not supported yet")
+
+					if parsed := args.ParsedCommand; parsed != nil {
+						p.Set("subcommand", parsed.Subcommand)
+						for name, value := range parsed.Arguments {
+							p.Set("arg_"+name, value)
+						}
+						for name, id := range parsed.Ids {
+							p.Set("arg_"+name+"_id", id)
+						}
+					}
+
+					if responseURL, err := CreateCommandWebhookURL(cmd.Id, args); err != nil {
+						return nil, err
+					} else {
+						p.Set("response_url", responseURL)
+					}
 
 					method := "POST"
 					if cmd.Method == model.COMMAND_METHOD_GET {
@@ -178,19 +202,28 @@ func ExecuteCommand(args *model.CommandArgs) (*model.CommandResponse, *model.App
 					}
 					client := &http.Client{Transport: tr}
 
-					req, _ := http.NewRequest(method, cmd.URL, strings.NewReader(p.Encode()))
+					body := p.Encode()
+
+					req, _ := http.NewRequest(method, cmd.URL, strings.NewReader(body))
 					req.Header.Set("Accept", "application/json")
 					if cmd.Method == model.COMMAND_METHOD_POST {
 						req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 					}
 
+					// commands created before signing secrets were added don't have one until they're updated
+					if cmd.SigningSecret != "" {
+						model.SignRequest(req, cmd.SigningSecret, []byte(body))
+					}
+
 					if resp, err := client.Do(req); err != nil {
 						return nil, model.NewAppError("command", "api.command.execute_command.failed.app_error", map[string]interface{}{"Trigger": trigger}, err.Error(), http.StatusInternalServerError)
 					} else {
 						if resp.StatusCode == http.StatusOK {
-							response := model.CommandResponseFromJson(resp.Body)
-							if response == nil {
+							response, err := model.CommandResponseFromHTTPBody(resp.Body)
+							if err != nil && err.Id == "model.command_response.empty.app_error" {
 								return nil, model.NewAppError("command", "api.command.execute_command.failed_empty.app_error", map[string]interface{}{"Trigger": trigger}, "", http.StatusInternalServerError)
+							} else if err != nil {
+								return nil, model.NewAppError("command", "api.command.execute_command.invalid_resp.app_error", map[string]interface{}{"Trigger": trigger}, err.Error(), http.StatusBadRequest)
 							} else {
 								return HandleCommandResponse(cmd, args, response, false)
 							}
@@ -208,6 +241,44 @@ func ExecuteCommand(args *model.CommandArgs) (*model.CommandResponse, *model.App
 	return nil, model.NewAppError("command", "api.command.execute_command.not_found.app_error", map[string]interface{}{"Trigger": trigger}, "", http.StatusNotFound)
 }
 
+// parseCommand parses the message of a command that describes its subcommands and arguments, and stores the result in
+// the command's args. If the message doesn't match, the returned response explains why to the user.
+func parseCommand(cmd *model.Command, args *model.CommandArgs, message string) *model.CommandResponse {
+	if cmd.AutocompleteData == nil {
+		return nil
+	}
+
+	parsed, err := cmd.AutocompleteData.Parse(message)
+	if err != nil {
+		err.Translate(args.T)
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: err.Message}
+	}
+
+	// look up the users and channels named in the command, but leave it to the command to decide what to do when
+	// they don't exist
+	for _, argument := range cmd.AutocompleteData.FindSubcommand(parsed.Subcommand).Arguments {
+		value, ok := parsed.Arguments[argument.Name]
+		if !ok {
+			continue
+		}
+
+		switch argument.Type {
+		case model.COMMAND_ARGUMENT_TYPE_USER:
+			if result := <-Srv.Store.User().GetByUsername(value); result.Err == nil {
+				parsed.Ids[argument.Name] = result.Data.(*model.User).Id
+			}
+		case model.COMMAND_ARGUMENT_TYPE_CHANNEL:
+			if result := <-Srv.Store.Channel().GetByName(args.TeamId, value, true); result.Err == nil {
+				parsed.Ids[argument.Name] = result.Data.(*model.Channel).Id
+			}
+		}
+	}
+
+	args.ParsedCommand = parsed
+
+	return nil
+}
+
 func HandleCommandResponse(command *model.Command, args *model.CommandArgs, response *model.CommandResponse, builtIn bool) (*model.CommandResponse, *model.AppError) {
 	post := &model.Post{}
 	post.ChannelId = args.ChannelId
@@ -215,6 +286,15 @@ func HandleCommandResponse(command *model.Command, args *model.CommandArgs, resp
 	post.ParentId = args.ParentId
 	post.UserId = args.UserId
 
+	// ephemeral responses are always sent to the user that ran the command
+	if response.ResponseType == model.COMMAND_RESPONSE_TYPE_IN_CHANNEL {
+		if userId, err := getPostingUserId(args.UserId, command.BotUserId); err != nil {
+			return nil, err
+		} else {
+			post.UserId = userId
+		}
+	}
+
 	if !builtIn {
 		post.AddProp("from_webhook", "true")
 	}
@@ -251,6 +331,10 @@ func CreateCommand(cmd *model.Command) (*model.Command, *model.AppError) {
 
 	cmd.Trigger = strings.ToLower(cmd.Trigger)
 
+	if err := ValidateBotForIntegration(cmd.BotUserId, cmd.CreatorId); err != nil {
+		return nil, err
+	}
+
 	if result := <-Srv.Store.Command().GetByTeam(cmd.TeamId); result.Err != nil {
 		return nil, result.Err
 	} else {
@@ -281,6 +365,7 @@ func GetCommand(commandId string) (*model.Command, *model.AppError) {
 	}
 
 	if result := <-Srv.Store.Command().Get(commandId); result.Err != nil {
+		result.Err.StatusCode = http.StatusNotFound
 		return nil, result.Err
 	} else {
 		return result.Data.(*model.Command), nil
@@ -301,6 +386,17 @@ func UpdateCommand(oldCmd, updatedCmd *model.Command) (*model.Command, *model.Ap
 	updatedCmd.CreatorId = oldCmd.CreatorId
 	updatedCmd.TeamId = oldCmd.TeamId
 
+	updatedCmd.SigningSecret = oldCmd.SigningSecret
+	if updatedCmd.SigningSecret == "" {
+		updatedCmd.SigningSecret = model.NewSigningSecret()
+	}
+
+	if updatedCmd.BotUserId != oldCmd.BotUserId {
+		if err := ValidateBotForIntegration(updatedCmd.BotUserId, oldCmd.CreatorId); err != nil {
+			return nil, err
+		}
+	}
+
 	if result := <-Srv.Store.Command().Update(updatedCmd); result.Err != nil {
 		return nil, result.Err
 	} else {
@@ -314,6 +410,7 @@ func RegenCommandToken(cmd *model.Command) (*model.Command, *model.AppError) {
 	}
 
 	cmd.Token = model.NewId()
+	cmd.SigningSecret = model.NewSigningSecret()
 
 	if result := <-Srv.Store.Command().Update(cmd); result.Err != nil {
 		return nil, result.Err
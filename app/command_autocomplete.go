@@ -0,0 +1,159 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+	goi18n "github.com/nicksnyder/go-i18n/i18n"
+)
+
+const COMMAND_SUGGESTIONS_MAX = 25
+
+// GetCommandSuggestions suggests ways to finish a command line that a user has partially typed. Triggers are suggested
+// until the first space, and after that the command's subcommands, enum options, users or channels depending on what
+// its autocomplete data expects next. Each suggestion is the whole command line with the last word completed.
+func GetCommandSuggestions(userInput, teamId string, T goi18n.TranslateFunc) ([]*model.SuggestCommand, *model.AppError) {
+	suggestions := []*model.SuggestCommand{}
+
+	if !strings.HasPrefix(userInput, "/") {
+		return suggestions, nil
+	}
+
+	commands, err := ListCommands(teamId, T)
+	if err != nil {
+		return nil, err
+	}
+
+	i := strings.IndexFunc(userInput, unicode.IsSpace)
+	if i == -1 {
+		partial := strings.ToLower(userInput[1:])
+
+		matches := map[string]*model.Command{}
+		triggers := []string{}
+		for _, cmd := range commands {
+			if strings.HasPrefix(cmd.Trigger, partial) {
+				matches[cmd.Trigger] = cmd
+				triggers = append(triggers, cmd.Trigger)
+			}
+		}
+
+		sort.Strings(triggers)
+
+		for _, trigger := range triggers {
+			suggestions = append(suggestions, &model.SuggestCommand{
+				Suggestion:  "/" + trigger,
+				Description: matches[trigger].AutoCompleteDesc,
+				Hint:        matches[trigger].AutoCompleteHint,
+			})
+		}
+
+		return suggestions, nil
+	}
+
+	trigger := strings.ToLower(userInput[1:i])
+	message := userInput[i:]
+
+	var command *model.Command
+	for _, cmd := range commands {
+		if cmd.Trigger == trigger {
+			command = cmd
+			break
+		}
+	}
+
+	if command == nil || command.AutocompleteData == nil {
+		return suggestions, nil
+	}
+
+	data, argument := command.AutocompleteData.FindPosition(message)
+	if data == nil {
+		return suggestions, nil
+	}
+
+	partial := ""
+	if words := strings.Fields(message); len(words) > 0 && !strings.HasSuffix(message, " ") {
+		partial = words[len(words)-1]
+	}
+	prefix := userInput[:len(userInput)-len(partial)]
+
+	if argument == nil {
+		for _, subcommand := range data.Subcommands {
+			if strings.HasPrefix(subcommand.Trigger, partial) {
+				suggestions = append(suggestions, &model.SuggestCommand{
+					Suggestion:  prefix + subcommand.Trigger,
+					Description: subcommand.HelpText,
+					Hint:        subcommand.Hint(),
+				})
+			}
+		}
+
+		return suggestions, nil
+	}
+
+	switch argument.Type {
+	case model.COMMAND_ARGUMENT_TYPE_ENUM:
+		for _, option := range argument.Options {
+			if strings.HasPrefix(option, partial) {
+				suggestions = append(suggestions, &model.SuggestCommand{
+					Suggestion:  prefix + option,
+					Description: argument.HelpText,
+				})
+			}
+		}
+	case model.COMMAND_ARGUMENT_TYPE_USER:
+		if term := strings.TrimPrefix(partial, "@"); term != "" {
+			searchOptions := map[string]bool{store.USER_SEARCH_OPTION_NAMES_ONLY: true}
+			if !utils.Cfg.PrivacySettings.ShowFullName {
+				searchOptions = map[string]bool{store.USER_SEARCH_OPTION_NAMES_ONLY_NO_FULL_NAME: true}
+			}
+
+			result, err := AutocompleteUsersInTeam(teamId, term, searchOptions, false)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, user := range result.InTeam {
+				suggestions = append(suggestions, &model.SuggestCommand{
+					Suggestion:  prefix + "@" + user.Username,
+					Description: user.GetDisplayName(),
+				})
+			}
+		}
+	case model.COMMAND_ARGUMENT_TYPE_CHANNEL:
+		if term := strings.TrimPrefix(partial, "~"); term != "" {
+			channels, err := SearchChannels(teamId, term)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, channel := range *channels {
+				suggestions = append(suggestions, &model.SuggestCommand{
+					Suggestion:  prefix + "~" + channel.Name,
+					Description: channel.DisplayName,
+				})
+			}
+		}
+	}
+
+	if len(suggestions) > COMMAND_SUGGESTIONS_MAX {
+		suggestions = suggestions[:COMMAND_SUGGESTIONS_MAX]
+	}
+
+	if len(suggestions) == 0 {
+		// there's nothing to pick from, so just describe what's expected next
+		suggestions = append(suggestions, &model.SuggestCommand{
+			Suggestion:  userInput,
+			Description: argument.HelpText,
+			Hint:        "[" + argument.Name + "]",
+		})
+	}
+
+	return suggestions, nil
+}
@@ -30,10 +30,24 @@ func (me *JoinProvider) GetCommand(T goi18n.TranslateFunc) *model.Command {
 		AutoCompleteDesc: T("api.command_join.desc"),
 		AutoCompleteHint: T("api.command_join.hint"),
 		DisplayName:      T("api.command_join.name"),
+		AutocompleteData: &model.CommandAutocompleteData{
+			Arguments: []*model.CommandArgument{
+				{Name: "channel", Type: model.COMMAND_ARGUMENT_TYPE_CHANNEL, HelpText: T("api.command_join.channel.help"), Required: true},
+			},
+		},
 	}
 }
 
 func (me *JoinProvider) DoCommand(args *model.CommandArgs, message string) *model.CommandResponse {
+	// commands run without going through ExecuteCommand haven't been parsed yet
+	if args.ParsedCommand == nil {
+		if response := parseCommand(me.GetCommand(args.T), args, message); response != nil {
+			return response
+		}
+	}
+
+	message = args.ParsedCommand.Arguments["channel"]
+
 	if result := <-Srv.Store.Channel().GetByName(args.TeamId, message, true); result.Err != nil {
 		return &model.CommandResponse{Text: args.T("api.command_join.list.app_error"), ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL}
 	} else {
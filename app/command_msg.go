@@ -4,8 +4,6 @@
 package app
 
 import (
-	"strings"
-
 	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/platform/model"
 	goi18n "github.com/nicksnyder/go-i18n/i18n"
@@ -33,23 +31,27 @@ func (me *msgProvider) GetCommand(T goi18n.TranslateFunc) *model.Command {
 		AutoCompleteDesc: T("api.command_msg.desc"),
 		AutoCompleteHint: T("api.command_msg.hint"),
 		DisplayName:      T("api.command_msg.name"),
+		AutocompleteData: &model.CommandAutocompleteData{
+			Arguments: []*model.CommandArgument{
+				{Name: "user", Type: model.COMMAND_ARGUMENT_TYPE_USER, HelpText: T("api.command_msg.user.help"), Required: true},
+				{Name: "message", Type: model.COMMAND_ARGUMENT_TYPE_TEXT, HelpText: T("api.command_msg.message.help")},
+			},
+		},
 	}
 }
 
 func (me *msgProvider) DoCommand(args *model.CommandArgs, message string) *model.CommandResponse {
+	// commands run without going through ExecuteCommand haven't been parsed yet
+	if args.ParsedCommand == nil {
+		if response := parseCommand(me.GetCommand(args.T), args, message); response != nil {
+			return response
+		}
+	}
 
-	splitMessage := strings.SplitN(message, " ", 2)
-
-	parsedMessage := ""
-	targetUsername := ""
+	parsedMessage := args.ParsedCommand.Arguments["message"]
+	targetUsername := args.ParsedCommand.Arguments["user"]
 	teamId := ""
 
-	if len(splitMessage) > 1 {
-		parsedMessage = strings.SplitN(message, " ", 2)[1]
-	}
-	targetUsername = strings.SplitN(message, " ", 2)[0]
-	targetUsername = strings.TrimPrefix(targetUsername, "@")
-
 	var userProfile *model.User
 	if result := <-Srv.Store.User().GetByUsername(targetUsername); result.Err != nil {
 		l4g.Error(result.Err.Error())
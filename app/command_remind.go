@@ -0,0 +1,240 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	goi18n "github.com/nicksnyder/go-i18n/i18n"
+)
+
+type RemindProvider struct {
+}
+
+const (
+	CMD_REMIND = "remind"
+
+	REMIND_LIST_MAX    = 50
+	REMIND_TIME_FORMAT = "Mon Jan 2 15:04 MST"
+)
+
+// remindPeriods maps the periods accepted by /remind every to the recurrences of the posts that they create.
+var remindPeriods = map[string]string{
+	"day":     model.SCHEDULED_POST_RECURRENCE_DAILY,
+	"weekday": model.SCHEDULED_POST_RECURRENCE_WEEKDAYS,
+	"week":    model.SCHEDULED_POST_RECURRENCE_WEEKLY,
+	"month":   model.SCHEDULED_POST_RECURRENCE_MONTHLY,
+}
+
+func init() {
+	RegisterCommandProvider(&RemindProvider{})
+}
+
+func (me *RemindProvider) GetTrigger() string {
+	return CMD_REMIND
+}
+
+func (me *RemindProvider) GetCommand(T goi18n.TranslateFunc) *model.Command {
+	return &model.Command{
+		Trigger:          CMD_REMIND,
+		AutoComplete:     true,
+		AutoCompleteDesc: T("api.command_remind.desc"),
+		AutoCompleteHint: T("api.command_remind.hint"),
+		DisplayName:      T("api.command_remind.name"),
+		AutocompleteData: &model.CommandAutocompleteData{
+			Subcommands: []*model.CommandAutocompleteData{
+				{
+					Trigger:  "in",
+					HelpText: T("api.command_remind.in.help"),
+					Arguments: []*model.CommandArgument{
+						{Name: "reminder", Type: model.COMMAND_ARGUMENT_TYPE_TEXT, HelpText: T("api.command_remind.reminder.help"), Required: true},
+					},
+				},
+				{
+					Trigger:  "every",
+					HelpText: T("api.command_remind.every.help"),
+					Arguments: []*model.CommandArgument{
+						{Name: "period", Type: model.COMMAND_ARGUMENT_TYPE_ENUM, HelpText: T("api.command_remind.period.help"), Required: true, Options: []string{"day", "weekday", "week", "month"}},
+						{Name: "message", Type: model.COMMAND_ARGUMENT_TYPE_TEXT, HelpText: T("api.command_remind.message.help"), Required: true},
+					},
+				},
+				{
+					Trigger:  "list",
+					HelpText: T("api.command_remind.list.help"),
+				},
+				{
+					Trigger:  "cancel",
+					HelpText: T("api.command_remind.cancel.help"),
+					Arguments: []*model.CommandArgument{
+						{Name: "id", Type: model.COMMAND_ARGUMENT_TYPE_TEXT, HelpText: T("api.command_remind.id.help"), Required: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (me *RemindProvider) DoCommand(args *model.CommandArgs, message string) *model.CommandResponse {
+	// commands run without going through ExecuteCommand haven't been parsed yet
+	if args.ParsedCommand == nil {
+		if response := parseCommand(me.GetCommand(args.T), args, message); response != nil {
+			return response
+		}
+	}
+
+	parsed := args.ParsedCommand
+
+	var text string
+	switch parsed.Subcommand {
+	case "in":
+		text = doRemindIn(args, parsed.Arguments["reminder"])
+	case "every":
+		text = doRemindEvery(args, parsed.Arguments["period"], parsed.Arguments["message"])
+	case "list":
+		text = doRemindList(args)
+	case "cancel":
+		text = doRemindCancel(args, parsed.Arguments["id"])
+	}
+
+	return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: text}
+}
+
+func doRemindIn(args *model.CommandArgs, reminder string) string {
+	when, message := splitRemindTime(reminder)
+
+	duration, ok := parseRemindDuration(when)
+	if !ok || message == "" {
+		return args.T("api.command_remind.duration.app_error")
+	}
+
+	scheduledPost := &model.ScheduledPost{
+		UserId:      args.UserId,
+		ChannelId:   args.ChannelId,
+		RootId:      args.RootId,
+		Message:     message,
+		ScheduledAt: model.GetMillis() + int64(duration/time.Millisecond),
+	}
+
+	if _, err := CreateScheduledPost(scheduledPost); err != nil {
+		err.Translate(args.T)
+		return err.Message
+	}
+
+	return args.T("api.command_remind.in.success", map[string]interface{}{"Duration": when})
+}
+
+func doRemindEvery(args *model.CommandArgs, period, message string) string {
+	now := model.GetMillis()
+
+	// recurring reminders are sent at the same time of day in the user's timezone, and clients that don't say what
+	// that is get UTC, which the reply shows
+	timezone := args.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	scheduledPost := &model.ScheduledPost{
+		UserId:      args.UserId,
+		ChannelId:   args.ChannelId,
+		RootId:      args.RootId,
+		Message:     message,
+		ScheduledAt: now,
+		Recurrence:  remindPeriods[period],
+		Timezone:    timezone,
+	}
+	scheduledPost.ScheduledAt = scheduledPost.NextScheduledAt(now)
+
+	if _, err := CreateScheduledPost(scheduledPost); err != nil {
+		err.Translate(args.T)
+		return err.Message
+	}
+
+	return args.T("api.command_remind.every.success", map[string]interface{}{"Period": period, "Time": formatRemindTime(scheduledPost.ScheduledAt, scheduledPost.Timezone)})
+}
+
+func doRemindList(args *model.CommandArgs) string {
+	scheduledPosts, err := GetScheduledPostsForUserPage(args.UserId, 0, REMIND_LIST_MAX)
+	if err != nil {
+		err.Translate(args.T)
+		return err.Message
+	}
+
+	if len(scheduledPosts) == 0 {
+		return args.T("api.command_remind.list.empty")
+	}
+
+	lines := []string{args.T("api.command_remind.list.title")}
+	for _, scheduledPost := range scheduledPosts {
+		item := map[string]interface{}{
+			"Id":         scheduledPost.Id,
+			"Time":       formatRemindTime(scheduledPost.ScheduledAt, scheduledPost.Timezone),
+			"Recurrence": scheduledPost.Recurrence,
+			"Message":    scheduledPost.Message,
+		}
+
+		if scheduledPost.Recurrence == model.SCHEDULED_POST_RECURRENCE_NONE {
+			lines = append(lines, args.T("api.command_remind.list.item", item))
+		} else {
+			lines = append(lines, args.T("api.command_remind.list.item_recurring", item))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func doRemindCancel(args *model.CommandArgs, id string) string {
+	if scheduledPost, err := GetScheduledPost(id); err != nil || scheduledPost.UserId != args.UserId {
+		return args.T("api.command_remind.cancel.not_found")
+	}
+
+	if err := CancelScheduledPost(id); err != nil {
+		err.Translate(args.T)
+		return err.Message
+	}
+
+	return args.T("api.command_remind.cancel.success")
+}
+
+// parseRemindDuration parses a duration such as 90s, 30m or 1h30m, and also accepts a number of days such as 2d.
+func parseRemindDuration(when string) (time.Duration, bool) {
+	var duration time.Duration
+	if strings.HasSuffix(when, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(when, "d"))
+		if err != nil {
+			return 0, false
+		}
+		duration = time.Duration(days) * 24 * time.Hour
+	} else {
+		var err error
+		if duration, err = time.ParseDuration(when); err != nil {
+			return 0, false
+		}
+	}
+
+	return duration, duration > 0
+}
+
+// formatRemindTime formats a time in the given timezone, or in UTC if it's empty.
+func formatRemindTime(millis int64, timezone string) string {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	return time.Unix(0, millis*int64(time.Millisecond)).In(location).Format(REMIND_TIME_FORMAT)
+}
+
+// splitRemindTime splits the time off of the start of a reminder, returning it and the message.
+func splitRemindTime(message string) (string, string) {
+	message = strings.TrimSpace(message)
+
+	if i := strings.IndexAny(message, " \t\n"); i != -1 {
+		return message[:i], strings.TrimSpace(message[i:])
+	}
+
+	return message, ""
+}
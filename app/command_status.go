@@ -0,0 +1,108 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"time"
+
+	"github.com/mattermost/platform/model"
+	goi18n "github.com/nicksnyder/go-i18n/i18n"
+)
+
+type StatusProvider struct {
+}
+
+const (
+	CMD_STATUS = "status"
+)
+
+func init() {
+	RegisterCommandProvider(&StatusProvider{})
+}
+
+func (me *StatusProvider) GetTrigger() string {
+	return CMD_STATUS
+}
+
+func (me *StatusProvider) GetCommand(T goi18n.TranslateFunc) *model.Command {
+	return &model.Command{
+		Trigger:          CMD_STATUS,
+		AutoComplete:     true,
+		AutoCompleteDesc: T("api.command_status.desc"),
+		AutoCompleteHint: T("api.command_status.hint"),
+		DisplayName:      T("api.command_status.name"),
+		AutocompleteData: &model.CommandAutocompleteData{
+			Subcommands: []*model.CommandAutocompleteData{
+				{
+					Trigger:  "set",
+					HelpText: T("api.command_status.set.help"),
+					Arguments: []*model.CommandArgument{
+						{Name: "status", Type: model.COMMAND_ARGUMENT_TYPE_TEXT, HelpText: T("api.command_status.status.help"), Required: true},
+					},
+				},
+				{
+					Trigger:  "for",
+					HelpText: T("api.command_status.for.help"),
+					Arguments: []*model.CommandArgument{
+						{Name: "status", Type: model.COMMAND_ARGUMENT_TYPE_TEXT, HelpText: T("api.command_status.for_status.help"), Required: true},
+					},
+				},
+				{
+					Trigger:  "clear",
+					HelpText: T("api.command_status.clear.help"),
+				},
+			},
+		},
+	}
+}
+
+func (me *StatusProvider) DoCommand(args *model.CommandArgs, message string) *model.CommandResponse {
+	// commands run without going through ExecuteCommand haven't been parsed yet
+	if args.ParsedCommand == nil {
+		if response := parseCommand(me.GetCommand(args.T), args, message); response != nil {
+			return response
+		}
+	}
+
+	parsed := args.ParsedCommand
+
+	var text string
+	switch parsed.Subcommand {
+	case "set":
+		text = doStatusSet(args, parsed.Arguments["status"], 0)
+	case "for":
+		when, status := splitRemindTime(parsed.Arguments["status"])
+
+		if duration, ok := parseRemindDuration(when); !ok || status == "" {
+			text = args.T("api.command_status.duration.app_error")
+		} else {
+			text = doStatusSet(args, status, model.GetMillis()+int64(duration/time.Millisecond))
+		}
+	case "clear":
+		if err := RemoveCustomStatus(args.UserId); err != nil {
+			err.Translate(args.T)
+			text = err.Message
+		} else {
+			text = args.T("api.command_status.clear.success")
+		}
+	}
+
+	return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: text}
+}
+
+func doStatusSet(args *model.CommandArgs, status string, expiresAt int64) string {
+	customStatus := model.ParseCustomStatus(status)
+	customStatus.ExpiresAt = expiresAt
+
+	if err := SetCustomStatus(args.UserId, customStatus); err != nil {
+		err.Translate(args.T)
+		return err.Message
+	}
+
+	if expiresAt != 0 {
+		return args.T("api.command_status.set.success_until", map[string]interface{}{"Time": formatRemindTime(expiresAt, args.Timezone)})
+	}
+
+	return args.T("api.command_status.set.success")
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mattermost/platform/jobs"
+	"github.com/mattermost/platform/model"
+)
+
+const COMMAND_WEBHOOK_CLEANUP_JOB_INTERVAL = time.Hour
+
+var commandWebhookSecret string
+var commandWebhookSecretMutex sync.Mutex
+
+// CreateCommandWebhookURL returns the signed response_url for a run of a slash command. The hook isn't saved until
+// the url is first used, since most commands never use it.
+func CreateCommandWebhookURL(commandId string, args *model.CommandArgs) (string, *model.AppError) {
+	hook := &model.CommandWebhook{
+		CommandId: commandId,
+		UserId:    args.UserId,
+		ChannelId: args.ChannelId,
+		RootId:    args.RootId,
+		ParentId:  args.ParentId,
+	}
+	hook.PreSave()
+
+	secret, err := getCommandWebhookSecret()
+	if err != nil {
+		return "", err
+	}
+
+	return args.SiteURL + "/hooks/commands/" + hook.Id + "?" + hook.ToQuery(secret).Encode(), nil
+}
+
+// getCommandWebhookSecret returns the secret that response urls are signed with. It's generated the first time that
+// it's needed and kept in the database so that every server in a cluster uses the same one.
+func getCommandWebhookSecret() (string, *model.AppError) {
+	commandWebhookSecretMutex.Lock()
+	defer commandWebhookSecretMutex.Unlock()
+
+	if commandWebhookSecret != "" {
+		return commandWebhookSecret, nil
+	}
+
+	if result := <-Srv.Store.System().GetByName(model.SYSTEM_COMMAND_WEBHOOK_SECRET); result.Err == nil {
+		commandWebhookSecret = result.Data.(*model.System).Value
+		return commandWebhookSecret, nil
+	}
+
+	system := &model.System{Name: model.SYSTEM_COMMAND_WEBHOOK_SECRET, Value: model.NewRandomString(32)}
+	if result := <-Srv.Store.System().Save(system); result.Err != nil {
+		// another server may have saved one first
+		if result := <-Srv.Store.System().GetByName(model.SYSTEM_COMMAND_WEBHOOK_SECRET); result.Err != nil {
+			return "", result.Err
+		} else {
+			system = result.Data.(*model.System)
+		}
+	}
+
+	commandWebhookSecret = system.Value
+	return commandWebhookSecret, nil
+}
+
+// HandleCommandWebhook posts a delayed response from a slash command to the channel that it was run in, as if it
+// had been returned when the command was run. The hook is read from the query string of its signed url and saved
+// the first time that it's used so that its uses can be counted.
+func HandleCommandWebhook(hookId string, query url.Values, response *model.CommandResponse) *model.AppError {
+	if response == nil {
+		return model.NewAppError("HandleCommandWebhook", "app.command_webhook.handle_command_webhook.parse.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	secret, err := getCommandWebhookSecret()
+	if err != nil {
+		return err
+	}
+
+	hook := model.CommandWebhookFromQuery(hookId, query, secret)
+	if hook == nil {
+		return model.NewAppError("HandleCommandWebhook", "app.command_webhook.handle_command_webhook.signature.app_error", nil, "id="+hookId, http.StatusUnauthorized)
+	}
+
+	if hook.IsExpired() {
+		return model.NewAppError("HandleCommandWebhook", "app.command_webhook.handle_command_webhook.expired.app_error", nil, "id="+hookId, http.StatusBadRequest)
+	}
+
+	var cmd *model.Command
+	if result := <-Srv.Store.Command().Get(hook.CommandId); result.Err != nil {
+		return model.NewAppError("HandleCommandWebhook", "app.command_webhook.handle_command_webhook.command.app_error", nil, "command_id="+hook.CommandId+", err="+result.Err.Message, http.StatusBadRequest)
+	} else {
+		cmd = result.Data.(*model.Command)
+	}
+
+	// as with Slack, delayed responses are only shown to the user that ran the command unless they say otherwise
+	if response.ResponseType == "" {
+		response.ResponseType = model.COMMAND_RESPONSE_TYPE_EPHEMERAL
+	}
+
+	args := &model.CommandArgs{
+		UserId:    hook.UserId,
+		ChannelId: hook.ChannelId,
+		TeamId:    cmd.TeamId,
+		RootId:    hook.RootId,
+		ParentId:  hook.ParentId,
+	}
+
+	if result := <-Srv.Store.CommandWebhook().Get(hook.Id); result.Err != nil {
+		if result := <-Srv.Store.CommandWebhook().Save(hook); result.Err != nil {
+			// another request may have saved it first
+			if result := <-Srv.Store.CommandWebhook().Get(hook.Id); result.Err != nil {
+				return result.Err
+			}
+		}
+	}
+
+	if result := <-Srv.Store.CommandWebhook().TryUse(hook.Id, model.COMMAND_WEBHOOK_MAX_USES); result.Err != nil {
+		return model.NewAppError("HandleCommandWebhook", "app.command_webhook.handle_command_webhook.uses.app_error", nil, "id="+hook.Id, http.StatusBadRequest)
+	}
+
+	_, err = HandleCommandResponse(cmd, args, response, false)
+	return err
+}
+
+func MakeCommandWebhookCleanupWorker() jobs.Worker {
+	return jobs.NewSimpleWorker("CommandWebhookCleanup", func(job *model.Job, cancel <-chan interface{}) *model.AppError {
+		return CleanupCommandWebhooks()
+	})
+}
+
+func MakeCommandWebhookCleanupScheduler() jobs.Scheduler {
+	return jobs.NewPeriodicScheduler(model.JOB_TYPE_COMMAND_WEBHOOK_CLEANUP, COMMAND_WEBHOOK_CLEANUP_JOB_INTERVAL, func() bool {
+		return true
+	})
+}
+
+// CleanupCommandWebhooks permanently deletes the hooks that have expired.
+func CleanupCommandWebhooks() *model.AppError {
+	if result := <-Srv.Store.CommandWebhook().Cleanup(); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
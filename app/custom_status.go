@@ -0,0 +1,95 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/jobs"
+	"github.com/mattermost/platform/model"
+)
+
+const CUSTOM_STATUSES_JOB_INTERVAL = time.Minute
+
+// SetCustomStatus sets the custom status shown alongside a user's name and lets everyone else know about it.
+func SetCustomStatus(userId string, customStatus *model.CustomStatus) *model.AppError {
+	if err := customStatus.IsValid(); err != nil {
+		return err
+	}
+
+	if customStatus.ExpiresAt != 0 && customStatus.ExpiresAt <= model.GetMillis() {
+		return model.NewAppError("SetCustomStatus", "app.custom_status.expires_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return updateCustomStatus(userId, customStatus)
+}
+
+func RemoveCustomStatus(userId string) *model.AppError {
+	return updateCustomStatus(userId, nil)
+}
+
+func updateCustomStatus(userId string, customStatus *model.CustomStatus) *model.AppError {
+	if result := <-Srv.Store.User().UpdateCustomStatus(userId, customStatus); result.Err != nil {
+		return result.Err
+	}
+
+	return sendUpdatedCustomStatus(userId)
+}
+
+// sendUpdatedCustomStatus lets everyone know that a user's custom status has changed.
+func sendUpdatedCustomStatus(userId string) *model.AppError {
+	InvalidateCacheForUser(userId)
+
+	user, err := GetUser(userId)
+	if err != nil {
+		return err
+	}
+
+	SanitizeProfile(user, false)
+	sendUpdatedUserEvent(user)
+
+	return nil
+}
+
+func MakeCustomStatusesWorker() jobs.Worker {
+	return jobs.NewSimpleWorker("CustomStatuses", func(job *model.Job, cancel <-chan interface{}) *model.AppError {
+		return ClearExpiredCustomStatuses()
+	})
+}
+
+func MakeCustomStatusesScheduler() jobs.Scheduler {
+	return jobs.NewPeriodicScheduler(model.JOB_TYPE_CUSTOM_STATUSES, CUSTOM_STATUSES_JOB_INTERVAL, func() bool {
+		return true
+	})
+}
+
+// ClearExpiredCustomStatuses removes the custom statuses that have expired. A custom status that's changed while this
+// is running is left alone.
+func ClearExpiredCustomStatuses() *model.AppError {
+	now := model.GetMillis()
+
+	var users []*model.User
+	if result := <-Srv.Store.User().GetWithExpiredCustomStatus(now); result.Err != nil {
+		return result.Err
+	} else {
+		users = result.Data.([]*model.User)
+	}
+
+	for _, user := range users {
+		if result := <-Srv.Store.User().ClearExpiredCustomStatus(user.Id, user.CustomStatusExpiresAt, now); result.Err != nil {
+			l4g.Error(result.Err.Error())
+			continue
+		} else if !result.Data.(bool) {
+			continue
+		}
+
+		if err := sendUpdatedCustomStatus(user.Id); err != nil {
+			l4g.Error(err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/jobs"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	DATA_RETENTION_JOB_INTERVAL = 24 * time.Hour
+	DATA_RETENTION_BATCH_SIZE   = 1000
+	DATA_RETENTION_AUDIT_ACTION = "data_retention"
+)
+
+type DataRetentionResult struct {
+	Posts     int64
+	Reactions int64
+	FileInfos int64
+	Files     int64
+}
+
+func MakeDataRetentionWorker() jobs.Worker {
+	return jobs.NewSimpleWorker("DataRetention", func(job *model.Job, cancel <-chan interface{}) *model.AppError {
+		_, err := runDataRetention(model.GetMillis(), func(progress int64) {
+			if err := jobs.SetJobProgress(job, progress); err != nil {
+				l4g.Warn(utils.T("app.data_retention.set_progress.warn"), job.Id, err.Error())
+			}
+		}, cancel)
+		return err
+	})
+}
+
+func MakeDataRetentionScheduler() jobs.Scheduler {
+	return jobs.NewPeriodicScheduler(model.JOB_TYPE_DATA_RETENTION, DATA_RETENTION_JOB_INTERVAL, func() bool {
+		return *utils.Cfg.DataRetentionSettings.EnableMessageDeletion
+	})
+}
+
+// RunDataRetention permanently deletes every post, along with its reactions, file infos and stored files, that
+// is older than the data retention policy covering its channel. A record of the run is saved to the audit log.
+func RunDataRetention(now int64) (*DataRetentionResult, *model.AppError) {
+	return runDataRetention(now, nil, nil)
+}
+
+// runDataRetention reports its progress after each policy and stops early once cancel is closed.
+func runDataRetention(now int64, setProgress func(progress int64), cancel <-chan interface{}) (*DataRetentionResult, *model.AppError) {
+	result := &DataRetentionResult{}
+
+	var err *model.AppError
+	policies := model.GetDataRetentionPolicies(&utils.Cfg.DataRetentionSettings)
+	for i, policy := range policies {
+		if err = runDataRetentionPolicy(policy, policy.EndTime(now), result, cancel); err != nil || isCanceled(cancel) {
+			break
+		}
+
+		if setProgress != nil {
+			setProgress(int64((i + 1) * 100 / len(policies)))
+		}
+	}
+
+	extraInfo := fmt.Sprintf("posts=%v reactions=%v file_infos=%v files=%v", result.Posts, result.Reactions, result.FileInfos, result.Files)
+	if err != nil {
+		extraInfo += " error=" + err.Id
+	}
+
+	audit := &model.Audit{Action: DATA_RETENTION_AUDIT_ACTION, ExtraInfo: extraInfo}
+	if r := <-Srv.Store.Audit().Save(audit); r.Err != nil {
+		l4g.Error(r.Err.Error())
+	}
+
+	return result, err
+}
+
+func runDataRetentionPolicy(policy *model.DataRetentionPolicy, endTime int64, result *DataRetentionResult, cancel <-chan interface{}) *model.AppError {
+	for !isCanceled(cancel) {
+		var posts []*model.Post
+		if r := <-Srv.Store.Post().GetPostsForDataRetention(policy, endTime, DATA_RETENTION_BATCH_SIZE); r.Err != nil {
+			return r.Err
+		} else {
+			posts = r.Data.([]*model.Post)
+		}
+
+		if len(posts) == 0 {
+			return nil
+		}
+
+		if err := deletePostsForDataRetention(posts, result); err != nil {
+			return err
+		}
+
+		if len(posts) < DATA_RETENTION_BATCH_SIZE {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func isCanceled(cancel <-chan interface{}) bool {
+	select {
+	case <-cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+func deletePostsForDataRetention(posts []*model.Post, result *DataRetentionResult) *model.AppError {
+	postIds := make([]string, len(posts))
+	channelIds := make(map[string]bool)
+	for i, post := range posts {
+		postIds[i] = post.Id
+		channelIds[post.ChannelId] = true
+	}
+
+	if r := <-Srv.Store.Reaction().PermanentDeleteForPosts(postIds); r.Err != nil {
+		return r.Err
+	} else {
+		result.Reactions += r.Data.(int64)
+	}
+
+	if r := <-Srv.Store.FileInfo().PermanentDeleteForPosts(postIds); r.Err != nil {
+		return r.Err
+	} else {
+		infos := r.Data.([]*model.FileInfo)
+		result.FileInfos += int64(len(infos))
+
+		for _, info := range infos {
+			for _, path := range []string{info.Path, info.ThumbnailPath, info.PreviewPath} {
+				if path == "" {
+					continue
+				}
+
+				if err := RemoveFile(path); err != nil {
+					l4g.Warn(utils.T("app.data_retention.remove_file.warn"), path, err.Error())
+				} else {
+					result.Files++
+				}
+			}
+		}
+	}
+
+	if r := <-Srv.Store.Post().PermanentDeleteByIds(postIds); r.Err != nil {
+		return r.Err
+	} else {
+		result.Posts += r.Data.(int64)
+	}
+
+	for _, postId := range postIds {
+		Srv.Store.FileInfo().InvalidateFileInfosForPostCache(postId)
+		Srv.Store.Reaction().InvalidateCacheForPost(postId)
+	}
+
+	for channelId := range channelIds {
+		InvalidateCacheForChannelPosts(channelId)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestRunDataRetention(t *testing.T) {
+	th := Setup().InitBasic()
+
+	enableMessageDeletion := *utils.Cfg.DataRetentionSettings.EnableMessageDeletion
+	messageRetentionDays := *utils.Cfg.DataRetentionSettings.MessageRetentionDays
+	directMessageRetentionDays := *utils.Cfg.DataRetentionSettings.DirectMessageRetentionDays
+	channelRetentionDays := utils.Cfg.DataRetentionSettings.ChannelRetentionDays
+	defer func() {
+		*utils.Cfg.DataRetentionSettings.EnableMessageDeletion = enableMessageDeletion
+		*utils.Cfg.DataRetentionSettings.MessageRetentionDays = messageRetentionDays
+		*utils.Cfg.DataRetentionSettings.DirectMessageRetentionDays = directMessageRetentionDays
+		utils.Cfg.DataRetentionSettings.ChannelRetentionDays = channelRetentionDays
+	}()
+
+	*utils.Cfg.DataRetentionSettings.EnableMessageDeletion = true
+	*utils.Cfg.DataRetentionSettings.MessageRetentionDays = 0
+	*utils.Cfg.DataRetentionSettings.DirectMessageRetentionDays = 0
+	utils.Cfg.DataRetentionSettings.ChannelRetentionDays = map[string]int{th.BasicChannel.Id: 1}
+
+	now := model.GetMillis()
+
+	oldPost := store.Must(Srv.Store.Post().Save(&model.Post{
+		ChannelId: th.BasicChannel.Id,
+		UserId:    th.BasicUser.Id,
+		Message:   "old message",
+		CreateAt:  now - 2*model.DAY_MILLISECONDS,
+	})).(*model.Post)
+
+	store.Must(Srv.Store.Reaction().Save(&model.Reaction{
+		UserId:    th.BasicUser.Id,
+		PostId:    oldPost.Id,
+		EmojiName: "smile",
+	}))
+
+	result, err := RunDataRetention(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Posts != 1 || result.Reactions != 1 {
+		t.Fatal("should've deleted the old post and its reaction", result)
+	}
+
+	if r := <-Srv.Store.Post().GetSingle(oldPost.Id); r.Err == nil {
+		t.Fatal("old post should've been deleted")
+	}
+
+	if r := <-Srv.Store.Post().GetSingle(th.BasicPost.Id); r.Err != nil {
+		t.Fatal("recent post shouldn't have been deleted")
+	}
+}
@@ -19,6 +19,11 @@ import (
 
 const (
 	EMAIL_BATCHING_TASK_NAME = "Email Batching"
+
+	// notifications for users in do not disturb are held until it ends, but only for this long or up to this many
+	// notifications so that a long do not disturb can't fill up the server's memory
+	EMAIL_BATCHING_DND_MAX_HOLD_TIME     = 24 * time.Hour
+	EMAIL_BATCHING_DND_MAX_NOTIFICATIONS = 100
 )
 
 var emailBatchingJob *EmailBatchingJob
@@ -136,6 +141,14 @@ func (job *EmailBatchingJob) checkPendingNotifications(now time.Time, handler fu
 		} else if status := result.Data.(*model.Status); status.LastActivityAt >= batchStartTime {
 			delete(job.pendingNotifications, userId)
 			continue
+		} else if status.Status == model.STATUS_DND {
+			if len(notifications) >= EMAIL_BATCHING_DND_MAX_NOTIFICATIONS || now.Sub(time.Unix(batchStartTime/1000, 0)) > EMAIL_BATCHING_DND_MAX_HOLD_TIME {
+				go handler(userId, notifications)
+				delete(job.pendingNotifications, userId)
+			}
+
+			// otherwise hold the notifications until the user is no longer in do not disturb
+			continue
 		}
 
 		// get how long we need to wait to send notifications to the user
@@ -191,3 +191,73 @@ func TestCheckPendingNotifications(t *testing.T) {
 		t.Fatal("timed out waiting for second post notification")
 	}
 }
+
+func TestCheckPendingNotificationsDND(t *testing.T) {
+	Setup()
+
+	id1 := model.NewId()
+
+	job := MakeEmailBatchingJob(128)
+	job.pendingNotifications[id1] = []*batchedNotification{
+		{
+			post: &model.Post{
+				UserId:   id1,
+				CreateAt: 10000000,
+			},
+		},
+	}
+
+	store.Must(Srv.Store.Status().SaveOrUpdate(&model.Status{
+		UserId:         id1,
+		Status:         model.STATUS_DND,
+		Manual:         true,
+		LastActivityAt: 9999000,
+	}))
+
+	sent := make(chan bool, 1)
+	handler := func(string, []*batchedNotification) {
+		sent <- true
+	}
+
+	// test that notifications are held while the user is in do not disturb
+	job.checkPendingNotifications(time.Unix(10000, 0).Add(time.Hour), handler)
+
+	if len(job.pendingNotifications[id1]) != 1 {
+		t.Fatal("should've held queued post")
+	}
+
+	// test that they stop being held after a while
+	job.checkPendingNotifications(time.Unix(10000, 0).Add(EMAIL_BATCHING_DND_MAX_HOLD_TIME+time.Minute), handler)
+
+	if len(job.pendingNotifications[id1]) != 0 {
+		t.Fatal("should've sent queued post after holding it for too long")
+	}
+
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification to be sent")
+	}
+
+	// test that they stop being held once there are too many
+	for i := 0; i < EMAIL_BATCHING_DND_MAX_NOTIFICATIONS; i++ {
+		job.pendingNotifications[id1] = append(job.pendingNotifications[id1], &batchedNotification{
+			post: &model.Post{
+				UserId:   id1,
+				CreateAt: 10000000,
+			},
+		})
+	}
+
+	job.checkPendingNotifications(time.Unix(10000, 0).Add(time.Hour), handler)
+
+	if len(job.pendingNotifications[id1]) != 0 {
+		t.Fatal("should've sent queued posts after holding too many")
+	}
+
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notifications to be sent")
+	}
+}
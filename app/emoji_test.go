@@ -0,0 +1,90 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+)
+
+func TestResizeEmoji(t *testing.T) {
+	// try to resize a jpeg image within MaxEmojiWidth and MaxEmojiHeight
+	small_img_data := createTestJpeg(t, MaxEmojiWidth, MaxEmojiHeight)
+	if small_img, _, err := image.Decode(bytes.NewReader(small_img_data)); err != nil {
+		t.Fatal("failed to decode jpeg bytes to image.Image")
+	} else {
+		resized_img := resizeEmoji(small_img, small_img.Bounds().Dx(), small_img.Bounds().Dy())
+		if resized_img.Bounds().Dx() > MaxEmojiWidth || resized_img.Bounds().Dy() > MaxEmojiHeight {
+			t.Fatal("resized jpeg width and height should not be greater than MaxEmojiWidth or MaxEmojiHeight")
+		}
+		if resized_img != small_img {
+			t.Fatal("should've returned small_img itself")
+		}
+	}
+	// try to resize a jpeg image
+	jpeg_data := createTestJpeg(t, 256, 256)
+	if jpeg_img, _, err := image.Decode(bytes.NewReader(jpeg_data)); err != nil {
+		t.Fatal("failed to decode jpeg bytes to image.Image")
+	} else {
+		resized_jpeg := resizeEmoji(jpeg_img, jpeg_img.Bounds().Dx(), jpeg_img.Bounds().Dy())
+		if resized_jpeg.Bounds().Dx() > MaxEmojiWidth || resized_jpeg.Bounds().Dy() > MaxEmojiHeight {
+			t.Fatal("resized jpeg width and height should not be greater than MaxEmojiWidth or MaxEmojiHeight")
+		}
+	}
+	// try to resize a png image
+	png_data := createTestJpeg(t, 256, 256)
+	if png_img, _, err := image.Decode(bytes.NewReader(png_data)); err != nil {
+		t.Fatal("failed to decode png bytes to image.Image")
+	} else {
+		resized_png := resizeEmoji(png_img, png_img.Bounds().Dx(), png_img.Bounds().Dy())
+		if resized_png.Bounds().Dx() > MaxEmojiWidth || resized_png.Bounds().Dy() > MaxEmojiHeight {
+			t.Fatal("resized png width and height should not be greater than MaxEmojiWidth or MaxEmojiHeight")
+		}
+	}
+	// try to resize an animated gif
+	gif_data := createTestAnimatedGif(t, 256, 256, 10)
+	if gif_img, err := gif.DecodeAll(bytes.NewReader(gif_data)); err != nil {
+		t.Fatal("failed to decode gif bytes to gif.GIF")
+	} else {
+		resized_gif := resizeEmojiGif(gif_img)
+		if resized_gif.Config.Width > MaxEmojiWidth || resized_gif.Config.Height > MaxEmojiHeight {
+			t.Fatal("resized gif width and height should not be greater than MaxEmojiWidth or MaxEmojiHeight")
+		}
+		if len(resized_gif.Image) != len(gif_img.Image) {
+			t.Fatal("resized gif should have the same number of frames as original gif")
+		}
+	}
+}
+
+func createTestAnimatedGif(t *testing.T, width int, height int, frames int) []byte {
+	var buffer bytes.Buffer
+
+	img := gif.GIF{
+		Image: make([]*image.Paletted, frames, frames),
+		Delay: make([]int, frames, frames),
+	}
+	for i := 0; i < frames; i++ {
+		img.Image[i] = image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.Black})
+		img.Delay[i] = 0
+	}
+	if err := gif.EncodeAll(&buffer, &img); err != nil {
+		t.Fatalf("failed to create animated gif: %v", err.Error())
+	}
+
+	return buffer.Bytes()
+}
+
+func createTestJpeg(t *testing.T, width int, height int) []byte {
+	var buffer bytes.Buffer
+
+	if err := jpeg.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatalf("failed to create jpeg: %v", err.Error())
+	}
+
+	return buffer.Bytes()
+}
@@ -0,0 +1,566 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	EXPORT_POSTS_BATCH_SIZE           = 1000
+	EXPORT_DIRECT_CHANNELS_BATCH_SIZE = 1000
+)
+
+// exportContext maps the ids found in the database to the names used by the bulk import format.
+type exportContext struct {
+	teamNames    map[string]string
+	channelNames map[string]string
+	usernames    map[string]string
+
+	// teamChannels lists the channels that were exported for each team, in export order.
+	teamChannels map[string][]*model.Channel
+}
+
+//
+// -- Bulk Export Functions --
+// These functions write the contents of the database in the format read by BulkImport so that the data can be
+// moved to another server. Passwords and attached files can't be exported, so users that log in with a password
+// will need to reset it.
+//
+
+func BulkExport(writer io.Writer) *model.AppError {
+	ctx := &exportContext{
+		teamNames:    make(map[string]string),
+		channelNames: make(map[string]string),
+		usernames:    make(map[string]string),
+		teamChannels: make(map[string][]*model.Channel),
+	}
+
+	encoder := json.NewEncoder(writer)
+
+	version := 1
+	if err := exportWriteLine(encoder, &LineImportData{Type: "version", Version: &version}); err != nil {
+		return err
+	}
+
+	if err := exportTeams(encoder, ctx); err != nil {
+		return err
+	}
+
+	if err := exportChannels(encoder, ctx); err != nil {
+		return err
+	}
+
+	if err := exportUsers(encoder, ctx); err != nil {
+		return err
+	}
+
+	if err := exportPosts(encoder, ctx); err != nil {
+		return err
+	}
+
+	if err := exportDirectPosts(encoder, ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func exportWriteLine(encoder *json.Encoder, line *LineImportData) *model.AppError {
+	if err := encoder.Encode(line); err != nil {
+		return model.NewAppError("BulkExport", "app.export.write_line.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func exportTeams(encoder *json.Encoder, ctx *exportContext) *model.AppError {
+	var teams []*model.Team
+	if result := <-Srv.Store.Team().GetAll(); result.Err != nil {
+		return result.Err
+	} else {
+		teams = result.Data.([]*model.Team)
+	}
+
+	for _, team := range teams {
+		if team.DeleteAt != 0 {
+			continue
+		}
+
+		ctx.teamNames[team.Id] = team.Name
+
+		if err := exportWriteLine(encoder, &LineImportData{
+			Type: "team",
+			Team: ImportTeamDataFromTeam(team),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func exportChannels(encoder *json.Encoder, ctx *exportContext) *model.AppError {
+	for teamId, teamName := range ctx.teamNames {
+		var channels *model.ChannelList
+		if result := <-Srv.Store.Channel().GetTeamChannels(teamId); result.Err != nil {
+			if result.Err.Id == "store.sql_channel.get_channels.not_found.app_error" {
+				continue
+			}
+			return result.Err
+		} else {
+			channels = result.Data.(*model.ChannelList)
+		}
+
+		for _, channel := range *channels {
+			if channel.DeleteAt != 0 || (channel.Type != model.CHANNEL_OPEN && channel.Type != model.CHANNEL_PRIVATE) {
+				continue
+			}
+
+			ctx.channelNames[channel.Id] = channel.Name
+			ctx.teamChannels[teamId] = append(ctx.teamChannels[teamId], channel)
+
+			if err := exportWriteLine(encoder, &LineImportData{
+				Type:    "channel",
+				Channel: ImportChannelDataFromChannel(channel, teamName),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func exportUsers(encoder *json.Encoder, ctx *exportContext) *model.AppError {
+	var users []*model.User
+	if result := <-Srv.Store.User().GetAll(); result.Err != nil {
+		return result.Err
+	} else {
+		users = result.Data.([]*model.User)
+	}
+
+	for _, user := range users {
+		ctx.usernames[user.Id] = user.Username
+
+		data := ImportUserDataFromUser(user)
+
+		if err := exportUserPreferences(user, data); err != nil {
+			return err
+		}
+
+		if teams, err := exportUserTeams(user, ctx); err != nil {
+			return err
+		} else if len(teams) > 0 {
+			data.Teams = &teams
+		}
+
+		if err := exportWriteLine(encoder, &LineImportData{
+			Type: "user",
+			User: data,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func exportUserPreferences(user *model.User, data *UserImportData) *model.AppError {
+	var preferences model.Preferences
+	if result := <-Srv.Store.Preference().GetAll(user.Id); result.Err != nil {
+		return result.Err
+	} else {
+		preferences = result.Data.(model.Preferences)
+	}
+
+	for _, preference := range preferences {
+		value := preference.Value
+
+		switch preference.Category {
+		case model.PREFERENCE_CATEGORY_THEME:
+			// only the theme used across all teams can be imported
+			if preference.Name == "" {
+				data.Theme = &value
+			}
+		case model.PREFERENCE_CATEGORY_DISPLAY_SETTINGS:
+			switch preference.Name {
+			case "selected_font":
+				data.SelectedFont = &value
+			case "use_military_time":
+				data.UseMilitaryTime = &value
+			case "name_format":
+				data.NameFormat = &value
+			case "collapse_previews":
+				data.CollapsePreviews = &value
+			case "message_display":
+				data.MessageDisplay = &value
+			case "channel_display_mode":
+				data.ChannelDisplayMode = &value
+			}
+		}
+	}
+
+	return nil
+}
+
+func exportUserTeams(user *model.User, ctx *exportContext) ([]UserTeamImportData, *model.AppError) {
+	var members []*model.TeamMember
+	if result := <-Srv.Store.Team().GetTeamsForUser(user.Id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		members = result.Data.([]*model.TeamMember)
+	}
+
+	teams := []UserTeamImportData{}
+	for _, member := range members {
+		teamName, ok := ctx.teamNames[member.TeamId]
+		if !ok || member.DeleteAt != 0 {
+			continue
+		}
+
+		var channelMembers *model.ChannelMembers
+		if result := <-Srv.Store.Channel().GetMembersForUser(member.TeamId, user.Id); result.Err != nil {
+			return nil, result.Err
+		} else {
+			channelMembers = result.Data.(*model.ChannelMembers)
+		}
+
+		channels := []UserChannelImportData{}
+		for _, channelMember := range *channelMembers {
+			// direct and group channels have no team, so they aren't in the exported channels
+			if channelName, ok := ctx.channelNames[channelMember.ChannelId]; ok {
+				channels = append(channels, *ImportUserChannelDataFromChannelMember(&channelMember, channelName))
+			}
+		}
+
+		roles := member.Roles
+		teams = append(teams, UserTeamImportData{
+			Name:     &teamName,
+			Roles:    &roles,
+			Channels: &channels,
+		})
+	}
+
+	return teams, nil
+}
+
+func exportPosts(encoder *json.Encoder, ctx *exportContext) *model.AppError {
+	for teamId, channels := range ctx.teamChannels {
+		teamName := ctx.teamNames[teamId]
+
+		for _, channel := range channels {
+			if err := exportChannelPosts(encoder, ctx, teamName, channel); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func exportChannelPosts(encoder *json.Encoder, ctx *exportContext, teamName string, channel *model.Channel) *model.AppError {
+	afterCreateAt := int64(0)
+	afterId := ""
+
+	for {
+		var posts []*model.Post
+		if result := <-Srv.Store.Post().GetPostsForExport(channel.Id, afterCreateAt, afterId, EXPORT_POSTS_BATCH_SIZE); result.Err != nil {
+			return result.Err
+		} else {
+			posts = result.Data.([]*model.Post)
+		}
+
+		for _, post := range posts {
+			afterCreateAt = post.CreateAt
+			afterId = post.Id
+
+			// system messages are generated by the server and are recreated by the import itself, and replies are
+			// exported along with their root post
+			if post.IsSystemMessage() || post.RootId != "" {
+				continue
+			}
+
+			username, ok := ctx.usernames[post.UserId]
+			if !ok {
+				continue
+			}
+
+			data := ImportPostDataFromPost(post, teamName, channel.Name, username)
+
+			var err *model.AppError
+			if data.Reactions, err = exportReactions(ctx, post); err != nil {
+				return err
+			}
+
+			if data.Replies, err = exportReplies(ctx, post); err != nil {
+				return err
+			}
+
+			if err := exportWriteLine(encoder, &LineImportData{
+				Type: "post",
+				Post: data,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if len(posts) < EXPORT_POSTS_BATCH_SIZE {
+			return nil
+		}
+	}
+}
+
+func exportDirectPosts(encoder *json.Encoder, ctx *exportContext) *model.AppError {
+	afterId := ""
+
+	for {
+		var channels *model.ChannelList
+		if result := <-Srv.Store.Channel().GetDirectChannelsForExport(afterId, EXPORT_DIRECT_CHANNELS_BATCH_SIZE); result.Err != nil {
+			return result.Err
+		} else {
+			channels = result.Data.(*model.ChannelList)
+		}
+
+		for _, channel := range *channels {
+			afterId = channel.Id
+
+			if err := exportDirectChannelPosts(encoder, ctx, channel); err != nil {
+				return err
+			}
+		}
+
+		if len(*channels) < EXPORT_DIRECT_CHANNELS_BATCH_SIZE {
+			return nil
+		}
+	}
+}
+
+func exportDirectChannelPosts(encoder *json.Encoder, ctx *exportContext, channel *model.Channel) *model.AppError {
+	var members *model.ChannelMembers
+	if result := <-Srv.Store.Channel().GetMembers(channel.Id, 0, model.CHANNEL_GROUP_MAX_USERS); result.Err != nil {
+		return result.Err
+	} else {
+		members = result.Data.(*model.ChannelMembers)
+	}
+
+	var channelMembers []string
+	for _, member := range *members {
+		if username, ok := ctx.usernames[member.UserId]; ok {
+			channelMembers = append(channelMembers, username)
+		}
+	}
+
+	if len(channelMembers) < 2 {
+		return nil
+	}
+
+	afterCreateAt := int64(0)
+	afterId := ""
+
+	for {
+		var posts []*model.Post
+		if result := <-Srv.Store.Post().GetPostsForExport(channel.Id, afterCreateAt, afterId, EXPORT_POSTS_BATCH_SIZE); result.Err != nil {
+			return result.Err
+		} else {
+			posts = result.Data.([]*model.Post)
+		}
+
+		for _, post := range posts {
+			afterCreateAt = post.CreateAt
+			afterId = post.Id
+
+			if post.IsSystemMessage() || post.RootId != "" {
+				continue
+			}
+
+			username, ok := ctx.usernames[post.UserId]
+			if !ok {
+				continue
+			}
+
+			data := ImportDirectPostDataFromPost(post, channelMembers, username)
+
+			var err *model.AppError
+			if data.Reactions, err = exportReactions(ctx, post); err != nil {
+				return err
+			}
+
+			if data.Replies, err = exportReplies(ctx, post); err != nil {
+				return err
+			}
+
+			if err := exportWriteLine(encoder, &LineImportData{
+				Type:       "direct_post",
+				DirectPost: data,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if len(posts) < EXPORT_POSTS_BATCH_SIZE {
+			return nil
+		}
+	}
+}
+
+func exportReplies(ctx *exportContext, root *model.Post) (*[]ReplyImportData, *model.AppError) {
+	var posts []*model.Post
+	if result := <-Srv.Store.Post().GetRepliesForExport(root.Id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		posts = result.Data.([]*model.Post)
+	}
+
+	replies := []ReplyImportData{}
+	for _, post := range posts {
+		username, ok := ctx.usernames[post.UserId]
+		if !ok || post.IsSystemMessage() {
+			continue
+		}
+
+		reply := ImportReplyDataFromPost(post, username)
+
+		var err *model.AppError
+		if reply.Reactions, err = exportReactions(ctx, post); err != nil {
+			return nil, err
+		}
+
+		replies = append(replies, *reply)
+	}
+
+	if len(replies) == 0 {
+		return nil, nil
+	}
+
+	return &replies, nil
+}
+
+func exportReactions(ctx *exportContext, post *model.Post) (*[]ReactionImportData, *model.AppError) {
+	if !post.HasReactions {
+		return nil, nil
+	}
+
+	var reactions []*model.Reaction
+	if result := <-Srv.Store.Reaction().GetForPost(post.Id, false); result.Err != nil {
+		return nil, result.Err
+	} else {
+		reactions = result.Data.([]*model.Reaction)
+	}
+
+	data := []ReactionImportData{}
+	for _, reaction := range reactions {
+		if username, ok := ctx.usernames[reaction.UserId]; ok {
+			data = append(data, *ImportReactionDataFromReaction(reaction, username))
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	return &data, nil
+}
+
+func ImportTeamDataFromTeam(team *model.Team) *TeamImportData {
+	return &TeamImportData{
+		Name:            &team.Name,
+		DisplayName:     &team.DisplayName,
+		Type:            &team.Type,
+		Description:     &team.Description,
+		AllowOpenInvite: &team.AllowOpenInvite,
+	}
+}
+
+func ImportChannelDataFromChannel(channel *model.Channel, teamName string) *ChannelImportData {
+	return &ChannelImportData{
+		Team:        &teamName,
+		Name:        &channel.Name,
+		DisplayName: &channel.DisplayName,
+		Type:        &channel.Type,
+		Header:      &channel.Header,
+		Purpose:     &channel.Purpose,
+	}
+}
+
+func ImportUserDataFromUser(user *model.User) *UserImportData {
+	data := &UserImportData{
+		Username:  &user.Username,
+		Email:     &user.Email,
+		Nickname:  &user.Nickname,
+		FirstName: &user.FirstName,
+		LastName:  &user.LastName,
+		Position:  &user.Position,
+		Roles:     &user.Roles,
+		Locale:    &user.Locale,
+	}
+
+	if user.AuthService != "" {
+		data.AuthService = &user.AuthService
+	}
+
+	if user.AuthData != nil && *user.AuthData != "" {
+		data.AuthData = user.AuthData
+	}
+
+	return data
+}
+
+func ImportUserChannelDataFromChannelMember(member *model.ChannelMember, channelName string) *UserChannelImportData {
+	roles := member.Roles
+	data := &UserChannelImportData{
+		Name:        &channelName,
+		Roles:       &roles,
+		NotifyProps: &UserChannelNotifyPropsImportData{},
+	}
+
+	if desktop, ok := member.NotifyProps[model.DESKTOP_NOTIFY_PROP]; ok {
+		data.NotifyProps.Desktop = &desktop
+	}
+
+	if markUnread, ok := member.NotifyProps[model.MARK_UNREAD_NOTIFY_PROP]; ok {
+		data.NotifyProps.MarkUnread = &markUnread
+	}
+
+	return data
+}
+
+func ImportPostDataFromPost(post *model.Post, teamName string, channelName string, username string) *PostImportData {
+	return &PostImportData{
+		Team:     &teamName,
+		Channel:  &channelName,
+		User:     &username,
+		Message:  &post.Message,
+		CreateAt: &post.CreateAt,
+	}
+}
+
+func ImportDirectPostDataFromPost(post *model.Post, channelMembers []string, username string) *DirectPostImportData {
+	return &DirectPostImportData{
+		ChannelMembers: &channelMembers,
+		User:           &username,
+		Message:        &post.Message,
+		CreateAt:       &post.CreateAt,
+	}
+}
+
+func ImportReplyDataFromPost(post *model.Post, username string) *ReplyImportData {
+	return &ReplyImportData{
+		User:     &username,
+		Message:  &post.Message,
+		CreateAt: &post.CreateAt,
+	}
+}
+
+func ImportReactionDataFromReaction(reaction *model.Reaction, username string) *ReactionImportData {
+	return &ReactionImportData{
+		User:      &username,
+		EmojiName: &reaction.EmojiName,
+		CreateAt:  &reaction.CreateAt,
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestExportBulkExport(t *testing.T) {
+	_ = Setup()
+
+	teamName := model.NewId()
+	channelName := model.NewId()
+	username := "n" + model.NewId()
+	otherUsername := "n" + model.NewId()
+
+	data := `{"type": "version", "version": 1}
+{"type": "team", "team": {"type": "O", "display_name": "lskmw2d7a5ao7ppwqh5ljchvr4", "name": "` + teamName + `"}}
+{"type": "channel", "channel": {"type": "O", "display_name": "xr6m6udffngark2uekvr3hoeny", "team": "` + teamName + `", "name": "` + channelName + `"}}
+{"type": "user", "user": {"username": "` + username + `", "email": "` + username + `@example.com", "theme": "{\"awayIndicator\":\"#DCBD4E\"}", "use_military_time": "true", "teams": [{"name": "` + teamName + `", "channels": [{"name": "` + channelName + `", "notify_props": {"desktop": "mention"}}]}]}}
+{"type": "post", "post": {"team": "` + teamName + `", "channel": "` + channelName + `", "user": "` + username + `", "message": "Hello World", "create_at": 123456789012, "reactions": [{"user": "` + username + `", "emoji_name": "smile", "create_at": 123456789013}], "replies": [{"user": "` + username + `", "message": "Reply", "create_at": 123456789014}]}}
+{"type": "user", "user": {"username": "` + otherUsername + `", "email": "` + otherUsername + `@example.com"}}
+{"type": "direct_post", "direct_post": {"channel_members": ["` + username + `", "` + otherUsername + `"], "user": "` + username + `", "message": "Direct message", "create_at": 123456789015}}`
+
+	if err, line := BulkImport(strings.NewReader(data), false); err != nil || line != 0 {
+		t.Fatalf("BulkImport should have succeeded: %v, %v", err.Error(), line)
+	}
+
+	var buf bytes.Buffer
+	if err := BulkExport(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	var version LineImportData
+	if err := json.Unmarshal([]byte(lines[0]), &version); err != nil {
+		t.Fatal(err)
+	} else if version.Type != "version" || version.Version == nil || *version.Version != 1 {
+		t.Fatal("the first line should be the version")
+	}
+
+	var team *TeamImportData
+	var channel *ChannelImportData
+	var user *UserImportData
+	var post *PostImportData
+	var directPost *DirectPostImportData
+	for _, line := range lines[1:] {
+		var lineData LineImportData
+		if err := json.Unmarshal([]byte(line), &lineData); err != nil {
+			t.Fatal(err)
+		}
+
+		switch {
+		case lineData.Team != nil && *lineData.Team.Name == teamName:
+			team = lineData.Team
+		case lineData.Channel != nil && *lineData.Channel.Name == channelName:
+			channel = lineData.Channel
+		case lineData.User != nil && *lineData.User.Username == username:
+			user = lineData.User
+		case lineData.Post != nil && *lineData.Post.Channel == channelName:
+			post = lineData.Post
+		case lineData.DirectPost != nil && *lineData.DirectPost.User == username:
+			directPost = lineData.DirectPost
+		}
+	}
+
+	if team == nil || *team.DisplayName != "lskmw2d7a5ao7ppwqh5ljchvr4" || *team.Type != model.TEAM_OPEN {
+		t.Fatal("team wasn't exported correctly")
+	}
+
+	if channel == nil || *channel.Team != teamName || *channel.Type != model.CHANNEL_OPEN {
+		t.Fatal("channel wasn't exported correctly")
+	}
+
+	if user == nil || *user.Email != username+"@example.com" {
+		t.Fatal("user wasn't exported correctly")
+	} else if user.Theme == nil || *user.Theme != `{"awayIndicator":"#DCBD4E"}` || user.UseMilitaryTime == nil || *user.UseMilitaryTime != "true" {
+		t.Fatal("user preferences weren't exported correctly")
+	} else if user.AuthService != nil {
+		t.Fatal("auth service shouldn't be exported for an email user")
+	} else if user.Teams == nil || len(*user.Teams) != 1 || *(*user.Teams)[0].Name != teamName {
+		t.Fatal("user teams weren't exported correctly")
+	}
+
+	found := false
+	for _, channelData := range *(*user.Teams)[0].Channels {
+		if *channelData.Name == channelName {
+			found = true
+			if channelData.NotifyProps == nil || *channelData.NotifyProps.Desktop != model.CHANNEL_NOTIFY_MENTION {
+				t.Fatal("user channel notify props weren't exported correctly")
+			}
+		}
+	}
+	if !found {
+		t.Fatal("user channels weren't exported correctly")
+	}
+
+	if post == nil || *post.Team != teamName || *post.User != username || *post.Message != "Hello World" || *post.CreateAt != 123456789012 {
+		t.Fatal("post wasn't exported correctly")
+	} else if post.Reactions == nil || len(*post.Reactions) != 1 || *(*post.Reactions)[0].EmojiName != "smile" {
+		t.Fatal("post reactions weren't exported correctly")
+	} else if post.Replies == nil || len(*post.Replies) != 1 || *(*post.Replies)[0].Message != "Reply" {
+		t.Fatal("post replies weren't exported correctly")
+	}
+
+	if directPost == nil || *directPost.Message != "Direct message" || len(*directPost.ChannelMembers) != 2 {
+		t.Fatal("direct post wasn't exported correctly")
+	}
+
+	// The exported data for these entities should be importable as it is.
+	exported := []LineImportData{
+		{Type: "team", Team: team},
+		{Type: "channel", Channel: channel},
+		{Type: "user", User: user},
+		{Type: "post", Post: post},
+		{Type: "direct_post", DirectPost: directPost},
+	}
+	reimport := `{"type": "version", "version": 1}`
+	for _, line := range exported {
+		if b, err := json.Marshal(line); err != nil {
+			t.Fatal(err)
+		} else {
+			reimport += "\n" + string(b)
+		}
+	}
+
+	if err, line := BulkImport(strings.NewReader(reimport), true); err != nil || line != 0 {
+		t.Fatalf("BulkImport of the exported data should have succeeded: %v, %v", err.Error(), line)
+	}
+}
@@ -14,12 +14,8 @@ import (
 	_ "image/gif"
 	"image/jpeg"
 	"io"
-	"io/ioutil"
-	"mime/multipart"
 	"net/http"
 	"net/url"
-	"os"
-	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -28,7 +24,6 @@ import (
 	"github.com/disintegration/imaging"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
-	s3 "github.com/minio/minio-go"
 	"github.com/rwcarlsen/goexif/exif"
 	_ "golang.org/x/image/bmp"
 )
@@ -56,138 +51,105 @@ const (
 	MaxImageSize = 6048 * 4032 // 24 megapixels, roughly 36MB as a raw image
 )
 
-func ReadFile(path string) ([]byte, *model.AppError) {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
-		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
-		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
-		secure := *utils.Cfg.FileSettings.AmazonS3SSL
-		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
-		if err != nil {
-			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
-		}
-		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
-		minioObject, err := s3Clnt.GetObject(bucket, path)
-		defer minioObject.Close()
-		if err != nil {
-			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
-		}
-		if f, err := ioutil.ReadAll(minioObject); err != nil {
-			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
-		} else {
-			return f, nil
-		}
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if f, err := ioutil.ReadFile(utils.Cfg.FileSettings.Directory + path); err != nil {
-			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.reading_local.app_error", nil, err.Error())
-		} else {
-			return f, nil
-		}
-	} else {
-		return nil, model.NewLocAppError("ReadFile", "api.file.read_file.configured.app_error", nil, "")
-	}
-}
+var fileBackend utils.FileBackend
+var fileBackendKey string
+var fileBackendLock sync.Mutex
 
-func MoveFile(oldPath, newPath string) *model.AppError {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
-		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
-		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
-		secure := *utils.Cfg.FileSettings.AmazonS3SSL
-		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
-		if err != nil {
-			return model.NewLocAppError("moveFile", "api.file.write_file.s3.app_error", nil, err.Error())
-		}
-		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
+// FileBackend returns the storage driver for the current file settings. The driver is only rebuilt when
+// those settings change so that a new S3 client isn't created for every file that is read or written.
+func FileBackend() (utils.FileBackend, *model.AppError) {
+	fileBackendLock.Lock()
+	defer fileBackendLock.Unlock()
 
-		var copyConds = s3.NewCopyConditions()
-		if err = s3Clnt.CopyObject(bucket, newPath, "/"+path.Join(bucket, oldPath), copyConds); err != nil {
-			return model.NewLocAppError("moveFile", "api.file.move_file.delete_from_s3.app_error", nil, err.Error())
-		}
-		if err = s3Clnt.RemoveObject(bucket, oldPath); err != nil {
-			return model.NewLocAppError("moveFile", "api.file.move_file.delete_from_s3.app_error", nil, err.Error())
-		}
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if err := os.MkdirAll(filepath.Dir(utils.Cfg.FileSettings.Directory+newPath), 0774); err != nil {
-			return model.NewLocAppError("moveFile", "api.file.move_file.rename.app_error", nil, err.Error())
-		}
+	settings := &utils.Cfg.FileSettings
+	key := fileBackendSettingsKey(settings)
 
-		if err := os.Rename(utils.Cfg.FileSettings.Directory+oldPath, utils.Cfg.FileSettings.Directory+newPath); err != nil {
-			return model.NewLocAppError("moveFile", "api.file.move_file.rename.app_error", nil, err.Error())
+	if fileBackend == nil || key != fileBackendKey {
+		backend, err := utils.NewFileBackend(settings)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		return model.NewLocAppError("moveFile", "api.file.move_file.configured.app_error", nil, "")
+
+		fileBackend = backend
+		fileBackendKey = key
 	}
 
-	return nil
+	return fileBackend, nil
 }
 
-func WriteFile(f []byte, path string) *model.AppError {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
-		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
-		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
-		secure := *utils.Cfg.FileSettings.AmazonS3SSL
-		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
-		if err != nil {
-			return model.NewLocAppError("WriteFile", "api.file.write_file.s3.app_error", nil, err.Error())
-		}
-		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
-		ext := filepath.Ext(path)
+func fileBackendSettingsKey(settings *model.FileSettings) string {
+	return strings.Join([]string{
+		settings.DriverName,
+		settings.Directory,
+		settings.AmazonS3AccessKeyId,
+		settings.AmazonS3SecretAccessKey,
+		settings.AmazonS3Bucket,
+		settings.AmazonS3Region,
+		settings.AmazonS3Endpoint,
+		fmt.Sprint(settings.AmazonS3SSL != nil && *settings.AmazonS3SSL),
+	}, "\x00")
+}
 
-		if model.IsFileExtImage(ext) {
-			_, err = s3Clnt.PutObject(bucket, path, bytes.NewReader(f), model.GetImageMimeType(ext))
-		} else {
-			_, err = s3Clnt.PutObject(bucket, path, bytes.NewReader(f), "binary/octet-stream")
-		}
-		if err != nil {
-			return model.NewLocAppError("WriteFile", "api.file.write_file.s3.app_error", nil, err.Error())
-		}
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if err := writeFileLocally(f, utils.Cfg.FileSettings.Directory+path); err != nil {
-			return err
-		}
-	} else {
-		return model.NewLocAppError("WriteFile", "api.file.write_file.configured.app_error", nil, "")
+func TestFileConnection(cfg *model.Config) *model.AppError {
+	if cfg.FileSettings.AmazonS3SecretAccessKey == model.FAKE_SETTING {
+		cfg.FileSettings.AmazonS3SecretAccessKey = utils.Cfg.FileSettings.AmazonS3SecretAccessKey
 	}
 
-	return nil
+	backend, err := utils.NewFileBackend(&cfg.FileSettings)
+	if err != nil {
+		return err
+	}
+
+	return backend.TestConnection()
 }
 
-func writeFileLocally(f []byte, path string) *model.AppError {
-	if err := os.MkdirAll(filepath.Dir(path), 0774); err != nil {
-		directory, _ := filepath.Abs(filepath.Dir(path))
-		return model.NewLocAppError("WriteFile", "api.file.write_file_locally.create_dir.app_error", nil, "directory="+directory+", err="+err.Error())
+func ReadFile(path string) ([]byte, *model.AppError) {
+	backend, err := FileBackend()
+	if err != nil {
+		return nil, err
 	}
+	return backend.ReadFile(path)
+}
 
-	if err := ioutil.WriteFile(path, f, 0644); err != nil {
-		return model.NewLocAppError("WriteFile", "api.file.write_file_locally.writing.app_error", nil, err.Error())
+func FileReader(path string) (io.ReadCloser, *model.AppError) {
+	backend, err := FileBackend()
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	return backend.Reader(path)
 }
 
-func openFileWriteStream(path string) (io.Writer, *model.AppError) {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		return nil, model.NewLocAppError("openFileWriteStream", "api.file.open_file_write_stream.s3.app_error", nil, "")
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if err := os.MkdirAll(filepath.Dir(utils.Cfg.FileSettings.Directory+path), 0774); err != nil {
-			return nil, model.NewLocAppError("openFileWriteStream", "api.file.open_file_write_stream.creating_dir.app_error", nil, err.Error())
-		}
+func MoveFile(oldPath, newPath string) *model.AppError {
+	backend, err := FileBackend()
+	if err != nil {
+		return err
+	}
+	return backend.MoveFile(oldPath, newPath)
+}
 
-		if fileHandle, err := os.Create(utils.Cfg.FileSettings.Directory + path); err != nil {
-			return nil, model.NewLocAppError("openFileWriteStream", "api.file.open_file_write_stream.local_server.app_error", nil, err.Error())
-		} else {
-			fileHandle.Chmod(0644)
-			return fileHandle, nil
-		}
+func WriteFile(f []byte, path string) *model.AppError {
+	backend, err := FileBackend()
+	if err != nil {
+		return err
 	}
+	_, err = backend.WriteFile(bytes.NewReader(f), path)
+	return err
+}
 
-	return nil, model.NewLocAppError("openFileWriteStream", "api.file.open_file_write_stream.configured.app_error", nil, "")
+func WriteFileStream(fr io.Reader, path string) (int64, *model.AppError) {
+	backend, err := FileBackend()
+	if err != nil {
+		return 0, err
+	}
+	return backend.WriteFile(fr, path)
 }
 
-func closeFileWriteStream(file io.Writer) {
-	file.(*os.File).Close()
+func RemoveFile(path string) *model.AppError {
+	backend, err := FileBackend()
+	if err != nil {
+		return err
+	}
+	return backend.RemoveFile(path)
 }
 
 func GetInfoForFilename(post *model.Post, teamId string, filename string) *model.FileInfo {
@@ -376,7 +338,13 @@ func GeneratePublicLinkHash(fileId, salt string) string {
 	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil))
 }
 
-func UploadFiles(teamId string, channelId string, userId string, fileHeaders []*multipart.FileHeader, clientIds []string) (*model.FileUploadResponse, *model.AppError) {
+func UploadFiles(teamId string, channelId string, userId string, files []io.ReadCloser, filenames []string, clientIds []string) (*model.FileUploadResponse, *model.AppError) {
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+
 	if len(utils.Cfg.FileSettings.DriverName) == 0 {
 		return nil, model.NewAppError("uploadFile", "api.file.upload_file.storage.app_error", nil, "", http.StatusNotImplemented)
 	}
@@ -386,32 +354,12 @@ func UploadFiles(teamId string, channelId string, userId string, fileHeaders []*
 		ClientIds: []string{},
 	}
 
-	previewPathList := []string{}
-	thumbnailPathList := []string{}
-	imageDataList := [][]byte{}
-
-	for i, fileHeader := range fileHeaders {
-		file, fileErr := fileHeader.Open()
-		defer file.Close()
-		if fileErr != nil {
-			return nil, model.NewAppError("UploadFiles", "api.file.upload_file.bad_parse.app_error", nil, fileErr.Error(), http.StatusBadRequest)
-		}
-
-		buf := bytes.NewBuffer(nil)
-		io.Copy(buf, file)
-		data := buf.Bytes()
-
-		info, err := DoUploadFile(teamId, channelId, userId, fileHeader.Filename, data)
+	for i, file := range files {
+		info, err := DoUploadFile(teamId, channelId, userId, filenames[i], file)
 		if err != nil {
 			return nil, err
 		}
 
-		if info.PreviewPath != "" || info.ThumbnailPath != "" {
-			previewPathList = append(previewPathList, info.PreviewPath)
-			thumbnailPathList = append(thumbnailPathList, info.ThumbnailPath)
-			imageDataList = append(imageDataList, data)
-		}
-
 		resStruct.FileInfos = append(resStruct.FileInfos, info)
 
 		if len(clientIds) > 0 {
@@ -419,20 +367,17 @@ func UploadFiles(teamId string, channelId string, userId string, fileHeaders []*
 		}
 	}
 
-	HandleImages(previewPathList, thumbnailPathList, imageDataList)
+	HandleImages(resStruct.FileInfos)
 
 	return resStruct, nil
 }
 
-func DoUploadFile(teamId string, channelId string, userId string, rawFilename string, data []byte) (*model.FileInfo, *model.AppError) {
+// DoUploadFile streams a file into storage and saves a FileInfo for it. Only the start of an image is held in
+// memory while checking its dimensions, and the rest of any file is copied straight through to the file backend.
+func DoUploadFile(teamId string, channelId string, userId string, rawFilename string, file io.Reader) (*model.FileInfo, *model.AppError) {
 	filename := filepath.Base(rawFilename)
 
-	info, err := model.GetInfoForBytes(filename, data)
-	if err != nil {
-		err.StatusCode = http.StatusBadRequest
-		return nil, err
-	}
-
+	info := model.NewInfo(filename)
 	info.Id = model.NewId()
 	info.CreatorId = userId
 
@@ -440,20 +385,38 @@ func DoUploadFile(teamId string, channelId string, userId string, rawFilename st
 	info.Path = pathPrefix + filename
 
 	if info.IsImage() {
-		// Check dimensions before loading the whole thing into memory later on
-		if info.Width*info.Height > MaxImageSize {
-			err := model.NewLocAppError("uploadFile", "api.file.upload_file.large_image.app_error", map[string]interface{}{"Filename": filename}, "")
-			err.StatusCode = http.StatusBadRequest
-			return nil, err
+		// Check dimensions before storing the file and loading the whole thing into memory later on
+		header := &bytes.Buffer{}
+		if config, _, err := image.DecodeConfig(io.TeeReader(file, header)); err == nil {
+			info.Width = config.Width
+			info.Height = config.Height
+			info.HasPreviewImage = true
+
+			if info.Width*info.Height > MaxImageSize {
+				err := model.NewLocAppError("uploadFile", "api.file.upload_file.large_image.app_error", map[string]interface{}{"Filename": filename}, "")
+				err.StatusCode = http.StatusBadRequest
+				return nil, err
+			}
 		}
+		file = io.MultiReader(header, file)
 
 		nameWithoutExtension := filename[:strings.LastIndex(filename, ".")]
 		info.PreviewPath = pathPrefix + nameWithoutExtension + "_preview.jpg"
 		info.ThumbnailPath = pathPrefix + nameWithoutExtension + "_thumb.jpg"
 	}
 
-	if err := WriteFile(data, info.Path); err != nil {
+	if written, err := WriteFileStream(file, info.Path); err != nil {
 		return nil, err
+	} else {
+		info.Size = written
+	}
+
+	if info.MimeType == "image/gif" && info.HasPreviewImage {
+		// Just show the gif itself instead of a preview image for animated gifs
+		if reader, err := FileReader(info.Path); err == nil {
+			info.HasPreviewImage = !model.IsAnimatedGif(reader)
+			reader.Close()
+		}
 	}
 
 	if result := <-Srv.Store.FileInfo().Save(info); result.Err != nil {
@@ -463,21 +426,35 @@ func DoUploadFile(teamId string, channelId string, userId string, rawFilename st
 	return info, nil
 }
 
-func HandleImages(previewPathList []string, thumbnailPathList []string, fileData [][]byte) {
-	for i, data := range fileData {
-		go func(i int, data []byte) {
-			img, width, height := prepareImage(fileData[i])
+// HandleImages asynchronously generates the thumbnails and previews for any of the given files that need them.
+func HandleImages(infos []*model.FileInfo) {
+	for _, info := range infos {
+		if info.PreviewPath == "" && info.ThumbnailPath == "" {
+			continue
+		}
+
+		go func(info *model.FileInfo) {
+			img, width, height := prepareImage(info.Path)
 			if img != nil {
-				go generateThumbnailImage(*img, thumbnailPathList[i], width, height)
-				go generatePreviewImage(*img, previewPathList[i], width)
+				go generateThumbnailImage(*img, info.ThumbnailPath, width, height)
+				go generatePreviewImage(*img, info.PreviewPath, width)
 			}
-		}(i, data)
+		}(info)
 	}
 }
 
-func prepareImage(fileData []byte) (*image.Image, int, int) {
+// prepareImage decodes the image stored at the given path straight from the file backend so that only the
+// decoded image is held in memory.
+func prepareImage(path string) (*image.Image, int, int) {
+	reader, appErr := FileReader(path)
+	if appErr != nil {
+		l4g.Error(utils.T("api.file.handle_images_forget.decode.error"), appErr)
+		return nil, 0, 0
+	}
+	defer reader.Close()
+
 	// Decode image bytes into Image object
-	img, imgType, err := image.Decode(bytes.NewReader(fileData))
+	img, imgType, err := image.Decode(reader)
 	if err != nil {
 		l4g.Error(utils.T("api.file.handle_images_forget.decode.error"), err)
 		return nil, 0, 0
@@ -495,7 +472,7 @@ func prepareImage(fileData []byte) (*image.Image, int, int) {
 	}
 
 	// Flip the image to be upright
-	orientation, _ := getImageOrientation(fileData)
+	orientation, _ := getImageOrientation(path)
 
 	switch orientation {
 	case UprightMirrored:
@@ -517,8 +494,14 @@ func prepareImage(fileData []byte) (*image.Image, int, int) {
 	return &img, width, height
 }
 
-func getImageOrientation(imageData []byte) (int, error) {
-	if exifData, err := exif.Decode(bytes.NewReader(imageData)); err != nil {
+func getImageOrientation(path string) (int, error) {
+	reader, appErr := FileReader(path)
+	if appErr != nil {
+		return Upright, appErr
+	}
+	defer reader.Close()
+
+	if exifData, err := exif.Decode(reader); err != nil {
 		return Upright, err
 	} else {
 		if tag, err := exifData.Get("Orientation"); err != nil {
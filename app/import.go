@@ -4,13 +4,11 @@
 package app
 
 import (
-	"bufio"
-	"bytes"
-	"encoding/json"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"regexp"
-	"strings"
 	"unicode/utf8"
 
 	l4g "github.com/alecthomas/log4go"
@@ -21,12 +19,13 @@ import (
 // Import Data Models
 
 type LineImportData struct {
-	Type    string             `json:"type"`
-	Team    *TeamImportData    `json:"team"`
-	Channel *ChannelImportData `json:"channel"`
-	User    *UserImportData    `json:"user"`
-	Post    *PostImportData    `json:"post"`
-	Version *int               `json:"version"`
+	Type       string                `json:"type"`
+	Team       *TeamImportData       `json:"team"`
+	Channel    *ChannelImportData    `json:"channel"`
+	User       *UserImportData       `json:"user"`
+	Post       *PostImportData       `json:"post"`
+	DirectPost *DirectPostImportData `json:"direct_post"`
+	Version    *int                  `json:"version"`
 }
 
 type TeamImportData struct {
@@ -93,6 +92,42 @@ type PostImportData struct {
 
 	Message  *string `json:"message"`
 	CreateAt *int64  `json:"create_at"`
+
+	Reactions   *[]ReactionImportData   `json:"reactions"`
+	Replies     *[]ReplyImportData      `json:"replies"`
+	Attachments *[]AttachmentImportData `json:"attachments"`
+}
+
+type DirectPostImportData struct {
+	ChannelMembers *[]string `json:"channel_members"`
+	User           *string   `json:"user"`
+
+	Message  *string `json:"message"`
+	CreateAt *int64  `json:"create_at"`
+
+	Reactions   *[]ReactionImportData   `json:"reactions"`
+	Replies     *[]ReplyImportData      `json:"replies"`
+	Attachments *[]AttachmentImportData `json:"attachments"`
+}
+
+type ReplyImportData struct {
+	User *string `json:"user"`
+
+	Message  *string `json:"message"`
+	CreateAt *int64  `json:"create_at"`
+
+	Reactions   *[]ReactionImportData   `json:"reactions"`
+	Attachments *[]AttachmentImportData `json:"attachments"`
+}
+
+type ReactionImportData struct {
+	User      *string `json:"user"`
+	CreateAt  *int64  `json:"create_at"`
+	EmojiName *string `json:"emoji_name"`
+}
+
+type AttachmentImportData struct {
+	Path *string `json:"path"`
 }
 
 //
@@ -102,36 +137,18 @@ type PostImportData struct {
 //
 
 func BulkImport(fileReader io.Reader, dryRun bool) (*model.AppError, int) {
-	scanner := bufio.NewScanner(fileReader)
-	lineNumber := 0
-	for scanner.Scan() {
-		decoder := json.NewDecoder(strings.NewReader(scanner.Text()))
-		lineNumber++
-
-		var line LineImportData
-		if err := decoder.Decode(&line); err != nil {
-			return model.NewLocAppError("BulkImport", "app.import.bulk_import.json_decode.error", nil, err.Error()), lineNumber
-		} else {
-			if lineNumber == 1 {
-				importDataFileVersion, apperr := processImportDataFileVersionLine(line)
-				if apperr != nil {
-					return apperr, lineNumber
-				}
-
-				if importDataFileVersion != 1 {
-					return model.NewAppError("BulkImport", "app.import.bulk_import.unsupported_version.error", nil, "", http.StatusBadRequest), lineNumber
-				}
-			} else if err := ImportLine(line, dryRun); err != nil {
-				return err, lineNumber
-			}
-		}
-	}
+	return BulkImportWithPath(fileReader, dryRun, "")
+}
 
-	if err := scanner.Err(); err != nil {
-		return model.NewLocAppError("BulkImport", "app.import.bulk_import.file_scan.error", nil, err.Error()), 0
-	}
+// BulkImportWithPath imports the data like BulkImport, resolving the paths of attachments relative to importPath,
+// which is usually the directory that contains the import file.
+func BulkImportWithPath(fileReader io.Reader, dryRun bool, importPath string) (*model.AppError, int) {
+	result, err := BulkImportWithOptions(fileReader, &BulkImportOptions{
+		DryRun:     dryRun,
+		ImportPath: importPath,
+	})
 
-	return nil, 0
+	return err, result.ErrorLine
 }
 
 func processImportDataFileVersionLine(line LineImportData) (int, *model.AppError) {
@@ -142,6 +159,39 @@ func processImportDataFileVersionLine(line LineImportData) (int, *model.AppError
 	return *line.Version, nil
 }
 
+// resolveAttachmentPaths makes the relative attachment paths in a line relative to importPath instead of the working
+// directory.
+func resolveAttachmentPaths(line *LineImportData, importPath string) {
+	var attachments []*[]AttachmentImportData
+	var replies *[]ReplyImportData
+
+	if line.Post != nil {
+		attachments = append(attachments, line.Post.Attachments)
+		replies = line.Post.Replies
+	} else if line.DirectPost != nil {
+		attachments = append(attachments, line.DirectPost.Attachments)
+		replies = line.DirectPost.Replies
+	}
+
+	if replies != nil {
+		for _, reply := range *replies {
+			attachments = append(attachments, reply.Attachments)
+		}
+	}
+
+	for _, data := range attachments {
+		if data == nil {
+			continue
+		}
+
+		for _, attachment := range *data {
+			if attachment.Path != nil && *attachment.Path != "" && !filepath.IsAbs(*attachment.Path) {
+				*attachment.Path = filepath.Join(importPath, *attachment.Path)
+			}
+		}
+	}
+}
+
 func ImportLine(line LineImportData, dryRun bool) *model.AppError {
 	switch {
 	case line.Type == "team":
@@ -168,6 +218,12 @@ func ImportLine(line LineImportData, dryRun bool) *model.AppError {
 		} else {
 			return ImportPost(line.Post, dryRun)
 		}
+	case line.Type == "direct_post":
+		if line.DirectPost == nil {
+			return model.NewAppError("BulkImport", "app.import.import_line.null_direct_post.error", nil, "", http.StatusBadRequest)
+		} else {
+			return ImportDirectPost(line.DirectPost, dryRun)
+		}
 	default:
 		return model.NewLocAppError("BulkImport", "app.import.import_line.unknown_line_type.error", map[string]interface{}{"Type": line.Type}, "")
 	}
@@ -738,37 +794,116 @@ func ImportPost(data *PostImportData, dryRun bool) *model.AppError {
 		channel = result.Data.(*model.Channel)
 	}
 
-	var user *model.User
-	if result := <-Srv.Store.User().GetByUsername(*data.User); result.Err != nil {
-		return model.NewAppError("BulkImport", "app.import.import_post.user_not_found.error", map[string]interface{}{"Username": *data.User}, "", http.StatusBadRequest)
+	user, err := getImportUser(*data.User)
+	if err != nil {
+		return err
+	}
+
+	post, err := findImportPost(channel.Id, "", *data.Message, *data.CreateAt)
+	if err != nil {
+		return err
+	}
+
+	post.ChannelId = channel.Id
+	post.Message = *data.Message
+	post.UserId = user.Id
+	post.CreateAt = *data.CreateAt
+
+	if err := saveImportPost(post, data.Attachments, data.Reactions); err != nil {
+		return err
+	}
+
+	return importReplies(post, data.Replies)
+}
+
+func ImportDirectPost(data *DirectPostImportData, dryRun bool) *model.AppError {
+	if err := validateDirectPostImportData(data); err != nil {
+		return err
+	}
+
+	// If this is a Dry Run, do not continue any further.
+	if dryRun {
+		return nil
+	}
+
+	var userIds []string
+	for _, username := range *data.ChannelMembers {
+		if user, err := getImportUser(username); err != nil {
+			return err
+		} else {
+			userIds = append(userIds, user.Id)
+		}
+	}
+
+	var channel *model.Channel
+	var err *model.AppError
+	if len(userIds) == 2 {
+		channel, err = CreateDirectChannel(userIds[0], userIds[1])
 	} else {
-		user = result.Data.(*model.User)
+		channel, err = CreateGroupChannel(userIds)
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := getImportUser(*data.User)
+	if err != nil {
+		return err
 	}
 
-	// Check if this post already exists.
+	post, err := findImportPost(channel.Id, "", *data.Message, *data.CreateAt)
+	if err != nil {
+		return err
+	}
+
+	post.ChannelId = channel.Id
+	post.Message = *data.Message
+	post.UserId = user.Id
+	post.CreateAt = *data.CreateAt
+
+	if err := saveImportPost(post, data.Attachments, data.Reactions); err != nil {
+		return err
+	}
+
+	return importReplies(post, data.Replies)
+}
+
+func getImportUser(username string) (*model.User, *model.AppError) {
+	if result := <-Srv.Store.User().GetByUsername(username); result.Err != nil {
+		return nil, model.NewAppError("BulkImport", "app.import.import_post.user_not_found.error", map[string]interface{}{"Username": username}, "", http.StatusBadRequest)
+	} else {
+		return result.Data.(*model.User), nil
+	}
+}
+
+// findImportPost returns the post created by an earlier import of the same data, or a new post if there isn't one.
+func findImportPost(channelId string, rootId string, message string, createAt int64) (*model.Post, *model.AppError) {
 	var posts []*model.Post
-	if result := <-Srv.Store.Post().GetPostsCreatedAt(channel.Id, *data.CreateAt); result.Err != nil {
-		return result.Err
+	if result := <-Srv.Store.Post().GetPostsCreatedAt(channelId, createAt); result.Err != nil {
+		return nil, result.Err
 	} else {
 		posts = result.Data.([]*model.Post)
 	}
 
-	var post *model.Post
 	for _, p := range posts {
-		if p.Message == *data.Message {
-			post = p
-			break
+		if p.Message == message && p.RootId == rootId {
+			return p, nil
 		}
 	}
 
-	if post == nil {
-		post = &model.Post{}
+	return &model.Post{}, nil
+}
+
+// saveImportPost saves or overwrites a post along with the attachments and reactions that it doesn't have yet.
+func saveImportPost(post *model.Post, attachments *[]AttachmentImportData, reactions *[]ReactionImportData) *model.AppError {
+	infos, err := uploadImportAttachments(post, attachments)
+	if err != nil {
+		return err
 	}
 
-	post.ChannelId = channel.Id
-	post.Message = *data.Message
-	post.UserId = user.Id
-	post.CreateAt = *data.CreateAt
+	for _, info := range infos {
+		post.FileIds = append(post.FileIds, info.Id)
+	}
 
 	post.Hashtags, _ = model.ParseHashtags(post.Message)
 
@@ -782,6 +917,120 @@ func ImportPost(data *PostImportData, dryRun bool) *model.AppError {
 		}
 	}
 
+	for _, info := range infos {
+		if result := <-Srv.Store.FileInfo().AttachToPost(info.Id, post.Id); result.Err != nil {
+			return result.Err
+		}
+	}
+
+	if len(infos) > 0 {
+		Srv.Store.FileInfo().InvalidateFileInfosForPostCache(post.Id)
+	}
+
+	return importReactions(post, reactions)
+}
+
+// uploadImportAttachments uploads the attached files, skipping any with the same name as a file that is already
+// attached to the post.
+func uploadImportAttachments(post *model.Post, data *[]AttachmentImportData) ([]*model.FileInfo, *model.AppError) {
+	if data == nil || len(*data) == 0 {
+		return nil, nil
+	}
+
+	existing := make(map[string]bool)
+	if post.Id != "" {
+		if result := <-Srv.Store.FileInfo().GetForPost(post.Id, true, false); result.Err != nil {
+			return nil, result.Err
+		} else {
+			for _, info := range result.Data.([]*model.FileInfo) {
+				existing[info.Name] = true
+			}
+		}
+	}
+
+	var infos []*model.FileInfo
+	for _, attachment := range *data {
+		if existing[filepath.Base(*attachment.Path)] {
+			continue
+		}
+
+		file, err := os.Open(*attachment.Path)
+		if err != nil {
+			return nil, model.NewAppError("BulkImport", "app.import.upload_attachments.open.error", map[string]interface{}{"Path": *attachment.Path}, err.Error(), http.StatusBadRequest)
+		}
+
+		// Files uploaded through the API aren't stored under a team either
+		info, appErr := DoUploadFile("noteam", post.ChannelId, post.UserId, *attachment.Path, file)
+		file.Close()
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		infos = append(infos, info)
+	}
+
+	HandleImages(infos)
+
+	return infos, nil
+}
+
+func importReactions(post *model.Post, data *[]ReactionImportData) *model.AppError {
+	if data == nil {
+		return nil
+	}
+
+	for _, rdata := range *data {
+		user, err := getImportUser(*rdata.User)
+		if err != nil {
+			return err
+		}
+
+		reaction := &model.Reaction{
+			UserId:    user.Id,
+			PostId:    post.Id,
+			EmojiName: *rdata.EmojiName,
+			CreateAt:  *rdata.CreateAt,
+		}
+
+		// Saving a reaction that already exists doesn't do anything, so reimporting a post is safe
+		if result := <-Srv.Store.Reaction().Save(reaction); result.Err != nil {
+			return result.Err
+		}
+	}
+
+	Srv.Store.Reaction().InvalidateCacheForPost(post.Id)
+
+	return nil
+}
+
+func importReplies(root *model.Post, data *[]ReplyImportData) *model.AppError {
+	if data == nil {
+		return nil
+	}
+
+	for _, rdata := range *data {
+		user, err := getImportUser(*rdata.User)
+		if err != nil {
+			return err
+		}
+
+		reply, err := findImportPost(root.ChannelId, root.Id, *rdata.Message, *rdata.CreateAt)
+		if err != nil {
+			return err
+		}
+
+		reply.ChannelId = root.ChannelId
+		reply.RootId = root.Id
+		reply.ParentId = root.Id
+		reply.Message = *rdata.Message
+		reply.UserId = user.Id
+		reply.CreateAt = *rdata.CreateAt
+
+		if err := saveImportPost(reply, rdata.Attachments, rdata.Reactions); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -810,6 +1059,128 @@ func validatePostImportData(data *PostImportData) *model.AppError {
 		return model.NewAppError("BulkImport", "app.import.validate_post_import_data.create_at_zero.error", nil, "", http.StatusBadRequest)
 	}
 
+	return validatePostChildrenImportData(*data.CreateAt, data.Reactions, data.Replies, data.Attachments)
+}
+
+func validateDirectPostImportData(data *DirectPostImportData) *model.AppError {
+	if data.ChannelMembers == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_direct_post_import_data.channel_members_missing.error", nil, "", http.StatusBadRequest)
+	} else if len(*data.ChannelMembers) < 2 || len(*data.ChannelMembers) > model.CHANNEL_GROUP_MAX_USERS {
+		return model.NewAppError("BulkImport", "app.import.validate_direct_post_import_data.channel_members_count.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.User == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_direct_post_import_data.user_missing.error", nil, "", http.StatusBadRequest)
+	}
+
+	isMember := false
+	for _, member := range *data.ChannelMembers {
+		if member == *data.User {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		return model.NewAppError("BulkImport", "app.import.validate_direct_post_import_data.user_not_member.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.Message == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_direct_post_import_data.message_missing.error", nil, "", http.StatusBadRequest)
+	} else if utf8.RuneCountInString(*data.Message) > model.POST_MESSAGE_MAX_RUNES {
+		return model.NewAppError("BulkImport", "app.import.validate_direct_post_import_data.message_length.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.CreateAt == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_direct_post_import_data.create_at_missing.error", nil, "", http.StatusBadRequest)
+	} else if *data.CreateAt == 0 {
+		return model.NewAppError("BulkImport", "app.import.validate_direct_post_import_data.create_at_zero.error", nil, "", http.StatusBadRequest)
+	}
+
+	return validatePostChildrenImportData(*data.CreateAt, data.Reactions, data.Replies, data.Attachments)
+}
+
+func validatePostChildrenImportData(createAt int64, reactions *[]ReactionImportData, replies *[]ReplyImportData, attachments *[]AttachmentImportData) *model.AppError {
+	if reactions != nil {
+		for _, reaction := range *reactions {
+			if err := validateReactionImportData(&reaction, createAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	if replies != nil {
+		for _, reply := range *replies {
+			if err := validateReplyImportData(&reply, createAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	if attachments != nil {
+		for _, attachment := range *attachments {
+			if err := validateAttachmentImportData(&attachment); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateReplyImportData(data *ReplyImportData, parentCreateAt int64) *model.AppError {
+	if data.User == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_reply_import_data.user_missing.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.Message == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_reply_import_data.message_missing.error", nil, "", http.StatusBadRequest)
+	} else if utf8.RuneCountInString(*data.Message) > model.POST_MESSAGE_MAX_RUNES {
+		return model.NewAppError("BulkImport", "app.import.validate_reply_import_data.message_length.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.CreateAt == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_reply_import_data.create_at_missing.error", nil, "", http.StatusBadRequest)
+	} else if *data.CreateAt == 0 {
+		return model.NewAppError("BulkImport", "app.import.validate_reply_import_data.create_at_zero.error", nil, "", http.StatusBadRequest)
+	} else if *data.CreateAt < parentCreateAt {
+		return model.NewAppError("BulkImport", "app.import.validate_reply_import_data.create_at_before_parent.error", nil, "", http.StatusBadRequest)
+	}
+
+	// Replies can't have replies of their own
+	return validatePostChildrenImportData(*data.CreateAt, data.Reactions, nil, data.Attachments)
+}
+
+func validateReactionImportData(data *ReactionImportData, parentCreateAt int64) *model.AppError {
+	if data.User == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_reaction_import_data.user_missing.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.EmojiName == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_reaction_import_data.emoji_name_missing.error", nil, "", http.StatusBadRequest)
+	} else if len(*data.EmojiName) == 0 || len(*data.EmojiName) > model.EMOJI_NAME_MAX_LENGTH {
+		return model.NewAppError("BulkImport", "app.import.validate_reaction_import_data.emoji_name_length.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.CreateAt == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_reaction_import_data.create_at_missing.error", nil, "", http.StatusBadRequest)
+	} else if *data.CreateAt == 0 {
+		return model.NewAppError("BulkImport", "app.import.validate_reaction_import_data.create_at_zero.error", nil, "", http.StatusBadRequest)
+	} else if *data.CreateAt < parentCreateAt {
+		return model.NewAppError("BulkImport", "app.import.validate_reaction_import_data.create_at_before_parent.error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func validateAttachmentImportData(data *AttachmentImportData) *model.AppError {
+	if data.Path == nil || *data.Path == "" {
+		return model.NewAppError("BulkImport", "app.import.validate_attachment_import_data.path_missing.error", nil, "", http.StatusBadRequest)
+	}
+
+	if info, err := os.Stat(*data.Path); err != nil || info.IsDir() {
+		return model.NewAppError("BulkImport", "app.import.validate_attachment_import_data.file_not_found.error", map[string]interface{}{"Path": *data.Path}, "", http.StatusBadRequest)
+	}
+
 	return nil
 }
 
@@ -884,19 +1255,17 @@ func OldImportChannel(channel *model.Channel) *model.Channel {
 }
 
 func OldImportFile(file io.Reader, teamId string, channelId string, userId string, fileName string) (*model.FileInfo, error) {
-	buf := bytes.NewBuffer(nil)
-	io.Copy(buf, file)
-	data := buf.Bytes()
-
-	fileInfo, err := DoUploadFile(teamId, channelId, userId, fileName, data)
+	fileInfo, err := DoUploadFile(teamId, channelId, userId, fileName, file)
 	if err != nil {
 		return nil, err
 	}
 
-	img, width, height := prepareImage(data)
-	if img != nil {
-		generateThumbnailImage(*img, fileInfo.ThumbnailPath, width, height)
-		generatePreviewImage(*img, fileInfo.PreviewPath, width)
+	if fileInfo.PreviewPath != "" || fileInfo.ThumbnailPath != "" {
+		img, width, height := prepareImage(fileInfo.Path)
+		if img != nil {
+			generateThumbnailImage(*img, fileInfo.ThumbnailPath, width, height)
+			generatePreviewImage(*img, fileInfo.PreviewPath, width)
+		}
 	}
 
 	return fileInfo, nil
@@ -915,37 +1284,8 @@ func OldImportIncomingWebhookPost(post *model.Post, props model.StringInterface)
 	if len(props) > 0 {
 		for key, val := range props {
 			if key == "attachments" {
-				if list, success := val.([]interface{}); success {
-					// parse attachment links into Markdown format
-					for i, aInt := range list {
-						attachment := aInt.(map[string]interface{})
-						if aText, ok := attachment["text"].(string); ok {
-							aText = linkWithTextRegex.ReplaceAllString(aText, "[${2}](${1})")
-							attachment["text"] = aText
-							list[i] = attachment
-						}
-						if aText, ok := attachment["pretext"].(string); ok {
-							aText = linkWithTextRegex.ReplaceAllString(aText, "[${2}](${1})")
-							attachment["pretext"] = aText
-							list[i] = attachment
-						}
-						if fVal, ok := attachment["fields"]; ok {
-							if fields, ok := fVal.([]interface{}); ok {
-								// parse attachment field links into Markdown format
-								for j, fInt := range fields {
-									field := fInt.(map[string]interface{})
-									if fValue, ok := field["value"].(string); ok {
-										fValue = linkWithTextRegex.ReplaceAllString(fValue, "[${2}](${1})")
-										field["value"] = fValue
-										fields[j] = field
-									}
-								}
-								attachment["fields"] = fields
-								list[i] = attachment
-							}
-						}
-					}
-					post.AddProp(key, list)
+				if attachments := model.SlackAttachmentsFromInterface(val); attachments != nil {
+					parseSlackAttachment(post, attachments)
 				}
 			} else if key != "from_webhook" {
 				post.AddProp(key, val)
@@ -0,0 +1,338 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	IMPORT_MAX_LINE_SIZE               = 10 * 1024 * 1024
+	IMPORT_CHECKPOINT_INTERVAL         = 1000
+	IMPORT_CHECKPOINT_TEMP_FILE_SUFFIX = ".tmp"
+)
+
+type BulkImportOptions struct {
+	DryRun bool
+
+	// Workers is the number of lines that are imported at the same time. Lines are only imported alongside lines of
+	// the same type, so everything a line depends on has finished importing before it starts.
+	Workers int
+
+	// ImportPath is the directory that attachment paths are relative to.
+	ImportPath string
+
+	// CheckpointPath is a file that records how much of the data has been imported so that an interrupted import can
+	// be resumed by running it again with the same data. It isn't used for a dry run.
+	CheckpointPath string
+
+	// Rejects receives each line that fails to import along with its error. When it's set, the import carries on
+	// past those lines instead of stopping at the first one.
+	Rejects io.Writer
+}
+
+type BulkImportResult struct {
+	Lines    int
+	Skipped  int
+	Rejected int
+
+	// ErrorLine is the line that stopped the import when it failed, or 0 if it didn't fail on a particular line.
+	ErrorLine int
+}
+
+type ImportCheckpoint struct {
+	// LineNumber is the last line of the data that has been imported, along with every line before it.
+	LineNumber int `json:"line_number"`
+}
+
+type ImportRejectedLine struct {
+	LineNumber int             `json:"line_number"`
+	Line       string          `json:"line"`
+	Error      *model.AppError `json:"error"`
+}
+
+type importLineJob struct {
+	lineNumber int
+	text       string
+	line       LineImportData
+}
+
+// bulkImporter tracks the lines that have finished importing across all of the workers.
+type bulkImporter struct {
+	options *BulkImportOptions
+	result  *BulkImportResult
+	pending sync.WaitGroup
+
+	mutex      sync.Mutex
+	finished   map[int]bool
+	checkpoint int
+	saved      int
+	err        *model.AppError
+}
+
+// BulkImportWithOptions imports the data like BulkImport, but can import several lines at once, resume an earlier
+// import that was interrupted and record the lines that fail instead of stopping.
+func BulkImportWithOptions(fileReader io.Reader, options *BulkImportOptions) (*BulkImportResult, *model.AppError) {
+	importer := &bulkImporter{
+		options:  options,
+		result:   &BulkImportResult{},
+		finished: make(map[int]bool),
+	}
+
+	useCheckpoint := options.CheckpointPath != "" && !options.DryRun
+	if useCheckpoint {
+		if checkpoint, err := readImportCheckpoint(options.CheckpointPath); err != nil {
+			return importer.result, err
+		} else {
+			importer.checkpoint = checkpoint.LineNumber
+			importer.saved = checkpoint.LineNumber
+		}
+	}
+
+	var jobs chan importLineJob
+	if options.Workers > 1 {
+		jobs = make(chan importLineJob)
+		for i := 0; i < options.Workers; i++ {
+			go func() {
+				for job := range jobs {
+					importer.importLine(job)
+				}
+			}()
+		}
+		defer close(jobs)
+	}
+
+	scanner := bufio.NewScanner(fileReader)
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), IMPORT_MAX_LINE_SIZE)
+
+	currentType := ""
+	for scanner.Scan() {
+		if importer.failed() {
+			break
+		}
+
+		importer.result.Lines++
+		lineNumber := importer.result.Lines
+		text := scanner.Text()
+
+		var line LineImportData
+		if err := json.NewDecoder(strings.NewReader(text)).Decode(&line); err != nil {
+			appErr := model.NewLocAppError("BulkImport", "app.import.bulk_import.json_decode.error", nil, err.Error())
+			if lineNumber == 1 {
+				importer.result.ErrorLine = lineNumber
+				return importer.result, appErr
+			}
+
+			importer.pending.Add(1)
+			importer.lineFinished(lineNumber, text, appErr)
+			continue
+		}
+
+		if lineNumber == 1 {
+			if err := validateImportDataFileVersion(line); err != nil {
+				importer.result.ErrorLine = lineNumber
+				return importer.result, err
+			}
+
+			importer.pending.Add(1)
+			importer.lineFinished(lineNumber, text, nil)
+			continue
+		}
+
+		if lineNumber <= importer.checkpoint {
+			importer.result.Skipped++
+			continue
+		}
+
+		resolveAttachmentPaths(&line, options.ImportPath)
+
+		if line.Type != currentType {
+			// Wait for the lines that this one might depend on
+			importer.pending.Wait()
+			currentType = line.Type
+
+			if useCheckpoint {
+				if err := importer.saveCheckpoint(); err != nil {
+					return importer.result, err
+				}
+			}
+
+			if importer.failed() {
+				break
+			}
+		}
+
+		job := importLineJob{lineNumber: lineNumber, text: text, line: line}
+		importer.pending.Add(1)
+		if jobs != nil {
+			jobs <- job
+		} else {
+			importer.importLine(job)
+		}
+	}
+
+	importer.pending.Wait()
+
+	if useCheckpoint {
+		if err := importer.saveCheckpoint(); err != nil {
+			return importer.result, err
+		}
+	}
+
+	if importer.err != nil {
+		return importer.result, importer.err
+	}
+
+	if err := scanner.Err(); err != nil {
+		return importer.result, model.NewLocAppError("BulkImport", "app.import.bulk_import.file_scan.error", nil, err.Error())
+	}
+
+	return importer.result, nil
+}
+
+func validateImportDataFileVersion(line LineImportData) *model.AppError {
+	if importDataFileVersion, err := processImportDataFileVersionLine(line); err != nil {
+		return err
+	} else if importDataFileVersion != 1 {
+		return model.NewAppError("BulkImport", "app.import.bulk_import.unsupported_version.error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (importer *bulkImporter) importLine(job importLineJob) {
+	importer.lineFinished(job.lineNumber, job.text, ImportLine(job.line, importer.options.DryRun))
+}
+
+func (importer *bulkImporter) failed() bool {
+	importer.mutex.Lock()
+	defer importer.mutex.Unlock()
+
+	return importer.err != nil
+}
+
+// lineFinished records the outcome of importing a line. A line that failed is written to the rejects if there are
+// any, and otherwise stops the import.
+func (importer *bulkImporter) lineFinished(lineNumber int, text string, err *model.AppError) {
+	defer importer.pending.Done()
+
+	importer.mutex.Lock()
+	defer importer.mutex.Unlock()
+
+	if err != nil {
+		if importer.options.Rejects == nil {
+			// Keep the first line that failed so that the error is the same however many workers there are
+			if importer.err == nil || lineNumber < importer.result.ErrorLine {
+				importer.err = err
+				importer.result.ErrorLine = lineNumber
+			}
+			return
+		}
+
+		if rejectErr := importer.writeRejectedLine(lineNumber, text, err); rejectErr != nil && importer.err == nil {
+			importer.err = rejectErr
+			return
+		}
+
+		importer.result.Rejected++
+	}
+
+	importer.finished[lineNumber] = true
+	for importer.finished[importer.checkpoint+1] {
+		delete(importer.finished, importer.checkpoint+1)
+		importer.checkpoint++
+	}
+
+	if importer.options.CheckpointPath != "" && !importer.options.DryRun && importer.checkpoint-importer.saved >= IMPORT_CHECKPOINT_INTERVAL {
+		if err := importer.saveCheckpointLocked(); err != nil && importer.err == nil {
+			importer.err = err
+		}
+	}
+}
+
+func (importer *bulkImporter) writeRejectedLine(lineNumber int, text string, err *model.AppError) *model.AppError {
+	err.Translate(utils.T)
+
+	b, jsonErr := json.Marshal(&ImportRejectedLine{
+		LineNumber: lineNumber,
+		Line:       text,
+		Error:      err,
+	})
+	if jsonErr != nil {
+		return model.NewAppError("BulkImport", "app.import.bulk_import.write_rejected_line.error", nil, jsonErr.Error(), http.StatusInternalServerError)
+	}
+
+	if _, writeErr := importer.options.Rejects.Write(append(b, '\n')); writeErr != nil {
+		return model.NewAppError("BulkImport", "app.import.bulk_import.write_rejected_line.error", nil, writeErr.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (importer *bulkImporter) saveCheckpoint() *model.AppError {
+	importer.mutex.Lock()
+	defer importer.mutex.Unlock()
+
+	return importer.saveCheckpointLocked()
+}
+
+func (importer *bulkImporter) saveCheckpointLocked() *model.AppError {
+	if importer.checkpoint == importer.saved {
+		return nil
+	}
+
+	if err := writeImportCheckpoint(importer.options.CheckpointPath, &ImportCheckpoint{LineNumber: importer.checkpoint}); err != nil {
+		return err
+	}
+
+	importer.saved = importer.checkpoint
+	return nil
+}
+
+func readImportCheckpoint(path string) (*ImportCheckpoint, *model.AppError) {
+	checkpoint := &ImportCheckpoint{}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return checkpoint, nil
+	} else if err != nil {
+		return nil, model.NewAppError("BulkImport", "app.import.bulk_import.read_checkpoint.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, model.NewAppError("BulkImport", "app.import.bulk_import.read_checkpoint.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return checkpoint, nil
+}
+
+// writeImportCheckpoint replaces the checkpoint file in a single step so that it isn't left half written if the
+// import is interrupted.
+func writeImportCheckpoint(path string, checkpoint *ImportCheckpoint) *model.AppError {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return model.NewAppError("BulkImport", "app.import.bulk_import.write_checkpoint.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	tempPath := path + IMPORT_CHECKPOINT_TEMP_FILE_SUFFIX
+	if err := ioutil.WriteFile(tempPath, data, 0600); err != nil {
+		return model.NewAppError("BulkImport", "app.import.bulk_import.write_checkpoint.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		return model.NewAppError("BulkImport", "app.import.bulk_import.write_checkpoint.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestImportBulkImportWithOptionsWorkers(t *testing.T) {
+	_ = Setup()
+
+	lines := []string{`{"type": "version", "version": 1}`}
+	for i := 0; i < 50; i++ {
+		lines = append(lines, `{"type": "post", "post": {"team": "team", "channel": "channel", "user": "user", "message": "message `+strconv.Itoa(i)+`", "create_at": 123456789012}}`)
+	}
+
+	// Every line is valid.
+	result, err := BulkImportWithOptions(strings.NewReader(strings.Join(lines, "\n")), &BulkImportOptions{DryRun: true, Workers: 4})
+	if err != nil {
+		t.Fatal(err)
+	} else if result.Lines != 51 || result.ErrorLine != 0 {
+		t.Fatal("should've processed every line", result)
+	}
+
+	// The first line that fails is reported however many workers there are.
+	lines[20] = `{"type": "post", "post": {"team": "team", "channel": "channel", "user": "user"}}`
+	lines[30] = `{"type": "post", "post": {"team": "team"}}`
+	result, err = BulkImportWithOptions(strings.NewReader(strings.Join(lines, "\n")), &BulkImportOptions{DryRun: true, Workers: 4})
+	if err == nil {
+		t.Fatal("should've failed")
+	} else if result.ErrorLine != 21 {
+		t.Fatalf("should've failed on line 21, got %v", result.ErrorLine)
+	}
+}
+
+func TestImportBulkImportWithOptionsRejects(t *testing.T) {
+	_ = Setup()
+
+	data := `{"type": "version", "version": 1}
+{"type": "team", "team": {"type": "O", "display_name": "lskmw2d7a5ao7ppwqh5ljchvr4", "name": "` + model.NewId() + `"}}
+{"type": "team", "team": {"type": "X", "display_name": "lskmw2d7a5ao7ppwqh5ljchvr4", "name": "` + model.NewId() + `"}}
+{"type": "team", "team": {"type": "O"
+{"type": "team", "team": {"type": "O", "display_name": "lskmw2d7a5ao7ppwqh5ljchvr4", "name": "` + model.NewId() + `"}}`
+
+	var rejects bytes.Buffer
+	result, err := BulkImportWithOptions(strings.NewReader(data), &BulkImportOptions{DryRun: true, Workers: 2, Rejects: &rejects})
+	if err != nil {
+		t.Fatal(err)
+	} else if result.Lines != 5 || result.Rejected != 2 {
+		t.Fatal("should've rejected the invalid lines and carried on", result)
+	}
+
+	rejected := make(map[int]*ImportRejectedLine)
+	for _, line := range strings.Split(strings.TrimSpace(rejects.String()), "\n") {
+		var rejectedLine ImportRejectedLine
+		if err := json.Unmarshal([]byte(line), &rejectedLine); err != nil {
+			t.Fatal(err)
+		}
+		rejected[rejectedLine.LineNumber] = &rejectedLine
+	}
+
+	if line := rejected[3]; line == nil || line.Error == nil || line.Error.Id != "app.import.validate_team_import_data.type_invalid.error" {
+		t.Fatal("should've recorded the invalid team with its error")
+	} else if line.Line != strings.Split(data, "\n")[2] {
+		t.Fatal("should've recorded the text of the line")
+	} else if line.Error.Message == line.Error.Id {
+		t.Fatal("should've translated the error")
+	}
+
+	if line := rejected[4]; line == nil || line.Error == nil || line.Error.Id != "app.import.bulk_import.json_decode.error" {
+		t.Fatal("should've recorded the line that isn't valid json")
+	}
+}
+
+func TestImportBulkImportWithOptionsCheckpoint(t *testing.T) {
+	_ = Setup()
+
+	dir, err := ioutil.TempDir("", "import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	checkpointPath := filepath.Join(dir, "checkpoint")
+
+	teamName := model.NewId()
+	data := `{"type": "version", "version": 1}
+{"type": "team", "team": {"type": "X", "display_name": "lskmw2d7a5ao7ppwqh5ljchvr4", "name": "` + model.NewId() + `"}}
+{"type": "team", "team": {"type": "O", "display_name": "lskmw2d7a5ao7ppwqh5ljchvr4", "name": "` + teamName + `"}}`
+
+	// The import stops at the invalid line, and the checkpoint records the lines before it.
+	result, appErr := BulkImportWithOptions(strings.NewReader(data), &BulkImportOptions{CheckpointPath: checkpointPath})
+	if appErr == nil || result.ErrorLine != 2 {
+		t.Fatal("should've failed on line 2")
+	}
+
+	if checkpoint, err := readImportCheckpoint(checkpointPath); err != nil {
+		t.Fatal(err)
+	} else if checkpoint.LineNumber != 1 {
+		t.Fatalf("checkpoint should've been at line 1, got %v", checkpoint.LineNumber)
+	}
+
+	// Pretend the bad line was fixed by hand and resume after it.
+	if err := writeImportCheckpoint(checkpointPath, &ImportCheckpoint{LineNumber: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, appErr = BulkImportWithOptions(strings.NewReader(data), &BulkImportOptions{CheckpointPath: checkpointPath})
+	if appErr != nil {
+		t.Fatal(appErr)
+	} else if result.Skipped != 1 {
+		t.Fatal("should've skipped the line before the checkpoint")
+	}
+
+	if _, err := GetTeamByName(teamName); err != nil {
+		t.Fatal("should've imported the line after the checkpoint")
+	}
+
+	if checkpoint, err := readImportCheckpoint(checkpointPath); err != nil {
+		t.Fatal(err)
+	} else if checkpoint.LineNumber != 3 {
+		t.Fatalf("checkpoint should've been at the last line, got %v", checkpoint.LineNumber)
+	}
+}
+
+func TestImportReadImportCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint")
+
+	if checkpoint, err := readImportCheckpoint(path); err != nil {
+		t.Fatal(err)
+	} else if checkpoint.LineNumber != 0 {
+		t.Fatal("a missing checkpoint should start from the beginning")
+	}
+
+	if err := writeImportCheckpoint(path, &ImportCheckpoint{LineNumber: 1234}); err != nil {
+		t.Fatal(err)
+	}
+
+	if checkpoint, err := readImportCheckpoint(path); err != nil {
+		t.Fatal(err)
+	} else if checkpoint.LineNumber != 1234 {
+		t.Fatal("should've read the saved checkpoint")
+	}
+
+	if err := ioutil.WriteFile(path, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readImportCheckpoint(path); err == nil {
+		t.Fatal("should've failed to read an invalid checkpoint")
+	}
+}
@@ -571,6 +571,310 @@ func TestImportValidatePostImportData(t *testing.T) {
 	}
 }
 
+func TestImportValidateDirectPostImportData(t *testing.T) {
+
+	// Test with minimum required valid properties.
+	data := DirectPostImportData{
+		ChannelMembers: &[]string{"username", "otherusername"},
+		User:           ptrStr("username"),
+		Message:        ptrStr("message"),
+		CreateAt:       ptrInt64(model.GetMillis()),
+	}
+	if err := validateDirectPostImportData(&data); err != nil {
+		t.Fatal("Validation failed but should have been valid.")
+	}
+
+	// Test with a group channel.
+	data.ChannelMembers = &[]string{"username", "otherusername", "thirdusername"}
+	if err := validateDirectPostImportData(&data); err != nil {
+		t.Fatal("Validation failed but should have been valid.")
+	}
+
+	// Test with missing required properties.
+	data.ChannelMembers = nil
+	if err := validateDirectPostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	data = DirectPostImportData{
+		ChannelMembers: &[]string{"username", "otherusername"},
+		Message:        ptrStr("message"),
+		CreateAt:       ptrInt64(model.GetMillis()),
+	}
+	if err := validateDirectPostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	data = DirectPostImportData{
+		ChannelMembers: &[]string{"username", "otherusername"},
+		User:           ptrStr("username"),
+		CreateAt:       ptrInt64(model.GetMillis()),
+	}
+	if err := validateDirectPostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	data = DirectPostImportData{
+		ChannelMembers: &[]string{"username", "otherusername"},
+		User:           ptrStr("username"),
+		Message:        ptrStr("message"),
+	}
+	if err := validateDirectPostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	// Test with invalid properties.
+	data = DirectPostImportData{
+		ChannelMembers: &[]string{"username"},
+		User:           ptrStr("username"),
+		Message:        ptrStr("message"),
+		CreateAt:       ptrInt64(model.GetMillis()),
+	}
+	if err := validateDirectPostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to too few channel members.")
+	}
+
+	data.ChannelMembers = &[]string{"u1", "u2", "u3", "u4", "u5", "u6", "u7", "u8", "username"}
+	if err := validateDirectPostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to too many channel members.")
+	}
+
+	data.ChannelMembers = &[]string{"otherusername", "thirdusername"}
+	if err := validateDirectPostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to the user not being a channel member.")
+	}
+
+	data = DirectPostImportData{
+		ChannelMembers: &[]string{"username", "otherusername"},
+		User:           ptrStr("username"),
+		Message:        ptrStr(strings.Repeat("1234567890", 500)),
+		CreateAt:       ptrInt64(model.GetMillis()),
+	}
+	if err := validateDirectPostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to too long message.")
+	}
+
+	data = DirectPostImportData{
+		ChannelMembers: &[]string{"username", "otherusername"},
+		User:           ptrStr("username"),
+		Message:        ptrStr("message"),
+		CreateAt:       ptrInt64(0),
+	}
+	if err := validateDirectPostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to 0 create-at value.")
+	}
+
+	// Test with an invalid reply.
+	data = DirectPostImportData{
+		ChannelMembers: &[]string{"username", "otherusername"},
+		User:           ptrStr("username"),
+		Message:        ptrStr("message"),
+		CreateAt:       ptrInt64(model.GetMillis()),
+		Replies:        &[]ReplyImportData{{User: ptrStr("otherusername")}},
+	}
+	if err := validateDirectPostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to an invalid reply.")
+	}
+}
+
+func TestImportValidateReplyImportData(t *testing.T) {
+	parentCreateAt := model.GetMillis() - 100
+
+	// Test with minimum required valid properties.
+	data := ReplyImportData{
+		User:     ptrStr("username"),
+		Message:  ptrStr("message"),
+		CreateAt: ptrInt64(model.GetMillis()),
+	}
+	if err := validateReplyImportData(&data, parentCreateAt); err != nil {
+		t.Fatal("Validation failed but should have been valid.")
+	}
+
+	// Test with missing required properties.
+	data = ReplyImportData{
+		Message:  ptrStr("message"),
+		CreateAt: ptrInt64(model.GetMillis()),
+	}
+	if err := validateReplyImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	data = ReplyImportData{
+		User:     ptrStr("username"),
+		CreateAt: ptrInt64(model.GetMillis()),
+	}
+	if err := validateReplyImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	data = ReplyImportData{
+		User:    ptrStr("username"),
+		Message: ptrStr("message"),
+	}
+	if err := validateReplyImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	// Test with invalid properties.
+	data = ReplyImportData{
+		User:     ptrStr("username"),
+		Message:  ptrStr(strings.Repeat("1234567890", 500)),
+		CreateAt: ptrInt64(model.GetMillis()),
+	}
+	if err := validateReplyImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to too long message.")
+	}
+
+	data = ReplyImportData{
+		User:     ptrStr("username"),
+		Message:  ptrStr("message"),
+		CreateAt: ptrInt64(0),
+	}
+	if err := validateReplyImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to 0 create-at value.")
+	}
+
+	data = ReplyImportData{
+		User:     ptrStr("username"),
+		Message:  ptrStr("message"),
+		CreateAt: ptrInt64(parentCreateAt - 1),
+	}
+	if err := validateReplyImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to create-at value before the parent.")
+	}
+
+	// Test with an invalid reaction.
+	data = ReplyImportData{
+		User:      ptrStr("username"),
+		Message:   ptrStr("message"),
+		CreateAt:  ptrInt64(model.GetMillis()),
+		Reactions: &[]ReactionImportData{{User: ptrStr("username")}},
+	}
+	if err := validateReplyImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to an invalid reaction.")
+	}
+}
+
+func TestImportValidateReactionImportData(t *testing.T) {
+	parentCreateAt := model.GetMillis() - 100
+
+	// Test with minimum required valid properties.
+	data := ReactionImportData{
+		User:      ptrStr("username"),
+		EmojiName: ptrStr("emoji"),
+		CreateAt:  ptrInt64(model.GetMillis()),
+	}
+	if err := validateReactionImportData(&data, parentCreateAt); err != nil {
+		t.Fatal("Validation failed but should have been valid.")
+	}
+
+	// Test with missing required properties.
+	data = ReactionImportData{
+		EmojiName: ptrStr("emoji"),
+		CreateAt:  ptrInt64(model.GetMillis()),
+	}
+	if err := validateReactionImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	data = ReactionImportData{
+		User:     ptrStr("username"),
+		CreateAt: ptrInt64(model.GetMillis()),
+	}
+	if err := validateReactionImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	data = ReactionImportData{
+		User:      ptrStr("username"),
+		EmojiName: ptrStr("emoji"),
+	}
+	if err := validateReactionImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	// Test with invalid properties.
+	data = ReactionImportData{
+		User:      ptrStr("username"),
+		EmojiName: ptrStr(strings.Repeat("1234567890", 7)),
+		CreateAt:  ptrInt64(model.GetMillis()),
+	}
+	if err := validateReactionImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to too long emoji name.")
+	}
+
+	data = ReactionImportData{
+		User:      ptrStr("username"),
+		EmojiName: ptrStr("emoji"),
+		CreateAt:  ptrInt64(0),
+	}
+	if err := validateReactionImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to 0 create-at value.")
+	}
+
+	data = ReactionImportData{
+		User:      ptrStr("username"),
+		EmojiName: ptrStr("emoji"),
+		CreateAt:  ptrInt64(parentCreateAt - 1),
+	}
+	if err := validateReactionImportData(&data, parentCreateAt); err == nil {
+		t.Fatal("Should have failed due to create-at value before the parent.")
+	}
+}
+
+func TestImportValidateAttachmentImportData(t *testing.T) {
+
+	// Test with a file that exists.
+	data := AttachmentImportData{Path: ptrStr("../tests/test.png")}
+	if err := validateAttachmentImportData(&data); err != nil {
+		t.Fatal("Validation failed but should have been valid.")
+	}
+
+	// Test with missing required properties.
+	data = AttachmentImportData{}
+	if err := validateAttachmentImportData(&data); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	data = AttachmentImportData{Path: ptrStr("")}
+	if err := validateAttachmentImportData(&data); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	// Test with files that can't be imported.
+	data = AttachmentImportData{Path: ptrStr("../tests/" + model.NewId() + ".png")}
+	if err := validateAttachmentImportData(&data); err == nil {
+		t.Fatal("Should have failed due to the file not existing.")
+	}
+
+	data = AttachmentImportData{Path: ptrStr("../tests")}
+	if err := validateAttachmentImportData(&data); err == nil {
+		t.Fatal("Should have failed due to the path being a directory.")
+	}
+}
+
+func TestImportResolveAttachmentPaths(t *testing.T) {
+	line := LineImportData{
+		Type: "post",
+		Post: &PostImportData{
+			Attachments: &[]AttachmentImportData{{Path: ptrStr("files/a.png")}, {Path: ptrStr("/abs/b.png")}},
+			Replies: &[]ReplyImportData{{
+				Attachments: &[]AttachmentImportData{{Path: ptrStr("c.png")}},
+			}},
+		},
+	}
+
+	resolveAttachmentPaths(&line, "/import")
+
+	if path := *(*line.Post.Attachments)[0].Path; path != "/import/files/a.png" {
+		t.Fatalf("relative path should've been resolved against the import path, got %v", path)
+	} else if path := *(*line.Post.Attachments)[1].Path; path != "/abs/b.png" {
+		t.Fatalf("absolute path shouldn't have changed, got %v", path)
+	} else if path := *(*(*line.Post.Replies)[0].Attachments)[0].Path; path != "/import/c.png" {
+		t.Fatalf("reply attachment path should've been resolved against the import path, got %v", path)
+	}
+}
+
 func TestImportImportTeam(t *testing.T) {
 	_ = Setup()
 
@@ -1694,6 +1998,184 @@ func TestImportImportPost(t *testing.T) {
 			t.Fatalf("Hashtags not as expected: %s", post.Hashtags)
 		}
 	}
+
+	// Save a post with replies, reactions and an attachment.
+	threadTime := time + 3
+	data = &PostImportData{
+		Team:     &teamName,
+		Channel:  &channelName,
+		User:     &username,
+		Message:  ptrStr("Thread root"),
+		CreateAt: &threadTime,
+		Reactions: &[]ReactionImportData{{
+			User:      &username,
+			EmojiName: ptrStr("smile"),
+			CreateAt:  ptrInt64(threadTime + 1),
+		}},
+		Replies: &[]ReplyImportData{{
+			User:     &username,
+			Message:  ptrStr("Thread reply"),
+			CreateAt: ptrInt64(threadTime + 2),
+			Reactions: &[]ReactionImportData{{
+				User:      &username,
+				EmojiName: ptrStr("tada"),
+				CreateAt:  ptrInt64(threadTime + 3),
+			}},
+		}},
+		Attachments: &[]AttachmentImportData{{Path: ptrStr("../tests/test.png")}},
+	}
+	if err := ImportPost(data, false); err != nil {
+		t.Fatalf("Expected success: %v", err.Error())
+	}
+	AssertAllPostsCount(t, initialPostCount, 6, team.Id)
+
+	var root *model.Post
+	if result := <-Srv.Store.Post().GetPostsCreatedAt(channel.Id, threadTime); result.Err != nil {
+		t.Fatal(result.Err.Error())
+	} else if posts := result.Data.([]*model.Post); len(posts) != 1 {
+		t.Fatal("Unexpected number of posts found.")
+	} else {
+		root = posts[0]
+	}
+
+	if len(root.FileIds) != 1 {
+		t.Fatal("The attachment should have been added to the post.")
+	} else if result := <-Srv.Store.FileInfo().GetForPost(root.Id, true, false); result.Err != nil {
+		t.Fatal(result.Err.Error())
+	} else if infos := result.Data.([]*model.FileInfo); len(infos) != 1 || infos[0].Name != "test.png" {
+		t.Fatal("The attachment should have been attached to the post.")
+	}
+
+	if result := <-Srv.Store.Reaction().GetForPost(root.Id, false); result.Err != nil {
+		t.Fatal(result.Err.Error())
+	} else if reactions := result.Data.([]*model.Reaction); len(reactions) != 1 || reactions[0].EmojiName != "smile" || reactions[0].UserId != user.Id {
+		t.Fatal("The reaction should have been added to the post.")
+	}
+
+	if result := <-Srv.Store.Post().GetPostsCreatedAt(channel.Id, threadTime+2); result.Err != nil {
+		t.Fatal(result.Err.Error())
+	} else if posts := result.Data.([]*model.Post); len(posts) != 1 || posts[0].RootId != root.Id || posts[0].Message != "Thread reply" {
+		t.Fatal("The reply should have been added to the thread.")
+	} else if result := <-Srv.Store.Reaction().GetForPost(posts[0].Id, false); result.Err != nil {
+		t.Fatal(result.Err.Error())
+	} else if reactions := result.Data.([]*model.Reaction); len(reactions) != 1 || reactions[0].EmojiName != "tada" {
+		t.Fatal("The reaction should have been added to the reply.")
+	}
+
+	// Importing the thread again shouldn't duplicate anything.
+	if err := ImportPost(data, false); err != nil {
+		t.Fatalf("Expected success: %v", err.Error())
+	}
+	AssertAllPostsCount(t, initialPostCount, 6, team.Id)
+
+	if result := <-Srv.Store.FileInfo().GetForPost(root.Id, true, false); result.Err != nil {
+		t.Fatal(result.Err.Error())
+	} else if infos := result.Data.([]*model.FileInfo); len(infos) != 1 {
+		t.Fatal("The attachment shouldn't have been uploaded again.")
+	}
+}
+
+func TestImportImportDirectPost(t *testing.T) {
+	_ = Setup()
+
+	// Create the users.
+	username1 := "n" + model.NewId()
+	username2 := "n" + model.NewId()
+	username3 := "n" + model.NewId()
+	for _, username := range []string{username1, username2, username3} {
+		if err := ImportUser(&UserImportData{
+			Username: ptrStr(username),
+			Email:    ptrStr(model.NewId() + "@example.com"),
+		}, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	user1, err := GetUserByUsername(username1)
+	if err != nil {
+		t.Fatalf("Failed to get user from database.")
+	}
+	user2, err := GetUserByUsername(username2)
+	if err != nil {
+		t.Fatalf("Failed to get user from database.")
+	}
+
+	// Try adding a valid direct post in dry run mode.
+	createAt := model.GetMillis()
+	data := &DirectPostImportData{
+		ChannelMembers: &[]string{username1, username2},
+		User:           &username1,
+		Message:        ptrStr("Message"),
+		CreateAt:       &createAt,
+	}
+	if err := ImportDirectPost(data, true); err != nil {
+		t.Fatalf("Expected success: %v", err.Error())
+	}
+
+	if result := <-Srv.Store.Channel().GetByName("", model.GetDMNameFromIds(user1.Id, user2.Id), false); result.Err == nil {
+		t.Fatal("The direct channel shouldn't have been created in dry run mode.")
+	}
+
+	// Try adding a direct post with an unknown channel member in apply mode.
+	data.ChannelMembers = &[]string{username1, model.NewId()}
+	if err := ImportDirectPost(data, false); err == nil {
+		t.Fatal("Expected error.")
+	}
+
+	// Try adding a valid direct post with a reply in apply mode.
+	data.ChannelMembers = &[]string{username1, username2}
+	data.Replies = &[]ReplyImportData{{
+		User:     &username2,
+		Message:  ptrStr("Reply"),
+		CreateAt: ptrInt64(createAt + 1),
+	}}
+	if err := ImportDirectPost(data, false); err != nil {
+		t.Fatalf("Expected success: %v", err.Error())
+	}
+
+	var channel *model.Channel
+	if result := <-Srv.Store.Channel().GetByName("", model.GetDMNameFromIds(user1.Id, user2.Id), false); result.Err != nil {
+		t.Fatal("The direct channel should have been created.")
+	} else {
+		channel = result.Data.(*model.Channel)
+	}
+
+	var root *model.Post
+	if result := <-Srv.Store.Post().GetPostsCreatedAt(channel.Id, createAt); result.Err != nil {
+		t.Fatal(result.Err.Error())
+	} else if posts := result.Data.([]*model.Post); len(posts) != 1 || posts[0].UserId != user1.Id || posts[0].Message != "Message" {
+		t.Fatal("The direct post should have been created.")
+	} else {
+		root = posts[0]
+	}
+
+	if result := <-Srv.Store.Post().GetPostsCreatedAt(channel.Id, createAt+1); result.Err != nil {
+		t.Fatal(result.Err.Error())
+	} else if posts := result.Data.([]*model.Post); len(posts) != 1 || posts[0].UserId != user2.Id || posts[0].RootId != root.Id {
+		t.Fatal("The reply should have been created.")
+	}
+
+	// Importing the post again shouldn't duplicate it.
+	if err := ImportDirectPost(data, false); err != nil {
+		t.Fatalf("Expected success: %v", err.Error())
+	}
+
+	if result := <-Srv.Store.Post().GetPostsCreatedAt(channel.Id, createAt); result.Err != nil {
+		t.Fatal(result.Err.Error())
+	} else if posts := result.Data.([]*model.Post); len(posts) != 1 {
+		t.Fatal("The direct post shouldn't have been duplicated.")
+	}
+
+	// Try adding a valid post to a group channel in apply mode.
+	data = &DirectPostImportData{
+		ChannelMembers: &[]string{username1, username2, username3},
+		User:           &username3,
+		Message:        ptrStr("Group message"),
+		CreateAt:       &createAt,
+	}
+	if err := ImportDirectPost(data, false); err != nil {
+		t.Fatalf("Expected success: %v", err.Error())
+	}
 }
 
 func TestImportImportLine(t *testing.T) {
@@ -1731,6 +2213,12 @@ func TestImportImportLine(t *testing.T) {
 	if err := ImportLine(line, false); err == nil {
 		t.Fatalf("Expected an error when importing a line with type post with a nil post.")
 	}
+
+	// Try import line with direct_post type but nil direct post.
+	line.Type = "direct_post"
+	if err := ImportLine(line, false); err == nil {
+		t.Fatalf("Expected an error when importing a line with type direct_post with a nil direct post.")
+	}
 }
 
 func TestImportBulkImport(t *testing.T) {
@@ -0,0 +1,64 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"github.com/mattermost/platform/jobs"
+	"github.com/mattermost/platform/model"
+)
+
+func InitJobs() {
+	jobs.Srv.RegisterWorker(model.JOB_TYPE_DATA_RETENTION, MakeDataRetentionWorker())
+	jobs.Srv.RegisterScheduler(model.JOB_TYPE_DATA_RETENTION, MakeDataRetentionScheduler())
+	jobs.Srv.RegisterWorker(model.JOB_TYPE_SCHEDULED_POSTS, MakeScheduledPostsWorker())
+	jobs.Srv.RegisterScheduler(model.JOB_TYPE_SCHEDULED_POSTS, MakeScheduledPostsScheduler())
+	jobs.Srv.RegisterWorker(model.JOB_TYPE_DND_STATUSES, MakeDNDStatusesWorker())
+	jobs.Srv.RegisterScheduler(model.JOB_TYPE_DND_STATUSES, MakeDNDStatusesScheduler())
+	jobs.Srv.RegisterWorker(model.JOB_TYPE_CUSTOM_STATUSES, MakeCustomStatusesWorker())
+	jobs.Srv.RegisterScheduler(model.JOB_TYPE_CUSTOM_STATUSES, MakeCustomStatusesScheduler())
+	jobs.Srv.RegisterWorker(model.JOB_TYPE_COMMAND_WEBHOOK_CLEANUP, MakeCommandWebhookCleanupWorker())
+	jobs.Srv.RegisterScheduler(model.JOB_TYPE_COMMAND_WEBHOOK_CLEANUP, MakeCommandWebhookCleanupScheduler())
+	jobs.Srv.RegisterWorker(model.JOB_TYPE_OUTGOING_WEBHOOK_DELIVERIES, MakeOutgoingWebhookDeliveriesWorker())
+	jobs.Srv.RegisterScheduler(model.JOB_TYPE_OUTGOING_WEBHOOK_DELIVERIES, MakeOutgoingWebhookDeliveriesScheduler())
+}
+
+func GetJob(id string) (*model.Job, *model.AppError) {
+	if result := <-Srv.Store.Job().Get(id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Job), nil
+	}
+}
+
+func GetJobsPage(page int, perPage int) ([]*model.Job, *model.AppError) {
+	return GetJobs(page*perPage, perPage)
+}
+
+func GetJobs(offset int, limit int) ([]*model.Job, *model.AppError) {
+	if result := <-Srv.Store.Job().GetAllPage(offset, limit); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Job), nil
+	}
+}
+
+func GetJobsByTypePage(jobType string, page int, perPage int) ([]*model.Job, *model.AppError) {
+	return GetJobsByType(jobType, page*perPage, perPage)
+}
+
+func GetJobsByType(jobType string, offset int, limit int) ([]*model.Job, *model.AppError) {
+	if result := <-Srv.Store.Job().GetAllByTypePage(jobType, offset, limit); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Job), nil
+	}
+}
+
+func CreateJob(job *model.Job) (*model.Job, *model.AppError) {
+	return jobs.CreateJob(job.Type, job.Data)
+}
+
+func CancelJob(jobId string) *model.AppError {
+	return jobs.RequestCancellation(jobId)
+}
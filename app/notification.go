@@ -4,12 +4,9 @@
 package app
 
 import (
-	"crypto/tls"
 	"fmt"
 	"html"
 	"html/template"
-	"io/ioutil"
-	"net/http"
 	"net/url"
 	"path/filepath"
 	"sort"
@@ -117,30 +114,8 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 		updateMentionChans = append(updateMentionChans, Srv.Store.Channel().IncrementMentionCount(post.ChannelId, id))
 	}
 
-	senderName := ""
-	channelName := ""
-	if post.IsSystemMessage() {
-		senderName = utils.T("system.message.name")
-	} else {
-		if value, ok := post.Props["override_username"]; ok && post.Props["from_webhook"] == "true" {
-			senderName = value.(string)
-		} else {
-			senderName = sender.Username
-		}
-	}
-
-	if channel.Type == model.CHANNEL_GROUP {
-		userList := []*model.User{}
-		for _, u := range profileMap {
-			if u.Id != sender.Id {
-				userList = append(userList, u)
-			}
-		}
-		userList = append(userList, sender)
-		channelName = model.GetGroupDisplayNameFromUsers(userList, false)
-	} else {
-		channelName = channel.DisplayName
-	}
+	senderName := getNotificationSenderName(post, sender)
+	channelName := getNotificationChannelName(channel, sender, profileMap)
 
 	var senderUsername string
 	if value, ok := post.Props["override_username"]; ok && post.Props["from_webhook"] == "true" {
@@ -170,8 +145,8 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 				}
 			}
 
-			if userAllowsEmails && status.Status != model.STATUS_ONLINE && profileMap[id].DeleteAt == 0 {
-				sendNotificationEmail(post, profileMap[id], channel, team, senderName, sender)
+			if userAllowsEmails && status.Status != model.STATUS_ONLINE && profileMap[id].DeleteAt == 0 && !profileMap[id].IsBot {
+				sendNotificationEmail(post, profileMap[id], status, channel, team, senderName, sender)
 			}
 		}
 	}
@@ -317,7 +292,7 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 	return mentionedUsersList, nil
 }
 
-func sendNotificationEmail(post *model.Post, user *model.User, channel *model.Channel, team *model.Team, senderName string, sender *model.User) *model.AppError {
+func sendNotificationEmail(post *model.Post, user *model.User, status *model.Status, channel *model.Channel, team *model.Team, senderName string, sender *model.User) *model.AppError {
 	if channel.IsGroupOrDirect() && channel.TeamId != team.Id {
 		// this message is a cross-team DM/GM so we need to find a team that the recipient is on to use in the link
 		if result := <-Srv.Store.Team().GetTeamsByUserId(user.Id); result.Err != nil {
@@ -362,6 +337,11 @@ func sendNotificationEmail(post *model.Post, user *model.User, channel *model.Ch
 		// fall back to sending a single email if we can't batch it for some reason
 	}
 
+	// batched emails are held until do not disturb ends, but single ones aren't sent at all
+	if status.Status == model.STATUS_DND {
+		return nil
+	}
+
 	var channelName string
 	var bodyText string
 	var subjectText string
@@ -469,18 +449,62 @@ func GetMessageForNotification(post *model.Post, translateFunc i18n.TranslateFun
 	}
 }
 
+func getNotificationSenderName(post *model.Post, sender *model.User) string {
+	if post.IsSystemMessage() {
+		return utils.T("system.message.name")
+	}
+
+	if value, ok := post.Props["override_username"]; ok && post.Props["from_webhook"] == "true" {
+		return value.(string)
+	}
+
+	return sender.Username
+}
+
+// getNotificationChannelName gets the name of a channel to show in notifications. The names of group channels are
+// made up from the channel's members.
+func getNotificationChannelName(channel *model.Channel, sender *model.User, profileMap map[string]*model.User) string {
+	if channel.Type != model.CHANNEL_GROUP {
+		return channel.DisplayName
+	}
+
+	userList := []*model.User{}
+	for _, u := range profileMap {
+		if u.Id != sender.Id {
+			userList = append(userList, u)
+		}
+	}
+	userList = append(userList, sender)
+
+	return model.GetGroupDisplayNameFromUsers(userList, false)
+}
+
 func sendPushNotification(post *model.Post, user *model.User, channel *model.Channel, senderName, channelName string, wasMentioned bool) *model.AppError {
 	sessions, err := getMobileAppSessions(user.Id)
 	if err != nil {
 		return err
 	}
 
-	if channel.Type == model.CHANNEL_DIRECT {
-		channelName = senderName
+	msg := buildPushNotification(post, user, channel, senderName, channelName, wasMentioned, *utils.Cfg.EmailSettings.PushNotificationContents)
+
+	l4g.Debug(utils.T("api.post.send_notifications_and_forget.push_notification.debug"), msg.DeviceId, msg.Message)
+
+	for _, session := range sessions {
+		tmpMessage := *model.PushNotificationFromJson(strings.NewReader(msg.ToJson()))
+		tmpMessage.SetDeviceIdAndPlatform(session.DeviceId)
+		queuePushNotification(tmpMessage, session)
+
+		if einterfaces.GetMetricsInterface() != nil {
+			einterfaces.GetMetricsInterface().IncrementPostSentPush()
+		}
 	}
 
-	userLocale := utils.GetUserTranslations(user.Locale)
+	return nil
+}
 
+// buildPushNotification creates the push notification sent to a user for a post. With id loaded contents, only the
+// ids of the post and channel are included and the device fetches the rest using GetPushNotificationForPost.
+func buildPushNotification(post *model.Post, user *model.User, channel *model.Channel, senderName, channelName string, wasMentioned bool, contents string) model.PushNotification {
 	msg := model.PushNotification{}
 	if badge := <-Srv.Store.User().GetUnreadCount(user.Id); badge.Err != nil {
 		msg.Badge = 1
@@ -489,11 +513,25 @@ func sendPushNotification(post *model.Post, user *model.User, channel *model.Cha
 		msg.Badge = int(badge.Data.(int64))
 	}
 	msg.Type = model.PUSH_TYPE_MESSAGE
-	msg.TeamId = channel.TeamId
 	msg.ChannelId = channel.Id
+	msg.PostId = post.Id
+
+	if contents == model.ID_LOADED_NOTIFICATION {
+		msg.IsIdLoaded = true
+		msg.ContentAvailable = 1
+		return msg
+	}
+
+	msg.TeamId = channel.TeamId
 	msg.ChannelName = channel.Name
 
-	if *utils.Cfg.EmailSettings.PushNotificationContents == model.FULL_NOTIFICATION {
+	if channel.Type == model.CHANNEL_DIRECT {
+		channelName = senderName
+	}
+
+	userLocale := utils.GetUserTranslations(user.Locale)
+
+	if contents == model.FULL_NOTIFICATION {
 		if channel.Type == model.CHANNEL_DIRECT {
 			msg.Category = model.CATEGORY_DM
 			msg.Message = senderName + ": " + model.ClearMentionTags(post.Message)
@@ -511,19 +549,47 @@ func sendPushNotification(post *model.Post, user *model.User, channel *model.Cha
 		}
 	}
 
-	l4g.Debug(utils.T("api.post.send_notifications_and_forget.push_notification.debug"), msg.DeviceId, msg.Message)
+	return msg
+}
 
-	for _, session := range sessions {
-		tmpMessage := *model.PushNotificationFromJson(strings.NewReader(msg.ToJson()))
-		tmpMessage.SetDeviceIdAndPlatform(session.DeviceId)
-		go sendToPushProxy(tmpMessage, session)
+// GetPushNotificationForPost gets the full contents of the push notification for a post so that a device that was
+// sent an id loaded notification can show it.
+func GetPushNotificationForPost(postId, userId string) (*model.PushNotification, *model.AppError) {
+	post, err := GetSinglePost(postId)
+	if err != nil {
+		return nil, err
+	}
 
-		if einterfaces.GetMetricsInterface() != nil {
-			einterfaces.GetMetricsInterface().IncrementPostSentPush()
+	channel, err := GetChannel(post.ChannelId)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := GetUser(post.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var profileMap map[string]*model.User
+	if channel.Type == model.CHANNEL_GROUP {
+		if result := <-Srv.Store.User().GetAllProfilesInChannel(channel.Id, true); result.Err != nil {
+			return nil, result.Err
+		} else {
+			profileMap = result.Data.(map[string]*model.User)
 		}
 	}
 
-	return nil
+	senderName := getNotificationSenderName(post, sender)
+	channelName := getNotificationChannelName(channel, sender, profileMap)
+
+	msg := buildPushNotification(post, user, channel, senderName, channelName, true, model.FULL_NOTIFICATION)
+
+	return &msg, nil
 }
 
 func ClearPushNotification(userId string, channelId string) *model.AppError {
@@ -548,43 +614,12 @@ func ClearPushNotification(userId string, channelId string) *model.AppError {
 	for _, session := range sessions {
 		tmpMessage := *model.PushNotificationFromJson(strings.NewReader(msg.ToJson()))
 		tmpMessage.SetDeviceIdAndPlatform(session.DeviceId)
-		go sendToPushProxy(tmpMessage, session)
+		queuePushNotification(tmpMessage, session)
 	}
 
 	return nil
 }
 
-func sendToPushProxy(msg model.PushNotification, session *model.Session) {
-	msg.ServerId = utils.CfgDiagnosticId
-
-	tr := &http.Transport{
-		TLSClientConfig:   &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
-		DisableKeepAlives: true,
-	}
-	httpClient := &http.Client{Transport: tr}
-	request, _ := http.NewRequest("POST", *utils.Cfg.EmailSettings.PushNotificationServer+model.API_URL_SUFFIX_V1+"/send_push", strings.NewReader(msg.ToJson()))
-
-	if resp, err := httpClient.Do(request); err != nil {
-		l4g.Error("Device push reported as error for UserId=%v SessionId=%v message=%v", session.UserId, session.Id, err.Error())
-	} else {
-		pushResponse := model.PushResponseFromJson(resp.Body)
-		if resp.Body != nil {
-			ioutil.ReadAll(resp.Body)
-			resp.Body.Close()
-		}
-
-		if pushResponse[model.PUSH_STATUS] == model.PUSH_STATUS_REMOVE {
-			l4g.Info("Device was reported as removed for UserId=%v SessionId=%v removing push for this session", session.UserId, session.Id)
-			AttachDeviceId(session.Id, "", session.ExpiresAt)
-			ClearSessionCacheForUser(session.UserId)
-		}
-
-		if pushResponse[model.PUSH_STATUS] == model.PUSH_STATUS_FAIL {
-			l4g.Error("Device push reported as error for UserId=%v SessionId=%v message=%v", session.UserId, session.Id, pushResponse[model.PUSH_STATUS_ERROR_MSG])
-		}
-	}
-}
-
 func getMobileAppSessions(userId string) ([]*model.Session, *model.AppError) {
 	if result := <-Srv.Store.Session().GetSessionsWithActiveDeviceIds(userId); result.Err != nil {
 		return nil, result.Err
@@ -787,6 +822,10 @@ func DoesNotifyPropsAllowPushNotification(user *model.User, channelNotifyProps m
 }
 
 func DoesStatusAllowPushNotification(userNotifyProps model.StringMap, status *model.Status, channelId string) bool {
+	if status.Status == model.STATUS_DND {
+		return false
+	}
+
 	if pushStatus, ok := userNotifyProps["push_status"]; (pushStatus == model.STATUS_ONLINE || !ok) && (status.ActiveChannel != channelId || model.GetMillis()-status.LastActivityAt > model.STATUS_CHANNEL_TIMEOUT) {
 		return true
 	} else if pushStatus == model.STATUS_AWAY && (status.Status == model.STATUS_AWAY || status.Status == model.STATUS_OFFLINE) {
@@ -4,6 +4,7 @@
 package app
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/mattermost/platform/model"
@@ -516,6 +517,7 @@ func TestDoesStatusAllowPushNotification(t *testing.T) {
 	offline := &model.Status{UserId: userId, Status: model.STATUS_OFFLINE, Manual: false, LastActivityAt: 0, ActiveChannel: ""}
 	away := &model.Status{UserId: userId, Status: model.STATUS_AWAY, Manual: false, LastActivityAt: 0, ActiveChannel: ""}
 	online := &model.Status{UserId: userId, Status: model.STATUS_ONLINE, Manual: false, LastActivityAt: model.GetMillis(), ActiveChannel: ""}
+	dnd := &model.Status{UserId: userId, Status: model.STATUS_DND, Manual: true, LastActivityAt: 0, ActiveChannel: ""}
 
 	userNotifyProps["push_status"] = model.STATUS_ONLINE
 	// WHEN props is ONLINE and user is offline
@@ -600,4 +602,38 @@ func TestDoesStatusAllowPushNotification(t *testing.T) {
 	if DoesStatusAllowPushNotification(userNotifyProps, online, "") {
 		t.Fatal("Should have been false")
 	}
+
+	for _, pushStatus := range []string{model.STATUS_ONLINE, model.STATUS_AWAY, model.STATUS_OFFLINE} {
+		userNotifyProps["push_status"] = pushStatus
+		// WHEN user is in do not disturb
+		if DoesStatusAllowPushNotification(userNotifyProps, dnd, channelId) {
+			t.Fatal("Should have been false")
+		}
+	}
+}
+
+func TestBuildPushNotification(t *testing.T) {
+	th := Setup().InitBasic()
+
+	post := th.CreatePost(th.BasicChannel)
+
+	msg := buildPushNotification(post, th.BasicUser2, th.BasicChannel, th.BasicUser.Username, th.BasicChannel.DisplayName, true, model.FULL_NOTIFICATION)
+	if msg.IsIdLoaded || msg.PostId != post.Id || msg.ChannelName != th.BasicChannel.Name || !strings.Contains(msg.Message, post.Message) {
+		t.Fatal("should have included the full contents", msg.ToJson())
+	}
+
+	msg = buildPushNotification(post, th.BasicUser2, th.BasicChannel, th.BasicUser.Username, th.BasicChannel.DisplayName, true, model.ID_LOADED_NOTIFICATION)
+	if !msg.IsIdLoaded || msg.PostId != post.Id || msg.ChannelId != th.BasicChannel.Id {
+		t.Fatal("should have included the ids", msg.ToJson())
+	}
+
+	if msg.Message != "" || msg.ChannelName != "" || msg.TeamId != "" || msg.Category != "" {
+		t.Fatal("shouldn't have included any contents", msg.ToJson())
+	}
+
+	if full, err := GetPushNotificationForPost(post.Id, th.BasicUser2.Id); err != nil {
+		t.Fatal(err)
+	} else if full.IsIdLoaded || !strings.Contains(full.Message, post.Message) {
+		t.Fatal("should have fetched the full contents", full.ToJson())
+	}
 }
@@ -4,7 +4,10 @@
 package app
 
 import (
+	"net/http"
+
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
 )
 
 func RevokeAccessToken(token string) *model.AppError {
@@ -32,3 +35,156 @@ func RevokeAccessToken(token string) *model.AppError {
 
 	return nil
 }
+
+func CreateOAuthApp(oauthApp *model.OAuthApp) (*model.OAuthApp, *model.AppError) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		return nil, model.NewAppError("CreateOAuthApp", "api.oauth.register_oauth_app.turn_off.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if err := ValidateBotForIntegration(oauthApp.BotUserId, oauthApp.CreatorId); err != nil {
+		return nil, err
+	}
+
+	oauthApp.ClientSecret = model.NewId()
+
+	if result := <-Srv.Store.OAuth().SaveApp(oauthApp); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.OAuthApp), nil
+	}
+}
+
+func GetOAuthApp(appId string) (*model.OAuthApp, *model.AppError) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		return nil, model.NewAppError("GetOAuthApp", "api.oauth.allow_oauth.turn_off.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.OAuth().GetApp(appId); result.Err != nil {
+		result.Err.StatusCode = http.StatusNotFound
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.OAuthApp), nil
+	}
+}
+
+func GetOAuthApps() ([]*model.OAuthApp, *model.AppError) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		return nil, model.NewAppError("GetOAuthApps", "api.oauth.allow_oauth.turn_off.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.OAuth().GetApps(); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.OAuthApp), nil
+	}
+}
+
+func GetOAuthAppsByCreator(userId string) ([]*model.OAuthApp, *model.AppError) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		return nil, model.NewAppError("GetOAuthAppsByCreator", "api.oauth.allow_oauth.turn_off.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.OAuth().GetAppByUser(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.OAuthApp), nil
+	}
+}
+
+// UpdateOAuthApp applies the editable fields of updatedApp to oldApp. The client id, secret and creator can't be
+// changed this way.
+func UpdateOAuthApp(oldApp, updatedApp *model.OAuthApp) (*model.OAuthApp, *model.AppError) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		return nil, model.NewAppError("UpdateOAuthApp", "api.oauth.allow_oauth.turn_off.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if updatedApp.BotUserId != oldApp.BotUserId {
+		if err := ValidateBotForIntegration(updatedApp.BotUserId, oldApp.CreatorId); err != nil {
+			return nil, err
+		}
+	}
+
+	updatedApp.Id = oldApp.Id
+	updatedApp.CreatorId = oldApp.CreatorId
+	updatedApp.CreateAt = oldApp.CreateAt
+	updatedApp.ClientSecret = oldApp.ClientSecret
+
+	if result := <-Srv.Store.OAuth().UpdateApp(updatedApp); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([2]*model.OAuthApp)[0], nil
+	}
+}
+
+func DeleteOAuthApp(appId string) *model.AppError {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		return model.NewAppError("DeleteOAuthApp", "api.oauth.allow_oauth.turn_off.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if err := (<-Srv.Store.OAuth().DeleteApp(appId)).Err; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func RegenerateOAuthAppSecret(oauthApp *model.OAuthApp) (*model.OAuthApp, *model.AppError) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		return nil, model.NewAppError("RegenerateOAuthAppSecret", "api.oauth.allow_oauth.turn_off.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	oauthApp.ClientSecret = model.NewId()
+	if result := <-Srv.Store.OAuth().UpdateApp(oauthApp); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([2]*model.OAuthApp)[0], nil
+	}
+}
+
+// GetAuthorizedAppsForUser returns the sanitized OAuth apps that the user has allowed to access their account.
+func GetAuthorizedAppsForUser(userId string) ([]*model.OAuthApp, *model.AppError) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		return nil, model.NewAppError("GetAuthorizedAppsForUser", "api.oauth.allow_oauth.turn_off.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.OAuth().GetAuthorizedApps(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		apps := result.Data.([]*model.OAuthApp)
+		for _, oauthApp := range apps {
+			oauthApp.Sanitize()
+		}
+
+		return apps, nil
+	}
+}
+
+// DeauthorizeOAuthAppForUser revokes every access token that the user gave to the app and removes the app from the
+// user's authorized apps.
+func DeauthorizeOAuthAppForUser(userId, appId string) *model.AppError {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		return model.NewAppError("DeauthorizeOAuthAppForUser", "api.oauth.allow_oauth.turn_off.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.OAuth().GetAccessDataByUserForApp(userId, appId); result.Err != nil {
+		return result.Err
+	} else {
+		accessData := result.Data.([]*model.AccessData)
+
+		for _, a := range accessData {
+			if err := RevokeAccessToken(a.Token); err != nil {
+				return err
+			}
+
+			if rad := <-Srv.Store.OAuth().RemoveAccessData(a.Token); rad.Err != nil {
+				return rad.Err
+			}
+		}
+	}
+
+	if result := <-Srv.Store.Preference().Delete(userId, model.PREFERENCE_CATEGORY_AUTHORIZED_OAUTH_APP, appId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
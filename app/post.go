@@ -41,6 +41,10 @@ func CreatePostAsUser(post *model.Post) (*model.Post, *model.AppError) {
 		return nil, err
 	}
 
+	// only integrations can add actions that send requests when they're clicked
+	post.StripActionIntegrations()
+	post.GenerateActionIds()
+
 	if rp, err := CreatePost(post, channel.TeamId, true); err != nil {
 		if err.Id == "api.post.create_post.root_id.app_error" ||
 			err.Id == "api.post.create_post.channel_root_id.app_error" ||
@@ -180,40 +184,23 @@ func handlePostEvents(post *model.Post, teamId string, triggerWebhooks bool) *mo
 
 // This method only parses and processes the attachments,
 // all else should be set in the post which is passed
-func parseSlackAttachment(post *model.Post, attachments interface{}) {
+func parseSlackAttachment(post *model.Post, attachments []*model.SlackAttachment) {
 	post.Type = model.POST_SLACK_ATTACHMENT
 
-	if list, success := attachments.([]interface{}); success {
-		for i, aInt := range list {
-			attachment := aInt.(map[string]interface{})
-			if aText, ok := attachment["text"].(string); ok {
-				aText = linkWithTextRegex.ReplaceAllString(aText, "[${2}](${1})")
-				attachment["text"] = aText
-				list[i] = attachment
-			}
-			if aText, ok := attachment["pretext"].(string); ok {
-				aText = linkWithTextRegex.ReplaceAllString(aText, "[${2}](${1})")
-				attachment["pretext"] = aText
-				list[i] = attachment
-			}
-			if fVal, ok := attachment["fields"]; ok {
-				if fields, ok := fVal.([]interface{}); ok {
-					// parse attachment field links into Markdown format
-					for j, fInt := range fields {
-						field := fInt.(map[string]interface{})
-						if fValue, ok := field["value"].(string); ok {
-							fValue = linkWithTextRegex.ReplaceAllString(fValue, "[${2}](${1})")
-							field["value"] = fValue
-							fields[j] = field
-						}
-					}
-					attachment["fields"] = fields
-					list[i] = attachment
-				}
+	for _, attachment := range attachments {
+		attachment.Text = parseSlackLinksToMarkdown(attachment.Text)
+		attachment.Pretext = parseSlackLinksToMarkdown(attachment.Pretext)
+
+		// parse attachment field links into Markdown format
+		for _, field := range attachment.Fields {
+			if value, ok := field.Value.(string); ok {
+				field.Value = parseSlackLinksToMarkdown(value)
 			}
 		}
-		post.AddProp("attachments", list)
 	}
+
+	post.AddProp("attachments", attachments)
+	post.GenerateActionIds()
 }
 
 func parseSlackLinksToMarkdown(text string) string {
@@ -242,8 +229,11 @@ func SendEphemeralPost(teamId, userId string, post *model.Post) *model.Post {
 	return post
 }
 
-func UpdatePost(post *model.Post) (*model.Post, *model.AppError) {
-	if utils.IsLicensed {
+// UpdatePost edits the message of a post. Updates made by users are safe updates, which are subject to the
+// AllowEditPost restrictions and can only change the message. Updates sent by integrations in response to an
+// interactive message action can also replace the post's props.
+func UpdatePost(post *model.Post, safeUpdate bool) (*model.Post, *model.AppError) {
+	if utils.IsLicensed && safeUpdate {
 		if *utils.Cfg.ServiceSettings.AllowEditPost == model.ALLOW_EDIT_POST_NEVER {
 			err := model.NewLocAppError("updatePost", "api.post.update_post.permissions_denied.app_error", nil, "")
 			err.StatusCode = http.StatusForbidden
@@ -281,7 +271,14 @@ func UpdatePost(post *model.Post) (*model.Post, *model.AppError) {
 			return nil, err
 		}
 
-		if utils.IsLicensed {
+		// only posts made by integrations can have their props replaced, since the props can contain actions
+		if !safeUpdate && oldPost.Props["from_webhook"] != "true" {
+			err := model.NewLocAppError("updatePost", "api.post.update_post.integration.app_error", nil, "id="+post.Id)
+			err.StatusCode = http.StatusBadRequest
+			return nil, err
+		}
+
+		if utils.IsLicensed && safeUpdate {
 			if *utils.Cfg.ServiceSettings.AllowEditPost == model.ALLOW_EDIT_POST_TIME_LIMIT && model.GetMillis() > oldPost.CreateAt+int64(*utils.Cfg.ServiceSettings.PostEditTimeLimit*1000) {
 				err := model.NewLocAppError("updatePost", "api.post.update_post.permissions_time_limit.app_error", map[string]interface{}{"timeLimit": *utils.Cfg.ServiceSettings.PostEditTimeLimit}, "")
 				err.StatusCode = http.StatusBadRequest
@@ -297,6 +294,11 @@ func UpdatePost(post *model.Post) (*model.Post, *model.AppError) {
 	newPost.EditAt = model.GetMillis()
 	newPost.Hashtags, _ = model.ParseHashtags(post.Message)
 
+	if !safeUpdate {
+		newPost.Props = post.Props
+		newPost.GenerateActionIds()
+	}
+
 	if result := <-Srv.Store.Post().Update(newPost, oldPost); result.Err != nil {
 		return nil, result.Err
 	} else {
@@ -306,6 +308,7 @@ func UpdatePost(post *model.Post) (*model.Post, *model.AppError) {
 		message.Add("post", rpost.ToJson())
 
 		go Publish(message)
+		go handleWebhookEvent(model.OUTGOING_WEBHOOK_EVENT_POST_EDITED, rpost.ChannelId, rpost.UserId, rpost, "")
 
 		InvalidateCacheForChannelPosts(rpost.ChannelId)
 
@@ -422,6 +425,7 @@ func DeletePost(postId string) (*model.Post, *model.AppError) {
 		go Publish(message)
 		go DeletePostFiles(post)
 		go DeleteFlaggedPosts(post.Id)
+		go handleWebhookEvent(model.OUTGOING_WEBHOOK_EVENT_POST_DELETED, post.ChannelId, post.UserId, post, "")
 
 		InvalidateCacheForChannelPosts(post.ChannelId)
 
@@ -0,0 +1,135 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const POST_ACTION_TIMEOUT = 10 * time.Second
+
+// DoPostAction sends the context of an interactive message action that a user clicked to the action's integration,
+// then applies the integration's response by updating the post and/or showing the user an ephemeral message.
+func DoPostAction(postId, actionId, userId, selectedOption string) *model.AppError {
+	post, err := GetSinglePost(postId)
+	if err != nil {
+		err.StatusCode = http.StatusNotFound
+		return err
+	}
+
+	// actions only run for posts made by integrations, since users can't be trusted to choose where the request goes
+	action := post.GetAction(actionId)
+	if action == nil || action.Integration == nil || post.Props["from_webhook"] != "true" {
+		return model.NewAppError("DoPostAction", "api.post.do_action.action_id.app_error", nil, "action_id="+actionId, http.StatusNotFound)
+	}
+
+	if action.Type == model.SLACK_ATTACHMENT_ACTION_TYPE_SELECT && !action.HasOption(selectedOption) {
+		return model.NewAppError("DoPostAction", "api.post.do_action.selected_option.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	channel, err := GetChannel(post.ChannelId)
+	if err != nil {
+		return err
+	}
+
+	request := &model.PostActionIntegrationRequest{
+		UserId:    userId,
+		ChannelId: post.ChannelId,
+		TeamId:    channel.TeamId,
+		PostId:    postId,
+		Context:   model.StringInterface{},
+	}
+
+	for key, value := range action.Integration.Context {
+		request.Context[key] = value
+	}
+
+	if selectedOption != "" {
+		request.Context["selected_option"] = selectedOption
+	}
+
+	req, _ := http.NewRequest("POST", action.Integration.URL, strings.NewReader(request.ToJson()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+	}
+	client := &http.Client{Transport: tr, Timeout: POST_ACTION_TIMEOUT}
+
+	resp, httpErr := client.Do(req)
+	if httpErr != nil {
+		return model.NewAppError("DoPostAction", "api.post.do_action.action_integration.app_error", nil, httpErr.Error(), http.StatusBadRequest)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return model.NewAppError("DoPostAction", "api.post.do_action.action_integration.app_error", nil, "status="+resp.Status, http.StatusBadRequest)
+	}
+
+	// an empty response means that the integration doesn't want to change anything
+	var response model.PostActionIntegrationResponse
+	if jsonErr := json.NewDecoder(resp.Body).Decode(&response); jsonErr != nil && jsonErr != io.EOF {
+		return model.NewAppError("DoPostAction", "api.post.do_action.parse_response.app_error", nil, jsonErr.Error(), http.StatusBadRequest)
+	}
+
+	if response.Update != nil {
+		update := response.Update
+		update.Id = postId
+		update.UserId = post.UserId
+		update.MakeNonNil()
+
+		for _, key := range []string{"override_username", "override_icon_url"} {
+			if value, ok := post.Props[key]; ok {
+				if _, ok := update.Props[key]; !ok {
+					update.AddProp(key, value)
+				}
+			}
+		}
+		update.AddProp("from_webhook", "true")
+
+		if _, ok := update.Props["attachments"]; ok {
+			attachments := update.Attachments()
+			if attachments == nil {
+				return model.NewAppError("DoPostAction", "api.post.do_action.parse_response.app_error", nil, "attachments", http.StatusBadRequest)
+			}
+
+			if err := model.ValidateSlackAttachments(attachments); err != nil {
+				return err
+			}
+
+			parseSlackAttachment(update, attachments)
+		}
+
+		if _, err := UpdatePost(update, false); err != nil {
+			return err
+		}
+	}
+
+	if response.EphemeralText != "" {
+		ephemeralPost := &model.Post{
+			ChannelId: post.ChannelId,
+			RootId:    post.RootId,
+			UserId:    post.UserId,
+			Message:   parseSlackLinksToMarkdown(response.EphemeralText),
+		}
+
+		if ephemeralPost.RootId == "" {
+			ephemeralPost.RootId = post.Id
+		}
+
+		ephemeralPost.AddProp("from_webhook", "true")
+		SendEphemeralPost(channel.TeamId, userId, ephemeralPost)
+	}
+
+	return nil
+}
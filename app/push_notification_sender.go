@@ -0,0 +1,237 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	PUSH_NOTIFICATION_QUEUE_SIZE         = 10000
+	PUSH_NOTIFICATION_WORKERS            = 50
+	PUSH_NOTIFICATION_TIMEOUT            = 30 * time.Second
+	PUSH_NOTIFICATION_MAX_ATTEMPTS       = 3
+	PUSH_NOTIFICATION_RETRY_INTERVAL     = time.Second // doubled after each failed attempt
+	PUSH_NOTIFICATION_RESPONSE_MAX_BYTES = 1024 * 1024
+
+	PUSH_NOTIFICATION_OUTCOME_SUCCESS  = "success"
+	PUSH_NOTIFICATION_OUTCOME_REMOVED  = "removed"
+	PUSH_NOTIFICATION_OUTCOME_FAILED   = "failed"
+	PUSH_NOTIFICATION_OUTCOME_REJECTED = "rejected"
+	PUSH_NOTIFICATION_OUTCOME_RETRIED  = "retried"
+	PUSH_NOTIFICATION_OUTCOME_DROPPED  = "dropped"
+)
+
+// PushNotificationSender delivers push notifications to devices. An error means that a notification couldn't be
+// delivered and should be tried again, unless it's a *PushNotificationRejectedError, while a response with a fail or
+// remove status is final.
+type PushNotificationSender interface {
+	SendPushNotification(msg *model.PushNotification) (model.PushResponse, error)
+}
+
+// PushNotificationRejectedError is returned when a push notification was refused in a way that sending it again
+// won't fix, such as by the push proxy responding with a 4xx status.
+type PushNotificationRejectedError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *PushNotificationRejectedError) Error() string {
+	return fmt.Sprintf("push notification rejected: %v", e.Status)
+}
+
+// proxyPushNotificationSender sends push notifications through the push proxy set in the config.
+type proxyPushNotificationSender struct {
+	url string
+}
+
+// pushNotificationHttpClients are shared by every send so that connections to the push proxy are reused. They're keyed
+// by whether insecure outgoing connections are allowed since that can be changed in the config.
+var pushNotificationHttpClients = map[bool]*http.Client{
+	false: newPushNotificationHttpClient(false),
+	true:  newPushNotificationHttpClient(true),
+}
+
+func newPushNotificationHttpClient(insecure bool) *http.Client {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
+	}
+
+	return &http.Client{Transport: tr, Timeout: PUSH_NOTIFICATION_TIMEOUT}
+}
+
+func (s *proxyPushNotificationSender) SendPushNotification(msg *model.PushNotification) (model.PushResponse, error) {
+	httpClient := pushNotificationHttpClients[*utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections]
+
+	request, err := http.NewRequest("POST", s.url+model.API_URL_SUFFIX_V1+"/send_push", strings.NewReader(msg.ToJson()))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// only a bounded amount is drained so that a misbehaving proxy can't send an endless response
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, PUSH_NOTIFICATION_RESPONSE_MAX_BYTES))
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, errors.New(resp.Status)
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &PushNotificationRejectedError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	return model.PushResponseFromJson(io.LimitReader(resp.Body, PUSH_NOTIFICATION_RESPONSE_MAX_BYTES)), nil
+}
+
+// filePushNotificationSender appends push notifications to a file, one JSON object per line, instead of sending
+// them so that their contents can be checked without a push proxy.
+type filePushNotificationSender struct {
+	path string
+}
+
+var filePushNotificationLock sync.Mutex
+
+func (s *filePushNotificationSender) SendPushNotification(msg *model.PushNotification) (model.PushResponse, error) {
+	filePushNotificationLock.Lock()
+	defer filePushNotificationLock.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(msg.ToJson() + "\n"); err != nil {
+		return nil, err
+	}
+
+	return model.NewOkPushResponse(), nil
+}
+
+var registeredPushNotificationSender PushNotificationSender
+
+// RegisterPushNotificationSender makes every push notification go through the given sender instead of the one for
+// the transport set in the config, or goes back to using the config if it's nil.
+func RegisterPushNotificationSender(sender PushNotificationSender) {
+	registeredPushNotificationSender = sender
+}
+
+// GetPushNotificationSender returns the registered sender if there is one, or otherwise the sender for the transport
+// set in the config.
+func GetPushNotificationSender() PushNotificationSender {
+	if registeredPushNotificationSender != nil {
+		return registeredPushNotificationSender
+	}
+
+	if *utils.Cfg.EmailSettings.PushNotificationTransport == model.PUSH_NOTIFICATION_TRANSPORT_FILE {
+		return &filePushNotificationSender{path: *utils.Cfg.EmailSettings.PushNotificationFile}
+	}
+
+	return &proxyPushNotificationSender{url: *utils.Cfg.EmailSettings.PushNotificationServer}
+}
+
+type pushNotificationTask struct {
+	msg      model.PushNotification
+	session  *model.Session
+	attempts int
+}
+
+var pushNotificationQueue chan *pushNotificationTask
+var startPushNotificationWorkersOnce sync.Once
+
+func startPushNotificationWorkers() {
+	pushNotificationQueue = make(chan *pushNotificationTask, PUSH_NOTIFICATION_QUEUE_SIZE)
+
+	for i := 0; i < PUSH_NOTIFICATION_WORKERS; i++ {
+		go func() {
+			for task := range pushNotificationQueue {
+				deliverPushNotification(task)
+			}
+		}()
+	}
+}
+
+// queuePushNotification queues a push notification to be sent to one of a user's sessions. If the queue is full,
+// the notification is dropped instead of blocking the caller.
+func queuePushNotification(msg model.PushNotification, session *model.Session) {
+	startPushNotificationWorkersOnce.Do(startPushNotificationWorkers)
+
+	msg.ServerId = utils.CfgDiagnosticId
+
+	select {
+	case pushNotificationQueue <- &pushNotificationTask{msg: msg, session: session}:
+	default:
+		l4g.Error("Device push dropped because the queue is full for UserId=%v SessionId=%v", session.UserId, session.Id)
+		incrementPushNotificationMetric(PUSH_NOTIFICATION_OUTCOME_DROPPED)
+	}
+}
+
+func deliverPushNotification(task *pushNotificationTask) {
+	task.attempts++
+
+	session := task.session
+
+	pushResponse, err := GetPushNotificationSender().SendPushNotification(&task.msg)
+	if _, ok := err.(*PushNotificationRejectedError); ok {
+		l4g.Error("Device push rejected for UserId=%v SessionId=%v message=%v", session.UserId, session.Id, err.Error())
+		incrementPushNotificationMetric(PUSH_NOTIFICATION_OUTCOME_REJECTED)
+		return
+	} else if err != nil {
+		if task.attempts < PUSH_NOTIFICATION_MAX_ATTEMPTS {
+			l4g.Warn("Device push failed and will be retried for UserId=%v SessionId=%v message=%v", session.UserId, session.Id, err.Error())
+			incrementPushNotificationMetric(PUSH_NOTIFICATION_OUTCOME_RETRIED)
+
+			time.AfterFunc(PUSH_NOTIFICATION_RETRY_INTERVAL<<uint(task.attempts-1), func() {
+				select {
+				case pushNotificationQueue <- task:
+				default:
+					l4g.Error("Device push dropped because the queue is full for UserId=%v SessionId=%v", session.UserId, session.Id)
+					incrementPushNotificationMetric(PUSH_NOTIFICATION_OUTCOME_DROPPED)
+				}
+			})
+		} else {
+			l4g.Error("Device push reported as error for UserId=%v SessionId=%v message=%v", session.UserId, session.Id, err.Error())
+			incrementPushNotificationMetric(PUSH_NOTIFICATION_OUTCOME_FAILED)
+		}
+
+		return
+	}
+
+	switch pushResponse[model.PUSH_STATUS] {
+	case model.PUSH_STATUS_REMOVE:
+		l4g.Info("Device was reported as removed for UserId=%v SessionId=%v removing push for this session", session.UserId, session.Id)
+		AttachDeviceId(session.Id, "", session.ExpiresAt)
+		ClearSessionCacheForUser(session.UserId)
+		incrementPushNotificationMetric(PUSH_NOTIFICATION_OUTCOME_REMOVED)
+	case model.PUSH_STATUS_FAIL:
+		l4g.Error("Device push reported as error for UserId=%v SessionId=%v message=%v", session.UserId, session.Id, pushResponse[model.PUSH_STATUS_ERROR_MSG])
+		incrementPushNotificationMetric(PUSH_NOTIFICATION_OUTCOME_FAILED)
+	default:
+		incrementPushNotificationMetric(PUSH_NOTIFICATION_OUTCOME_SUCCESS)
+	}
+}
+
+func incrementPushNotificationMetric(outcome string) {
+	if einterfaces.GetMetricsInterface() != nil {
+		einterfaces.GetMetricsInterface().IncrementPushNotification(outcome)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestFilePushNotificationSender(t *testing.T) {
+	th := Setup().InitBasic()
+
+	f, err := ioutil.TempFile("", "push_notifications")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	transport := *utils.Cfg.EmailSettings.PushNotificationTransport
+	file := *utils.Cfg.EmailSettings.PushNotificationFile
+	contents := *utils.Cfg.EmailSettings.PushNotificationContents
+	defer func() {
+		*utils.Cfg.EmailSettings.PushNotificationTransport = transport
+		*utils.Cfg.EmailSettings.PushNotificationFile = file
+		*utils.Cfg.EmailSettings.PushNotificationContents = contents
+	}()
+	*utils.Cfg.EmailSettings.PushNotificationTransport = model.PUSH_NOTIFICATION_TRANSPORT_FILE
+	*utils.Cfg.EmailSettings.PushNotificationFile = f.Name()
+	*utils.Cfg.EmailSettings.PushNotificationContents = model.FULL_NOTIFICATION
+
+	if _, err := CreateSession(&model.Session{UserId: th.BasicUser2.Id, DeviceId: model.PUSH_NOTIFY_ANDROID + ":" + model.NewId(), ExpiresAt: model.GetMillis() + 100000}); err != nil {
+		t.Fatal(err)
+	}
+
+	post := th.CreatePost(th.BasicChannel)
+	if err := sendPushNotification(post, th.BasicUser2, th.BasicChannel, th.BasicUser.Username, th.BasicChannel.DisplayName, true); err != nil {
+		t.Fatal(err)
+	}
+
+	var msg *model.PushNotification
+	for i := 0; i < 50 && msg == nil; i++ {
+		time.Sleep(100 * time.Millisecond)
+
+		if b, err := ioutil.ReadFile(f.Name()); err != nil {
+			t.Fatal(err)
+		} else if len(b) > 0 {
+			msg = model.PushNotificationFromJson(strings.NewReader(strings.Split(string(b), "\n")[0]))
+		}
+	}
+
+	if msg == nil {
+		t.Fatal("should have written the push notification")
+	} else if msg.ChannelId != th.BasicChannel.Id || msg.Platform != model.PUSH_NOTIFY_ANDROID || !strings.Contains(msg.Message, post.Message) {
+		t.Fatal("wrote the wrong push notification", msg.ToJson())
+	}
+}
+
+func TestProxyPushNotificationSenderRetry(t *testing.T) {
+	Setup()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the proxy is unavailable for the first attempt
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		resp := model.NewOkPushResponse()
+		w.Write([]byte(resp.ToJson()))
+	}))
+	defer server.Close()
+
+	sender := &proxyPushNotificationSender{url: server.URL}
+
+	if _, err := sender.SendPushNotification(&model.PushNotification{}); err == nil {
+		t.Fatal("should have returned an error to be retried")
+	}
+
+	if resp, err := sender.SendPushNotification(&model.PushNotification{}); err != nil {
+		t.Fatal(err)
+	} else if resp[model.PUSH_STATUS] != model.PUSH_STATUS_OK {
+		t.Fatal("should have succeeded")
+	}
+
+	transport := *utils.Cfg.EmailSettings.PushNotificationTransport
+	pushServer := *utils.Cfg.EmailSettings.PushNotificationServer
+	defer func() {
+		*utils.Cfg.EmailSettings.PushNotificationTransport = transport
+		*utils.Cfg.EmailSettings.PushNotificationServer = pushServer
+	}()
+	*utils.Cfg.EmailSettings.PushNotificationTransport = model.PUSH_NOTIFICATION_TRANSPORT_PROXY
+	*utils.Cfg.EmailSettings.PushNotificationServer = server.URL
+
+	atomic.StoreInt32(&requests, 0)
+	queuePushNotification(model.PushNotification{}, &model.Session{Id: model.NewId(), UserId: model.NewId()})
+
+	for i := 0; i < 50 && atomic.LoadInt32(&requests) < 2; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Fatal("should have retried the push notification")
+	}
+}
+
+func TestProxyPushNotificationSenderRejected(t *testing.T) {
+	Setup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	sender := &proxyPushNotificationSender{url: server.URL}
+
+	if _, err := sender.SendPushNotification(&model.PushNotification{}); err == nil {
+		t.Fatal("should have returned an error")
+	} else if rejectedErr, ok := err.(*PushNotificationRejectedError); !ok || rejectedErr.StatusCode != http.StatusNotFound {
+		t.Fatal("should have been rejected", err)
+	}
+}
+
+type testPushNotificationSender struct {
+	requests int32
+	err      error
+}
+
+func (s *testPushNotificationSender) SendPushNotification(msg *model.PushNotification) (model.PushResponse, error) {
+	atomic.AddInt32(&s.requests, 1)
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return model.NewOkPushResponse(), nil
+}
+
+func TestRegisterPushNotificationSender(t *testing.T) {
+	Setup()
+
+	sender := &testPushNotificationSender{}
+	RegisterPushNotificationSender(sender)
+	defer RegisterPushNotificationSender(nil)
+
+	if GetPushNotificationSender() != sender {
+		t.Fatal("should have returned the registered sender")
+	}
+
+	queuePushNotification(model.PushNotification{}, &model.Session{Id: model.NewId(), UserId: model.NewId()})
+
+	for i := 0; i < 50 && atomic.LoadInt32(&sender.requests) < 1; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if atomic.LoadInt32(&sender.requests) != 1 {
+		t.Fatal("should have sent the push notification through the registered sender")
+	}
+
+	// rejected push notifications aren't retried
+	rejectingSender := &testPushNotificationSender{err: &PushNotificationRejectedError{StatusCode: http.StatusBadRequest, Status: "400 Bad Request"}}
+	RegisterPushNotificationSender(rejectingSender)
+
+	queuePushNotification(model.PushNotification{}, &model.Session{Id: model.NewId(), UserId: model.NewId()})
+
+	time.Sleep(PUSH_NOTIFICATION_RETRY_INTERVAL + time.Second)
+
+	if atomic.LoadInt32(&rejectingSender.requests) != 1 {
+		t.Fatal("shouldn't have retried the rejected push notification")
+	}
+
+	RegisterPushNotificationSender(nil)
+
+	if _, ok := GetPushNotificationSender().(*testPushNotificationSender); ok {
+		t.Fatal("should have gone back to the sender from the config")
+	}
+}
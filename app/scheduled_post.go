@@ -0,0 +1,161 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/jobs"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	SCHEDULED_POSTS_BATCH_SIZE   = 100
+	SCHEDULED_POSTS_JOB_INTERVAL = time.Minute
+	SCHEDULED_POSTS_RETRY_DELAY  = 5 * time.Minute
+)
+
+func MakeScheduledPostsWorker() jobs.Worker {
+	return jobs.NewSimpleWorker("ScheduledPosts", func(job *model.Job, cancel <-chan interface{}) *model.AppError {
+		return SendDueScheduledPosts()
+	})
+}
+
+func MakeScheduledPostsScheduler() jobs.Scheduler {
+	return jobs.NewPeriodicScheduler(model.JOB_TYPE_SCHEDULED_POSTS, SCHEDULED_POSTS_JOB_INTERVAL, func() bool {
+		return true
+	})
+}
+
+func CreateScheduledPost(scheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError) {
+	if scheduledPost.ScheduledAt <= model.GetMillis() {
+		return nil, model.NewAppError("CreateScheduledPost", "app.scheduled_post.scheduled_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if result := <-Srv.Store.ScheduledPost().Save(scheduledPost); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ScheduledPost), nil
+	}
+}
+
+func GetScheduledPost(scheduledPostId string) (*model.ScheduledPost, *model.AppError) {
+	if result := <-Srv.Store.ScheduledPost().Get(scheduledPostId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ScheduledPost), nil
+	}
+}
+
+func GetScheduledPostsForUserPage(userId string, page, perPage int) ([]*model.ScheduledPost, *model.AppError) {
+	if result := <-Srv.Store.ScheduledPost().GetForUser(userId, page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.ScheduledPost), nil
+	}
+}
+
+// UpdateScheduledPost changes the message and schedule of a post that hasn't been sent yet. The user, channel and
+// thread that it will be posted to can't be changed.
+func UpdateScheduledPost(oldScheduledPost, updatedScheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError) {
+	if updatedScheduledPost.ScheduledAt != oldScheduledPost.ScheduledAt && updatedScheduledPost.ScheduledAt <= model.GetMillis() {
+		return nil, model.NewAppError("UpdateScheduledPost", "app.scheduled_post.scheduled_at.app_error", nil, "id="+oldScheduledPost.Id, http.StatusBadRequest)
+	}
+
+	scheduledPost := *oldScheduledPost
+	scheduledPost.Message = updatedScheduledPost.Message
+	scheduledPost.ScheduledAt = updatedScheduledPost.ScheduledAt
+	scheduledPost.Recurrence = updatedScheduledPost.Recurrence
+	if updatedScheduledPost.Timezone != "" {
+		scheduledPost.Timezone = updatedScheduledPost.Timezone
+	}
+
+	if scheduledPost.ScheduledAt != oldScheduledPost.ScheduledAt || scheduledPost.Recurrence != oldScheduledPost.Recurrence || scheduledPost.Timezone != oldScheduledPost.Timezone {
+		// a monthly post moves to the day of the month that it's now scheduled for
+		scheduledPost.DayOfMonth = 0
+	}
+
+	if result := <-Srv.Store.ScheduledPost().Update(&scheduledPost); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ScheduledPost), nil
+	}
+}
+
+func CancelScheduledPost(scheduledPostId string) *model.AppError {
+	if result := <-Srv.Store.ScheduledPost().Delete(scheduledPostId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// SendDueScheduledPosts posts the messages that are due. It's run by the scheduled posts job, but each post is still
+// claimed before it's sent so that a job that overlaps with another, such as one started after its server was thought
+// to have gone away, can't send it twice. A post that's only sent once is pushed back when it's claimed and only
+// removed after it's been sent, so that it's retried if sending it fails for a reason that may go away.
+func SendDueScheduledPosts() *model.AppError {
+	now := model.GetMillis()
+
+	var scheduledPosts []*model.ScheduledPost
+	if result := <-Srv.Store.ScheduledPost().GetDue(now, SCHEDULED_POSTS_BATCH_SIZE); result.Err != nil {
+		return result.Err
+	} else {
+		scheduledPosts = result.Data.([]*model.ScheduledPost)
+	}
+
+	for _, scheduledPost := range scheduledPosts {
+		nextScheduledAt := scheduledPost.NextScheduledAt(now)
+		if nextScheduledAt == 0 {
+			nextScheduledAt = now + int64(SCHEDULED_POSTS_RETRY_DELAY/time.Millisecond)
+		}
+
+		if result := <-Srv.Store.ScheduledPost().Claim(scheduledPost, nextScheduledAt); result.Err != nil {
+			l4g.Error(result.Err.Error())
+			continue
+		} else if !result.Data.(bool) {
+			// it was sent by another job or changed since it was loaded
+			continue
+		}
+
+		_, err := sendScheduledPost(scheduledPost)
+		if err != nil {
+			l4g.Error(utils.T("app.scheduled_post.send.error"), scheduledPost.Id, err.Error())
+		}
+
+		if scheduledPost.Recurrence == model.SCHEDULED_POST_RECURRENCE_NONE && (err == nil || err.StatusCode < http.StatusInternalServerError) {
+			if err := CancelScheduledPost(scheduledPost.Id); err != nil {
+				l4g.Error(err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// sendScheduledPost creates a scheduled post in its channel, checking that its user is still allowed to post there.
+// Recurring posts whose users have lost access are cancelled.
+func sendScheduledPost(scheduledPost *model.ScheduledPost) (*model.Post, *model.AppError) {
+	if user, err := GetUser(scheduledPost.UserId); err != nil {
+		return nil, err
+	} else if user.DeleteAt != 0 || !HasPermissionToChannel(scheduledPost.UserId, scheduledPost.ChannelId, model.PERMISSION_CREATE_POST) {
+		if scheduledPost.Recurrence != model.SCHEDULED_POST_RECURRENCE_NONE {
+			CancelScheduledPost(scheduledPost.Id)
+		}
+
+		return nil, model.NewAppError("sendScheduledPost", "app.scheduled_post.send.permissions.app_error", nil, "id="+scheduledPost.Id, http.StatusForbidden)
+	}
+
+	post := &model.Post{
+		UserId:    scheduledPost.UserId,
+		ChannelId: scheduledPost.ChannelId,
+		RootId:    scheduledPost.RootId,
+		Message:   scheduledPost.Message,
+	}
+
+	return CreatePostAsUser(post)
+}
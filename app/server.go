@@ -13,6 +13,7 @@ import (
 	l4g "github.com/alecthomas/log4go"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/mattermost/platform/jobs"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
@@ -79,6 +80,7 @@ func NewServer() {
 
 func InitStores() {
 	Srv.Store = store.NewSqlStore()
+	jobs.Srv.Store = Srv.Store
 }
 
 type VaryBy struct{}
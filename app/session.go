@@ -43,6 +43,12 @@ func GetSession(token string) (*model.Session, *model.AppError) {
 
 	if session == nil {
 		if sessionResult := <-Srv.Store.Session().Get(token); sessionResult.Err != nil {
+			if len(token) == 26 {
+				if accessTokenSession, err := createSessionForUserAccessToken(token); err == nil {
+					return accessTokenSession, nil
+				}
+			}
+
 			return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token, "Error": sessionResult.Err.DetailedError}, "")
 		} else {
 			session = sessionResult.Data.(*model.Session)
@@ -51,7 +57,6 @@ func GetSession(token string) (*model.Session, *model.AppError) {
 				return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token, "Error": sessionResult.Err.DetailedError}, "")
 			} else {
 				AddSessionToCache(session)
-				return session, nil
 			}
 		}
 	}
@@ -60,6 +65,10 @@ func GetSession(token string) (*model.Session, *model.AppError) {
 		return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token}, "")
 	}
 
+	if session.IsUserAccessToken() && !*utils.Cfg.ServiceSettings.EnableUserAccessTokens {
+		return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token}, "EnableUserAccessTokens=false")
+	}
+
 	return session, nil
 }
 
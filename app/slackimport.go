@@ -40,17 +40,17 @@ type SlackFile struct {
 }
 
 type SlackPost struct {
-	User        string            `json:"user"`
-	BotId       string            `json:"bot_id"`
-	BotUsername string            `json:"username"`
-	Text        string            `json:"text"`
-	TimeStamp   string            `json:"ts"`
-	Type        string            `json:"type"`
-	SubType     string            `json:"subtype"`
-	Comment     *SlackComment     `json:"comment"`
-	Upload      bool              `json:"upload"`
-	File        *SlackFile        `json:"file"`
-	Attachments []SlackAttachment `json:"attachments"`
+	User        string                   `json:"user"`
+	BotId       string                   `json:"bot_id"`
+	BotUsername string                   `json:"username"`
+	Text        string                   `json:"text"`
+	TimeStamp   string                   `json:"ts"`
+	Type        string                   `json:"type"`
+	SubType     string                   `json:"subtype"`
+	Comment     *SlackComment            `json:"comment"`
+	Upload      bool                     `json:"upload"`
+	File        *SlackFile               `json:"file"`
+	Attachments []*model.SlackAttachment `json:"attachments"`
 }
 
 type SlackComment struct {
@@ -58,13 +58,6 @@ type SlackComment struct {
 	Comment string `json:"comment"`
 }
 
-type SlackAttachment struct {
-	Id      int                      `json:"id"`
-	Text    string                   `json:"text"`
-	Pretext string                   `json:"pretext"`
-	Fields  []map[string]interface{} `json:"fields"`
-}
-
 func truncateRunes(s string, i int) string {
 	runes := []rune(s)
 	if len(runes) > i {
@@ -284,15 +277,11 @@ func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, use
 			props := make(model.StringInterface)
 			props["override_username"] = sPost.BotUsername
 			if len(sPost.Attachments) > 0 {
-				var mAttachments []interface{}
-				for _, attachment := range sPost.Attachments {
-					mAttachments = append(mAttachments, map[string]interface{}{
-						"text":    attachment.Text,
-						"pretext": attachment.Pretext,
-						"fields":  attachment.Fields,
-					})
+				if err := model.ValidateSlackAttachments(sPost.Attachments); err != nil {
+					l4g.Warn(utils.T("api.slackimport.slack_add_posts.attachments.warn"), err.Error())
+				} else {
+					props["attachments"] = sPost.Attachments
 				}
-				props["attachments"] = mAttachments
 			}
 
 			post := &model.Post{
@@ -4,14 +4,27 @@
 package app
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	l4g "github.com/alecthomas/log4go"
 
 	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/jobs"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
 )
 
+const (
+	DND_STATUSES_JOB_INTERVAL = time.Minute
+
+	// quiet hours are only looked for this far back, so ones that started while every server was down for longer
+	// than this are skipped
+	DND_STATUSES_MAX_CATCH_UP = 12 * time.Hour
+)
+
 var statusCache *utils.Cache = utils.NewLru(model.STATUS_CACHE_SIZE)
 
 func ClearStatusCache() {
@@ -118,6 +131,8 @@ func SetStatusOnline(userId string, sessionId string, manual bool) {
 		status.Status = model.STATUS_ONLINE
 		status.Manual = false // for "online" there's no manual setting
 		status.LastActivityAt = model.GetMillis()
+		status.DNDEndTime = 0
+		status.PrevStatus = ""
 	}
 
 	AddStatusCache(status)
@@ -195,6 +210,8 @@ func SetStatusAwayIfNeeded(userId string, manual bool) {
 	status.Status = model.STATUS_AWAY
 	status.Manual = manual
 	status.ActiveChannel = ""
+	status.DNDEndTime = 0
+	status.PrevStatus = ""
 
 	AddStatusCache(status)
 
@@ -208,6 +225,153 @@ func SetStatusAwayIfNeeded(userId string, manual bool) {
 	go Publish(event)
 }
 
+// SetStatusDoNotDisturb stops push and email notifications from being sent to a user until the given time, or until
+// they change their status if it's 0. Since do not disturb is a manual status, it isn't changed by the user's activity.
+func SetStatusDoNotDisturb(userId string, endTime int64) {
+	status, err := GetStatus(userId)
+	if err != nil {
+		status = &model.Status{UserId: userId, Status: model.STATUS_OFFLINE, Manual: false, LastActivityAt: 0, ActiveChannel: ""}
+	}
+
+	if status.Status != model.STATUS_DND {
+		// only a manually set status is returned to afterwards since the automatic one will be out of date
+		status.PrevStatus = ""
+		if status.Manual {
+			status.PrevStatus = status.Status
+		}
+	}
+
+	status.Status = model.STATUS_DND
+	status.Manual = true
+	status.DNDEndTime = endTime
+
+	saveAndPublishStatus(status)
+}
+
+// endDoNotDisturb returns a user to the status that they set before do not disturb. If they didn't set one, they're
+// offline until they're next active. A do not disturb that the user changed in the meantime is left alone.
+func endDoNotDisturb(status *model.Status) {
+	dndEndTime := status.DNDEndTime
+
+	if len(status.PrevStatus) > 0 {
+		status.Status = status.PrevStatus
+		status.Manual = true
+	} else {
+		status.Status = model.STATUS_OFFLINE
+		status.Manual = false
+	}
+
+	status.DNDEndTime = 0
+	status.PrevStatus = ""
+
+	if result := <-Srv.Store.Status().EndExpiredDND(status.UserId, dndEndTime, status.Status, status.Manual); result.Err != nil {
+		l4g.Error(utils.T("api.status.save_status.error"), status.UserId, result.Err)
+		return
+	} else if !result.Data.(bool) {
+		return
+	}
+
+	AddStatusCache(status)
+	publishStatus(status)
+}
+
+func saveAndPublishStatus(status *model.Status) {
+	AddStatusCache(status)
+
+	if result := <-Srv.Store.Status().SaveOrUpdate(status); result.Err != nil {
+		l4g.Error(utils.T("api.status.save_status.error"), status.UserId, result.Err)
+	}
+
+	publishStatus(status)
+}
+
+func publishStatus(status *model.Status) {
+	event := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_STATUS_CHANGE, "", "", status.UserId, nil)
+	event.Add("status", status.Status)
+	event.Add("user_id", status.UserId)
+	if status.Status == model.STATUS_DND {
+		event.Add("dnd_end_time", status.DNDEndTime)
+	}
+	go Publish(event)
+}
+
+// UpdateDNDStatuses ends the do not disturb statuses that have expired by now, and puts users whose quiet hours
+// started since the last update into do not disturb until their quiet hours end. Both times are in milliseconds.
+func UpdateDNDStatuses(since, now int64) {
+	if result := <-Srv.Store.Status().GetExpiredDND(now); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	} else {
+		for _, status := range result.Data.([]*model.Status) {
+			endDoNotDisturb(status)
+		}
+	}
+
+	if result := <-Srv.Store.Preference().GetCategoryAndName(model.PREFERENCE_CATEGORY_NOTIFICATIONS, model.PREFERENCE_NAME_QUIET_HOURS); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	} else {
+		for _, preference := range result.Data.(model.Preferences) {
+			quietHours := model.QuietHoursFromJson(strings.NewReader(preference.Value))
+			if quietHours == nil {
+				continue
+			}
+
+			endTime, ok := quietHours.StartBetween(since, now)
+			if !ok || endTime <= now {
+				// quiet hours that started while the servers were down may have already ended
+				continue
+			}
+
+			// don't shorten a longer do not disturb that the user set themselves
+			if status, err := GetStatus(preference.UserId); err == nil && status.Status == model.STATUS_DND && (status.DNDEndTime == 0 || status.DNDEndTime >= endTime) {
+				continue
+			}
+
+			SetStatusDoNotDisturb(preference.UserId, endTime)
+		}
+	}
+}
+
+func MakeDNDStatusesWorker() jobs.Worker {
+	return jobs.NewSimpleWorker("DNDStatuses", func(job *model.Job, cancel <-chan interface{}) *model.AppError {
+		return RunDNDStatusesJob(model.GetMillis())
+	})
+}
+
+func MakeDNDStatusesScheduler() jobs.Scheduler {
+	return jobs.NewPeriodicScheduler(model.JOB_TYPE_DND_STATUSES, DND_STATUSES_JOB_INTERVAL, func() bool {
+		return true
+	})
+}
+
+// RunDNDStatusesJob updates do not disturb statuses for everything that has happened since the last time that it was
+// run by any server. That time is saved in the database so that quiet hours that start while the servers are
+// restarting aren't missed.
+func RunDNDStatusesJob(now int64) *model.AppError {
+	since := now - int64(DND_STATUSES_JOB_INTERVAL/time.Millisecond)
+	if result := <-Srv.Store.System().GetByName(model.SYSTEM_LAST_DND_UPDATE_TIME); result.Err == nil {
+		if lastUpdate, err := strconv.ParseInt(result.Data.(*model.System).Value, 10, 64); err == nil {
+			since = lastUpdate
+		}
+	}
+
+	if earliest := now - int64(DND_STATUSES_MAX_CATCH_UP/time.Millisecond); since < earliest {
+		since = earliest
+	}
+
+	if since >= now {
+		return nil
+	}
+
+	UpdateDNDStatuses(since, now)
+
+	system := &model.System{Name: model.SYSTEM_LAST_DND_UPDATE_TIME, Value: strconv.FormatInt(now, 10)}
+	if result := <-Srv.Store.System().SaveOrUpdate(system); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
 func GetStatusFromCache(userId string) *model.Status {
 	if result, ok := statusCache.Get(userId); ok {
 		status := result.(*model.Status)
@@ -161,6 +161,10 @@ func CreateUser(user *model.User) (*model.User, *model.AppError) {
 
 	user.Roles = model.ROLE_SYSTEM_USER.Id
 
+	// bot accounts can only be created through CreateBot
+	user.IsBot = false
+	user.BotOwnerId = ""
+
 	// Below is a special case where the first user in the entire
 	// system is granted the system_admin role
 	if result := <-Srv.Store.User().GetTotalUsersCount(); result.Err != nil {
@@ -187,9 +191,21 @@ func CreateUser(user *model.User) (*model.User, *model.AppError) {
 }
 
 func createUser(user *model.User) (*model.User, *model.AppError) {
+	// bot accounts can only be created through CreateBot
+	user.IsBot = false
+	user.BotOwnerId = ""
+
+	return saveNewUser(user)
+}
+
+// saveNewUser saves a user or bot account. Bots are the only users created without a password or an auth service.
+func saveNewUser(user *model.User) (*model.User, *model.AppError) {
 	user.MakeNonNil()
 
-	if err := utils.IsPasswordValid(user.Password); user.AuthService == "" && err != nil {
+	// custom statuses are only set through SetCustomStatus so that they're checked and expire properly
+	user.CustomStatus = nil
+
+	if err := utils.IsPasswordValid(user.Password); user.AuthService == "" && !user.IsBot && err != nil {
 		return nil, err
 	}
 
@@ -900,7 +916,8 @@ func UpdateUser(user *model.User, siteURL string, sendNotifications bool) (*mode
 	} else {
 		rusers := result.Data.([2]*model.User)
 
-		if sendNotifications {
+		// bots don't have a real email address to send notifications to
+		if sendNotifications && !rusers[0].IsBot {
 			if rusers[0].Email != rusers[1].Email {
 				go func() {
 					if err := SendEmailChangeEmail(rusers[1].Email, rusers[0].Email, rusers[0].Locale, siteURL); err != nil {
@@ -1141,6 +1158,10 @@ func PermanentDeleteUser(user *model.User) *model.AppError {
 		return result.Err
 	}
 
+	if result := <-Srv.Store.UserAccessToken().DeleteAllForUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
 	if result := <-Srv.Store.OAuth().PermanentDeleteAuthDataByUser(user.Id); result.Err != nil {
 		return result.Err
 	}
@@ -1157,6 +1178,10 @@ func PermanentDeleteUser(user *model.User) *model.AppError {
 		return result.Err
 	}
 
+	if result := <-Srv.Store.ScheduledPost().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
 	if result := <-Srv.Store.Preference().PermanentDeleteByUser(user.Id); result.Err != nil {
 		return result.Err
 	}
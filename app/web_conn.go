@@ -22,6 +22,10 @@ const (
 	PING_PERIOD               = (PONG_WAIT * 6) / 10
 	AUTH_TIMEOUT              = 5 * time.Second
 	WEBCONN_MEMBER_CACHE_TIME = 1000 * 60 * 30 // 30 minutes
+
+	WEBCONN_REPLAY_BUFFER_SIZE  = 128           // the number of events kept to be replayed to a resumed connection
+	WEBCONN_REPLAY_TIME         = 1000 * 60 * 5 // 5 minutes that a closed connection can be resumed for
+	WEBCONN_REPLAY_MAX_PER_USER = 4             // the number of closed connections kept for each user
 )
 
 type WebConn struct {
@@ -35,6 +39,15 @@ type WebConn struct {
 	Locale                    string
 	AllChannelMembers         map[string]string
 	LastAllChannelMembersTime int64
+	ConnectionId              string
+	Sequence                  int64 // the sequence number of the last event sent
+
+	// the events last sent, ending with the one numbered Sequence, along with the connection that the client asked
+	// to resume and when the connection was closed, which are only used by the hub
+	replay             []*model.WebSocketEvent
+	resumeConnectionId string
+	resumeSequence     int64
+	closedAt           int64
 }
 
 func NewWebConn(ws *websocket.Conn, session model.Session, t goi18n.TranslateFunc, locale string) *WebConn {
@@ -50,9 +63,17 @@ func NewWebConn(ws *websocket.Conn, session model.Session, t goi18n.TranslateFun
 		SessionExpiresAt: session.ExpiresAt,
 		T:                t,
 		Locale:           locale,
+		ConnectionId:     model.NewId(),
 	}
 }
 
+// SetResume asks for a new connection to carry on from a closed connection of the same user, replaying the events
+// sent after the given sequence number. It must be called before the connection is registered.
+func (webCon *WebConn) SetResume(connectionId string, sequence int64) {
+	webCon.resumeConnectionId = connectionId
+	webCon.resumeSequence = sequence
+}
+
 func (c *WebConn) ReadPump() {
 	defer func() {
 		HubUnregister(c)
@@ -179,10 +200,49 @@ func (webCon *WebConn) IsAuthenticated() bool {
 func (webCon *WebConn) SendHello() {
 	msg := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_HELLO, "", "", webCon.UserId, nil)
 	msg.Add("server_version", fmt.Sprintf("%v.%v.%v", model.CurrentVersion, model.BuildNumber, utils.CfgHash))
+	msg.Add(model.WEBSOCKET_PARAM_CONNECTION_ID, webCon.ConnectionId)
 	msg.DoPreComputeJson()
 	webCon.Send <- msg
 }
 
+// SendResyncRequired tells the client that the events it missed while disconnected can't be replayed, so it has to
+// fetch everything again.
+func (webCon *WebConn) SendResyncRequired() {
+	msg := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_RESYNC_REQUIRED, "", "", webCon.UserId, nil)
+	msg.DoPreComputeJson()
+	webCon.Send <- msg
+}
+
+// recordEvent numbers an event sent on the connection and keeps it to be replayed, returning its sequence number.
+func (webCon *WebConn) recordEvent(msg *model.WebSocketEvent) int64 {
+	webCon.Sequence++
+
+	webCon.replay = append(webCon.replay, msg)
+	if len(webCon.replay) > WEBCONN_REPLAY_BUFFER_SIZE {
+		webCon.replay = webCon.replay[len(webCon.replay)-WEBCONN_REPLAY_BUFFER_SIZE:]
+	}
+
+	return webCon.Sequence
+}
+
+// eventsSince returns the events sent after the given sequence number. It returns false if they aren't all kept.
+func (webCon *WebConn) eventsSince(sequence int64) ([]*model.WebSocketEvent, bool) {
+	missed := webCon.Sequence - sequence
+	if missed < 0 || missed > int64(len(webCon.replay)) {
+		return nil, false
+	}
+
+	return webCon.replay[int64(len(webCon.replay))-missed:], true
+}
+
+// resumeFrom carries on the numbering and the kept events of a closed connection.
+func (webCon *WebConn) resumeFrom(old *WebConn) {
+	webCon.ConnectionId = old.ConnectionId
+	webCon.Sequence = old.Sequence
+	webCon.replay = make([]*model.WebSocketEvent, len(old.replay))
+	copy(webCon.replay, old.replay)
+}
+
 func (webCon *WebConn) ShouldSendEvent(msg *model.WebSocketEvent) bool {
 	// IMPORTANT: Do not send event if WebConn does not have a session
 	if !webCon.IsAuthenticated() {
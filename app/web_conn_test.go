@@ -0,0 +1,65 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestWebConnReplay(t *testing.T) {
+	webCon := &WebConn{ConnectionId: model.NewId()}
+
+	if events, ok := webCon.eventsSince(0); !ok || len(events) != 0 {
+		t.Fatal("should have nothing to replay to a connection that hasn't been sent anything")
+	}
+
+	for i := 0; i < WEBCONN_REPLAY_BUFFER_SIZE+10; i++ {
+		msg := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", "", "", nil)
+		msg.Add("index", i)
+
+		if seq := webCon.recordEvent(msg); seq != int64(i+1) {
+			t.Fatal("events should be numbered from 1", seq)
+		}
+	}
+
+	if len(webCon.replay) != WEBCONN_REPLAY_BUFFER_SIZE {
+		t.Fatal("should only keep the last events", len(webCon.replay))
+	}
+
+	if events, ok := webCon.eventsSince(webCon.Sequence - 3); !ok || len(events) != 3 {
+		t.Fatal("should have replayed the last 3 events")
+	} else if events[0].Data["index"] != WEBCONN_REPLAY_BUFFER_SIZE+7 {
+		t.Fatal("should have replayed the events in order")
+	}
+
+	if events, ok := webCon.eventsSince(webCon.Sequence); !ok || len(events) != 0 {
+		t.Fatal("should have nothing to replay to an up to date client")
+	}
+
+	if _, ok := webCon.eventsSince(webCon.Sequence - WEBCONN_REPLAY_BUFFER_SIZE); !ok {
+		t.Fatal("should be able to replay every kept event")
+	}
+
+	if _, ok := webCon.eventsSince(9); ok {
+		t.Fatal("should require a resync when events weren't kept")
+	}
+
+	if _, ok := webCon.eventsSince(webCon.Sequence + 1); ok {
+		t.Fatal("should require a resync for a sequence number that wasn't sent")
+	}
+
+	resumed := &WebConn{ConnectionId: model.NewId()}
+	resumed.resumeFrom(webCon)
+	webCon.recordEvent(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", "", "", nil))
+
+	if resumed.ConnectionId != webCon.ConnectionId || resumed.Sequence != webCon.Sequence-1 {
+		t.Fatal("should have carried on from the closed connection")
+	}
+
+	if resumed.replay[len(resumed.replay)-1] == webCon.replay[len(webCon.replay)-1] {
+		t.Fatal("should have copied the kept events")
+	}
+}
@@ -18,6 +18,7 @@ import (
 
 type Hub struct {
 	connections    []*WebConn
+	closed         map[string][]*WebConn // recently closed connections that can be resumed, by user id
 	register       chan *WebConn
 	unregister     chan *WebConn
 	broadcast      chan *model.WebSocketEvent
@@ -33,6 +34,7 @@ func NewWebHub() *Hub {
 		register:       make(chan *WebConn),
 		unregister:     make(chan *WebConn),
 		connections:    make([]*WebConn, 0, model.SESSION_CACHE_SIZE),
+		closed:         make(map[string][]*WebConn),
 		broadcast:      make(chan *model.WebSocketEvent, 4096),
 		stop:           make(chan string),
 		invalidateUser: make(chan string),
@@ -216,10 +218,6 @@ func InvalidateCacheForReactionsSkipClusterSend(postId string) {
 
 func (h *Hub) Register(webConn *WebConn) {
 	h.register <- webConn
-
-	if webConn.IsAuthenticated() {
-		webConn.SendHello()
-	}
 }
 
 func (h *Hub) Unregister(webConn *WebConn) {
@@ -251,6 +249,10 @@ func (h *Hub) Start() {
 			case webCon := <-h.register:
 				h.connections = append(h.connections, webCon)
 
+				if webCon.IsAuthenticated() {
+					h.startConnection(webCon)
+				}
+
 			case webCon := <-h.unregister:
 				userId := webCon.UserId
 
@@ -273,6 +275,8 @@ func (h *Hub) Start() {
 					// Delete the webcon we are unregistering
 					h.connections[indexToDel] = h.connections[len(h.connections)-1]
 					h.connections = h.connections[:len(h.connections)-1]
+
+					h.keepClosed(webCon)
 				}
 
 				if len(userId) == 0 {
@@ -290,11 +294,17 @@ func (h *Hub) Start() {
 					}
 				}
 
+				for _, webCon := range h.closed[userId] {
+					webCon.InvalidateCache()
+				}
+
 			case msg := <-h.broadcast:
 				for _, webCon := range h.connections {
 					if webCon.ShouldSendEvent(msg) {
+						seq := webCon.recordEvent(msg)
+
 						select {
-						case webCon.Send <- msg:
+						case webCon.Send <- msg.SetSequence(seq):
 						default:
 							l4g.Error(fmt.Sprintf("webhub.broadcast: cannot send, closing websocket for userId=%v", webCon.UserId))
 							close(webCon.Send)
@@ -305,10 +315,14 @@ func (h *Hub) Start() {
 									break
 								}
 							}
+
+							h.keepClosed(webCon)
 						}
 					}
 				}
 
+				h.recordForClosed(msg)
+
 			case <-h.stop:
 				for _, webCon := range h.connections {
 					webCon.WebSocket.Close()
@@ -341,3 +355,107 @@ func (h *Hub) Start() {
 
 	go doRecoverableStart()
 }
+
+// startConnection sends the hello event to a newly registered connection. If the client asked to resume one of its
+// user's connections, the events that it missed are replayed, or it's told to resync if they weren't all kept. The
+// send buffer of a new connection is large enough to hold the replayed events.
+func (h *Hub) startConnection(webCon *WebConn) {
+	var missed []*model.WebSocketEvent
+	resync := false
+
+	if len(webCon.resumeConnectionId) > 0 {
+		if old := h.takeForResume(webCon); old == nil {
+			resync = true
+		} else if events, ok := old.eventsSince(webCon.resumeSequence); !ok {
+			resync = true
+		} else {
+			webCon.resumeFrom(old)
+			missed = events
+		}
+	}
+
+	webCon.SendHello()
+
+	if resync {
+		webCon.SendResyncRequired()
+	}
+
+	first := webCon.Sequence - int64(len(missed)) + 1
+	for i, msg := range missed {
+		webCon.Send <- msg.SetSequence(first + int64(i))
+	}
+}
+
+// takeForResume finds the connection that a new connection asked to resume. A connection that's still open, because
+// the server hasn't noticed that the client went away yet, is closed.
+func (h *Hub) takeForResume(webCon *WebConn) *WebConn {
+	closed := h.closed[webCon.UserId]
+	for i, old := range closed {
+		if old.ConnectionId == webCon.resumeConnectionId {
+			h.closed[webCon.UserId] = append(closed[:i], closed[i+1:]...)
+
+			if model.GetMillis()-old.closedAt > WEBCONN_REPLAY_TIME {
+				return nil
+			}
+
+			return old
+		}
+	}
+
+	for _, old := range h.connections {
+		if old != webCon && old.UserId == webCon.UserId && old.ConnectionId == webCon.resumeConnectionId {
+			old.WebSocket.Close()
+			return old
+		}
+	}
+
+	return nil
+}
+
+// keepClosed keeps a closed connection so that its client can resume it, unless a new connection already has.
+func (h *Hub) keepClosed(webCon *WebConn) {
+	if len(webCon.UserId) == 0 {
+		return
+	}
+
+	for _, other := range h.connections {
+		if other.ConnectionId == webCon.ConnectionId {
+			return
+		}
+	}
+
+	webCon.closedAt = model.GetMillis()
+
+	closed := append(h.closed[webCon.UserId], webCon)
+	if len(closed) > WEBCONN_REPLAY_MAX_PER_USER {
+		closed = closed[len(closed)-WEBCONN_REPLAY_MAX_PER_USER:]
+	}
+	h.closed[webCon.UserId] = closed
+}
+
+// recordForClosed keeps the events that closed connections would have been sent, and forgets the connections that
+// can no longer be resumed.
+func (h *Hub) recordForClosed(msg *model.WebSocketEvent) {
+	now := model.GetMillis()
+
+	for userId, closed := range h.closed {
+		kept := closed[:0]
+		for _, webCon := range closed {
+			if now-webCon.closedAt > WEBCONN_REPLAY_TIME {
+				continue
+			}
+
+			if webCon.ShouldSendEvent(msg) {
+				webCon.recordEvent(msg)
+			}
+
+			kept = append(kept, webCon)
+		}
+
+		if len(kept) == 0 {
+			delete(h.closed, userId)
+		} else {
+			h.closed[userId] = kept
+		}
+	}
+}
@@ -4,12 +4,11 @@
 package app
 
 import (
-	"crypto/tls"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"regexp"
 	"strings"
+	"sync"
 	"unicode/utf8"
 
 	l4g "github.com/alecthomas/log4go"
@@ -17,6 +16,8 @@ import (
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
+	"gopkg.in/throttled/throttled.v2"
+	"gopkg.in/throttled/throttled.v2/store/memstore"
 )
 
 const (
@@ -29,17 +30,11 @@ func handleWebhookEvents(post *model.Post, team *model.Team, channel *model.Chan
 		return nil
 	}
 
-	if channel.Type != model.CHANNEL_OPEN {
-		return nil
-	}
-
-	hchan := Srv.Store.Webhook().GetOutgoingByTeam(team.Id, -1, -1)
-	result := <-hchan
-	if result.Err != nil {
-		return result.Err
+	hooks, err := getOutgoingWebhooksForEvent(model.OUTGOING_WEBHOOK_EVENT_POSTED, channel)
+	if err != nil {
+		return err
 	}
 
-	hooks := result.Data.([]*model.OutgoingWebhook)
 	if len(hooks) == 0 {
 		return nil
 	}
@@ -52,81 +47,198 @@ func handleWebhookEvents(post *model.Post, team *model.Team, channel *model.Chan
 
 	relevantHooks := []*model.OutgoingWebhook{}
 	for _, hook := range hooks {
-		if hook.ChannelId == post.ChannelId || len(hook.ChannelId) == 0 {
-			if hook.ChannelId == post.ChannelId && len(hook.TriggerWords) == 0 {
-				relevantHooks = append(relevantHooks, hook)
-			} else if hook.TriggerWhen == TRIGGERWORDS_FULL && hook.HasTriggerWord(firstWord) {
-				relevantHooks = append(relevantHooks, hook)
-			} else if hook.TriggerWhen == TRIGGERWORDS_STARTSWITH && hook.TriggerWordStartsWith(firstWord) {
-				relevantHooks = append(relevantHooks, hook)
-			}
+		if hook.ChannelId == post.ChannelId && len(hook.TriggerWords) == 0 {
+			relevantHooks = append(relevantHooks, hook)
+		} else if hook.TriggerWhen == TRIGGERWORDS_FULL && hook.HasTriggerWord(firstWord) {
+			relevantHooks = append(relevantHooks, hook)
+		} else if hook.TriggerWhen == TRIGGERWORDS_STARTSWITH && hook.TriggerWordStartsWith(firstWord) {
+			relevantHooks = append(relevantHooks, hook)
 		}
 	}
 
 	for _, hook := range relevantHooks {
-		go func(hook *model.OutgoingWebhook) {
-			payload := &model.OutgoingWebhookPayload{
-				Token:       hook.Token,
-				TeamId:      hook.TeamId,
-				TeamDomain:  team.Name,
-				ChannelId:   post.ChannelId,
-				ChannelName: channel.Name,
-				Timestamp:   post.CreateAt,
-				UserId:      post.UserId,
-				UserName:    user.Username,
-				PostId:      post.Id,
-				Text:        post.Message,
-				TriggerWord: firstWord,
-			}
-			var body io.Reader
-			var contentType string
-			if hook.ContentType == "application/json" {
-				body = strings.NewReader(payload.ToJSON())
-				contentType = "application/json"
-			} else {
-				body = strings.NewReader(payload.ToFormValues())
-				contentType = "application/x-www-form-urlencoded"
-			}
-			tr := &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+		payload := newOutgoingWebhookPayload(hook, model.OUTGOING_WEBHOOK_EVENT_POSTED, team, channel, user, post.CreateAt)
+		payload.PostId = post.Id
+		payload.RootId = post.RootId
+		payload.Text = post.Message
+		payload.TriggerWord = firstWord
+
+		queueOutgoingWebhookPayload(hook, payload, post.Id, post.Props, post.Type)
+	}
+
+	return nil
+}
+
+// handleWebhookEvent sends an event other than a new post to the outgoing webhooks that are subscribed to it. The
+// post is nil for events that aren't about a post, and the emoji name is only set for reactions.
+func handleWebhookEvent(event string, channelId string, userId string, post *model.Post, emojiName string) {
+	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+		return
+	}
+
+	channel, err := GetChannel(channelId)
+	if err != nil {
+		l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.event_post.error"), err.Error())
+		return
+	}
+
+	hooks, err := getOutgoingWebhooksForEvent(event, channel)
+	if err != nil {
+		l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.event_post.error"), err.Error())
+		return
+	} else if len(hooks) == 0 {
+		return
+	}
+
+	// direct and group messages don't belong to a team
+	team := &model.Team{}
+	if len(channel.TeamId) > 0 {
+		if team, err = GetTeam(channel.TeamId); err != nil {
+			l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.event_post.error"), err.Error())
+			return
+		}
+	}
+
+	user, err := GetUser(userId)
+	if err != nil {
+		l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.event_post.error"), err.Error())
+		return
+	}
+
+	for _, hook := range hooks {
+		payload := newOutgoingWebhookPayload(hook, event, team, channel, user, model.GetMillis())
+		payload.EmojiName = emojiName
+
+		if post != nil {
+			payload.PostId = post.Id
+			payload.RootId = post.RootId
+			payload.Text = post.Message
+
+			queueOutgoingWebhookPayload(hook, payload, post.Id, post.Props, post.Type)
+		} else {
+			queueOutgoingWebhookPayload(hook, payload, "", nil, "")
+		}
+	}
+}
+
+// getOutgoingWebhooksForEvent gets the hooks that should be sent an event that happened in a channel. Hooks are only
+// sent events from private channels and direct messages if they opt in to it and their creator is a member of the
+// channel.
+func getOutgoingWebhooksForEvent(event string, channel *model.Channel) ([]*model.OutgoingWebhook, *model.AppError) {
+	var hchan store.StoreChannel
+	if len(channel.TeamId) == 0 {
+		hchan = Srv.Store.Webhook().GetOutgoingForDirectChannel(channel.Id)
+	} else {
+		hchan = Srv.Store.Webhook().GetOutgoingByTeam(channel.TeamId, -1, -1)
+	}
+
+	var hooks []*model.OutgoingWebhook
+	if result := <-hchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		hooks = result.Data.([]*model.OutgoingWebhook)
+	}
+
+	relevantHooks := []*model.OutgoingWebhook{}
+	for _, hook := range hooks {
+		if !hook.HasEvent(event) {
+			continue
+		}
+
+		if len(hook.ChannelId) != 0 && hook.ChannelId != channel.Id {
+			continue
+		}
+
+		if channel.Type != model.CHANNEL_OPEN {
+			if !hook.IncludePrivateChannels {
+				continue
 			}
-			client := &http.Client{Transport: tr}
-
-			for _, url := range hook.CallbackURLs {
-				go func(url string) {
-					req, _ := http.NewRequest("POST", url, body)
-					req.Header.Set("Content-Type", contentType)
-					req.Header.Set("Accept", "application/json")
-					if resp, err := client.Do(req); err != nil {
-						l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.event_post.error"), err.Error())
-					} else {
-						defer func() {
-							ioutil.ReadAll(resp.Body)
-							resp.Body.Close()
-						}()
-						respProps := model.MapFromJson(resp.Body)
-
-						if text, ok := respProps["text"]; ok {
-							if _, err := CreateWebhookPost(hook.CreatorId, hook.TeamId, post.ChannelId, text, respProps["username"], respProps["icon_url"], post.Props, post.Type); err != nil {
-								l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.create_post.error"), err)
-							}
-						}
-					}
-				}(url)
+
+			if result := <-Srv.Store.Channel().GetMember(channel.Id, hook.CreatorId); result.Err != nil {
+				continue
 			}
+		}
+
+		relevantHooks = append(relevantHooks, hook)
+	}
+
+	return relevantHooks, nil
+}
+
+func newOutgoingWebhookPayload(hook *model.OutgoingWebhook, event string, team *model.Team, channel *model.Channel, user *model.User, timestamp int64) *model.OutgoingWebhookPayload {
+	return &model.OutgoingWebhookPayload{
+		Token:       hook.Token,
+		TeamId:      hook.TeamId,
+		TeamDomain:  team.Name,
+		ChannelId:   channel.Id,
+		ChannelName: channel.Name,
+		Timestamp:   timestamp,
+		UserId:      user.Id,
+		UserName:    user.Username,
+		Event:       event,
+		Version:     model.OUTGOING_WEBHOOK_PAYLOAD_VERSION,
+	}
+}
 
-		}(hook)
+// queueOutgoingWebhookPayload queues a delivery of the payload to each of the hook's callback URLs. The props and
+// post type are used for the post created from the integration's response.
+func queueOutgoingWebhookPayload(hook *model.OutgoingWebhook, payload *model.OutgoingWebhookPayload, postId string, props model.StringInterface, postType string) {
+	var body string
+	var contentType string
+	if hook.ContentType == "application/json" {
+		body = payload.ToJSON()
+		contentType = "application/json"
+	} else {
+		body = payload.ToFormValues()
+		contentType = "application/x-www-form-urlencoded"
+	}
+
+	for _, url := range hook.CallbackURLs {
+		delivery := &model.OutgoingWebhookDelivery{
+			HookId:      hook.Id,
+			ChannelId:   payload.ChannelId,
+			PostId:      postId,
+			URL:         url,
+			ContentType: contentType,
+		}
+
+		if _, err := queueOutgoingWebhookDelivery(hook, delivery, body, props, postType); err != nil {
+			l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.event_post.error"), err.Error())
+		}
+	}
+}
+
+// validateOutgoingWebhookChannel checks that a hook can be limited to a channel. Hooks can only be limited to private
+// channels if they include private channels and their creator is a member of the channel.
+func validateOutgoingWebhookChannel(hook *model.OutgoingWebhook, teamId string, where string) *model.AppError {
+	channel, err := GetChannel(hook.ChannelId)
+	if err != nil {
+		return err
+	}
+
+	if channel.Type != model.CHANNEL_OPEN {
+		if !hook.IncludePrivateChannels || channel.Type != model.CHANNEL_PRIVATE {
+			return model.NewAppError(where, "api.webhook.create_outgoing.not_open.app_error", nil, "", http.StatusForbidden)
+		}
+
+		if _, err := GetChannelMember(channel.Id, hook.CreatorId); err != nil {
+			return model.NewAppError(where, "api.webhook.create_outgoing.permissions.app_error", nil, "", http.StatusForbidden)
+		}
+	}
+
+	if channel.TeamId != teamId {
+		return model.NewAppError(where, "api.webhook.create_outgoing.permissions.app_error", nil, "", http.StatusForbidden)
 	}
 
 	return nil
 }
 
-func CreateWebhookPost(userId, teamId, channelId, text, overrideUsername, overrideIconUrl string, props model.StringInterface, postType string) (*model.Post, *model.AppError) {
+func CreateWebhookPost(userId, teamId, channelId, text, overrideUsername, overrideIconUrl string, props model.StringInterface, postType string, fileIds []string) (*model.Post, *model.AppError) {
 	// parse links into Markdown format
 	linkWithTextRegex := regexp.MustCompile(`<([^<\|]+)\|([^>]+)>`)
 	text = linkWithTextRegex.ReplaceAllString(text, "[${2}](${1})")
 
-	post := &model.Post{UserId: userId, ChannelId: channelId, Message: text, Type: postType}
+	post := &model.Post{UserId: userId, ChannelId: channelId, Message: text, Type: postType, FileIds: fileIds}
 	post.AddProp("from_webhook", "true")
 
 	if metrics := einterfaces.GetMetricsInterface(); metrics != nil {
@@ -136,7 +248,8 @@ func CreateWebhookPost(userId, teamId, channelId, text, overrideUsername, overri
 	if utils.Cfg.ServiceSettings.EnablePostUsernameOverride {
 		if len(overrideUsername) != 0 {
 			post.AddProp("override_username", overrideUsername)
-		} else {
+		} else if user, err := GetUser(userId); err != nil || !user.IsBot {
+			// posts made as a bot show the bot's own name by default
 			post.AddProp("override_username", model.DEFAULT_WEBHOOK_USERNAME)
 		}
 	}
@@ -150,37 +263,8 @@ func CreateWebhookPost(userId, teamId, channelId, text, overrideUsername, overri
 	if len(props) > 0 {
 		for key, val := range props {
 			if key == "attachments" {
-				if list, success := val.([]interface{}); success {
-					// parse attachment links into Markdown format
-					for i, aInt := range list {
-						attachment := aInt.(map[string]interface{})
-						if aText, ok := attachment["text"].(string); ok {
-							aText = linkWithTextRegex.ReplaceAllString(aText, "[${2}](${1})")
-							attachment["text"] = aText
-							list[i] = attachment
-						}
-						if aText, ok := attachment["pretext"].(string); ok {
-							aText = linkWithTextRegex.ReplaceAllString(aText, "[${2}](${1})")
-							attachment["pretext"] = aText
-							list[i] = attachment
-						}
-						if fVal, ok := attachment["fields"]; ok {
-							if fields, ok := fVal.([]interface{}); ok {
-								// parse attachment field links into Markdown format
-								for j, fInt := range fields {
-									field := fInt.(map[string]interface{})
-									if fValue, ok := field["value"].(string); ok {
-										fValue = linkWithTextRegex.ReplaceAllString(fValue, "[${2}](${1})")
-										field["value"] = fValue
-										fields[j] = field
-									}
-								}
-								attachment["fields"] = fields
-								list[i] = attachment
-							}
-						}
-					}
-					post.AddProp(key, list)
+				if attachments := model.SlackAttachmentsFromInterface(val); attachments != nil {
+					parseSlackAttachment(post, attachments)
 				}
 			} else if key != "override_icon_url" && key != "override_username" && key != "from_webhook" {
 				post.AddProp(key, val)
@@ -203,6 +287,10 @@ func CreateIncomingWebhookForChannel(creatorId string, channel *model.Channel, h
 	hook.UserId = creatorId
 	hook.TeamId = channel.TeamId
 
+	if err := ValidateBotForIntegration(hook.BotUserId, creatorId); err != nil {
+		return nil, err
+	}
+
 	if result := <-Srv.Store.Webhook().SaveIncoming(hook); result.Err != nil {
 		return nil, result.Err
 	} else {
@@ -221,9 +309,17 @@ func UpdateIncomingWebhook(oldHook, updatedHook *model.IncomingWebhook) (*model.
 	updatedHook.TeamId = oldHook.TeamId
 	updatedHook.DeleteAt = oldHook.DeleteAt
 
+	if updatedHook.BotUserId != oldHook.BotUserId {
+		if err := ValidateBotForIntegration(updatedHook.BotUserId, oldHook.UserId); err != nil {
+			return nil, err
+		}
+	}
+
 	if result := <-Srv.Store.Webhook().UpdateIncoming(updatedHook); result.Err != nil {
 		return nil, result.Err
 	} else {
+		// make sure that changes to the channel lock and rate limit apply straight away
+		InvalidateCacheForWebhook(updatedHook.Id)
 		return result.Data.(*model.IncomingWebhook), nil
 	}
 }
@@ -284,26 +380,17 @@ func CreateOutgoingWebhook(hook *model.OutgoingWebhook) (*model.OutgoingWebhook,
 	}
 
 	if len(hook.ChannelId) != 0 {
-		cchan := Srv.Store.Channel().Get(hook.ChannelId, true)
-
-		var channel *model.Channel
-		if result := <-cchan; result.Err != nil {
-			return nil, result.Err
-		} else {
-			channel = result.Data.(*model.Channel)
-		}
-
-		if channel.Type != model.CHANNEL_OPEN {
-			return nil, model.NewAppError("CreateOutgoingWebhook", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusForbidden)
-		}
-
-		if channel.Type != model.CHANNEL_OPEN || channel.TeamId != hook.TeamId {
-			return nil, model.NewAppError("CreateOutgoingWebhook", "api.webhook.create_outgoing.permissions.app_error", nil, "", http.StatusForbidden)
+		if err := validateOutgoingWebhookChannel(hook, hook.TeamId, "CreateOutgoingWebhook"); err != nil {
+			return nil, err
 		}
-	} else if len(hook.TriggerWords) == 0 {
+	} else if len(hook.TriggerWords) == 0 && hook.HasEvent(model.OUTGOING_WEBHOOK_EVENT_POSTED) {
 		return nil, model.NewAppError("CreateOutgoingWebhook", "api.webhook.create_outgoing.triggers.app_error", nil, "", http.StatusBadRequest)
 	}
 
+	if err := ValidateBotForIntegration(hook.BotUserId, hook.CreatorId); err != nil {
+		return nil, err
+	}
+
 	if result := <-Srv.Store.Webhook().GetOutgoingByTeam(hook.TeamId, -1, -1); result.Err != nil {
 		return nil, result.Err
 	} else {
@@ -331,20 +418,14 @@ func UpdateOutgoingWebhook(oldHook, updatedHook *model.OutgoingWebhook) (*model.
 		return nil, model.NewAppError("UpdateOutgoingWebhook", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
+	// the channel is checked against the hook's original creator since they're the one whose access is used
+	updatedHook.CreatorId = oldHook.CreatorId
+
 	if len(updatedHook.ChannelId) > 0 {
-		channel, err := GetChannel(updatedHook.ChannelId)
-		if err != nil {
+		if err := validateOutgoingWebhookChannel(updatedHook, oldHook.TeamId, "UpdateOutgoingWebhook"); err != nil {
 			return nil, err
 		}
-
-		if channel.Type != model.CHANNEL_OPEN {
-			return nil, model.NewAppError("UpdateOutgoingWebhook", "api.webhook.create_outgoing.not_open.app_error", nil, "", http.StatusForbidden)
-		}
-
-		if channel.TeamId != oldHook.TeamId {
-			return nil, model.NewAppError("UpdateOutgoingWebhook", "api.webhook.create_outgoing.permissions.app_error", nil, "", http.StatusForbidden)
-		}
-	} else if len(updatedHook.TriggerWords) == 0 {
+	} else if len(updatedHook.TriggerWords) == 0 && updatedHook.HasEvent(model.OUTGOING_WEBHOOK_EVENT_POSTED) {
 		return nil, model.NewLocAppError("UpdateOutgoingWebhook", "api.webhook.create_outgoing.triggers.app_error", nil, "")
 	}
 
@@ -364,12 +445,22 @@ func UpdateOutgoingWebhook(oldHook, updatedHook *model.OutgoingWebhook) (*model.
 		}
 	}
 
-	updatedHook.CreatorId = oldHook.CreatorId
 	updatedHook.CreateAt = oldHook.CreateAt
 	updatedHook.DeleteAt = oldHook.DeleteAt
 	updatedHook.TeamId = oldHook.TeamId
 	updatedHook.UpdateAt = model.GetMillis()
 
+	updatedHook.SigningSecret = oldHook.SigningSecret
+	if updatedHook.SigningSecret == "" {
+		updatedHook.SigningSecret = model.NewSigningSecret()
+	}
+
+	if updatedHook.BotUserId != oldHook.BotUserId {
+		if err := ValidateBotForIntegration(updatedHook.BotUserId, oldHook.CreatorId); err != nil {
+			return nil, err
+		}
+	}
+
 	if result = <-Srv.Store.Webhook().UpdateOutgoing(updatedHook); result.Err != nil {
 		return nil, result.Err
 	} else {
@@ -383,6 +474,7 @@ func GetOutgoingWebhook(hookId string) (*model.OutgoingWebhook, *model.AppError)
 	}
 
 	if result := <-Srv.Store.Webhook().GetOutgoing(hookId); result.Err != nil {
+		result.Err.StatusCode = http.StatusNotFound
 		return nil, result.Err
 	} else {
 		return result.Data.(*model.OutgoingWebhook), nil
@@ -443,6 +535,7 @@ func RegenOutgoingWebhookToken(hook *model.OutgoingWebhook) (*model.OutgoingWebh
 	}
 
 	hook.Token = model.NewId()
+	hook.SigningSecret = model.NewSigningSecret()
 
 	if result := <-Srv.Store.Webhook().UpdateOutgoing(hook); result.Err != nil {
 		return nil, result.Err
@@ -451,19 +544,84 @@ func RegenOutgoingWebhookToken(hook *model.OutgoingWebhook) (*model.OutgoingWebh
 	}
 }
 
-func HandleIncomingWebhook(hookId string, req *model.IncomingWebhookRequest) *model.AppError {
+// incomingWebhookRateLimiters holds a rate limiter for each of the rate limits that incoming webhooks have been given.
+// Each hook is tracked separately by its id, and by each server in a cluster.
+var incomingWebhookRateLimiters = map[int]*throttled.GCRARateLimiter{}
+var incomingWebhookRateLimitStore *memstore.MemStore
+var incomingWebhookRateLimitersLock sync.Mutex
+
+// checkIncomingWebhookRateLimit returns an error if a hook has already made as many posts in the last minute as its
+// rate limit allows.
+func checkIncomingWebhookRateLimit(hook *model.IncomingWebhook) *model.AppError {
+	if hook.RateLimit <= 0 {
+		return nil
+	}
+
+	incomingWebhookRateLimitersLock.Lock()
+	if incomingWebhookRateLimitStore == nil {
+		incomingWebhookRateLimitStore, _ = memstore.New(0)
+	}
+
+	limiter, ok := incomingWebhookRateLimiters[hook.RateLimit]
+	if !ok {
+		var err error
+		limiter, err = throttled.NewGCRARateLimiter(incomingWebhookRateLimitStore, throttled.RateQuota{
+			MaxRate:  throttled.PerMin(hook.RateLimit),
+			MaxBurst: hook.RateLimit - 1,
+		})
+		if err != nil {
+			incomingWebhookRateLimitersLock.Unlock()
+			return model.NewAppError("checkIncomingWebhookRateLimit", "web.incoming_webhook.rate_limit.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		incomingWebhookRateLimiters[hook.RateLimit] = limiter
+	}
+	incomingWebhookRateLimitersLock.Unlock()
+
+	if limited, _, err := limiter.RateLimit(hook.Id, 1); err != nil {
+		return model.NewAppError("checkIncomingWebhookRateLimit", "web.incoming_webhook.rate_limit.app_error", nil, err.Error(), http.StatusInternalServerError)
+	} else if limited {
+		return model.NewAppError("checkIncomingWebhookRateLimit", "web.incoming_webhook.rate_limited.app_error", map[string]interface{}{"RateLimit": hook.RateLimit}, "hook_id="+hook.Id, http.StatusTooManyRequests)
+	}
+
+	return nil
+}
+
+// ParseIncomingWebhookPayload reads the request that a payload sent to an incoming webhook stands for. Payloads sent
+// to hooks with a message template can be any JSON, and are rendered with the template.
+func ParseIncomingWebhookPayload(hook *model.IncomingWebhook, payload io.Reader) (*model.IncomingWebhookRequest, *model.AppError) {
+	if hook.MessageTemplate != "" {
+		return model.RenderIncomingWebhookMessageTemplate(hook.MessageTemplate, payload)
+	}
+
+	return model.IncomingWebhookRequestFromJson(payload)
+}
+
+// HandleIncomingWebhook creates a post from a request made to an incoming webhook. Any files sent with the request
+// are uploaded to the channel that the post is made in and attached to it.
+func HandleIncomingWebhook(hookId string, payload io.Reader, files []io.ReadCloser, filenames []string) *model.AppError {
 	if !utils.Cfg.ServiceSettings.EnableIncomingWebhooks {
 		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
-	hchan := Srv.Store.Webhook().GetIncoming(hookId, true)
+	var hook *model.IncomingWebhook
+	if result := <-Srv.Store.Webhook().GetIncoming(hookId, true); result.Err != nil {
+		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.invalid.app_error", nil, "err="+result.Err.Message, http.StatusBadRequest)
+	} else {
+		hook = result.Data.(*model.IncomingWebhook)
+	}
+
+	req, err := ParseIncomingWebhookPayload(hook, payload)
+	if err != nil {
+		return err
+	}
 
 	if req == nil {
 		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.parse.app_error", nil, "", http.StatusBadRequest)
 	}
 
 	text := req.Text
-	if len(text) == 0 && req.Attachments == nil {
+	if len(text) == 0 && len(req.Attachments) == 0 && len(files) == 0 {
 		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.text.app_error", nil, "", http.StatusBadRequest)
 	}
 
@@ -476,7 +634,7 @@ func HandleIncomingWebhook(hookId string, req *model.IncomingWebhookRequest) *mo
 	webhookType := req.Type
 
 	// attachments is in here for slack compatibility
-	if req.Attachments != nil {
+	if len(req.Attachments) > 0 {
 		if len(req.Props) == 0 {
 			req.Props = make(model.StringInterface)
 		}
@@ -491,11 +649,13 @@ func HandleIncomingWebhook(hookId string, req *model.IncomingWebhookRequest) *mo
 		webhookType = model.POST_SLACK_ATTACHMENT
 	}
 
-	var hook *model.IncomingWebhook
-	if result := <-hchan; result.Err != nil {
-		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.invalid.app_error", nil, "err="+result.Err.Message, http.StatusBadRequest)
-	} else {
-		hook = result.Data.(*model.IncomingWebhook)
+	if err := checkIncomingWebhookRateLimit(hook); err != nil {
+		return err
+	}
+
+	postUserId, err := getPostingUserId(hook.UserId, hook.BotUserId)
+	if err != nil {
+		return err
 	}
 
 	var channel *model.Channel
@@ -504,11 +664,16 @@ func HandleIncomingWebhook(hookId string, req *model.IncomingWebhookRequest) *mo
 
 	if len(channelName) != 0 {
 		if channelName[0] == '@' {
+			// a locked hook can only post in its own channel, so don't bother looking for the direct channel
+			if hook.ChannelLocked {
+				return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.channel_locked.app_error", nil, "", http.StatusForbidden)
+			}
+
 			if result := <-Srv.Store.User().GetByUsername(channelName[1:]); result.Err != nil {
 				return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.user.app_error", nil, "err="+result.Err.Message, http.StatusBadRequest)
 			} else {
 				directUserId = result.Data.(*model.User).Id
-				channelName = model.GetDMNameFromIds(directUserId, hook.UserId)
+				channelName = model.GetDMNameFromIds(directUserId, postUserId)
 			}
 		} else if channelName[0] == '#' {
 			channelName = channelName[1:]
@@ -524,13 +689,13 @@ func HandleIncomingWebhook(hookId string, req *model.IncomingWebhookRequest) *mo
 
 	result := <-cchan
 	if result.Err != nil && result.Err.Id == store.MISSING_CHANNEL_ERROR && directUserId != "" {
-		newChanResult := <-Srv.Store.Channel().CreateDirectChannel(directUserId, hook.UserId)
+		newChanResult := <-Srv.Store.Channel().CreateDirectChannel(directUserId, postUserId)
 		if newChanResult.Err != nil {
 			return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.channel.app_error", nil, "err="+newChanResult.Err.Message, http.StatusBadRequest)
 		} else {
 			channel = newChanResult.Data.(*model.Channel)
 			InvalidateCacheForUser(directUserId)
-			InvalidateCacheForUser(hook.UserId)
+			InvalidateCacheForUser(postUserId)
 		}
 	} else if result.Err != nil {
 		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.channel.app_error", nil, "err="+result.Err.Message, result.Err.StatusCode)
@@ -538,11 +703,30 @@ func HandleIncomingWebhook(hookId string, req *model.IncomingWebhookRequest) *mo
 		channel = result.Data.(*model.Channel)
 	}
 
+	if hook.ChannelLocked && channel.Id != hook.ChannelId {
+		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.channel_locked.app_error", nil, "", http.StatusForbidden)
+	}
+
 	if channel.Type != model.CHANNEL_OPEN && !HasPermissionToChannel(hook.UserId, channel.Id, model.PERMISSION_READ_CHANNEL) {
 		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.permissions.app_error", nil, "", http.StatusForbidden)
 	}
 
-	if _, err := CreateWebhookPost(hook.UserId, hook.TeamId, channel.Id, text, overrideUsername, overrideIconUrl, req.Props, webhookType); err != nil {
+	var fileIds []string
+	if len(files) > 0 {
+		if len(files) > model.INCOMING_WEBHOOK_MAX_FILES {
+			return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.files.too_many.app_error", map[string]interface{}{"Max": model.INCOMING_WEBHOOK_MAX_FILES}, "", http.StatusBadRequest)
+		}
+
+		if resp, err := UploadFiles(hook.TeamId, channel.Id, postUserId, files, filenames, nil); err != nil {
+			return err
+		} else {
+			for _, info := range resp.FileInfos {
+				fileIds = append(fileIds, info.Id)
+			}
+		}
+	}
+
+	if _, err := CreateWebhookPost(postUserId, hook.TeamId, channel.Id, text, overrideUsername, overrideIconUrl, req.Props, webhookType, fileIds); err != nil {
 		return err
 	}
 
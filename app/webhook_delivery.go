@@ -0,0 +1,336 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/jobs"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	OUTGOING_WEBHOOK_QUEUE_SIZE         = 1000
+	OUTGOING_WEBHOOK_WORKERS            = 10
+	OUTGOING_WEBHOOK_TIMEOUT            = 10 * time.Second
+	OUTGOING_WEBHOOK_MAX_ATTEMPTS       = 5
+	OUTGOING_WEBHOOK_RETRY_INTERVAL     = time.Second // doubled after each failed attempt
+	OUTGOING_WEBHOOK_RESPONSE_MAX_BYTES = 1024 * 1024
+	OUTGOING_WEBHOOK_DELIVERY_RETENTION = 7 * 24 * time.Hour
+
+	// pending deliveries that haven't been attempted for this long are assumed to have been lost by a server that
+	// stopped. It's longer than a delivery can wait behind a full queue so that ones still queued aren't sent twice.
+	OUTGOING_WEBHOOK_DELIVERY_STALE_AFTER    = 30 * time.Minute
+	OUTGOING_WEBHOOK_DELIVERIES_JOB_INTERVAL = time.Minute
+)
+
+// outgoingWebhookTask is a delivery waiting in the queue. The payload is kept here as well as on the delivery since
+// payloads that are too large to be saved can still be sent.
+type outgoingWebhookTask struct {
+	hook     *model.OutgoingWebhook
+	delivery *model.OutgoingWebhookDelivery
+	payload  string
+	props    model.StringInterface
+	postType string
+}
+
+var outgoingWebhookQueue chan *outgoingWebhookTask
+var startOutgoingWebhookWorkersOnce sync.Once
+
+func startOutgoingWebhookWorkers() {
+	outgoingWebhookQueue = make(chan *outgoingWebhookTask, OUTGOING_WEBHOOK_QUEUE_SIZE)
+
+	for i := 0; i < OUTGOING_WEBHOOK_WORKERS; i++ {
+		go func() {
+			for task := range outgoingWebhookQueue {
+				deliverOutgoingWebhook(task)
+			}
+		}()
+	}
+}
+
+func MakeOutgoingWebhookDeliveriesWorker() jobs.Worker {
+	return jobs.NewSimpleWorker("OutgoingWebhookDeliveries", func(job *model.Job, cancel <-chan interface{}) *model.AppError {
+		if err := RequeueStaleOutgoingWebhookDeliveries(); err != nil {
+			return err
+		}
+
+		before := model.GetMillis() - int64(OUTGOING_WEBHOOK_DELIVERY_RETENTION/time.Millisecond)
+		if result := <-Srv.Store.Webhook().PermanentDeleteOutgoingDeliveriesBefore(before); result.Err != nil {
+			return result.Err
+		}
+
+		return nil
+	})
+}
+
+func MakeOutgoingWebhookDeliveriesScheduler() jobs.Scheduler {
+	return jobs.NewPeriodicScheduler(model.JOB_TYPE_OUTGOING_WEBHOOK_DELIVERIES, OUTGOING_WEBHOOK_DELIVERIES_JOB_INTERVAL, func() bool {
+		return utils.Cfg.ServiceSettings.EnableOutgoingWebhooks
+	})
+}
+
+// RequeueStaleOutgoingWebhookDeliveries queues the pending deliveries that were lost by a server that stopped, such
+// as ones waiting to be retried when it was restarted. Each delivery is claimed first so that only one server
+// requeues it.
+func RequeueStaleOutgoingWebhookDeliveries() *model.AppError {
+	before := model.GetMillis() - int64(OUTGOING_WEBHOOK_DELIVERY_STALE_AFTER/time.Millisecond)
+
+	var deliveries []*model.OutgoingWebhookDelivery
+	if result := <-Srv.Store.Webhook().GetStaleOutgoingDeliveries(before, OUTGOING_WEBHOOK_QUEUE_SIZE); result.Err != nil {
+		return result.Err
+	} else {
+		deliveries = result.Data.([]*model.OutgoingWebhookDelivery)
+	}
+
+	for _, delivery := range deliveries {
+		if result := <-Srv.Store.Webhook().ClaimOutgoingDelivery(delivery); result.Err != nil {
+			l4g.Error(result.Err.Error())
+			continue
+		} else if !result.Data.(bool) {
+			// another server got to it first or it was attempted since it was loaded
+			continue
+		}
+
+		requeueOutgoingWebhookDelivery(delivery)
+	}
+
+	return nil
+}
+
+// requeueOutgoingWebhookDelivery queues a pending delivery again, failing it if it can no longer be sent.
+func requeueOutgoingWebhookDelivery(delivery *model.OutgoingWebhookDelivery) {
+	var hook *model.OutgoingWebhook
+	if result := <-Srv.Store.Webhook().GetOutgoing(delivery.HookId); result.Err == nil {
+		hook = result.Data.(*model.OutgoingWebhook)
+	}
+
+	if hook == nil || !hasOutgoingWebhookCallbackURL(hook, delivery.URL) || delivery.Payload == "" {
+		delivery.Status = model.OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED
+		delivery.Error = utils.T("app.webhook.delivery.requeue.error")
+		saveOutgoingWebhookDelivery(delivery)
+		return
+	}
+
+	task := &outgoingWebhookTask{
+		hook:     hook,
+		delivery: delivery,
+		payload:  delivery.Payload,
+	}
+
+	if post, err := GetSinglePost(delivery.PostId); err == nil {
+		task.props = post.Props
+		task.postType = post.Type
+	}
+
+	startOutgoingWebhookWorkersOnce.Do(startOutgoingWebhookWorkers)
+
+	select {
+	case outgoingWebhookQueue <- task:
+	default:
+		delivery.Status = model.OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED
+		delivery.Error = utils.T("app.webhook.delivery.queue_full.error")
+		saveOutgoingWebhookDelivery(delivery)
+	}
+}
+
+// hasOutgoingWebhookCallbackURL checks that a URL is still one of the hook's callback URLs.
+func hasOutgoingWebhookCallbackURL(hook *model.OutgoingWebhook, url string) bool {
+	for _, callbackURL := range hook.CallbackURLs {
+		if callbackURL == url {
+			return true
+		}
+	}
+
+	return false
+}
+
+// queueOutgoingWebhookDelivery saves a record of a delivery and queues it to be sent. If the queue is full, the
+// delivery fails straight away instead of blocking the post that triggered it.
+func queueOutgoingWebhookDelivery(hook *model.OutgoingWebhook, delivery *model.OutgoingWebhookDelivery, payload string, props model.StringInterface, postType string) (*model.OutgoingWebhookDelivery, *model.AppError) {
+	startOutgoingWebhookWorkersOnce.Do(startOutgoingWebhookWorkers)
+
+	if len(payload) <= model.OUTGOING_WEBHOOK_DELIVERY_PAYLOAD_MAX_LENGTH {
+		delivery.Payload = payload
+	}
+
+	if result := <-Srv.Store.Webhook().SaveOutgoingDelivery(delivery); result.Err != nil {
+		return nil, result.Err
+	}
+
+	task := &outgoingWebhookTask{
+		hook:     hook,
+		delivery: delivery,
+		payload:  payload,
+		props:    props,
+		postType: postType,
+	}
+
+	select {
+	case outgoingWebhookQueue <- task:
+		return delivery, nil
+	default:
+		delivery.Status = model.OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED
+		delivery.Error = utils.T("app.webhook.delivery.queue_full.error")
+		saveOutgoingWebhookDelivery(delivery)
+
+		return nil, model.NewAppError("queueOutgoingWebhookDelivery", "app.webhook.delivery.queue_full.app_error", nil, "hook_id="+hook.Id, http.StatusServiceUnavailable)
+	}
+}
+
+func deliverOutgoingWebhook(task *outgoingWebhookTask) {
+	delivery := task.delivery
+	delivery.Attempts++
+
+	start := time.Now()
+	resp, err := sendOutgoingWebhook(delivery.URL, delivery.ContentType, task.payload, task.hook.SigningSecret)
+	delivery.Latency = int64(time.Since(start) / time.Millisecond)
+
+	retry := false
+	if err != nil {
+		delivery.StatusCode = 0
+		delivery.Error = err.Error()
+		retry = true
+	} else {
+		defer func() {
+			// only a bounded amount is drained so that a hostile endpoint can't send an endless response
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, OUTGOING_WEBHOOK_RESPONSE_MAX_BYTES))
+			resp.Body.Close()
+		}()
+
+		delivery.StatusCode = resp.StatusCode
+		delivery.Error = ""
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			delivery.Status = model.OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS
+			handleOutgoingWebhookResponse(task, io.LimitReader(resp.Body, OUTGOING_WEBHOOK_RESPONSE_MAX_BYTES))
+		} else {
+			delivery.Error = resp.Status
+			retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		}
+	}
+
+	if delivery.Status != model.OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS {
+		if retry && delivery.Attempts < OUTGOING_WEBHOOK_MAX_ATTEMPTS {
+			delivery.Status = model.OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING
+		} else {
+			delivery.Status = model.OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED
+			l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.event_post.error"), delivery.Error)
+		}
+	}
+
+	saveOutgoingWebhookDelivery(delivery)
+
+	if delivery.Status == model.OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING {
+		time.AfterFunc(OUTGOING_WEBHOOK_RETRY_INTERVAL<<uint(delivery.Attempts-1), func() {
+			select {
+			case outgoingWebhookQueue <- task:
+			default:
+				delivery.Status = model.OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED
+				delivery.Error = utils.T("app.webhook.delivery.queue_full.error")
+				saveOutgoingWebhookDelivery(delivery)
+			}
+		})
+	}
+}
+
+// sendOutgoingWebhook posts a payload to an integration. Each attempt is signed separately so that retries of a
+// delivery aren't rejected as being too old.
+func sendOutgoingWebhook(url, contentType, payload, signingSecret string) (*http.Response, error) {
+	req, err := http.NewRequest("POST", url, strings.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+
+	// hooks created before signing secrets were added don't have one until they're updated
+	if signingSecret != "" {
+		model.SignRequest(req, signingSecret, []byte(payload))
+	}
+
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+	}
+	client := &http.Client{Transport: tr, Timeout: OUTGOING_WEBHOOK_TIMEOUT}
+
+	return client.Do(req)
+}
+
+// handleOutgoingWebhookResponse posts the text of an integration's response back to the channel the webhook was
+// triggered from.
+func handleOutgoingWebhookResponse(task *outgoingWebhookTask, body io.Reader) {
+	respProps := model.MapFromJson(body)
+
+	if text, ok := respProps["text"]; ok {
+		if userId, err := getPostingUserId(task.hook.CreatorId, task.hook.BotUserId); err != nil {
+			l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.create_post.error"), err)
+		} else if _, err := CreateWebhookPost(userId, task.hook.TeamId, task.delivery.ChannelId, text, respProps["username"], respProps["icon_url"], task.props, task.postType, nil); err != nil {
+			l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.create_post.error"), err)
+		}
+	}
+}
+
+func saveOutgoingWebhookDelivery(delivery *model.OutgoingWebhookDelivery) {
+	if result := <-Srv.Store.Webhook().UpdateOutgoingDelivery(delivery); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	}
+}
+
+func GetOutgoingWebhookDeliveriesPage(hookId string, page, perPage int) ([]*model.OutgoingWebhookDelivery, *model.AppError) {
+	if result := <-Srv.Store.Webhook().GetOutgoingDeliveriesForHook(hookId, page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.OutgoingWebhookDelivery), nil
+	}
+}
+
+// RedeliverOutgoingWebhook queues a new delivery of the payload of one of a hook's past deliveries.
+func RedeliverOutgoingWebhook(hook *model.OutgoingWebhook, deliveryId string) (*model.OutgoingWebhookDelivery, *model.AppError) {
+	var delivery *model.OutgoingWebhookDelivery
+	if result := <-Srv.Store.Webhook().GetOutgoingDelivery(deliveryId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		delivery = result.Data.(*model.OutgoingWebhookDelivery)
+	}
+
+	if delivery.HookId != hook.Id {
+		return nil, model.NewAppError("RedeliverOutgoingWebhook", "app.webhook.redeliver.hook_id.app_error", nil, "id="+deliveryId, http.StatusNotFound)
+	}
+
+	if delivery.Payload == "" {
+		return nil, model.NewAppError("RedeliverOutgoingWebhook", "app.webhook.redeliver.payload.app_error", nil, "id="+deliveryId, http.StatusBadRequest)
+	}
+
+	// the hook's callback URLs may have been changed since, so only send it to one that's still allowed
+	if !hasOutgoingWebhookCallbackURL(hook, delivery.URL) {
+		return nil, model.NewAppError("RedeliverOutgoingWebhook", "app.webhook.redeliver.url.app_error", nil, "id="+deliveryId, http.StatusBadRequest)
+	}
+
+	var props model.StringInterface
+	var postType string
+	if post, err := GetSinglePost(delivery.PostId); err == nil {
+		props = post.Props
+		postType = post.Type
+	}
+
+	redelivery := &model.OutgoingWebhookDelivery{
+		HookId:      hook.Id,
+		ChannelId:   delivery.ChannelId,
+		PostId:      delivery.PostId,
+		URL:         delivery.URL,
+		ContentType: delivery.ContentType,
+	}
+
+	return queueOutgoingWebhookDelivery(hook, redelivery, delivery.Payload, props, postType)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+
+	"github.com/mattermost/platform/app"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export data.",
+}
+
+var bulkExportCmd = &cobra.Command{
+	Use:     "bulk [file]",
+	Short:   "Export bulk data.",
+	Long:    "Export all data to a Mattermost Bulk Import File.",
+	Example: "  export bulk bulk_data.json",
+	RunE:    bulkExportCmdF,
+}
+
+func init() {
+	exportCmd.AddCommand(
+		bulkExportCmd,
+	)
+}
+
+func bulkExportCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) != 1 {
+		return errors.New("Incorrect number of arguments.")
+	}
+
+	fileWriter, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	defer fileWriter.Close()
+
+	CommandPrettyPrintln("Running Bulk Export. This may take a long time.")
+
+	writer := bufio.NewWriter(fileWriter)
+	if err := app.BulkExport(writer); err != nil {
+		CommandPrettyPrintln(err.Error())
+		return nil
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	CommandPrettyPrintln("Finished Bulk Export. Passwords aren't exported, so users who log in with email will need to reset them.")
+
+	return nil
+}
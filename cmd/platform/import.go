@@ -5,6 +5,7 @@ package main
 import (
 	"errors"
 	"os"
+	"path/filepath"
 
 	"fmt"
 	"github.com/mattermost/platform/app"
@@ -35,6 +36,9 @@ var bulkImportCmd = &cobra.Command{
 func init() {
 	bulkImportCmd.Flags().Bool("apply", false, "Save the import data to the database. Use with caution - this cannot be reverted.")
 	bulkImportCmd.Flags().Bool("validate", false, "Validate the import data without making any changes to the system.")
+	bulkImportCmd.Flags().Int("workers", 1, "How many lines of the same type to import at once.")
+	bulkImportCmd.Flags().String("checkpoint", "", "File that records the progress of the import so that it can be resumed by running the same command again.")
+	bulkImportCmd.Flags().String("rejects", "", "File to write each line that fails to import to, along with its error, instead of stopping the import.")
 
 	importCmd.AddCommand(
 		bulkImportCmd,
@@ -87,6 +91,23 @@ func bulkImportCmdF(cmd *cobra.Command, args []string) error {
 		return errors.New("Validate flag error")
 	}
 
+	workers, err := cmd.Flags().GetInt("workers")
+	if err != nil {
+		return errors.New("Workers flag error")
+	} else if workers < 1 {
+		return errors.New("Workers must be at least 1.")
+	}
+
+	checkpointPath, err := cmd.Flags().GetString("checkpoint")
+	if err != nil {
+		return errors.New("Checkpoint flag error")
+	}
+
+	rejectsPath, err := cmd.Flags().GetString("rejects")
+	if err != nil {
+		return errors.New("Rejects flag error")
+	}
+
 	if len(args) != 1 {
 		return errors.New("Incorrect number of arguments.")
 	}
@@ -97,6 +118,24 @@ func bulkImportCmdF(cmd *cobra.Command, args []string) error {
 	}
 	defer fileReader.Close()
 
+	options := &app.BulkImportOptions{
+		DryRun:         !apply,
+		Workers:        workers,
+		ImportPath:     filepath.Dir(args[0]),
+		CheckpointPath: checkpointPath,
+	}
+
+	if rejectsPath != "" {
+		// Append so that the lines rejected before an import was resumed are kept
+		rejectsFile, err := os.OpenFile(rejectsPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		defer rejectsFile.Close()
+
+		options.Rejects = rejectsFile
+	}
+
 	if apply && validate {
 		CommandPrettyPrintln("Use only one of --apply or --validate.")
 		return nil
@@ -110,10 +149,18 @@ func bulkImportCmdF(cmd *cobra.Command, args []string) error {
 
 	CommandPrettyPrintln("")
 
-	if err, lineNumber := app.BulkImport(fileReader, !apply); err != nil {
-		CommandPrettyPrintln(err.Error())
-		if lineNumber != 0 {
-			CommandPrettyPrintln(fmt.Sprintf("Error occurred on data file line %v", lineNumber))
+	result, appErr := app.BulkImportWithOptions(fileReader, options)
+	if result.Skipped > 0 {
+		CommandPrettyPrintln(fmt.Sprintf("Skipped %v lines that were imported before the checkpoint.", result.Skipped))
+	}
+	if result.Rejected > 0 {
+		CommandPrettyPrintln(fmt.Sprintf("%v lines failed to import and were written to %v.", result.Rejected, rejectsPath))
+	}
+
+	if appErr != nil {
+		CommandPrettyPrintln(appErr.Error())
+		if result.ErrorLine != 0 {
+			CommandPrettyPrintln(fmt.Sprintf("Error occurred on data file line %v", result.ErrorLine))
 		}
 	} else {
 		if apply {
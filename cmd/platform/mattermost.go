@@ -34,7 +34,7 @@ func main() {
 
 	resetCmd.Flags().Bool("confirm", false, "Confirm you really want to delete everything and a DB backup has been performed.")
 
-	rootCmd.AddCommand(serverCmd, versionCmd, userCmd, teamCmd, licenseCmd, importCmd, resetCmd, channelCmd, rolesCmd, testCmd, ldapCmd)
+	rootCmd.AddCommand(serverCmd, versionCmd, userCmd, teamCmd, licenseCmd, importCmd, exportCmd, resetCmd, channelCmd, rolesCmd, testCmd, ldapCmd)
 
 	flag.Usage = func() {
 		rootCmd.Usage()
@@ -14,6 +14,7 @@ import (
 	"github.com/mattermost/platform/api4"
 	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/jobs"
 	"github.com/mattermost/platform/manualtesting"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
@@ -100,6 +101,10 @@ func runServer(configFileLocation string) {
 	go runSecurityJob()
 	go runDiagnosticsJob()
 
+	app.InitJobs()
+	jobs.Srv.StartWorkers()
+	jobs.Srv.StartSchedulers()
+
 	if complianceI := einterfaces.GetComplianceInterface(); complianceI != nil {
 		complianceI.StartComplianceDailyJob()
 	}
@@ -126,6 +131,9 @@ func runServer(configFileLocation string) {
 		einterfaces.GetMetricsInterface().StopServer()
 	}
 
+	jobs.Srv.StopSchedulers()
+	jobs.Srv.StopWorkers()
+
 	app.StopServer()
 }
 
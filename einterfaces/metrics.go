@@ -11,6 +11,7 @@ type MetricsInterface interface {
 	IncrementWebhookPost()
 	IncrementPostSentEmail()
 	IncrementPostSentPush()
+	IncrementPushNotification(outcome string)
 	IncrementPostBroadcast()
 	IncrementPostFileAttachment(count int)
 
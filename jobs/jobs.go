@@ -0,0 +1,215 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package jobs
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	CANCEL_WATCHER_POLLING_INTERVAL = 5 * time.Second
+	JOB_HEARTBEAT_INTERVAL          = time.Minute
+	JOB_ORPHAN_TIMEOUT              = 5 * JOB_HEARTBEAT_INTERVAL
+)
+
+func CreateJob(jobType string, jobData map[string]string) (*model.Job, *model.AppError) {
+	job := &model.Job{
+		Type: jobType,
+		Data: jobData,
+	}
+
+	if result := <-Srv.Store.Job().Save(job); result.Err != nil {
+		return nil, result.Err
+	}
+
+	return job, nil
+}
+
+// CreateJobIfNoneActive creates a job unless there's already one of the same type that's unfinished or that was
+// created after the given time. It returns nil if no job was created.
+func CreateJobIfNoneActive(jobType string, jobData map[string]string, createdAfter int64) (*model.Job, *model.AppError) {
+	job := &model.Job{
+		Type: jobType,
+		Data: jobData,
+	}
+
+	if result := <-Srv.Store.Job().SaveIfNoneActive(job, createdAfter); result.Err != nil {
+		return nil, result.Err
+	} else if !result.Data.(bool) {
+		return nil, nil
+	}
+
+	return job, nil
+}
+
+// ClaimJob marks a pending job as in progress. It returns false if the job has already been claimed, which can
+// happen when several servers share the same database.
+func ClaimJob(job *model.Job) (bool, *model.AppError) {
+	if result := <-Srv.Store.Job().UpdateStatusOptimistically(job.Id, model.JOB_STATUS_PENDING, model.JOB_STATUS_IN_PROGRESS); result.Err != nil {
+		return false, result.Err
+	} else if !result.Data.(bool) {
+		return false, nil
+	}
+
+	job.Status = model.JOB_STATUS_IN_PROGRESS
+	return true, nil
+}
+
+func SetJobProgress(job *model.Job, progress int64) *model.AppError {
+	job.Status = model.JOB_STATUS_IN_PROGRESS
+	job.Progress = progress
+
+	if result := <-Srv.Store.Job().UpdateOptimistically(job, model.JOB_STATUS_IN_PROGRESS); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func SetJobSuccess(job *model.Job) *model.AppError {
+	job.Status = model.JOB_STATUS_SUCCESS
+	job.Progress = 100
+
+	return updateFinishedJob(job)
+}
+
+func SetJobError(job *model.Job, jobError *model.AppError) *model.AppError {
+	job.Status = model.JOB_STATUS_ERROR
+	job.LastError = jobError.Error()
+
+	return updateFinishedJob(job)
+}
+
+func SetJobCanceled(job *model.Job) *model.AppError {
+	job.Status = model.JOB_STATUS_CANCELED
+
+	return updateFinishedJob(job)
+}
+
+// updateFinishedJob saves a job that was running, including one that was asked to stop after it had already
+// finished its work.
+func updateFinishedJob(job *model.Job) *model.AppError {
+	for _, currentStatus := range []string{model.JOB_STATUS_IN_PROGRESS, model.JOB_STATUS_CANCEL_REQUESTED} {
+		if result := <-Srv.Store.Job().UpdateOptimistically(job, currentStatus); result.Err != nil {
+			return result.Err
+		} else if result.Data.(bool) {
+			return nil
+		}
+	}
+
+	return model.NewAppError("Jobs.updateFinishedJob", "jobs.update_finished_job.not_running.app_error", nil, "id="+job.Id, http.StatusInternalServerError)
+}
+
+// isOrphaned returns true if a job is still marked as running but the server running it hasn't reported any activity
+// for a while, which happens when that server crashed or was restarted.
+func isOrphaned(job *model.Job, now time.Time) bool {
+	if job.Status != model.JOB_STATUS_IN_PROGRESS && job.Status != model.JOB_STATUS_CANCEL_REQUESTED {
+		return false
+	}
+
+	return time.Duration(job.LastActivityAt)*time.Millisecond+JOB_ORPHAN_TIMEOUT < time.Duration(now.UnixNano())
+}
+
+// FailOrphanedJob marks an orphaned job as failed so that it doesn't stop new jobs of its type from being scheduled.
+// It returns true if the job was orphaned and has been failed.
+func FailOrphanedJob(job *model.Job, now time.Time) (bool, *model.AppError) {
+	if !isOrphaned(job, now) {
+		return false, nil
+	}
+
+	currentStatus := job.Status
+	job.Status = model.JOB_STATUS_ERROR
+	job.LastError = utils.T("jobs.orphaned.error")
+
+	if result := <-Srv.Store.Job().UpdateOptimistically(job, currentStatus); result.Err != nil {
+		return false, result.Err
+	} else {
+		return result.Data.(bool), nil
+	}
+}
+
+// FailOrphanedJobs fails every job that was left running by a server that went away.
+func FailOrphanedJobs(now time.Time) *model.AppError {
+	for _, status := range []string{model.JOB_STATUS_IN_PROGRESS, model.JOB_STATUS_CANCEL_REQUESTED} {
+		result := <-Srv.Store.Job().GetAllByStatus(status)
+		if result.Err != nil {
+			return result.Err
+		}
+
+		for _, job := range result.Data.([]*model.Job) {
+			if failed, err := FailOrphanedJob(job, now); err != nil {
+				return err
+			} else if failed {
+				l4g.Warn(utils.T("jobs.orphaned.warn"), job.Type, job.Id)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Heartbeat regularly records that a job is still running until done is closed.
+func Heartbeat(jobId string, done <-chan interface{}) {
+	ticker := time.NewTicker(JOB_HEARTBEAT_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if result := <-Srv.Store.Job().UpdateLastActivityAt(jobId); result.Err != nil {
+				l4g.Error(result.Err.Error())
+			}
+		}
+	}
+}
+
+// RequestCancellation cancels a pending job straight away, or asks the server running an in progress job to stop it.
+// Requesting cancellation a second time marks the job as canceled even if no server acknowledged the first request,
+// which clears jobs left behind by a server that went away.
+func RequestCancellation(jobId string) *model.AppError {
+	for _, currentStatus := range []string{model.JOB_STATUS_PENDING, model.JOB_STATUS_CANCEL_REQUESTED} {
+		if result := <-Srv.Store.Job().UpdateStatusOptimistically(jobId, currentStatus, model.JOB_STATUS_CANCELED); result.Err != nil {
+			return result.Err
+		} else if result.Data.(bool) {
+			return nil
+		}
+	}
+
+	if result := <-Srv.Store.Job().UpdateStatusOptimistically(jobId, model.JOB_STATUS_IN_PROGRESS, model.JOB_STATUS_CANCEL_REQUESTED); result.Err != nil {
+		return result.Err
+	} else if result.Data.(bool) {
+		return nil
+	}
+
+	return model.NewAppError("Jobs.RequestCancellation", "jobs.request_cancellation.status.app_error", nil, "id="+jobId, http.StatusBadRequest)
+}
+
+// CancellationWatcher closes cancel once a cancellation has been requested for the job. It returns when that
+// happens or when done is closed.
+func CancellationWatcher(jobId string, cancel chan<- interface{}, done <-chan interface{}) {
+	ticker := time.NewTicker(CANCEL_WATCHER_POLLING_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if result := <-Srv.Store.Job().Get(jobId); result.Err == nil {
+				if result.Data.(*model.Job).Status == model.JOB_STATUS_CANCEL_REQUESTED {
+					close(cancel)
+					return
+				}
+			}
+		}
+	}
+}
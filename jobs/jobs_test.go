@@ -0,0 +1,205 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+func Setup() {
+	if Srv.Store == nil {
+		utils.TranslationsPreInit()
+		utils.LoadConfig("config.json")
+		utils.InitTranslations(utils.Cfg.LocalizationSettings)
+		Srv.Store = store.NewSqlStore()
+	}
+}
+
+func TestClaimJob(t *testing.T) {
+	Setup()
+
+	job, err := CreateJob(model.JOB_TYPE_DATA_RETENTION, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Srv.Store.Job().Delete(job.Id)
+
+	if claimed, err := ClaimJob(job); err != nil {
+		t.Fatal(err)
+	} else if !claimed || job.Status != model.JOB_STATUS_IN_PROGRESS {
+		t.Fatal("should've claimed the job")
+	}
+
+	if claimed, err := ClaimJob(job); err != nil {
+		t.Fatal(err)
+	} else if claimed {
+		t.Fatal("shouldn't be able to claim the job twice")
+	}
+
+	if err := SetJobProgress(job, 50); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetJobSuccess(job); err != nil {
+		t.Fatal(err)
+	}
+
+	if received := store.Must(Srv.Store.Job().Get(job.Id)).(*model.Job); received.Status != model.JOB_STATUS_SUCCESS || received.Progress != 100 {
+		t.Fatal("job should've succeeded")
+	}
+}
+
+func TestSetJobError(t *testing.T) {
+	Setup()
+
+	job, _ := CreateJob(model.JOB_TYPE_DATA_RETENTION, nil)
+	defer Srv.Store.Job().Delete(job.Id)
+
+	ClaimJob(job)
+
+	if err := SetJobError(job, model.NewAppError("TestSetJobError", "test.error", nil, "", 500)); err != nil {
+		t.Fatal(err)
+	}
+
+	if received := store.Must(Srv.Store.Job().Get(job.Id)).(*model.Job); received.Status != model.JOB_STATUS_ERROR || received.LastError == "" {
+		t.Fatal("job should've failed with an error")
+	}
+}
+
+func TestRequestCancellation(t *testing.T) {
+	Setup()
+
+	pendingJob, _ := CreateJob(model.JOB_TYPE_DATA_RETENTION, nil)
+	defer Srv.Store.Job().Delete(pendingJob.Id)
+
+	if err := RequestCancellation(pendingJob.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if received := store.Must(Srv.Store.Job().Get(pendingJob.Id)).(*model.Job); received.Status != model.JOB_STATUS_CANCELED {
+		t.Fatal("pending job should've been canceled straight away")
+	}
+
+	if err := RequestCancellation(pendingJob.Id); err == nil {
+		t.Fatal("shouldn't be able to cancel a finished job")
+	}
+
+	runningJob, _ := CreateJob(model.JOB_TYPE_DATA_RETENTION, nil)
+	defer Srv.Store.Job().Delete(runningJob.Id)
+
+	ClaimJob(runningJob)
+
+	if err := RequestCancellation(runningJob.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if received := store.Must(Srv.Store.Job().Get(runningJob.Id)).(*model.Job); received.Status != model.JOB_STATUS_CANCEL_REQUESTED {
+		t.Fatal("running job should've been asked to stop")
+	}
+
+	if err := SetJobCanceled(runningJob); err != nil {
+		t.Fatal(err)
+	}
+
+	if received := store.Must(Srv.Store.Job().Get(runningJob.Id)).(*model.Job); received.Status != model.JOB_STATUS_CANCELED {
+		t.Fatal("running job should've been canceled")
+	}
+}
+
+func TestSimpleWorker(t *testing.T) {
+	Setup()
+
+	ran := false
+	worker := NewSimpleWorker("TestSimpleWorker", func(job *model.Job, cancel <-chan interface{}) *model.AppError {
+		ran = true
+		return nil
+	})
+
+	job, _ := CreateJob(model.JOB_TYPE_DATA_RETENTION, nil)
+	defer Srv.Store.Job().Delete(job.Id)
+
+	worker.DoJob(job)
+
+	if !ran {
+		t.Fatal("worker should've run the job")
+	}
+
+	if received := store.Must(Srv.Store.Job().Get(job.Id)).(*model.Job); received.Status != model.JOB_STATUS_SUCCESS {
+		t.Fatal("job should've succeeded")
+	}
+}
+
+func TestNextRunDelayAfter(t *testing.T) {
+	now := time.Now()
+	interval := 24 * time.Hour
+
+	if delay := nextRunDelayAfter(nil, interval, now); delay != 0 {
+		t.Fatal("should run straight away when there are no previous jobs")
+	}
+
+	lastJob := &model.Job{
+		Status:   model.JOB_STATUS_SUCCESS,
+		CreateAt: now.Add(-interval).UnixNano() / int64(time.Millisecond),
+	}
+	if delay := nextRunDelayAfter(lastJob, interval, now); delay != 0 {
+		t.Fatal("should run straight away when the interval has passed")
+	}
+
+	lastJob.CreateAt = now.Add(-interval+time.Second).UnixNano() / int64(time.Millisecond)
+	if delay := nextRunDelayAfter(lastJob, interval, now); delay <= 0 || delay > time.Second {
+		t.Fatal("should wait for the rest of the interval", delay)
+	}
+
+	lastJob.CreateAt = now.UnixNano() / int64(time.Millisecond)
+	if delay := nextRunDelayAfter(lastJob, interval, now); delay != SCHEDULER_POLLING_INTERVAL {
+		t.Fatal("shouldn't wait longer than the polling interval")
+	}
+
+	lastJob.Status = model.JOB_STATUS_PENDING
+	lastJob.CreateAt = 0
+	if delay := nextRunDelayAfter(lastJob, interval, now); delay != SCHEDULER_POLLING_INTERVAL {
+		t.Fatal("shouldn't schedule another job while the last one is pending")
+	}
+
+	lastJob.Status = model.JOB_STATUS_IN_PROGRESS
+	lastJob.LastActivityAt = now.UnixNano() / int64(time.Millisecond)
+	if delay := nextRunDelayAfter(lastJob, interval, now); delay != SCHEDULER_POLLING_INTERVAL {
+		t.Fatal("shouldn't schedule another job while the last one is running")
+	}
+
+	lastJob.LastActivityAt = now.Add(-JOB_ORPHAN_TIMEOUT-time.Second).UnixNano() / int64(time.Millisecond)
+	if delay := nextRunDelayAfter(lastJob, interval, now); delay != 0 {
+		t.Fatal("should replace a job left running by a server that went away")
+	}
+}
+
+func TestFailOrphanedJobs(t *testing.T) {
+	Setup()
+
+	job, _ := CreateJob(model.JOB_TYPE_DATA_RETENTION, nil)
+	defer Srv.Store.Job().Delete(job.Id)
+	ClaimJob(job)
+
+	if err := FailOrphanedJobs(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if received := store.Must(Srv.Store.Job().Get(job.Id)).(*model.Job); received.Status != model.JOB_STATUS_IN_PROGRESS {
+		t.Fatal("shouldn't fail a job that's still running")
+	}
+
+	// no heartbeats are sent for the job, so it's orphaned once the timeout has passed
+	if err := FailOrphanedJobs(time.Now().Add(JOB_ORPHAN_TIMEOUT + time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	if received := store.Must(Srv.Store.Job().Get(job.Id)).(*model.Job); received.Status != model.JOB_STATUS_ERROR || received.LastError == "" {
+		t.Fatal("should've failed the orphaned job")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package jobs
+
+import (
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	SCHEDULER_POLLING_INTERVAL = time.Minute
+	JOB_HISTORY_RETENTION      = 7 * 24 * time.Hour
+)
+
+// PeriodicScheduler creates a job of the given type whenever the interval has passed since the last one was created.
+// Since the time of the last job is read from the database, the schedule survives restarts and is shared by every
+// server in a cluster.
+type PeriodicScheduler struct {
+	jobType  string
+	interval time.Duration
+	enabled  func() bool
+	stop     chan bool
+	stopped  chan bool
+}
+
+func NewPeriodicScheduler(jobType string, interval time.Duration, enabled func() bool) *PeriodicScheduler {
+	return &PeriodicScheduler{
+		jobType:  jobType,
+		interval: interval,
+		enabled:  enabled,
+		stop:     make(chan bool, 1),
+		stopped:  make(chan bool, 1),
+	}
+}
+
+func (scheduler *PeriodicScheduler) Run() {
+	l4g.Debug(utils.T("jobs.scheduler.starting"), scheduler.jobType)
+
+	defer func() {
+		l4g.Debug(utils.T("jobs.scheduler.stopped"), scheduler.jobType)
+		scheduler.stopped <- true
+	}()
+
+	delay := scheduler.nextRunDelay(time.Now())
+
+	for {
+		select {
+		case <-scheduler.stop:
+			return
+		case <-time.After(delay):
+			if err := scheduler.scheduleJobIfDue(time.Now()); err != nil {
+				l4g.Error(utils.T("jobs.scheduler.schedule_job.error"), scheduler.jobType, err.Error())
+				delay = SCHEDULER_POLLING_INTERVAL
+			} else {
+				delay = scheduler.nextRunDelay(time.Now())
+			}
+		}
+	}
+}
+
+func (scheduler *PeriodicScheduler) Stop() {
+	scheduler.stop <- true
+	<-scheduler.stopped
+}
+
+// nextRunDelay returns how long to wait before checking whether a job is due. It never waits longer than the polling
+// interval so that configuration changes and jobs created by other servers are noticed.
+func (scheduler *PeriodicScheduler) nextRunDelay(now time.Time) time.Duration {
+	if !scheduler.enabled() {
+		return SCHEDULER_POLLING_INTERVAL
+	}
+
+	result := <-Srv.Store.Job().GetNewestJobByType(scheduler.jobType)
+	if result.Err != nil {
+		return SCHEDULER_POLLING_INTERVAL
+	}
+
+	return nextRunDelayAfter(result.Data.(*model.Job), scheduler.interval, now)
+}
+
+func nextRunDelayAfter(lastJob *model.Job, interval time.Duration, now time.Time) time.Duration {
+	if lastJob == nil {
+		return 0
+	} else if !lastJob.IsFinished() {
+		if isOrphaned(lastJob, now) {
+			// the last job needs to be failed before another can be scheduled
+			return 0
+		}
+
+		// don't pile up jobs while the previous one is still waiting to run or running
+		return SCHEDULER_POLLING_INTERVAL
+	}
+
+	delay := time.Duration(lastJob.CreateAt)*time.Millisecond + interval - time.Duration(now.UnixNano())
+	if delay < 0 {
+		return 0
+	} else if delay > SCHEDULER_POLLING_INTERVAL {
+		return SCHEDULER_POLLING_INTERVAL
+	}
+
+	return delay
+}
+
+func (scheduler *PeriodicScheduler) scheduleJobIfDue(now time.Time) *model.AppError {
+	if !scheduler.enabled() {
+		return nil
+	}
+
+	result := <-Srv.Store.Job().GetNewestJobByType(scheduler.jobType)
+	if result.Err != nil {
+		return result.Err
+	}
+
+	lastJob := result.Data.(*model.Job)
+	if nextRunDelayAfter(lastJob, scheduler.interval, now) > 0 {
+		return nil
+	}
+
+	if lastJob != nil && !lastJob.IsFinished() {
+		if failed, err := FailOrphanedJob(lastJob, now); err != nil {
+			return err
+		} else if !failed {
+			return nil
+		}
+	}
+
+	// another server may have scheduled a job since the last one was read, so it's only created if there still isn't one
+	createdAfter := model.GetMillis() - int64(scheduler.interval/time.Millisecond)
+	job, err := CreateJobIfNoneActive(scheduler.jobType, nil, createdAfter)
+	if err != nil {
+		return err
+	} else if job == nil {
+		return nil
+	}
+
+	// start the job straight away if this server can run it instead of waiting for the next poll
+	Srv.notifyWorker(job)
+
+	// frequent jobs would otherwise fill up the table, so only their recent history is kept
+	before := model.GetMillis() - int64(JOB_HISTORY_RETENTION/time.Millisecond)
+	if result := <-Srv.Store.Job().PermanentDeleteFinishedByTypeBefore(scheduler.jobType, before); result.Err != nil {
+		l4g.Warn(utils.T("jobs.scheduler.delete_old_jobs.warn"), scheduler.jobType, result.Err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package jobs
+
+import (
+	"sync"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	WATCHER_POLLING_INTERVAL = 15 * time.Second
+)
+
+type Worker interface {
+	Run()
+	Stop()
+	JobChannel() chan<- model.Job
+}
+
+type Scheduler interface {
+	Run()
+	Stop()
+}
+
+type JobServer struct {
+	Store store.Store
+
+	mutex      sync.Mutex
+	workers    map[string]Worker
+	schedulers map[string]Scheduler
+
+	watcherStop    chan bool
+	watcherStopped chan bool
+
+	schedulersRunning bool
+}
+
+var Srv = JobServer{
+	workers:    make(map[string]Worker),
+	schedulers: make(map[string]Scheduler),
+}
+
+func (srv *JobServer) RegisterWorker(jobType string, worker Worker) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.workers[jobType] = worker
+}
+
+func (srv *JobServer) RegisterScheduler(jobType string, scheduler Scheduler) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.schedulers[jobType] = scheduler
+}
+
+// StartWorkers starts every registered worker along with a watcher that hands pending jobs from the database to them.
+func (srv *JobServer) StartWorkers() {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if srv.watcherStop != nil {
+		return
+	}
+
+	l4g.Info(utils.T("jobs.workers.starting"))
+
+	for _, worker := range srv.workers {
+		go worker.Run()
+	}
+
+	srv.watcherStop = make(chan bool)
+	srv.watcherStopped = make(chan bool)
+	go srv.watch(srv.watcherStop, srv.watcherStopped)
+}
+
+func (srv *JobServer) StopWorkers() {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if srv.watcherStop == nil {
+		return
+	}
+
+	close(srv.watcherStop)
+	<-srv.watcherStopped
+	srv.watcherStop = nil
+
+	for _, worker := range srv.workers {
+		worker.Stop()
+	}
+
+	l4g.Info(utils.T("jobs.workers.stopped"))
+}
+
+func (srv *JobServer) StartSchedulers() {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if srv.schedulersRunning {
+		return
+	}
+
+	l4g.Info(utils.T("jobs.schedulers.starting"))
+
+	for _, scheduler := range srv.schedulers {
+		go scheduler.Run()
+	}
+
+	srv.schedulersRunning = true
+}
+
+// StopSchedulers stops the schedulers if they were started. Stopping one that isn't running would wait forever.
+func (srv *JobServer) StopSchedulers() {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if !srv.schedulersRunning {
+		return
+	}
+
+	srv.schedulersRunning = false
+
+	for _, scheduler := range srv.schedulers {
+		scheduler.Stop()
+	}
+
+	l4g.Info(utils.T("jobs.schedulers.stopped"))
+}
+
+func (srv *JobServer) watch(stop <-chan bool, stopped chan<- bool) {
+	defer close(stopped)
+
+	// check for jobs straight away in case any were left pending while the server was down
+	srv.pollAndNotify()
+
+	ticker := time.NewTicker(WATCHER_POLLING_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			srv.pollAndNotify()
+		}
+	}
+}
+
+func (srv *JobServer) pollAndNotify() {
+	// jobs left running by a server that crashed or was restarted would otherwise stay in progress forever
+	if err := FailOrphanedJobs(time.Now()); err != nil {
+		l4g.Error(utils.T("jobs.watcher.fail_orphaned_jobs.error"), err.Error())
+	}
+
+	result := <-srv.Store.Job().GetAllByStatus(model.JOB_STATUS_PENDING)
+	if result.Err != nil {
+		l4g.Error(utils.T("jobs.watcher.get_pending_jobs.error"), result.Err.Error())
+		return
+	}
+
+	for _, job := range result.Data.([]*model.Job) {
+		srv.notifyWorker(job)
+	}
+}
+
+// notifyWorker hands a pending job to the worker for its type if this server has one that's running and isn't busy.
+// Otherwise the job is left pending until the next poll.
+func (srv *JobServer) notifyWorker(job *model.Job) {
+	if worker, ok := srv.workers[job.Type]; ok {
+		select {
+		case worker.JobChannel() <- *job:
+		default:
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package jobs
+
+import (
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// JobFunc does the work for a single job. cancel is closed if a cancellation is requested while it runs.
+type JobFunc func(job *model.Job, cancel <-chan interface{}) *model.AppError
+
+// SimpleWorker runs one job at a time with the given function and records the outcome on the job.
+type SimpleWorker struct {
+	name    string
+	run     JobFunc
+	jobs    chan model.Job
+	stop    chan bool
+	stopped chan bool
+}
+
+func NewSimpleWorker(name string, run JobFunc) *SimpleWorker {
+	return &SimpleWorker{
+		name:    name,
+		run:     run,
+		jobs:    make(chan model.Job),
+		stop:    make(chan bool, 1),
+		stopped: make(chan bool, 1),
+	}
+}
+
+func (worker *SimpleWorker) Run() {
+	l4g.Debug(utils.T("jobs.worker.starting"), worker.name)
+
+	defer func() {
+		l4g.Debug(utils.T("jobs.worker.stopped"), worker.name)
+		worker.stopped <- true
+	}()
+
+	for {
+		select {
+		case <-worker.stop:
+			return
+		case job := <-worker.jobs:
+			worker.DoJob(&job)
+		}
+	}
+}
+
+func (worker *SimpleWorker) Stop() {
+	worker.stop <- true
+	<-worker.stopped
+}
+
+func (worker *SimpleWorker) JobChannel() chan<- model.Job {
+	return worker.jobs
+}
+
+func (worker *SimpleWorker) DoJob(job *model.Job) {
+	if claimed, err := ClaimJob(job); err != nil {
+		l4g.Error(utils.T("jobs.worker.claim_job.error"), worker.name, job.Id, err.Error())
+		return
+	} else if !claimed {
+		return
+	}
+
+	cancel := make(chan interface{})
+	done := make(chan interface{})
+	go CancellationWatcher(job.Id, cancel, done)
+	go Heartbeat(job.Id, done)
+
+	err := worker.run(job, cancel)
+	close(done)
+
+	select {
+	case <-cancel:
+		if err := SetJobCanceled(job); err != nil {
+			l4g.Error(utils.T("jobs.worker.set_job_status.error"), worker.name, job.Id, err.Error())
+		}
+		return
+	default:
+	}
+
+	if err != nil {
+		l4g.Error(utils.T("jobs.worker.job_failed.error"), worker.name, job.Id, err.Error())
+		if err := SetJobError(job, err); err != nil {
+			l4g.Error(utils.T("jobs.worker.set_job_status.error"), worker.name, job.Id, err.Error())
+		}
+	} else if err := SetJobSuccess(job); err != nil {
+		l4g.Error(utils.T("jobs.worker.set_job_status.error"), worker.name, job.Id, err.Error())
+	}
+}
@@ -134,6 +134,14 @@ func (c *Client4) GetPostRoute(postId string) string {
 	return fmt.Sprintf(c.GetPostsRoute()+"/%v", postId)
 }
 
+func (c *Client4) GetScheduledPostsRoute() string {
+	return fmt.Sprintf("/scheduled_posts")
+}
+
+func (c *Client4) GetScheduledPostRoute(scheduledPostId string) string {
+	return fmt.Sprintf(c.GetScheduledPostsRoute()+"/%v", scheduledPostId)
+}
+
 func (c *Client4) GetFilesRoute() string {
 	return fmt.Sprintf("/files")
 }
@@ -150,6 +158,10 @@ func (c *Client4) GetTestEmailRoute() string {
 	return fmt.Sprintf("/email/test")
 }
 
+func (c *Client4) GetTestS3Route() string {
+	return fmt.Sprintf("/file/s3_test")
+}
+
 func (c *Client4) GetDatabaseRoute() string {
 	return fmt.Sprintf("/database")
 }
@@ -182,6 +194,10 @@ func (c *Client4) GetOutgoingWebhooksRoute() string {
 	return fmt.Sprintf("/hooks/outgoing")
 }
 
+func (c *Client4) GetOutgoingWebhookRoute(hookID string) string {
+	return fmt.Sprintf(c.GetOutgoingWebhooksRoute()+"/%v", hookID)
+}
+
 func (c *Client4) GetPreferencesRoute(userId string) string {
 	return fmt.Sprintf(c.GetUserRoute(userId) + "/preferences")
 }
@@ -198,6 +214,50 @@ func (c *Client4) GetBrandRoute() string {
 	return fmt.Sprintf("/brand")
 }
 
+func (c *Client4) GetJobsRoute() string {
+	return fmt.Sprintf("/jobs")
+}
+
+func (c *Client4) GetJobRoute(jobId string) string {
+	return fmt.Sprintf(c.GetJobsRoute()+"/%v", jobId)
+}
+
+func (c *Client4) GetBotsRoute() string {
+	return fmt.Sprintf("/bots")
+}
+
+func (c *Client4) GetBotRoute(botUserId string) string {
+	return fmt.Sprintf(c.GetBotsRoute()+"/%v", botUserId)
+}
+
+func (c *Client4) GetCommandsRoute() string {
+	return fmt.Sprintf("/commands")
+}
+
+func (c *Client4) GetCommandRoute(commandId string) string {
+	return fmt.Sprintf(c.GetCommandsRoute()+"/%v", commandId)
+}
+
+func (c *Client4) GetEmojisRoute() string {
+	return fmt.Sprintf("/emoji")
+}
+
+func (c *Client4) GetEmojiRoute(emojiId string) string {
+	return fmt.Sprintf(c.GetEmojisRoute()+"/%v", emojiId)
+}
+
+func (c *Client4) GetReactionsRoute() string {
+	return fmt.Sprintf("/reactions")
+}
+
+func (c *Client4) GetOAuthAppsRoute() string {
+	return fmt.Sprintf("/oauth/apps")
+}
+
+func (c *Client4) GetOAuthAppRoute(appId string) string {
+	return fmt.Sprintf(c.GetOAuthAppsRoute()+"/%v", appId)
+}
+
 func (c *Client4) DoApiGet(url string, etag string) (*http.Response, *AppError) {
 	return c.DoApiRequest(http.MethodGet, url, "", etag)
 }
@@ -585,6 +645,64 @@ func (c *Client4) RevokeSession(userId, sessionId string) (bool, *Response) {
 	}
 }
 
+// CreateUserAccessToken will generate a user access token that can be used in place of a session token to access
+// the REST API. The token is only returned in this response and can't be retrieved again.
+func (c *Client4) CreateUserAccessToken(userId, description string) (*UserAccessToken, *Response) {
+	requestBody := map[string]string{"description": description}
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/tokens", MapToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserAccessTokenFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetUserAccessToken will get a user access token's id, description and the user_id of the user it is for.
+// The actual token will not be returned.
+func (c *Client4) GetUserAccessToken(tokenId string) (*UserAccessToken, *Response) {
+	if r, err := c.DoApiGet(c.GetUsersRoute()+"/tokens/"+tokenId, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserAccessTokenFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetUserAccessTokensForUser will get a page of user access tokens for a user. The actual tokens will not be
+// returned.
+func (c *Client4) GetUserAccessTokensForUser(userId string, page, perPage int) ([]*UserAccessToken, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/tokens"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserAccessTokenListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetUserAccessTokens will get a page of user access tokens for every user on the system. The actual tokens will
+// not be returned. Must have the manage_system permission.
+func (c *Client4) GetUserAccessTokens(page, perPage int) ([]*UserAccessToken, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetUsersRoute()+"/tokens"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserAccessTokenListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RevokeUserAccessToken will revoke a user access token by id.
+func (c *Client4) RevokeUserAccessToken(tokenId string) (bool, *Response) {
+	requestBody := map[string]string{"token_id": tokenId}
+	if r, err := c.DoApiPost(c.GetUsersRoute()+"/tokens/revoke", MapToJson(requestBody)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
 // GetTeamsUnreadForUser will return an array with TeamUnread objects that contain the amount
 // of unread messages and mentions the current user has for the teams it belongs to.
 // An optional team ID can be set to exclude that team from the results. Must be authenticated.
@@ -658,6 +776,69 @@ func (c *Client4) SetProfileImage(userId string, data []byte) (bool, *Response)
 	}
 }
 
+// Bot Section
+
+// CreateBot creates a bot account owned by the current user.
+func (c *Client4) CreateBot(bot *User) (*User, *Response) {
+	if r, err := c.DoApiPost(c.GetBotsRoute(), bot.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetBot gets a bot account.
+func (c *Client4) GetBot(botUserId string) (*User, *Response) {
+	if r, err := c.DoApiGet(c.GetBotRoute(botUserId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetBots gets a page of bots. Users that can't manage every bot only get the bots they own.
+func (c *Client4) GetBots(page int, perPage int, includeDeleted bool) ([]*User, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v&include_deleted=%v", page, perPage, includeDeleted)
+	if r, err := c.DoApiGet(c.GetBotsRoute()+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DisableBot deactivates a bot account.
+func (c *Client4) DisableBot(botUserId string) (*User, *Response) {
+	if r, err := c.DoApiPost(c.GetBotRoute(botUserId)+"/disable", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// EnableBot reactivates a bot account.
+func (c *Client4) EnableBot(botUserId string) (*User, *Response) {
+	if r, err := c.DoApiPost(c.GetBotRoute(botUserId)+"/enable", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// AssignBot transfers the ownership of a bot to another user.
+func (c *Client4) AssignBot(botUserId, ownerId string) (*User, *Response) {
+	if r, err := c.DoApiPost(c.GetBotRoute(botUserId)+"/assign/"+ownerId, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // Team Section
 
 // CreateTeam creates a team in the system based on the provided team struct.
@@ -988,6 +1169,29 @@ func (c *Client4) DeletePost(postId string) (bool, *Response) {
 	}
 }
 
+// DoPostAction performs an interactive message action on a post. The selected option is only used by select menus
+// and may be empty.
+func (c *Client4) DoPostAction(postId, actionId, selectedOption string) (bool, *Response) {
+	requestBody := map[string]string{"selected_option": selectedOption}
+	if r, err := c.DoApiPost(c.GetPostRoute(postId)+"/actions/"+actionId, MapToJson(requestBody)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetPostPushNotification gets the full contents of the push notification for a post, for devices that were sent
+// an id loaded push notification.
+func (c *Client4) GetPostPushNotification(postId string) (*PushNotification, *Response) {
+	if r, err := c.DoApiGet(c.GetPostRoute(postId)+"/push_notification", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PushNotificationFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // GetPostThread gets a post with all the other posts in the same thread.
 func (c *Client4) GetPostThread(postId string, etag string) (*PostList, *Response) {
 	if r, err := c.DoApiGet(c.GetPostRoute(postId)+"/thread", etag); err != nil {
@@ -1020,6 +1224,59 @@ func (c *Client4) SearchPosts(teamId string, terms string, isOrSearch bool) (*Po
 	}
 }
 
+// Scheduled Posts Section
+
+// CreateScheduledPost schedules a message to be posted at a later time, optionally on a recurring schedule.
+func (c *Client4) CreateScheduledPost(scheduledPost *ScheduledPost) (*ScheduledPost, *Response) {
+	if r, err := c.DoApiPost(c.GetScheduledPostsRoute(), scheduledPost.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ScheduledPostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetScheduledPost gets a post that is waiting to be sent.
+func (c *Client4) GetScheduledPost(scheduledPostId string) (*ScheduledPost, *Response) {
+	if r, err := c.DoApiGet(c.GetScheduledPostRoute(scheduledPostId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ScheduledPostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetScheduledPostsForUser gets a page of a user's scheduled posts, ordered by when they will next be sent.
+func (c *Client4) GetScheduledPostsForUser(userId string, page int, perPage int) ([]*ScheduledPost, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/scheduled_posts"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ScheduledPostListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateScheduledPost changes the message, time or recurrence of a scheduled post.
+func (c *Client4) UpdateScheduledPost(scheduledPost *ScheduledPost) (*ScheduledPost, *Response) {
+	if r, err := c.DoApiPut(c.GetScheduledPostRoute(scheduledPost.Id), scheduledPost.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ScheduledPostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteScheduledPost cancels a scheduled post.
+func (c *Client4) DeleteScheduledPost(scheduledPostId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetScheduledPostRoute(scheduledPostId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
 // File Section
 
 // UploadFile will upload a file to a channel, to be later attached to a post.
@@ -1027,18 +1284,19 @@ func (c *Client4) UploadFile(data []byte, channelId string, filename string) (*F
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
-	if part, err := writer.CreateFormFile("files", filename); err != nil {
-		return nil, &Response{Error: NewAppError("UploadPostAttachment", "model.client.upload_post_attachment.file.app_error", nil, err.Error(), http.StatusBadRequest)}
-	} else if _, err = io.Copy(part, bytes.NewBuffer(data)); err != nil {
-		return nil, &Response{Error: NewAppError("UploadPostAttachment", "model.client.upload_post_attachment.file.app_error", nil, err.Error(), http.StatusBadRequest)}
-	}
-
+	// The channel is sent first so that the server can stream the file straight into storage
 	if part, err := writer.CreateFormField("channel_id"); err != nil {
 		return nil, &Response{Error: NewAppError("UploadPostAttachment", "model.client.upload_post_attachment.channel_id.app_error", nil, err.Error(), http.StatusBadRequest)}
 	} else if _, err = io.Copy(part, strings.NewReader(channelId)); err != nil {
 		return nil, &Response{Error: NewAppError("UploadPostAttachment", "model.client.upload_post_attachment.channel_id.app_error", nil, err.Error(), http.StatusBadRequest)}
 	}
 
+	if part, err := writer.CreateFormFile("files", filename); err != nil {
+		return nil, &Response{Error: NewAppError("UploadPostAttachment", "model.client.upload_post_attachment.file.app_error", nil, err.Error(), http.StatusBadRequest)}
+	} else if _, err = io.Copy(part, bytes.NewBuffer(data)); err != nil {
+		return nil, &Response{Error: NewAppError("UploadPostAttachment", "model.client.upload_post_attachment.file.app_error", nil, err.Error(), http.StatusBadRequest)}
+	}
+
 	if err := writer.Close(); err != nil {
 		return nil, &Response{Error: NewAppError("UploadPostAttachment", "model.client.upload_post_attachment.writer.app_error", nil, err.Error(), http.StatusBadRequest)}
 	}
@@ -1129,6 +1387,17 @@ func (c *Client4) TestEmail() (bool, *Response) {
 	}
 }
 
+// TestS3Connection will attempt to connect to the file storage described by the
+// FileSettings of the provided config.
+func (c *Client4) TestS3Connection(config *Config) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetTestS3Route(), config.ToJson()); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
 // GetConfig will retrieve the server config with some sanitized items.
 func (c *Client4) GetConfig() (*Config, *Response) {
 	if r, err := c.DoApiGet(c.GetConfigRoute(), ""); err != nil {
@@ -1211,6 +1480,17 @@ func (c *Client4) GetIncomingWebhook(hookID string, etag string) (*IncomingWebho
 	}
 }
 
+// TestIncomingWebhook renders a sample payload the way that the incoming webhook would, without posting it.
+func (c *Client4) TestIncomingWebhook(hookID string, payload string) (*IncomingWebhookRequest, *Response) {
+	if r, err := c.DoApiPost(c.GetIncomingWebhookRoute(hookID)+"/test", payload); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		req, _ := IncomingWebhookRequestFromJson(r.Body)
+		return req, BuildResponse(r)
+	}
+}
+
 // DeleteIncomingWebhook deletes and Incoming Webhook given the hook ID
 func (c *Client4) DeleteIncomingWebhook(hookID string) (bool, *Response) {
 	if r, err := c.DoApiDelete(c.GetIncomingWebhookRoute(hookID)); err != nil {
@@ -1264,6 +1544,29 @@ func (c *Client4) GetOutgoingWebhooksForTeam(teamId string, page int, perPage in
 	}
 }
 
+// GetOutgoingWebhookDeliveries returns a page of the most recent deliveries of an outgoing webhook. Page counting
+// starts at 0.
+func (c *Client4) GetOutgoingWebhookDeliveries(hookID string, page int, perPage int) ([]*OutgoingWebhookDelivery, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetOutgoingWebhookRoute(hookID)+"/deliveries"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return OutgoingWebhookDeliveryListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RedeliverOutgoingWebhook sends the payload of a past delivery of an outgoing webhook again, returning the new
+// delivery.
+func (c *Client4) RedeliverOutgoingWebhook(hookID string, deliveryID string) (*OutgoingWebhookDelivery, *Response) {
+	if r, err := c.DoApiPost(c.GetOutgoingWebhookRoute(hookID)+"/deliveries/"+deliveryID+"/redeliver", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return OutgoingWebhookDeliveryFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // Preferences Section
 
 // GetPreferences returns the user's preferences.
@@ -1561,3 +1864,407 @@ func (c *Client4) UploadBrandImage(data []byte) (bool, *Response) {
 		return CheckStatusOK(rp), BuildResponse(rp)
 	}
 }
+
+// Jobs Section
+
+// GetJob gets a single job.
+func (c *Client4) GetJob(id string) (*Job, *Response) {
+	if r, err := c.DoApiGet(c.GetJobRoute(id), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return JobFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetJobs gets all jobs, sorted with the job that was created most recently first.
+func (c *Client4) GetJobs(page int, perPage int) ([]*Job, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetJobsRoute()+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return JobsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetJobsByType gets all jobs of a given type, sorted with the job that was created most recently first.
+func (c *Client4) GetJobsByType(jobType string, page int, perPage int) ([]*Job, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetJobsRoute()+"/type/"+jobType+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return JobsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// CreateJob creates a job based on the provided job struct.
+func (c *Client4) CreateJob(job *Job) (*Job, *Response) {
+	if r, err := c.DoApiPost(c.GetJobsRoute(), job.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return JobFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// CancelJob requests the cancellation of the job with the provided Id.
+func (c *Client4) CancelJob(jobId string) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetJobRoute(jobId)+"/cancel", ""); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// Commands Section
+
+// CreateCommand will create a new slash command on the command's team.
+func (c *Client4) CreateCommand(cmd *Command) (*Command, *Response) {
+	if r, err := c.DoApiPost(c.GetCommandsRoute(), cmd.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CommandFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateCommand updates a slash command.
+func (c *Client4) UpdateCommand(cmd *Command) (*Command, *Response) {
+	if r, err := c.DoApiPut(c.GetCommandRoute(cmd.Id), cmd.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CommandFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteCommand deletes a slash command.
+func (c *Client4) DeleteCommand(commandId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetCommandRoute(commandId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// ListCommands will retrieve a list of the commands available on a team. If customOnly
+// is set, only the team's custom commands are returned, including their tokens.
+func (c *Client4) ListCommands(teamId string, customOnly bool) ([]*Command, *Response) {
+	query := fmt.Sprintf("?custom_only=%v", customOnly)
+	if r, err := c.DoApiGet(c.GetTeamRoute(teamId)+"/commands"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CommandListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetCommandAutocompleteSuggestions gets suggestions for finishing a partially typed slash command, such as "/msg @jo".
+func (c *Client4) GetCommandAutocompleteSuggestions(userInput, teamId string) ([]*SuggestCommand, *Response) {
+	query := fmt.Sprintf("?user_input=%v", url.QueryEscape(userInput))
+	if r, err := c.DoApiGet(c.GetTeamRoute(teamId)+"/commands/autocomplete_suggestions"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return SuggestCommandListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ExecuteCommand executes a given slash command in a channel.
+func (c *Client4) ExecuteCommand(channelId, command string) (*CommandResponse, *Response) {
+	commandArgs := &CommandArgs{
+		ChannelId: channelId,
+		Command:   command,
+	}
+
+	return c.ExecuteCommandWithArgs(commandArgs)
+}
+
+// ExecuteCommandWithArgs executes a slash command with the given arguments. It's needed
+// for direct and group channels, where the team the command runs in has to be specified.
+func (c *Client4) ExecuteCommandWithArgs(commandArgs *CommandArgs) (*CommandResponse, *Response) {
+	if r, err := c.DoApiPost(c.GetCommandsRoute()+"/execute", commandArgs.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CommandResponseFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RegenCommandToken will create a new token for a slash command and return it.
+func (c *Client4) RegenCommandToken(commandId string) (string, *Response) {
+	if r, err := c.DoApiPut(c.GetCommandRoute(commandId)+"/regen_token", ""); err != nil {
+		return "", &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MapFromJson(r.Body)["token"], BuildResponse(r)
+	}
+}
+
+// Emoji Section
+
+// CreateEmoji will save an emoji to the server if the current user has permission
+// to do so. If successful, the provided emoji will be returned with its Id field
+// filled in. Otherwise, an error will be returned.
+func (c *Client4) CreateEmoji(emoji *Emoji, image []byte, filename string) (*Emoji, *Response) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if part, err := writer.CreateFormFile("image", filename); err != nil {
+		return nil, &Response{Error: NewAppError("CreateEmoji", "model.client.create_emoji.image.app_error", nil, err.Error(), http.StatusBadRequest)}
+	} else if _, err = io.Copy(part, bytes.NewBuffer(image)); err != nil {
+		return nil, &Response{Error: NewAppError("CreateEmoji", "model.client.create_emoji.image.app_error", nil, err.Error(), http.StatusBadRequest)}
+	}
+
+	if err := writer.WriteField("emoji", emoji.ToJson()); err != nil {
+		return nil, &Response{Error: NewAppError("CreateEmoji", "model.client.create_emoji.emoji.app_error", nil, err.Error(), http.StatusBadRequest)}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, &Response{Error: NewAppError("CreateEmoji", "model.client.create_emoji.writer.app_error", nil, err.Error(), http.StatusBadRequest)}
+	}
+
+	return c.DoEmojiUploadFile(c.GetEmojisRoute(), body.Bytes(), writer.FormDataContentType())
+}
+
+func (c *Client4) DoEmojiUploadFile(url string, data []byte, contentType string) (*Emoji, *Response) {
+	rq, _ := http.NewRequest("POST", c.ApiUrl+url, bytes.NewReader(data))
+	rq.Header.Set("Content-Type", contentType)
+	rq.Close = true
+
+	if len(c.AuthToken) > 0 {
+		rq.Header.Set(HEADER_AUTH, c.AuthType+" "+c.AuthToken)
+	}
+
+	if rp, err := c.HttpClient.Do(rq); err != nil {
+		return nil, &Response{Error: NewAppError(url, "model.client.connecting.app_error", nil, err.Error(), 0)}
+	} else if rp.StatusCode >= 300 {
+		defer closeBody(rp)
+		return nil, &Response{StatusCode: rp.StatusCode, Error: AppErrorFromJson(rp.Body)}
+	} else {
+		defer closeBody(rp)
+		return EmojiFromJson(rp.Body), BuildResponse(rp)
+	}
+}
+
+// GetEmojiList returns a list of all the custom emoji on the server.
+func (c *Client4) GetEmojiList() ([]*Emoji, *Response) {
+	if r, err := c.DoApiGet(c.GetEmojisRoute(), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return EmojiListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetEmoji returns a custom emoji by id.
+func (c *Client4) GetEmoji(emojiId string) (*Emoji, *Response) {
+	if r, err := c.DoApiGet(c.GetEmojiRoute(emojiId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return EmojiFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteEmoji deletes a custom emoji and its image.
+func (c *Client4) DeleteEmoji(emojiId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetEmojiRoute(emojiId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetEmojiImage returns the image data for a custom emoji.
+func (c *Client4) GetEmojiImage(emojiId string) ([]byte, *Response) {
+	if r, err := c.DoApiGet(c.GetEmojiRoute(emojiId)+"/image", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else if data, err := ioutil.ReadAll(r.Body); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: NewAppError("GetEmojiImage", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode)}
+	} else {
+		return data, BuildResponse(r)
+	}
+}
+
+// Reactions Section
+
+// SaveReaction saves an emoji reaction for a post. Returns the saved reaction if successful, otherwise an error will be returned.
+func (c *Client4) SaveReaction(reaction *Reaction) (*Reaction, *Response) {
+	if r, err := c.DoApiPost(c.GetReactionsRoute(), reaction.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ReactionFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetReactions returns a list of reactions to a post.
+func (c *Client4) GetReactions(postId string) ([]*Reaction, *Response) {
+	if r, err := c.DoApiGet(c.GetPostRoute(postId)+"/reactions", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ReactionsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteReaction deletes the reaction of a user to a post.
+func (c *Client4) DeleteReaction(reaction *Reaction) (bool, *Response) {
+	route := fmt.Sprintf(c.GetUserRoute(reaction.UserId)+"/posts/%v/reactions/%v", reaction.PostId, reaction.EmojiName)
+	if r, err := c.DoApiDelete(route); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// OAuth Section
+
+// CreateOAuthApp will register a new OAuth 2.0 client application with Mattermost acting as an OAuth 2.0 service provider.
+func (c *Client4) CreateOAuthApp(app *OAuthApp) (*OAuthApp, *Response) {
+	if r, err := c.DoApiPost(c.GetOAuthAppsRoute(), app.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return OAuthAppFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateOAuthApp updates the name, description, callback URLs and other settings of an OAuth 2.0 client application.
+func (c *Client4) UpdateOAuthApp(app *OAuthApp) (*OAuthApp, *Response) {
+	if r, err := c.DoApiPut(c.GetOAuthAppRoute(app.Id), app.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return OAuthAppFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetOAuthApps gets the OAuth 2.0 client applications the current user can manage.
+func (c *Client4) GetOAuthApps() ([]*OAuthApp, *Response) {
+	if r, err := c.DoApiGet(c.GetOAuthAppsRoute(), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return OAuthAppListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetOAuthApp gets a registered OAuth 2.0 client application, including its secret.
+func (c *Client4) GetOAuthApp(appId string) (*OAuthApp, *Response) {
+	if r, err := c.DoApiGet(c.GetOAuthAppRoute(appId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return OAuthAppFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetOAuthAppInfo gets a sanitized version of a registered OAuth 2.0 client application, for display when authorizing it.
+func (c *Client4) GetOAuthAppInfo(appId string) (*OAuthApp, *Response) {
+	if r, err := c.DoApiGet(c.GetOAuthAppRoute(appId)+"/info", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return OAuthAppFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteOAuthApp deletes a registered OAuth 2.0 client application.
+func (c *Client4) DeleteOAuthApp(appId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetOAuthAppRoute(appId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// RegenerateOAuthAppSecret regenerates the client secret for a registered OAuth 2.0 client application.
+func (c *Client4) RegenerateOAuthAppSecret(appId string) (*OAuthApp, *Response) {
+	if r, err := c.DoApiPost(c.GetOAuthAppRoute(appId)+"/regen_secret", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return OAuthAppFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetAuthorizedOAuthAppsForUser gets the OAuth 2.0 client applications a user has authorized to access their account.
+func (c *Client4) GetAuthorizedOAuthAppsForUser(userId string) ([]*OAuthApp, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/oauth/apps/authorized", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return OAuthAppListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeauthorizeOAuthApp removes a user's authorization of an OAuth 2.0 client application, revoking its access tokens.
+func (c *Client4) DeauthorizeOAuthApp(userId, appId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetUserRoute(userId) + "/oauth/apps/authorized/" + appId); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// Status Section
+
+// GetUserStatus returns a user's status.
+func (c *Client4) GetUserStatus(userId string) (*Status, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/status", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return StatusFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetUsersStatusesByIds returns a map of user ids to statuses for a list of users.
+func (c *Client4) GetUsersStatusesByIds(userIds []string) (map[string]interface{}, *Response) {
+	if r, err := c.DoApiPost(c.GetUsersRoute()+"/status/ids", ArrayToJson(userIds)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return StringInterfaceFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateUserStatus sets a user's status to online, away or offline.
+func (c *Client4) UpdateUserStatus(userId string, status *Status) (*Status, *Response) {
+	if r, err := c.DoApiPut(c.GetUserRoute(userId)+"/status", status.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return StatusFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateUserCustomStatus sets the custom status shown alongside a user's name.
+func (c *Client4) UpdateUserCustomStatus(userId string, customStatus *CustomStatus) (bool, *Response) {
+	if r, err := c.DoApiPut(c.GetUserRoute(userId)+"/status/custom", customStatus.ToJson()); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// RemoveUserCustomStatus clears a user's custom status.
+func (c *Client4) RemoveUserCustomStatus(userId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetUserRoute(userId) + "/status/custom"); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
@@ -17,23 +17,26 @@ const (
 )
 
 type Command struct {
-	Id               string `json:"id"`
-	Token            string `json:"token"`
-	CreateAt         int64  `json:"create_at"`
-	UpdateAt         int64  `json:"update_at"`
-	DeleteAt         int64  `json:"delete_at"`
-	CreatorId        string `json:"creator_id"`
-	TeamId           string `json:"team_id"`
-	Trigger          string `json:"trigger"`
-	Method           string `json:"method"`
-	Username         string `json:"username"`
-	IconURL          string `json:"icon_url"`
-	AutoComplete     bool   `json:"auto_complete"`
-	AutoCompleteDesc string `json:"auto_complete_desc"`
-	AutoCompleteHint string `json:"auto_complete_hint"`
-	DisplayName      string `json:"display_name"`
-	Description      string `json:"description"`
-	URL              string `json:"url"`
+	Id               string                   `json:"id"`
+	Token            string                   `json:"token"`
+	CreateAt         int64                    `json:"create_at"`
+	UpdateAt         int64                    `json:"update_at"`
+	DeleteAt         int64                    `json:"delete_at"`
+	CreatorId        string                   `json:"creator_id"`
+	TeamId           string                   `json:"team_id"`
+	Trigger          string                   `json:"trigger"`
+	Method           string                   `json:"method"`
+	Username         string                   `json:"username"`
+	IconURL          string                   `json:"icon_url"`
+	AutoComplete     bool                     `json:"auto_complete"`
+	AutoCompleteDesc string                   `json:"auto_complete_desc"`
+	AutoCompleteHint string                   `json:"auto_complete_hint"`
+	AutocompleteData *CommandAutocompleteData `json:"autocomplete_data,omitempty"`
+	DisplayName      string                   `json:"display_name"`
+	Description      string                   `json:"description"`
+	URL              string                   `json:"url"`
+	BotUserId        string                   `json:"bot_user_id"`
+	SigningSecret    string                   `json:"signing_secret"`
 }
 
 func (o *Command) ToJson() string {
@@ -126,6 +129,16 @@ func (o *Command) IsValid() *AppError {
 		return NewLocAppError("Command.IsValid", "model.command.is_valid.description.app_error", nil, "")
 	}
 
+	if len(o.BotUserId) != 0 && len(o.BotUserId) != 26 {
+		return NewLocAppError("Command.IsValid", "model.command.is_valid.bot_user_id.app_error", nil, "")
+	}
+
+	if o.AutocompleteData != nil {
+		if err := o.AutocompleteData.IsValid(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -138,6 +151,10 @@ func (o *Command) PreSave() {
 		o.Token = NewId()
 	}
 
+	if o.SigningSecret == "" {
+		o.SigningSecret = NewSigningSecret()
+	}
+
 	o.CreateAt = GetMillis()
 	o.UpdateAt = o.CreateAt
 }
@@ -153,4 +170,6 @@ func (o *Command) Sanitize() {
 	o.URL = ""
 	o.Username = ""
 	o.IconURL = ""
+	o.BotUserId = ""
+	o.SigningSecret = ""
 }
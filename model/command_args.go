@@ -17,9 +17,13 @@ type CommandArgs struct {
 	RootId    string               `json:"root_id"`
 	ParentId  string               `json:"parent_id"`
 	Command   string               `json:"command"`
+	Timezone  string               `json:"timezone"` // the name of the user's timezone, if the client knows it
 	SiteURL   string               `json:"-"`
 	T         goi18n.TranslateFunc `json:"-"`
 	Session   Session              `json:"-"`
+
+	// ParsedCommand is set before a command with autocomplete data is run
+	ParsedCommand *ParsedCommand `json:"-"`
 }
 
 func (o *CommandArgs) ToJson() string {
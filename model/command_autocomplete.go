@@ -0,0 +1,300 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+const (
+	COMMAND_ARGUMENT_TYPE_TEXT    = "text"
+	COMMAND_ARGUMENT_TYPE_USER    = "user"
+	COMMAND_ARGUMENT_TYPE_CHANNEL = "channel"
+	COMMAND_ARGUMENT_TYPE_ENUM    = "enum"
+
+	COMMAND_AUTOCOMPLETE_DATA_MAX_LENGTH = 4000
+)
+
+// CommandAutocompleteData describes the subcommands or arguments accepted by a slash command, or by one of its
+// subcommands, so that they can be suggested while the user is typing and parsed before the command is run. The
+// trigger of the data describing the command itself is ignored.
+type CommandAutocompleteData struct {
+	Trigger     string                     `json:"trigger"`
+	HelpText    string                     `json:"help_text"`
+	Subcommands []*CommandAutocompleteData `json:"subcommands,omitempty"`
+	Arguments   []*CommandArgument         `json:"arguments,omitempty"`
+}
+
+type CommandArgument struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	HelpText string   `json:"help_text"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"` // the values allowed for an enum argument
+}
+
+// ParsedCommand holds the subcommand and arguments parsed from the message of a command. User and channel arguments
+// are stored without their leading @ or ~, and their ids are stored separately if they exist.
+type ParsedCommand struct {
+	Subcommand string            `json:"subcommand"` // the triggers of the subcommands used, separated by spaces
+	Arguments  map[string]string `json:"arguments"`
+	Ids        map[string]string `json:"ids"`
+}
+
+func (o *CommandAutocompleteData) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func CommandAutocompleteDataFromJson(data io.Reader) *CommandAutocompleteData {
+	var o *CommandAutocompleteData
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func (o *CommandAutocompleteData) IsValid() *AppError {
+	if len(o.ToJson()) > COMMAND_AUTOCOMPLETE_DATA_MAX_LENGTH {
+		return NewAppError("CommandAutocompleteData.IsValid", "model.command_autocomplete.is_valid.length.app_error", map[string]interface{}{"Max": COMMAND_AUTOCOMPLETE_DATA_MAX_LENGTH}, "", http.StatusBadRequest)
+	}
+
+	return o.isValid()
+}
+
+func (o *CommandAutocompleteData) isValid() *AppError {
+	if len(o.Subcommands) > 0 && len(o.Arguments) > 0 {
+		return NewAppError("CommandAutocompleteData.IsValid", "model.command_autocomplete.is_valid.subcommands_and_arguments.app_error", nil, "trigger="+o.Trigger, http.StatusBadRequest)
+	}
+
+	triggers := map[string]bool{}
+	for i, subcommand := range o.Subcommands {
+		if subcommand == nil {
+			return NewAppError("CommandAutocompleteData.IsValid", "model.command_autocomplete.is_valid.subcommand.app_error", nil, fmt.Sprintf("trigger=%v index=%v", o.Trigger, i), http.StatusBadRequest)
+		}
+
+		if !isValidCommandWord(subcommand.Trigger) || triggers[subcommand.Trigger] {
+			return NewAppError("CommandAutocompleteData.IsValid", "model.command_autocomplete.is_valid.trigger.app_error", nil, "trigger="+subcommand.Trigger, http.StatusBadRequest)
+		}
+		triggers[subcommand.Trigger] = true
+
+		if err := subcommand.isValid(); err != nil {
+			return err
+		}
+	}
+
+	names := map[string]bool{}
+	for i, argument := range o.Arguments {
+		if argument == nil {
+			return NewAppError("CommandAutocompleteData.IsValid", "model.command_autocomplete.is_valid.argument.app_error", nil, fmt.Sprintf("trigger=%v index=%v", o.Trigger, i), http.StatusBadRequest)
+		}
+
+		if !isValidCommandWord(argument.Name) || names[argument.Name] {
+			return NewAppError("CommandAutocompleteData.IsValid", "model.command_autocomplete.is_valid.name.app_error", nil, "name="+argument.Name, http.StatusBadRequest)
+		}
+		names[argument.Name] = true
+
+		switch argument.Type {
+		case COMMAND_ARGUMENT_TYPE_USER, COMMAND_ARGUMENT_TYPE_CHANNEL:
+		case COMMAND_ARGUMENT_TYPE_TEXT:
+			// text takes up the rest of the message
+			if i != len(o.Arguments)-1 {
+				return NewAppError("CommandAutocompleteData.IsValid", "model.command_autocomplete.is_valid.text.app_error", nil, "name="+argument.Name, http.StatusBadRequest)
+			}
+		case COMMAND_ARGUMENT_TYPE_ENUM:
+			if len(argument.Options) == 0 {
+				return NewAppError("CommandAutocompleteData.IsValid", "model.command_autocomplete.is_valid.options.app_error", nil, "name="+argument.Name, http.StatusBadRequest)
+			}
+
+			for _, option := range argument.Options {
+				if !isValidCommandWord(option) {
+					return NewAppError("CommandAutocompleteData.IsValid", "model.command_autocomplete.is_valid.options.app_error", nil, "name="+argument.Name, http.StatusBadRequest)
+				}
+			}
+		default:
+			return NewAppError("CommandAutocompleteData.IsValid", "model.command_autocomplete.is_valid.type.app_error", nil, "name="+argument.Name, http.StatusBadRequest)
+		}
+
+		// arguments are matched by position, so an optional one can't be followed by a required one
+		if argument.Required && i > 0 && !o.Arguments[i-1].Required {
+			return NewAppError("CommandAutocompleteData.IsValid", "model.command_autocomplete.is_valid.required.app_error", nil, "name="+argument.Name, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+func isValidCommandWord(word string) bool {
+	return len(word) > 0 && len(word) <= MAX_TRIGGER_LENGTH && strings.IndexFunc(word, unicode.IsSpace) == -1
+}
+
+func (o *CommandAutocompleteData) GetSubcommand(trigger string) *CommandAutocompleteData {
+	for _, subcommand := range o.Subcommands {
+		if subcommand.Trigger == trigger {
+			return subcommand
+		}
+	}
+
+	return nil
+}
+
+// FindSubcommand gets a subcommand from the triggers leading to it, separated by spaces. An empty path returns the
+// data itself.
+func (o *CommandAutocompleteData) FindSubcommand(path string) *CommandAutocompleteData {
+	data := o
+	for _, trigger := range strings.Fields(path) {
+		if data = data.GetSubcommand(trigger); data == nil {
+			return nil
+		}
+	}
+
+	return data
+}
+
+// Hint returns a short description of the arguments expected after the subcommand, such as "[user] [message]".
+func (o *CommandAutocompleteData) Hint() string {
+	if len(o.Subcommands) > 0 {
+		return "[subcommand]"
+	}
+
+	hints := make([]string, 0, len(o.Arguments))
+	for _, argument := range o.Arguments {
+		hints = append(hints, "["+argument.Name+"]")
+	}
+
+	return strings.Join(hints, " ")
+}
+
+func (o *CommandArgument) HasOption(option string) bool {
+	for _, o := range o.Options {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Parse matches the message of a command, which is everything after its trigger, against the command's subcommands
+// and arguments.
+func (o *CommandAutocompleteData) Parse(message string) (*ParsedCommand, *AppError) {
+	parsed := &ParsedCommand{Arguments: map[string]string{}, Ids: map[string]string{}}
+
+	data := o
+	rest := strings.TrimSpace(message)
+	subcommands := []string{}
+
+	var word string
+	for len(data.Subcommands) > 0 {
+		word, rest = splitCommandWord(rest)
+		if word == "" {
+			return nil, NewAppError("CommandAutocompleteData.Parse", "model.command_autocomplete.parse.missing_subcommand.app_error", map[string]interface{}{"Subcommands": data.subcommandTriggers()}, "", http.StatusBadRequest)
+		}
+
+		if data = data.GetSubcommand(word); data == nil {
+			return nil, NewAppError("CommandAutocompleteData.Parse", "model.command_autocomplete.parse.unknown_subcommand.app_error", map[string]interface{}{"Subcommand": word}, "", http.StatusBadRequest)
+		}
+
+		subcommands = append(subcommands, word)
+	}
+
+	for _, argument := range data.Arguments {
+		var value string
+		if argument.Type == COMMAND_ARGUMENT_TYPE_TEXT {
+			value, rest = rest, ""
+		} else {
+			value, rest = splitCommandWord(rest)
+		}
+
+		if value == "" {
+			if argument.Required {
+				return nil, NewAppError("CommandAutocompleteData.Parse", "model.command_autocomplete.parse.missing_argument.app_error", map[string]interface{}{"Name": argument.Name}, "", http.StatusBadRequest)
+			}
+
+			continue
+		}
+
+		switch argument.Type {
+		case COMMAND_ARGUMENT_TYPE_USER:
+			value = strings.TrimPrefix(value, "@")
+		case COMMAND_ARGUMENT_TYPE_CHANNEL:
+			value = strings.TrimPrefix(value, "~")
+		case COMMAND_ARGUMENT_TYPE_ENUM:
+			if !argument.HasOption(value) {
+				return nil, NewAppError("CommandAutocompleteData.Parse", "model.command_autocomplete.parse.invalid_option.app_error", map[string]interface{}{"Name": argument.Name, "Options": strings.Join(argument.Options, ", ")}, "", http.StatusBadRequest)
+			}
+		}
+
+		parsed.Arguments[argument.Name] = value
+	}
+
+	if rest != "" {
+		return nil, NewAppError("CommandAutocompleteData.Parse", "model.command_autocomplete.parse.too_many_arguments.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	parsed.Subcommand = strings.Join(subcommands, " ")
+
+	return parsed, nil
+}
+
+// FindPosition works out what the last word of a partially typed message is for. It returns the subcommand being
+// typed and, if its arguments have been reached, the argument being typed. The returned data is nil if the message
+// doesn't match the command.
+func (o *CommandAutocompleteData) FindPosition(message string) (*CommandAutocompleteData, *CommandArgument) {
+	words := strings.Fields(message)
+	if len(words) > 0 && !strings.HasSuffix(message, " ") {
+		// the last word is still being typed
+		words = words[:len(words)-1]
+	}
+
+	data := o
+	for len(data.Subcommands) > 0 {
+		if len(words) == 0 {
+			return data, nil
+		}
+
+		if data = data.GetSubcommand(words[0]); data == nil {
+			return nil, nil
+		}
+
+		words = words[1:]
+	}
+
+	for _, argument := range data.Arguments {
+		if len(words) == 0 || argument.Type == COMMAND_ARGUMENT_TYPE_TEXT {
+			return data, argument
+		}
+
+		words = words[1:]
+	}
+
+	return nil, nil
+}
+
+func (o *CommandAutocompleteData) subcommandTriggers() string {
+	triggers := make([]string, 0, len(o.Subcommands))
+	for _, subcommand := range o.Subcommands {
+		triggers = append(triggers, subcommand.Trigger)
+	}
+
+	return strings.Join(triggers, ", ")
+}
+
+// splitCommandWord splits the first word off of a message, returning it and the rest of the message.
+func splitCommandWord(message string) (string, string) {
+	message = strings.TrimSpace(message)
+
+	if i := strings.IndexFunc(message, unicode.IsSpace); i != -1 {
+		return message[:i], strings.TrimSpace(message[i:])
+	}
+
+	return message, ""
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func getTestAutocompleteData() *CommandAutocompleteData {
+	return &CommandAutocompleteData{
+		Subcommands: []*CommandAutocompleteData{
+			{
+				Trigger:  "add",
+				HelpText: "Add a user",
+				Arguments: []*CommandArgument{
+					{Name: "user", Type: COMMAND_ARGUMENT_TYPE_USER, Required: true},
+					{Name: "role", Type: COMMAND_ARGUMENT_TYPE_ENUM, Options: []string{"member", "admin"}},
+				},
+			},
+			{
+				Trigger:  "move",
+				HelpText: "Move to a channel",
+				Arguments: []*CommandArgument{
+					{Name: "channel", Type: COMMAND_ARGUMENT_TYPE_CHANNEL, Required: true},
+					{Name: "reason", Type: COMMAND_ARGUMENT_TYPE_TEXT},
+				},
+			},
+		},
+	}
+}
+
+func TestCommandAutocompleteDataJson(t *testing.T) {
+	o := getTestAutocompleteData()
+	ro := CommandAutocompleteDataFromJson(strings.NewReader(o.ToJson()))
+
+	if len(ro.Subcommands) != 2 || ro.Subcommands[0].Arguments[1].Options[1] != "admin" {
+		t.Fatal("data should have round tripped")
+	}
+}
+
+func TestCommandAutocompleteDataIsValid(t *testing.T) {
+	o := getTestAutocompleteData()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o = getTestAutocompleteData()
+	o.Arguments = []*CommandArgument{{Name: "text", Type: COMMAND_ARGUMENT_TYPE_TEXT}}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't allow both subcommands and arguments")
+	}
+
+	o = getTestAutocompleteData()
+	o.Subcommands[1].Trigger = "add"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't allow duplicate triggers")
+	}
+
+	o = getTestAutocompleteData()
+	o.Subcommands[0].Trigger = "add user"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't allow spaces in triggers")
+	}
+
+	o = getTestAutocompleteData()
+	o.Subcommands[1].Arguments[0], o.Subcommands[1].Arguments[1] = o.Subcommands[1].Arguments[1], o.Subcommands[1].Arguments[0]
+	if err := o.IsValid(); err == nil {
+		t.Fatal("text should be the last argument")
+	}
+
+	o = getTestAutocompleteData()
+	o.Subcommands[0].Arguments[1].Options = nil
+	if err := o.IsValid(); err == nil {
+		t.Fatal("enums should need options")
+	}
+
+	o = getTestAutocompleteData()
+	o.Subcommands[0].Arguments[1].Type = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should have failed on the argument type")
+	}
+
+	o = getTestAutocompleteData()
+	o.Subcommands[0].Arguments[0].Required = false
+	o.Subcommands[0].Arguments[1].Required = true
+	if err := o.IsValid(); err == nil {
+		t.Fatal("required arguments shouldn't follow optional ones")
+	}
+
+	o = getTestAutocompleteData()
+	o.Subcommands[0].HelpText = strings.Repeat("a", COMMAND_AUTOCOMPLETE_DATA_MAX_LENGTH)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should have been too long")
+	}
+
+	o = getTestAutocompleteData()
+	o.Subcommands = append(o.Subcommands, nil)
+	if err := o.IsValid(); err == nil || err.Id != "model.command_autocomplete.is_valid.subcommand.app_error" {
+		t.Fatal("should have rejected a nil subcommand")
+	}
+
+	o = getTestAutocompleteData()
+	o.Subcommands[1].Arguments = append([]*CommandArgument{nil}, o.Subcommands[1].Arguments...)
+	if err := o.IsValid(); err == nil || err.Id != "model.command_autocomplete.is_valid.argument.app_error" {
+		t.Fatal("should have rejected a nil argument")
+	}
+
+	// null entries sent by clients are decoded as nil
+	for _, data := range []string{`{"arguments":[null]}`, `{"subcommands":[null]}`} {
+		if err := CommandAutocompleteDataFromJson(strings.NewReader(data)).IsValid(); err == nil {
+			t.Fatal("should have rejected null entries in " + data)
+		}
+	}
+}
+
+func TestCommandAutocompleteDataParse(t *testing.T) {
+	o := getTestAutocompleteData()
+
+	if parsed, err := o.Parse(" add @bob  admin "); err != nil {
+		t.Fatal(err)
+	} else if parsed.Subcommand != "add" || parsed.Arguments["user"] != "bob" || parsed.Arguments["role"] != "admin" {
+		t.Fatal("parsed incorrectly", parsed)
+	}
+
+	if parsed, err := o.Parse("add bob"); err != nil {
+		t.Fatal(err)
+	} else if _, ok := parsed.Arguments["role"]; ok {
+		t.Fatal("optional argument shouldn't have been set")
+	}
+
+	if parsed, err := o.Parse("move ~town-square because  of reasons"); err != nil {
+		t.Fatal(err)
+	} else if parsed.Arguments["channel"] != "town-square" || parsed.Arguments["reason"] != "because  of reasons" {
+		t.Fatal("parsed incorrectly", parsed)
+	}
+
+	if _, err := o.Parse(""); err == nil || err.Id != "model.command_autocomplete.parse.missing_subcommand.app_error" {
+		t.Fatal("should have been missing a subcommand")
+	}
+
+	if _, err := o.Parse("remove bob"); err == nil || err.Id != "model.command_autocomplete.parse.unknown_subcommand.app_error" {
+		t.Fatal("should have been an unknown subcommand")
+	}
+
+	if _, err := o.Parse("add"); err == nil || err.Id != "model.command_autocomplete.parse.missing_argument.app_error" {
+		t.Fatal("should have been missing the user")
+	}
+
+	if _, err := o.Parse("add bob owner"); err == nil || err.Id != "model.command_autocomplete.parse.invalid_option.app_error" {
+		t.Fatal("should have rejected the option")
+	}
+
+	if _, err := o.Parse("add bob admin now"); err == nil || err.Id != "model.command_autocomplete.parse.too_many_arguments.app_error" {
+		t.Fatal("should have had too many arguments")
+	}
+}
+
+func TestCommandAutocompleteDataFindPosition(t *testing.T) {
+	o := getTestAutocompleteData()
+
+	if data, argument := o.FindPosition(" a"); data != o || argument != nil {
+		t.Fatal("should be typing a subcommand")
+	}
+
+	if data, argument := o.FindPosition(" add "); data != o.Subcommands[0] || argument != o.Subcommands[0].Arguments[0] {
+		t.Fatal("should be at the user argument")
+	}
+
+	if data, argument := o.FindPosition(" add @bob adm"); data != o.Subcommands[0] || argument != o.Subcommands[0].Arguments[1] {
+		t.Fatal("should be typing the role argument")
+	}
+
+	if data, argument := o.FindPosition(" move ~town-square some long reason"); data != o.Subcommands[1] || argument != o.Subcommands[1].Arguments[1] {
+		t.Fatal("should be typing the text argument")
+	}
+
+	if data, _ := o.FindPosition(" remove "); data != nil {
+		t.Fatal("shouldn't match an unknown subcommand")
+	}
+
+	if data, _ := o.FindPosition(" add bob admin "); data != nil {
+		t.Fatal("shouldn't match past the last argument")
+	}
+}
@@ -6,6 +6,7 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"net/http"
 )
 
 const (
@@ -14,12 +15,12 @@ const (
 )
 
 type CommandResponse struct {
-	ResponseType string      `json:"response_type"`
-	Text         string      `json:"text"`
-	Username     string      `json:"username"`
-	IconURL      string      `json:"icon_url"`
-	GotoLocation string      `json:"goto_location"`
-	Attachments  interface{} `json:"attachments"`
+	ResponseType string             `json:"response_type"`
+	Text         string             `json:"text"`
+	Username     string             `json:"username"`
+	IconURL      string             `json:"icon_url"`
+	GotoLocation string             `json:"goto_location"`
+	Attachments  []*SlackAttachment `json:"attachments"`
 }
 
 func (o *CommandResponse) ToJson() string {
@@ -41,3 +42,21 @@ func CommandResponseFromJson(data io.Reader) *CommandResponse {
 		return nil
 	}
 }
+
+// CommandResponseFromHTTPBody decodes the response of a custom slash command, reporting why it couldn't be used if the
+// integration replied with something other than a valid response.
+func CommandResponseFromHTTPBody(data io.Reader) (*CommandResponse, *AppError) {
+	decoder := json.NewDecoder(data)
+	var o CommandResponse
+	if err := decoder.Decode(&o); err == io.EOF {
+		return nil, NewAppError("CommandResponseFromHTTPBody", "model.command_response.empty.app_error", nil, "", http.StatusBadRequest)
+	} else if err != nil {
+		return nil, NewAppError("CommandResponseFromHTTPBody", "model.command_response.parse.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	if err := ValidateSlackAttachments(o.Attachments); err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
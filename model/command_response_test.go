@@ -17,3 +17,23 @@ func TestCommandResponseJson(t *testing.T) {
 		t.Fatal("Ids do not match")
 	}
 }
+
+func TestCommandResponseFromHTTPBody(t *testing.T) {
+	if _, err := CommandResponseFromHTTPBody(strings.NewReader("")); err == nil || err.Id != "model.command_response.empty.app_error" {
+		t.Fatal("should have failed on an empty response")
+	}
+
+	if _, err := CommandResponseFromHTTPBody(strings.NewReader(`{"text": "test", "attachments": [1]}`)); err == nil || err.Id != "model.command_response.parse.app_error" {
+		t.Fatal("should have failed on a malformed attachment")
+	}
+
+	if _, err := CommandResponseFromHTTPBody(strings.NewReader(`{"text": "test", "attachments": [{"image_url": "not a url"}]}`)); err == nil || err.Id != "model.slack_attachment.is_valid.url.app_error" {
+		t.Fatal("should have failed on an invalid attachment")
+	}
+
+	if response, err := CommandResponseFromHTTPBody(strings.NewReader(`{"text": "test", "attachments": [{"text": "attachment", "fields": [{"title": "count", "value": 3}]}]}`)); err != nil {
+		t.Fatal(err)
+	} else if response.Attachments[0].Text != "attachment" || response.Attachments[0].Fields[0].Value != float64(3) {
+		t.Fatal("attachments weren't decoded correctly")
+	}
+}
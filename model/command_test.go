@@ -161,6 +161,16 @@ func TestCommandIsValid(t *testing.T) {
 	if err := o.IsValid(); err != nil {
 		t.Fatal(err)
 	}
+
+	o.AutocompleteData = &CommandAutocompleteData{Arguments: []*CommandArgument{{Name: "text", Type: "junk"}}}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.AutocompleteData.Arguments[0].Type = COMMAND_ARGUMENT_TYPE_TEXT
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func TestCommandPreSave(t *testing.T) {
@@ -0,0 +1,125 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	COMMAND_WEBHOOK_LIFETIME = 1000 * 60 * 30
+	COMMAND_WEBHOOK_MAX_USES = 5
+)
+
+// CommandWebhook is the response_url given to a custom slash command each time that it's run. It lets the command
+// post delayed responses to the channel it was run in for a limited time and number of uses. Everything that the hook
+// is made of is carried in the url and signed by the server, so that it only needs to be saved once it's used.
+type CommandWebhook struct {
+	Id        string
+	CreateAt  int64
+	CommandId string
+	UserId    string
+	ChannelId string
+	RootId    string
+	ParentId  string
+	UseCount  int
+}
+
+func (o *CommandWebhook) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+}
+
+func (o *CommandWebhook) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("CommandWebhook.IsValid", "model.command_hook.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("CommandWebhook.IsValid", "model.command_hook.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.CommandId) != 26 {
+		return NewAppError("CommandWebhook.IsValid", "model.command_hook.command_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("CommandWebhook.IsValid", "model.command_hook.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewAppError("CommandWebhook.IsValid", "model.command_hook.channel_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.RootId) != 0 && len(o.RootId) != 26 {
+		return NewAppError("CommandWebhook.IsValid", "model.command_hook.root_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.ParentId) != 0 && len(o.ParentId) != 26 {
+		return NewAppError("CommandWebhook.IsValid", "model.command_hook.parent_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// IsExpired returns true once the hook is older than COMMAND_WEBHOOK_LIFETIME.
+func (o *CommandWebhook) IsExpired() bool {
+	return GetMillis() > o.CreateAt+COMMAND_WEBHOOK_LIFETIME
+}
+
+// ToQuery returns the query string of the hook's response url, including a signature made with the given secret.
+func (o *CommandWebhook) ToQuery(secret string) url.Values {
+	query := url.Values{}
+	query.Set("create_at", strconv.FormatInt(o.CreateAt, 10))
+	query.Set("command_id", o.CommandId)
+	query.Set("user_id", o.UserId)
+	query.Set("channel_id", o.ChannelId)
+	query.Set("root_id", o.RootId)
+	query.Set("parent_id", o.ParentId)
+	query.Set("signature", o.Sign(secret))
+
+	return query
+}
+
+// CommandWebhookFromQuery reads the hook with the given id from the query string of its response url. It returns nil
+// if the signature in the query string isn't the one made with the given secret.
+func CommandWebhookFromQuery(id string, query url.Values, secret string) *CommandWebhook {
+	createAt, _ := strconv.ParseInt(query.Get("create_at"), 10, 64)
+
+	o := &CommandWebhook{
+		Id:        id,
+		CreateAt:  createAt,
+		CommandId: query.Get("command_id"),
+		UserId:    query.Get("user_id"),
+		ChannelId: query.Get("channel_id"),
+		RootId:    query.Get("root_id"),
+		ParentId:  query.Get("parent_id"),
+	}
+
+	if !hmac.Equal([]byte(query.Get("signature")), []byte(o.Sign(secret))) {
+		return nil
+	}
+
+	return o
+}
+
+// Sign returns an HMAC-SHA256 of the hook, hex encoded. It covers when the hook was created so that its lifetime
+// can't be extended.
+func (o *CommandWebhook) Sign(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{o.Id, strconv.FormatInt(o.CreateAt, 10), o.CommandId, o.UserId, o.ChannelId, o.RootId, o.ParentId}, ":")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestCommandWebhookIsValid(t *testing.T) {
+	h := &CommandWebhook{
+		CommandId: NewId(),
+		UserId:    NewId(),
+		ChannelId: NewId(),
+	}
+	h.PreSave()
+
+	if err := h.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	h.RootId = "junk"
+	if err := h.IsValid(); err == nil {
+		t.Fatal("should be invalid with a bad root id")
+	}
+	h.RootId = NewId()
+
+	h.ParentId = "junk"
+	if err := h.IsValid(); err == nil {
+		t.Fatal("should be invalid with a bad parent id")
+	}
+	h.ParentId = h.RootId
+
+	h.ChannelId = ""
+	if err := h.IsValid(); err == nil {
+		t.Fatal("should be invalid without a channel id")
+	}
+}
+
+func TestCommandWebhookIsExpired(t *testing.T) {
+	h := &CommandWebhook{}
+	h.PreSave()
+
+	if h.IsExpired() {
+		t.Fatal("new hook shouldn't be expired")
+	}
+
+	h.CreateAt = GetMillis() - COMMAND_WEBHOOK_LIFETIME - 1000
+	if !h.IsExpired() {
+		t.Fatal("old hook should be expired")
+	}
+}
+
+func TestCommandWebhookQuery(t *testing.T) {
+	h := &CommandWebhook{
+		CommandId: NewId(),
+		UserId:    NewId(),
+		ChannelId: NewId(),
+		RootId:    NewId(),
+	}
+	h.PreSave()
+
+	query := h.ToQuery("secret")
+
+	if received := CommandWebhookFromQuery(h.Id, query, "secret"); received == nil {
+		t.Fatal("should have read the hook")
+	} else if *received != *h {
+		t.Fatal("should have read the same hook")
+	}
+
+	if CommandWebhookFromQuery(h.Id, query, "other secret") != nil {
+		t.Fatal("shouldn't accept a hook signed with another secret")
+	}
+
+	if CommandWebhookFromQuery(NewId(), query, "secret") != nil {
+		t.Fatal("shouldn't accept the signature for another hook")
+	}
+
+	query.Set("channel_id", NewId())
+	if CommandWebhookFromQuery(h.Id, query, "secret") != nil {
+		t.Fatal("shouldn't accept a hook that was changed")
+	}
+
+	query = h.ToQuery("secret")
+	query.Set("create_at", strconv.FormatInt(h.CreateAt+COMMAND_WEBHOOK_LIFETIME, 10))
+	if CommandWebhookFromQuery(h.Id, query, "secret") != nil {
+		t.Fatal("shouldn't accept a hook whose lifetime was extended")
+	}
+}
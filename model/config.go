@@ -32,8 +32,12 @@ const (
 	WEBSERVER_MODE_GZIP     = "gzip"
 	WEBSERVER_MODE_DISABLED = "disabled"
 
-	GENERIC_NOTIFICATION = "generic"
-	FULL_NOTIFICATION    = "full"
+	GENERIC_NOTIFICATION   = "generic"
+	FULL_NOTIFICATION      = "full"
+	ID_LOADED_NOTIFICATION = "id_loaded" // the device fetches the contents from the server using the post id
+
+	PUSH_NOTIFICATION_TRANSPORT_PROXY = "proxy"
+	PUSH_NOTIFICATION_TRANSPORT_FILE  = "file"
 
 	DIRECT_MESSAGE_ANY  = "any"
 	DIRECT_MESSAGE_TEAM = "team"
@@ -107,6 +111,9 @@ const (
 	WEBRTC_SETTINGS_DEFAULT_TURN_URI = ""
 
 	ANALYTICS_SETTINGS_DEFAULT_MAX_USERS_FOR_STATISTICS = 2500
+
+	DATA_RETENTION_SETTINGS_DEFAULT_MESSAGE_RETENTION_DAYS        = 365
+	DATA_RETENTION_SETTINGS_DEFAULT_DIRECT_MESSAGE_RETENTION_DAYS = 365
 )
 
 type ServiceSettings struct {
@@ -152,6 +159,7 @@ type ServiceSettings struct {
 	TimeBetweenUserTypingUpdatesMilliseconds *int64
 	EnableUserTypingMessages                 *bool
 	ClusterLogTimeoutMilliseconds            *int
+	EnableUserAccessTokens                   *bool
 }
 
 type ClusterSettings struct {
@@ -252,6 +260,11 @@ type EmailSettings struct {
 	EnableEmailBatching      *bool
 	EmailBatchingBufferSize  *int
 	EmailBatchingInterval    *int
+
+	// PushNotificationTransport is how push notifications are delivered. The file transport writes them to
+	// PushNotificationFile instead of sending them so that their contents can be checked without a push proxy.
+	PushNotificationTransport *string
+	PushNotificationFile      *string
 }
 
 type RateLimitSettings struct {
@@ -389,29 +402,38 @@ type WebrtcSettings struct {
 	TurnSharedKey       *string
 }
 
+type DataRetentionSettings struct {
+	EnableMessageDeletion      *bool
+	MessageRetentionDays       *int
+	DirectMessageRetentionDays *int
+	TeamRetentionDays          map[string]int
+	ChannelRetentionDays       map[string]int
+}
+
 type Config struct {
-	ServiceSettings      ServiceSettings
-	TeamSettings         TeamSettings
-	SqlSettings          SqlSettings
-	LogSettings          LogSettings
-	PasswordSettings     PasswordSettings
-	FileSettings         FileSettings
-	EmailSettings        EmailSettings
-	RateLimitSettings    RateLimitSettings
-	PrivacySettings      PrivacySettings
-	SupportSettings      SupportSettings
-	GitLabSettings       SSOSettings
-	GoogleSettings       SSOSettings
-	Office365Settings    SSOSettings
-	LdapSettings         LdapSettings
-	ComplianceSettings   ComplianceSettings
-	LocalizationSettings LocalizationSettings
-	SamlSettings         SamlSettings
-	NativeAppSettings    NativeAppSettings
-	ClusterSettings      ClusterSettings
-	MetricsSettings      MetricsSettings
-	AnalyticsSettings    AnalyticsSettings
-	WebrtcSettings       WebrtcSettings
+	ServiceSettings       ServiceSettings
+	TeamSettings          TeamSettings
+	SqlSettings           SqlSettings
+	LogSettings           LogSettings
+	PasswordSettings      PasswordSettings
+	FileSettings          FileSettings
+	EmailSettings         EmailSettings
+	RateLimitSettings     RateLimitSettings
+	PrivacySettings       PrivacySettings
+	SupportSettings       SupportSettings
+	GitLabSettings        SSOSettings
+	GoogleSettings        SSOSettings
+	Office365Settings     SSOSettings
+	LdapSettings          LdapSettings
+	ComplianceSettings    ComplianceSettings
+	LocalizationSettings  LocalizationSettings
+	SamlSettings          SamlSettings
+	NativeAppSettings     NativeAppSettings
+	ClusterSettings       ClusterSettings
+	MetricsSettings       MetricsSettings
+	AnalyticsSettings     AnalyticsSettings
+	WebrtcSettings        WebrtcSettings
+	DataRetentionSettings DataRetentionSettings
 }
 
 func (o *Config) ToJson() string {
@@ -660,6 +682,16 @@ func (o *Config) SetDefaults() {
 		*o.EmailSettings.PushNotificationContents = GENERIC_NOTIFICATION
 	}
 
+	if o.EmailSettings.PushNotificationTransport == nil {
+		o.EmailSettings.PushNotificationTransport = new(string)
+		*o.EmailSettings.PushNotificationTransport = PUSH_NOTIFICATION_TRANSPORT_PROXY
+	}
+
+	if o.EmailSettings.PushNotificationFile == nil {
+		o.EmailSettings.PushNotificationFile = new(string)
+		*o.EmailSettings.PushNotificationFile = ""
+	}
+
 	if o.EmailSettings.FeedbackOrganization == nil {
 		o.EmailSettings.FeedbackOrganization = new(string)
 		*o.EmailSettings.FeedbackOrganization = EMAIL_SETTINGS_DEFAULT_FEEDBACK_ORGANIZATION
@@ -855,6 +887,11 @@ func (o *Config) SetDefaults() {
 		*o.ServiceSettings.EnableCommands = false
 	}
 
+	if o.ServiceSettings.EnableUserAccessTokens == nil {
+		o.ServiceSettings.EnableUserAccessTokens = new(bool)
+		*o.ServiceSettings.EnableUserAccessTokens = false
+	}
+
 	if o.ServiceSettings.EnableOnlyAdminIntegrations == nil {
 		o.ServiceSettings.EnableOnlyAdminIntegrations = new(bool)
 		*o.ServiceSettings.EnableOnlyAdminIntegrations = true
@@ -1142,6 +1179,7 @@ func (o *Config) SetDefaults() {
 	}
 
 	o.defaultWebrtcSettings()
+	o.defaultDataRetentionSettings()
 }
 
 func (o *Config) IsValid() *AppError {
@@ -1260,6 +1298,18 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.email_batching_interval.app_error", nil, "")
 	}
 
+	if !(*o.EmailSettings.PushNotificationContents == GENERIC_NOTIFICATION || *o.EmailSettings.PushNotificationContents == FULL_NOTIFICATION || *o.EmailSettings.PushNotificationContents == ID_LOADED_NOTIFICATION) {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.push_notification_contents.app_error", nil, "")
+	}
+
+	if !(*o.EmailSettings.PushNotificationTransport == PUSH_NOTIFICATION_TRANSPORT_PROXY || *o.EmailSettings.PushNotificationTransport == PUSH_NOTIFICATION_TRANSPORT_FILE) {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.push_notification_transport.app_error", nil, "")
+	}
+
+	if *o.EmailSettings.PushNotificationTransport == PUSH_NOTIFICATION_TRANSPORT_FILE && len(*o.EmailSettings.PushNotificationFile) == 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.push_notification_file.app_error", nil, "")
+	}
+
 	if o.RateLimitSettings.MemoryStoreSize <= 0 {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.rate_mem.app_error", nil, "")
 	}
@@ -1360,6 +1410,10 @@ func (o *Config) IsValid() *AppError {
 		return err
 	}
 
+	if err := o.isValidDataRetentionSettings(); err != nil {
+		return err
+	}
+
 	if !(*o.ServiceSettings.ConnectionSecurity == CONN_SECURITY_NONE || *o.ServiceSettings.ConnectionSecurity == CONN_SECURITY_TLS) {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.webserver_security.app_error", nil, "")
 	}
@@ -1482,3 +1536,52 @@ func (o *Config) isValidWebrtcSettings() *AppError {
 
 	return nil
 }
+
+func (o *Config) defaultDataRetentionSettings() {
+	if o.DataRetentionSettings.EnableMessageDeletion == nil {
+		o.DataRetentionSettings.EnableMessageDeletion = new(bool)
+		*o.DataRetentionSettings.EnableMessageDeletion = false
+	}
+
+	if o.DataRetentionSettings.MessageRetentionDays == nil {
+		o.DataRetentionSettings.MessageRetentionDays = new(int)
+		*o.DataRetentionSettings.MessageRetentionDays = DATA_RETENTION_SETTINGS_DEFAULT_MESSAGE_RETENTION_DAYS
+	}
+
+	if o.DataRetentionSettings.DirectMessageRetentionDays == nil {
+		o.DataRetentionSettings.DirectMessageRetentionDays = new(int)
+		*o.DataRetentionSettings.DirectMessageRetentionDays = DATA_RETENTION_SETTINGS_DEFAULT_DIRECT_MESSAGE_RETENTION_DAYS
+	}
+
+	if o.DataRetentionSettings.TeamRetentionDays == nil {
+		o.DataRetentionSettings.TeamRetentionDays = map[string]int{}
+	}
+
+	if o.DataRetentionSettings.ChannelRetentionDays == nil {
+		o.DataRetentionSettings.ChannelRetentionDays = map[string]int{}
+	}
+}
+
+func (o *Config) isValidDataRetentionSettings() *AppError {
+	if *o.DataRetentionSettings.MessageRetentionDays < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention_message_retention_days.app_error", nil, "")
+	}
+
+	if *o.DataRetentionSettings.DirectMessageRetentionDays < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention_direct_message_retention_days.app_error", nil, "")
+	}
+
+	for teamId, days := range o.DataRetentionSettings.TeamRetentionDays {
+		if len(teamId) != 26 || days < 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention_team_retention_days.app_error", nil, "team_id="+teamId)
+		}
+	}
+
+	for channelId, days := range o.DataRetentionSettings.ChannelRetentionDays {
+		if len(channelId) != 26 || days < 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention_channel_retention_days.app_error", nil, "channel_id="+channelId)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	CUSTOM_STATUS_TEXT_MAX_RUNES = 100
+	CUSTOM_STATUS_MAX_LENGTH     = 1024
+)
+
+// CustomStatus is a short message that a user shows alongside their name, such as "In a meeting".
+type CustomStatus struct {
+	Emoji     string `json:"emoji"` // the name of the emoji, without colons
+	Text      string `json:"text"`
+	ExpiresAt int64  `json:"expires_at"` // when the custom status is cleared, or 0 if it's kept until the user clears it
+}
+
+func (o *CustomStatus) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func CustomStatusFromJson(data io.Reader) *CustomStatus {
+	var o *CustomStatus
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func (o *CustomStatus) IsValid() *AppError {
+	if o.Emoji == "" && o.Text == "" {
+		return NewAppError("CustomStatus.IsValid", "model.custom_status.is_valid.empty.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.Emoji) > EMOJI_NAME_MAX_LENGTH || strings.ContainsAny(o.Emoji, ": \t\n") {
+		return NewAppError("CustomStatus.IsValid", "model.custom_status.is_valid.emoji.app_error", nil, "emoji="+o.Emoji, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Text) > CUSTOM_STATUS_TEXT_MAX_RUNES {
+		return NewAppError("CustomStatus.IsValid", "model.custom_status.is_valid.text.app_error", map[string]interface{}{"Max": CUSTOM_STATUS_TEXT_MAX_RUNES}, "", http.StatusBadRequest)
+	}
+
+	if o.ExpiresAt < 0 {
+		return NewAppError("CustomStatus.IsValid", "model.custom_status.is_valid.expires_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// ParseCustomStatus reads a custom status typed as text, such as ":calendar: In a meeting", where the emoji is
+// optional.
+func ParseCustomStatus(text string) *CustomStatus {
+	text = strings.TrimSpace(text)
+
+	if strings.HasPrefix(text, ":") {
+		if end := strings.Index(text[1:], ":"); end > 0 && !strings.ContainsAny(text[1:end+1], " \t\n") {
+			return &CustomStatus{Emoji: text[1 : end+1], Text: strings.TrimSpace(text[end+2:])}
+		}
+	}
+
+	return &CustomStatus{Text: text}
+}
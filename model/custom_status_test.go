@@ -0,0 +1,67 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCustomStatusJson(t *testing.T) {
+	o := CustomStatus{Emoji: "calendar", Text: "In a meeting", ExpiresAt: GetMillis()}
+	json := o.ToJson()
+	ro := CustomStatusFromJson(strings.NewReader(json))
+
+	if *ro != o {
+		t.Fatal("Ids do not match")
+	}
+}
+
+func TestCustomStatusIsValid(t *testing.T) {
+	o := CustomStatus{}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without an emoji or text")
+	}
+
+	o.Emoji = "calendar"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Emoji = ":calendar:"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with colons in the emoji name")
+	}
+
+	o.Emoji = ""
+	o.Text = strings.Repeat("ü", CUSTOM_STATUS_TEXT_MAX_RUNES)
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Text += "a"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with text that's too long")
+	}
+
+	o.Text = "In a meeting"
+	o.ExpiresAt = -1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a negative expiry")
+	}
+}
+
+func TestParseCustomStatus(t *testing.T) {
+	for text, expected := range map[string]CustomStatus{
+		":calendar: In a meeting": {Emoji: "calendar", Text: "In a meeting"},
+		"  :palm_tree:  ":         {Emoji: "palm_tree"},
+		"In a meeting":            {Text: "In a meeting"},
+		": not an emoji: hello":   {Text: ": not an emoji: hello"},
+		"::":                      {Text: "::"},
+	} {
+		if actual := ParseCustomStatus(text); *actual != expected {
+			t.Fatalf("%q was parsed as %v instead of %v", text, actual, expected)
+		}
+	}
+}
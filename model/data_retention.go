@@ -0,0 +1,94 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"sort"
+)
+
+const (
+	DATA_RETENTION_SCOPE_CHANNEL = "channel"
+	DATA_RETENTION_SCOPE_TEAM    = "team"
+	DATA_RETENTION_SCOPE_DIRECT  = "direct"
+	DATA_RETENTION_SCOPE_DEFAULT = "default"
+
+	DAY_MILLISECONDS = 24 * 60 * 60 * 1000
+)
+
+// DataRetentionPolicy describes a set of channels whose posts are kept for a given number of days.
+// Depending on the scope, ScopeId is a channel or team id. Channels and teams that have their own
+// policy are listed in ExcludeChannelIds and ExcludeTeamIds so that exactly one policy applies to
+// every channel.
+type DataRetentionPolicy struct {
+	Scope             string
+	ScopeId           string
+	Days              int
+	ExcludeTeamIds    []string
+	ExcludeChannelIds []string
+}
+
+// EndTime returns the creation time before which posts covered by the policy should be deleted.
+func (p *DataRetentionPolicy) EndTime(now int64) int64 {
+	return now - int64(p.Days)*DAY_MILLISECONDS
+}
+
+// GetDataRetentionPolicies builds the list of policies described by the settings. Policies with a
+// retention of 0 days keep posts forever and are left out, but they still exclude their channels
+// and teams from broader policies.
+func GetDataRetentionPolicies(settings *DataRetentionSettings) []*DataRetentionPolicy {
+	policies := []*DataRetentionPolicy{}
+
+	channelIds := sortedKeys(settings.ChannelRetentionDays)
+	teamIds := sortedKeys(settings.TeamRetentionDays)
+
+	for _, channelId := range channelIds {
+		if days := settings.ChannelRetentionDays[channelId]; days > 0 {
+			policies = append(policies, &DataRetentionPolicy{
+				Scope:   DATA_RETENTION_SCOPE_CHANNEL,
+				ScopeId: channelId,
+				Days:    days,
+			})
+		}
+	}
+
+	if days := *settings.DirectMessageRetentionDays; days > 0 {
+		policies = append(policies, &DataRetentionPolicy{
+			Scope:             DATA_RETENTION_SCOPE_DIRECT,
+			Days:              days,
+			ExcludeChannelIds: channelIds,
+		})
+	}
+
+	for _, teamId := range teamIds {
+		if days := settings.TeamRetentionDays[teamId]; days > 0 {
+			policies = append(policies, &DataRetentionPolicy{
+				Scope:             DATA_RETENTION_SCOPE_TEAM,
+				ScopeId:           teamId,
+				Days:              days,
+				ExcludeChannelIds: channelIds,
+			})
+		}
+	}
+
+	if days := *settings.MessageRetentionDays; days > 0 {
+		policies = append(policies, &DataRetentionPolicy{
+			Scope:             DATA_RETENTION_SCOPE_DEFAULT,
+			Days:              days,
+			ExcludeTeamIds:    teamIds,
+			ExcludeChannelIds: channelIds,
+		})
+	}
+
+	return policies
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
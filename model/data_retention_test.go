@@ -0,0 +1,88 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"testing"
+)
+
+func TestGetDataRetentionPolicies(t *testing.T) {
+	cfg := Config{}
+	cfg.defaultDataRetentionSettings()
+	settings := &cfg.DataRetentionSettings
+
+	if policies := GetDataRetentionPolicies(settings); len(policies) != 2 {
+		t.Fatal("should have a default and a direct message policy")
+	} else if policies[0].Scope != DATA_RETENTION_SCOPE_DIRECT || policies[1].Scope != DATA_RETENTION_SCOPE_DEFAULT {
+		t.Fatal("policies in the wrong order")
+	}
+
+	teamId := NewId()
+	keptTeamId := NewId()
+	channelId := NewId()
+
+	*settings.MessageRetentionDays = 540
+	*settings.DirectMessageRetentionDays = 90
+	settings.TeamRetentionDays[teamId] = 30
+	settings.TeamRetentionDays[keptTeamId] = 0
+	settings.ChannelRetentionDays[channelId] = 7
+
+	policies := GetDataRetentionPolicies(settings)
+	if len(policies) != 4 {
+		t.Fatal("should have 4 policies, got", len(policies))
+	}
+
+	if policies[0].Scope != DATA_RETENTION_SCOPE_CHANNEL || policies[0].ScopeId != channelId || policies[0].Days != 7 {
+		t.Fatal("bad channel policy")
+	}
+
+	if policies[1].Scope != DATA_RETENTION_SCOPE_DIRECT || policies[1].Days != 90 {
+		t.Fatal("bad direct message policy")
+	} else if len(policies[1].ExcludeChannelIds) != 1 || policies[1].ExcludeChannelIds[0] != channelId {
+		t.Fatal("direct message policy should exclude the overridden channel")
+	}
+
+	if policies[2].Scope != DATA_RETENTION_SCOPE_TEAM || policies[2].ScopeId != teamId || policies[2].Days != 30 {
+		t.Fatal("bad team policy")
+	}
+
+	if policies[3].Scope != DATA_RETENTION_SCOPE_DEFAULT || policies[3].Days != 540 {
+		t.Fatal("bad default policy")
+	} else if len(policies[3].ExcludeTeamIds) != 2 {
+		t.Fatal("default policy should exclude both overridden teams")
+	}
+
+	*settings.MessageRetentionDays = 0
+	if policies := GetDataRetentionPolicies(settings); len(policies) != 3 {
+		t.Fatal("a retention of 0 days should keep posts forever")
+	}
+}
+
+func TestDataRetentionPolicyEndTime(t *testing.T) {
+	policy := &DataRetentionPolicy{Days: 2}
+
+	if policy.EndTime(3*DAY_MILLISECONDS) != DAY_MILLISECONDS {
+		t.Fatal("bad end time")
+	}
+}
+
+func TestConfigIsValidDataRetentionSettings(t *testing.T) {
+	cfg := Config{}
+	cfg.defaultDataRetentionSettings()
+
+	if err := cfg.isValidDataRetentionSettings(); err != nil {
+		t.Fatal(err)
+	}
+
+	*cfg.DataRetentionSettings.MessageRetentionDays = -1
+	if err := cfg.isValidDataRetentionSettings(); err == nil {
+		t.Fatal("negative retention should be invalid")
+	}
+	*cfg.DataRetentionSettings.MessageRetentionDays = 10
+
+	cfg.DataRetentionSettings.ChannelRetentionDays["junk"] = 10
+	if err := cfg.isValidDataRetentionSettings(); err == nil {
+		t.Fatal("bad channel id should be invalid")
+	}
+}
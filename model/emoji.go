@@ -8,6 +8,10 @@ import (
 	"io"
 )
 
+const (
+	EMOJI_NAME_MAX_LENGTH = 64
+)
+
 type Emoji struct {
 	Id        string `json:"id"`
 	CreateAt  int64  `json:"create_at"`
@@ -34,7 +38,7 @@ func (emoji *Emoji) IsValid() *AppError {
 		return NewLocAppError("Emoji.IsValid", "model.emoji.user_id.app_error", nil, "")
 	}
 
-	if len(emoji.Name) == 0 || len(emoji.Name) > 64 {
+	if len(emoji.Name) == 0 || len(emoji.Name) > EMOJI_NAME_MAX_LENGTH {
 		return NewLocAppError("Emoji.IsValid", "model.emoji.name.app_error", nil, "")
 	}
 
@@ -4,10 +4,11 @@
 package model
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"image"
-	"image/gif"
+	_ "image/gif"
 	"io"
 	"mime"
 	"path/filepath"
@@ -116,12 +117,11 @@ func (o *FileInfo) IsImage() bool {
 	return strings.HasPrefix(o.MimeType, "image")
 }
 
-func GetInfoForBytes(name string, data []byte) (*FileInfo, *AppError) {
+// NewInfo returns a FileInfo populated with everything that can be determined from the name of a file.
+func NewInfo(name string) *FileInfo {
 	info := &FileInfo{
 		Name: name,
-		Size: int64(len(data)),
 	}
-	var err *AppError
 
 	extension := strings.ToLower(filepath.Ext(name))
 	info.MimeType = mime.TypeByExtension(extension)
@@ -133,6 +133,13 @@ func GetInfoForBytes(name string, data []byte) (*FileInfo, *AppError) {
 		info.Extension = extension
 	}
 
+	return info
+}
+
+func GetInfoForBytes(name string, data []byte) (*FileInfo, *AppError) {
+	info := NewInfo(name)
+	info.Size = int64(len(data))
+
 	if info.IsImage() {
 		// Only set the width and height if it's actually an image that we can understand
 		if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
@@ -141,20 +148,101 @@ func GetInfoForBytes(name string, data []byte) (*FileInfo, *AppError) {
 
 			if info.MimeType == "image/gif" {
 				// Just show the gif itself instead of a preview image for animated gifs
-				if gifConfig, err := gif.DecodeAll(bytes.NewReader(data)); err != nil {
-					// Still return the rest of the info even though it doesn't appear to be an actual gif
-					info.HasPreviewImage = true
-					err = NewLocAppError("GetInfoForBytes", "model.file_info.get.gif.app_error", nil, "name="+name)
-				} else {
-					info.HasPreviewImage = len(gifConfig.Image) == 1
-				}
+				info.HasPreviewImage = !IsAnimatedGif(bytes.NewReader(data))
 			} else {
 				info.HasPreviewImage = true
 			}
 		}
 	}
 
-	return info, err
+	return info, nil
+}
+
+const (
+	gifImageSeparator      = 0x2C
+	gifExtensionIntroducer = 0x21
+	gifColorTableFlag      = 0x80
+)
+
+// IsAnimatedGif returns true if the given data is a gif with more than one frame. Rather than decoding the frames, it
+// only walks the gif's blocks until it finds a second image. Data that isn't a gif is treated as a still image.
+func IsAnimatedGif(data io.Reader) bool {
+	reader := bufio.NewReader(data)
+
+	// the header is followed by the logical screen descriptor, which says whether there's a global color table
+	header := make([]byte, 13)
+	if _, err := io.ReadFull(reader, header); err != nil || (string(header[:6]) != "GIF87a" && string(header[:6]) != "GIF89a") {
+		return false
+	}
+
+	if !skipGifColorTable(reader, header[10]) {
+		return false
+	}
+
+	images := 0
+	for {
+		introducer, err := reader.ReadByte()
+		if err != nil {
+			return false
+		}
+
+		switch introducer {
+		case gifImageSeparator:
+			if images++; images > 1 {
+				return true
+			}
+
+			// the image descriptor is followed by an optional local color table, the minimum LZW code size and then
+			// the image data
+			descriptor := make([]byte, 9)
+			if _, err := io.ReadFull(reader, descriptor); err != nil {
+				return false
+			} else if !skipGifColorTable(reader, descriptor[8]) {
+				return false
+			} else if _, err := reader.ReadByte(); err != nil {
+				return false
+			} else if !skipGifSubBlocks(reader) {
+				return false
+			}
+		case gifExtensionIntroducer:
+			if _, err := reader.ReadByte(); err != nil {
+				return false
+			} else if !skipGifSubBlocks(reader) {
+				return false
+			}
+		default:
+			// the trailer or anything that isn't valid
+			return false
+		}
+	}
+}
+
+// skipGifColorTable skips the color table that follows a gif's logical screen descriptor or image descriptor if the
+// given packed field of that descriptor says that there is one.
+func skipGifColorTable(reader *bufio.Reader, packed byte) bool {
+	if packed&gifColorTableFlag == 0 {
+		return true
+	}
+
+	_, err := reader.Discard(3 * (1 << ((packed & 0x07) + 1)))
+	return err == nil
+}
+
+// skipGifSubBlocks skips a sequence of data sub-blocks, each of which starts with its length, up to the empty block
+// that ends it.
+func skipGifSubBlocks(reader *bufio.Reader) bool {
+	for {
+		size, err := reader.ReadByte()
+		if err != nil {
+			return false
+		} else if size == 0 {
+			return true
+		}
+
+		if _, err := reader.Discard(int(size)); err != nil {
+			return false
+		}
+	}
 }
 
 func GetEtagForFileInfos(infos []*FileInfo) string {
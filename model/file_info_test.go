@@ -4,8 +4,11 @@
 package model
 
 import (
+	"bytes"
 	"encoding/base64"
-	_ "image/gif"
+	"image"
+	"image/color"
+	"image/gif"
 	_ "image/png"
 	"io/ioutil"
 	"strings"
@@ -198,3 +201,66 @@ func TestGetInfoForFile(t *testing.T) {
 		t.Fatalf("Got incorrect mime type: %v", info.MimeType)
 	}
 }
+
+func TestNewInfo(t *testing.T) {
+	if info := NewInfo("test.png"); info.Name != "test.png" {
+		t.Fatalf("Got incorrect filename: %v", info.Name)
+	} else if info.Extension != "png" {
+		t.Fatalf("Got incorrect extension: %v", info.Extension)
+	} else if info.MimeType != "image/png" {
+		t.Fatalf("Got incorrect mime type: %v", info.MimeType)
+	} else if info.Size != 0 || info.Width != 0 || info.Height != 0 || info.HasPreviewImage {
+		t.Fatal("Shouldn't have set any fields that depend on the contents of the file")
+	}
+
+	if info := NewInfo("filewithoutextension"); info.Extension != "" {
+		t.Fatalf("Got incorrect extension: %v", info.Extension)
+	} else if info.MimeType != "" {
+		t.Fatalf("Got incorrect mime type: %v", info.MimeType)
+	}
+}
+
+func TestIsAnimatedGif(t *testing.T) {
+	// base 64 encoded version of handtinywhite.gif from http://probablyprogramming.com/2009/03/15/the-tiniest-gif-ever
+	gifFile, _ := base64.StdEncoding.DecodeString("R0lGODlhAQABAIABAP///wAAACwAAAAAAQABAAACAkQBADs=")
+	if IsAnimatedGif(bytes.NewReader(gifFile)) {
+		t.Fatal("Single frame gif shouldn't be animated")
+	}
+
+	animatedGifFile, err := ioutil.ReadFile("../tests/testgif.gif")
+	if err != nil {
+		t.Fatalf("Failed to load testgif.gif: %v", err.Error())
+	}
+	if !IsAnimatedGif(bytes.NewReader(animatedGifFile)) {
+		t.Fatal("Multiple frame gif should be animated")
+	}
+
+	// the data stops partway through the second frame
+	twoFrameGifFile := createTestAnimatedGif(t)
+	if !IsAnimatedGif(bytes.NewReader(twoFrameGifFile[:len(twoFrameGifFile)-8])) {
+		t.Fatal("Multiple frame gif should be animated without reading all of its frames")
+	}
+
+	if IsAnimatedGif(bytes.NewReader(gifFile[:20])) {
+		t.Fatal("Truncated gif shouldn't be animated")
+	}
+
+	if IsAnimatedGif(strings.NewReader("not a gif")) {
+		t.Fatal("Invalid data shouldn't be animated")
+	}
+}
+
+func createTestAnimatedGif(t *testing.T) []byte {
+	var buffer bytes.Buffer
+
+	frame := image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black, color.White})
+	img := &gif.GIF{
+		Image: []*image.Paletted{frame, frame},
+		Delay: []int{0, 0},
+	}
+	if err := gif.EncodeAll(&buffer, img); err != nil {
+		t.Fatalf("failed to create animated gif: %v", err.Error())
+	}
+
+	return buffer.Bytes()
+}
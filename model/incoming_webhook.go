@@ -6,36 +6,43 @@ package model
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io"
+	"net/http"
 	"regexp"
 	"strings"
 )
 
 const (
 	DEFAULT_WEBHOOK_USERNAME = "webhook"
+
+	INCOMING_WEBHOOK_MAX_RATE_LIMIT = 6000 // posts per minute
+	INCOMING_WEBHOOK_MAX_FILES      = 5
 )
 
 type IncomingWebhook struct {
-	Id          string `json:"id"`
-	CreateAt    int64  `json:"create_at"`
-	UpdateAt    int64  `json:"update_at"`
-	DeleteAt    int64  `json:"delete_at"`
-	UserId      string `json:"user_id"`
-	ChannelId   string `json:"channel_id"`
-	TeamId      string `json:"team_id"`
-	DisplayName string `json:"display_name"`
-	Description string `json:"description"`
+	Id              string `json:"id"`
+	CreateAt        int64  `json:"create_at"`
+	UpdateAt        int64  `json:"update_at"`
+	DeleteAt        int64  `json:"delete_at"`
+	UserId          string `json:"user_id"`
+	ChannelId       string `json:"channel_id"`
+	TeamId          string `json:"team_id"`
+	DisplayName     string `json:"display_name"`
+	Description     string `json:"description"`
+	BotUserId       string `json:"bot_user_id"`
+	ChannelLocked   bool   `json:"channel_locked"`   // stops requests from posting anywhere other than ChannelId
+	RateLimit       int    `json:"rate_limit"`       // the most posts that the hook can make per minute, or 0 for no limit
+	MessageTemplate string `json:"message_template"` // maps payloads in another format to requests, see RenderIncomingWebhookMessageTemplate
 }
 
 type IncomingWebhookRequest struct {
-	Text        string          `json:"text"`
-	Username    string          `json:"username"`
-	IconURL     string          `json:"icon_url"`
-	ChannelName string          `json:"channel"`
-	Props       StringInterface `json:"props"`
-	Attachments interface{}     `json:"attachments"`
-	Type        string          `json:"type"`
+	Text        string             `json:"text"`
+	Username    string             `json:"username"`
+	IconURL     string             `json:"icon_url"`
+	ChannelName string             `json:"channel"`
+	Props       StringInterface    `json:"props"`
+	Attachments []*SlackAttachment `json:"attachments"`
+	Type        string             `json:"type"`
 }
 
 func (o *IncomingWebhook) ToJson() string {
@@ -58,6 +65,15 @@ func IncomingWebhookFromJson(data io.Reader) *IncomingWebhook {
 	}
 }
 
+func (o *IncomingWebhookRequest) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
 func IncomingWebhookListToJson(l []*IncomingWebhook) string {
 	b, err := json.Marshal(l)
 	if err != nil {
@@ -112,6 +128,24 @@ func (o *IncomingWebhook) IsValid() *AppError {
 		return NewLocAppError("IncomingWebhook.IsValid", "model.incoming_hook.description.app_error", nil, "")
 	}
 
+	if len(o.BotUserId) != 0 && len(o.BotUserId) != 26 {
+		return NewLocAppError("IncomingWebhook.IsValid", "model.incoming_hook.bot_user_id.app_error", nil, "")
+	}
+
+	if o.RateLimit < 0 || o.RateLimit > INCOMING_WEBHOOK_MAX_RATE_LIMIT {
+		return NewLocAppError("IncomingWebhook.IsValid", "model.incoming_hook.rate_limit.app_error", map[string]interface{}{"Max": INCOMING_WEBHOOK_MAX_RATE_LIMIT}, "")
+	}
+
+	if len(o.MessageTemplate) > INCOMING_WEBHOOK_MESSAGE_TEMPLATE_MAX_LENGTH {
+		return NewLocAppError("IncomingWebhook.IsValid", "model.incoming_hook.message_template.length.app_error", map[string]interface{}{"Max": INCOMING_WEBHOOK_MESSAGE_TEMPLATE_MAX_LENGTH}, "")
+	}
+
+	if len(o.MessageTemplate) > 0 {
+		if err := ValidateIncomingWebhookMessageTemplate(o.MessageTemplate); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -135,22 +169,24 @@ func (o *IncomingWebhook) PreUpdate() {
 // try to handle that. An example invalid JSON string from an incoming webhook
 // might look like this (strings for both "text" and "fallback" attributes are
 // invalid JSON strings because they contain unescaped newlines and tabs):
-//  `{
-//    "text": "this is a test
-//						 that contains a newline and tabs",
-//    "attachments": [
-//      {
-//        "fallback": "Required plain-text summary of the attachment
-//										that contains a newline and tabs",
-//        "color": "#36a64f",
-//  			...
-//        "text": "Optional text that appears within the attachment
-//								 that contains a newline and tabs",
-//  			...
-//        "thumb_url": "http://example.com/path/to/thumb.png"
-//      }
-//    ]
-//  }`
+//
+//	 `{
+//	   "text": "this is a test
+//							 that contains a newline and tabs",
+//	   "attachments": [
+//	     {
+//	       "fallback": "Required plain-text summary of the attachment
+//											that contains a newline and tabs",
+//	       "color": "#36a64f",
+//	 			...
+//	       "text": "Optional text that appears within the attachment
+//									 that contains a newline and tabs",
+//	 			...
+//	       "thumb_url": "http://example.com/path/to/thumb.png"
+//	     }
+//	   ]
+//	 }`
+//
 // This function will search for `"key": "value"` pairs, and escape \n, \t
 // from the value.
 func escapeControlCharsFromPayload(by []byte) []byte {
@@ -212,37 +248,28 @@ func expandAnnouncement(text string) string {
 func expandAnnouncements(i *IncomingWebhookRequest) {
 	i.Text = expandAnnouncement(i.Text)
 
-	if i.Attachments != nil {
-		attachments := i.Attachments.([]interface{})
-		for _, attachment := range attachments {
-			a := attachment.(map[string]interface{})
-
-			if a["pretext"] != nil {
-				a["pretext"] = expandAnnouncement(a["pretext"].(string))
-			}
+	for _, attachment := range i.Attachments {
+		if attachment == nil {
+			continue
+		}
 
-			if a["text"] != nil {
-				a["text"] = expandAnnouncement(a["text"].(string))
-			}
+		attachment.Pretext = expandAnnouncement(attachment.Pretext)
+		attachment.Text = expandAnnouncement(attachment.Text)
+		attachment.Title = expandAnnouncement(attachment.Title)
 
-			if a["title"] != nil {
-				a["title"] = expandAnnouncement(a["title"].(string))
+		for _, field := range attachment.Fields {
+			if field == nil {
+				continue
 			}
 
-			if a["fields"] != nil {
-				fields := a["fields"].([]interface{})
-				for _, field := range fields {
-					f := field.(map[string]interface{})
-					if f["value"] != nil {
-						f["value"] = expandAnnouncement(fmt.Sprintf("%v", f["value"]))
-					}
-				}
+			if value, ok := field.Value.(string); ok {
+				field.Value = expandAnnouncement(value)
 			}
 		}
 	}
 }
 
-func IncomingWebhookRequestFromJson(data io.Reader) *IncomingWebhookRequest {
+func IncomingWebhookRequestFromJson(data io.Reader) (*IncomingWebhookRequest, *AppError) {
 	buf := new(bytes.Buffer)
 	buf.ReadFrom(data)
 	by := buf.Bytes()
@@ -253,11 +280,15 @@ func IncomingWebhookRequestFromJson(data io.Reader) *IncomingWebhookRequest {
 	if err != nil {
 		o, err = decodeIncomingWebhookRequest(escapeControlCharsFromPayload(by))
 		if err != nil {
-			return nil
+			return nil, NewAppError("IncomingWebhookRequestFromJson", "model.incoming_hook.parse_data.app_error", nil, err.Error(), http.StatusBadRequest)
 		}
 	}
 
+	if err := ValidateSlackAttachments(o.Attachments); err != nil {
+		return nil, err
+	}
+
 	expandAnnouncements(o)
 
-	return o
+	return o, nil
 }
@@ -0,0 +1,156 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"text/template"
+	"text/template/parse"
+)
+
+// An incoming webhook's message template lets it accept payloads in a fixed format chosen by another service. The
+// template is a JSON object shaped like an IncomingWebhookRequest, such as
+//
+//	{"text": "Build {{.build.number}} {{.build.status}}", "attachments": [{"title": "{{.repository.name}}"}]}
+//
+// where every string is a text/template that is executed with the decoded payload as its data.
+const INCOMING_WEBHOOK_MESSAGE_TEMPLATE_MAX_LENGTH = 4000
+
+// messageTemplateFuncs are available to message templates. emptyIfMissing is added to the end of every action that
+// prints something so that fields missing from the payload, or set to null, come out empty.
+var messageTemplateFuncs = template.FuncMap{
+	"emptyIfMissing": func(value interface{}) interface{} {
+		if value == nil {
+			return ""
+		}
+
+		return value
+	},
+}
+
+// ValidateIncomingWebhookMessageTemplate checks that a message template is a JSON object and that each of its strings
+// is a valid template.
+func ValidateIncomingWebhookMessageTemplate(messageTemplate string) *AppError {
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(messageTemplate), &parsed); err != nil {
+		return NewAppError("ValidateIncomingWebhookMessageTemplate", "model.incoming_hook.message_template.json.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	if _, err := renderMessageTemplateValue(parsed, nil, false); err != nil {
+		return NewAppError("ValidateIncomingWebhookMessageTemplate", "model.incoming_hook.message_template.parse.app_error", map[string]interface{}{"Error": err.Error()}, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// RenderIncomingWebhookMessageTemplate fills in a message template with the fields of a JSON payload to build the
+// request that the payload stands for.
+func RenderIncomingWebhookMessageTemplate(messageTemplate string, payload io.Reader) (*IncomingWebhookRequest, *AppError) {
+	var data interface{}
+	decoder := json.NewDecoder(payload)
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, NewAppError("RenderIncomingWebhookMessageTemplate", "model.incoming_hook.message_template.payload.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(messageTemplate), &parsed); err != nil {
+		return nil, NewAppError("RenderIncomingWebhookMessageTemplate", "model.incoming_hook.message_template.json.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	rendered, err := renderMessageTemplateValue(parsed, data, true)
+	if err != nil {
+		return nil, NewAppError("RenderIncomingWebhookMessageTemplate", "model.incoming_hook.message_template.execute.app_error", map[string]interface{}{"Error": err.Error()}, "", http.StatusBadRequest)
+	}
+
+	b, _ := json.Marshal(rendered)
+
+	return IncomingWebhookRequestFromJson(bytes.NewReader(b))
+}
+
+// renderMessageTemplateValue walks a decoded template, replacing each string with the result of executing it. If
+// execute is false, the strings are only parsed.
+func renderMessageTemplateValue(value interface{}, data interface{}, execute bool) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		tmpl, err := template.New("").Option("missingkey=zero").Funcs(messageTemplateFuncs).Parse(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if !execute {
+			return v, nil
+		}
+
+		// fields missing from the payload would otherwise be printed as "<no value>" since the payload is decoded
+		// into interfaces
+		for _, t := range tmpl.Templates() {
+			printMissingAsEmpty(t.Tree.Root)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+
+		return buf.String(), nil
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			renderedItem, err := renderMessageTemplateValue(item, data, execute)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = renderedItem
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			renderedItem, err := renderMessageTemplateValue(item, data, execute)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = renderedItem
+		}
+		return rendered, nil
+	default:
+		return v, nil
+	}
+}
+
+// printMissingAsEmpty pipes the value printed by each action in a parsed template through emptyIfMissing.
+func printMissingAsEmpty(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			printMissingAsEmpty(child)
+		}
+	case *parse.ActionNode:
+		// actions that only set variables don't print anything
+		if len(n.Pipe.Decl) == 0 {
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier("emptyIfMissing").SetPos(n.Pos)},
+			})
+		}
+	case *parse.IfNode:
+		printMissingAsEmpty(n.List)
+		printMissingAsEmpty(n.ElseList)
+	case *parse.RangeNode:
+		printMissingAsEmpty(n.List)
+		printMissingAsEmpty(n.ElseList)
+	case *parse.WithNode:
+		printMissingAsEmpty(n.List)
+		printMissingAsEmpty(n.ElseList)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateIncomingWebhookMessageTemplate(t *testing.T) {
+	if err := ValidateIncomingWebhookMessageTemplate(`{"text": "Build {{.build.number}}", "attachments": [{"title": "{{.repo}}"}]}`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateIncomingWebhookMessageTemplate(`"{{.text}}"`); err == nil {
+		t.Fatal("should have required an object")
+	}
+
+	if err := ValidateIncomingWebhookMessageTemplate(`{"text": "{{.build.number"}`); err == nil {
+		t.Fatal("should have failed to parse the template")
+	}
+
+	if err := ValidateIncomingWebhookMessageTemplate(`{"attachments": [{"fields": [{"value": "{{if}}"}]}]}`); err == nil {
+		t.Fatal("should have checked nested templates")
+	}
+}
+
+func TestRenderIncomingWebhookMessageTemplate(t *testing.T) {
+	messageTemplate := `{
+		"text": "Build {{.build.number}} of {{.repository.name}} {{.build.status}}{{if .build.url}} ({{.build.url}}){{end}}",
+		"username": "{{.sender}}",
+		"attachments": [{"title": "{{.build.commit.message}}", "color": "#ff0000"}]
+	}`
+
+	payload := `{
+		"build": {"number": 1234567890, "status": "failed", "commit": {"message": "Fix \"the\" tests"}},
+		"repository": {"name": "platform"}
+	}`
+
+	req, err := RenderIncomingWebhookMessageTemplate(messageTemplate, strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Text != "Build 1234567890 of platform failed" {
+		t.Fatal("rendered the wrong text", req.Text)
+	}
+
+	if req.Username != "" {
+		t.Fatal("missing fields should be empty", req.Username)
+	}
+
+	if len(req.Attachments) != 1 || req.Attachments[0].Title != `Fix "the" tests` || req.Attachments[0].Color != "#ff0000" {
+		t.Fatal("rendered the wrong attachments", req.Attachments)
+	}
+
+	// text in the payload is kept even if it looks like a missing field
+	if req, err := RenderIncomingWebhookMessageTemplate(`{"text": "{{.message}}{{range .items}} {{.name}}{{end}}{{.missing}}"}`, strings.NewReader(`{"message": "<no value>", "items": [{"name": "a"}, {}, {"name": null}]}`)); err != nil {
+		t.Fatal(err)
+	} else if req.Text != "<no value> a  " {
+		t.Fatal("rendered the wrong text", req.Text)
+	}
+
+	if _, err := RenderIncomingWebhookMessageTemplate(messageTemplate, strings.NewReader("payload=text")); err == nil {
+		t.Fatal("should have required a JSON payload")
+	}
+
+	if _, err := RenderIncomingWebhookMessageTemplate(`{"text": "{{.build.number.digits}}"}`, strings.NewReader(payload)); err == nil {
+		t.Fatal("should have failed to execute the template")
+	}
+}
@@ -4,6 +4,7 @@
 package model
 
 import (
+	"net/http"
 	"strings"
 	"testing"
 )
@@ -89,6 +90,36 @@ func TestIncomingWebhookIsValid(t *testing.T) {
 	if err := o.IsValid(); err != nil {
 		t.Fatal(err)
 	}
+
+	o.RateLimit = -1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.RateLimit = INCOMING_WEBHOOK_MAX_RATE_LIMIT + 1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.RateLimit = 60
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.MessageTemplate = `{"text": "{{.status"}`
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.MessageTemplate = `{"text": "` + strings.Repeat("1", INCOMING_WEBHOOK_MESSAGE_TEMPLATE_MAX_LENGTH) + `"}`
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.MessageTemplate = `{"text": "{{.status}}"}`
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func TestIncomingWebhookPreSave(t *testing.T) {
@@ -108,10 +139,10 @@ func TestIncomingWebhookRequestFromJson_Announcements(t *testing.T) {
 	// simple payload
 	payload := `{"text": "` + text + `"}`
 	data := strings.NewReader(payload)
-	iwr := IncomingWebhookRequestFromJson(data)
+	iwr, err := IncomingWebhookRequestFromJson(data)
 
-	if iwr == nil {
-		t.Fatal("IncomingWebhookRequest should not be nil")
+	if err != nil {
+		t.Fatal(err)
 	}
 	if iwr.Text != expected {
 		t.Fatalf("Sample text should be: %s, got: %s", expected, iwr.Text)
@@ -136,27 +167,25 @@ func TestIncomingWebhookRequestFromJson_Announcements(t *testing.T) {
 		}`
 
 	data = strings.NewReader(payload)
-	iwr = IncomingWebhookRequestFromJson(data)
+	iwr, err = IncomingWebhookRequestFromJson(data)
 
-	if iwr == nil {
-		t.Fatal("IncomingWebhookRequest should not be nil")
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	attachments := iwr.Attachments.([]interface{})
-	attachment := attachments[0].(map[string]interface{})
-	if attachment["pretext"] != expected {
-		t.Fatalf("Sample attachment pretext should be: %s, got: %s", expected, attachment["pretext"])
+	attachment := iwr.Attachments[0]
+	if attachment.Pretext != expected {
+		t.Fatalf("Sample attachment pretext should be: %s, got: %s", expected, attachment.Pretext)
 	}
-	if attachment["text"] != expected {
-		t.Fatalf("Sample attachment text should be: %s, got: %s", expected, attachment["text"])
+	if attachment.Text != expected {
+		t.Fatalf("Sample attachment text should be: %s, got: %s", expected, attachment.Text)
 	}
-	if attachment["title"] != expected {
-		t.Fatalf("Sample attachment title should be: %s, got: %s", expected, attachment["title"])
+	if attachment.Title != expected {
+		t.Fatalf("Sample attachment title should be: %s, got: %s", expected, attachment.Title)
 	}
-	fields := attachment["fields"].([]interface{})
-	field := fields[0].(map[string]interface{})
-	if field["value"] != expected {
-		t.Fatalf("Sample attachment field value should be: %s, got: %s", expected, field["value"])
+	field := attachment.Fields[0]
+	if field.Value != expected {
+		t.Fatalf("Sample attachment field value should be: %s, got: %s", expected, field.Value)
 	}
 }
 
@@ -214,20 +243,37 @@ func TestIncomingWebhookRequestFromJson(t *testing.T) {
 
 		// try to create an IncomingWebhookRequest from the payload
 		data := strings.NewReader(payload)
-		iwr := IncomingWebhookRequestFromJson(data)
+		iwr, err := IncomingWebhookRequestFromJson(data)
 
 		// After it has been decoded, the JSON string won't contain the escape char anymore
 		expected := strings.Replace(text, `\"`, `"`, -1)
-		if iwr == nil {
-			t.Fatal("IncomingWebhookRequest should not be nil")
+		if err != nil {
+			t.Fatal(err)
 		}
 		if iwr.Text != expected {
 			t.Fatalf("Sample %d text should be: %s, got: %s", i, expected, iwr.Text)
 		}
-		attachments := iwr.Attachments.([]interface{})
-		attachment := attachments[0].(map[string]interface{})
-		if attachment["text"] != expected {
-			t.Fatalf("Sample %d attachment text should be: %s, got: %s", i, expected, attachment["text"])
+		if iwr.Attachments[0].Text != expected {
+			t.Fatalf("Sample %d attachment text should be: %s, got: %s", i, expected, iwr.Attachments[0].Text)
+		}
+	}
+}
+
+func TestIncomingWebhookRequestFromJson_InvalidAttachments(t *testing.T) {
+	payloads := []string{
+		`{"text": "test", "attachments": "not a list"}`,
+		`{"text": "test", "attachments": ["not an object"]}`,
+		`{"text": "test", "attachments": [{"fields": "not a list"}]}`,
+		`{"text": "test", "attachments": [{"fields": [{"title": "test", "value": {"not": "a string"}}]}]}`,
+		`{"text": "test", "attachments": [{"title_link": "javascript:alert(1)"}]}`,
+		`{"text": "test", "attachments": [null]}`,
+	}
+
+	for i, payload := range payloads {
+		if _, err := IncomingWebhookRequestFromJson(strings.NewReader(payload)); err == nil {
+			t.Fatalf("payload %d should have been rejected", i)
+		} else if err.StatusCode != http.StatusBadRequest {
+			t.Fatalf("payload %d should have been a bad request, got %v", i, err.StatusCode)
 		}
 	}
 }
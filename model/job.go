@@ -1,100 +1,144 @@
-// Copyright (c) 2016 Mattermost, Inc. All Rights Reserved.
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
 // See License.txt for license information.
 
 package model
 
 import (
-	"fmt"
-	"time"
+	"encoding/json"
+	"io"
 )
 
-type TaskFunc func()
+const (
+	JOB_TYPE_DATA_RETENTION              = "data_retention"
+	JOB_TYPE_SCHEDULED_POSTS             = "scheduled_posts"
+	JOB_TYPE_DND_STATUSES                = "dnd_statuses"
+	JOB_TYPE_CUSTOM_STATUSES             = "custom_statuses"
+	JOB_TYPE_COMMAND_WEBHOOK_CLEANUP     = "command_webhook_cleanup"
+	JOB_TYPE_OUTGOING_WEBHOOK_DELIVERIES = "outgoing_webhook_deliveries"
+
+	JOB_STATUS_PENDING          = "pending"
+	JOB_STATUS_IN_PROGRESS      = "in_progress"
+	JOB_STATUS_SUCCESS          = "success"
+	JOB_STATUS_ERROR            = "error"
+	JOB_STATUS_CANCEL_REQUESTED = "cancel_requested"
+	JOB_STATUS_CANCELED         = "canceled"
+)
 
-type ScheduledTask struct {
-	Name      string        `json:"name"`
-	Interval  time.Duration `json:"interval"`
-	Recurring bool          `json:"recurring"`
-	function  TaskFunc
-	timer     *time.Timer
+var JOB_TYPES = []string{
+	JOB_TYPE_DATA_RETENTION,
+	JOB_TYPE_SCHEDULED_POSTS,
+	JOB_TYPE_DND_STATUSES,
+	JOB_TYPE_CUSTOM_STATUSES,
+	JOB_TYPE_COMMAND_WEBHOOK_CLEANUP,
+	JOB_TYPE_OUTGOING_WEBHOOK_DELIVERIES,
 }
 
-var tasks = make(map[string]*ScheduledTask)
-
-func addTask(task *ScheduledTask) {
-	tasks[task.Name] = task
+type Job struct {
+	Id             string    `json:"id"`
+	Type           string    `json:"type"`
+	Priority       int64     `json:"priority"`
+	CreateAt       int64     `json:"create_at"`
+	StartAt        int64     `json:"start_at"`
+	LastActivityAt int64     `json:"last_activity_at"`
+	Status         string    `json:"status"`
+	Progress       int64     `json:"progress"`
+	LastError      string    `json:"last_error"`
+	Data           StringMap `json:"data"`
 }
 
-func removeTaskByName(name string) {
-	delete(tasks, name)
-}
+func (j *Job) PreSave() {
+	if j.Id == "" {
+		j.Id = NewId()
+	}
 
-func GetTaskByName(name string) *ScheduledTask {
-	if task, ok := tasks[name]; ok {
-		return task
+	if j.Status == "" {
+		j.Status = JOB_STATUS_PENDING
 	}
-	return nil
-}
 
-func GetAllTasks() *map[string]*ScheduledTask {
-	return &tasks
+	if j.Data == nil {
+		j.Data = make(StringMap)
+	}
+
+	j.CreateAt = GetMillis()
+	j.LastActivityAt = j.CreateAt
 }
 
-func CreateTask(name string, function TaskFunc, timeToExecution time.Duration) *ScheduledTask {
-	task := &ScheduledTask{
-		Name:      name,
-		Interval:  timeToExecution,
-		Recurring: false,
-		function:  function,
+func (j *Job) IsValid() *AppError {
+	if len(j.Id) != 26 {
+		return NewAppError("Job.IsValid", "model.job.is_valid.id.app_error", nil, "id="+j.Id, 400)
 	}
 
-	taskRunner := func() {
-		go task.function()
-		removeTaskByName(task.Name)
+	if j.CreateAt == 0 {
+		return NewAppError("Job.IsValid", "model.job.is_valid.create_at.app_error", nil, "id="+j.Id, 400)
 	}
 
-	task.timer = time.AfterFunc(timeToExecution, taskRunner)
+	if !IsValidJobType(j.Type) {
+		return NewAppError("Job.IsValid", "model.job.is_valid.type.app_error", nil, "id="+j.Id, 400)
+	}
 
-	addTask(task)
+	switch j.Status {
+	case JOB_STATUS_PENDING:
+	case JOB_STATUS_IN_PROGRESS:
+	case JOB_STATUS_SUCCESS:
+	case JOB_STATUS_ERROR:
+	case JOB_STATUS_CANCEL_REQUESTED:
+	case JOB_STATUS_CANCELED:
+	default:
+		return NewAppError("Job.IsValid", "model.job.is_valid.status.app_error", nil, "id="+j.Id, 400)
+	}
 
-	return task
+	if j.Progress < 0 || j.Progress > 100 {
+		return NewAppError("Job.IsValid", "model.job.is_valid.progress.app_error", nil, "id="+j.Id, 400)
+	}
+
+	return nil
 }
 
-func CreateRecurringTask(name string, function TaskFunc, interval time.Duration) *ScheduledTask {
-	task := &ScheduledTask{
-		Name:      name,
-		Interval:  interval,
-		Recurring: true,
-		function:  function,
-	}
+// IsFinished returns true if the job has stopped running and will not be picked up again.
+func (j *Job) IsFinished() bool {
+	return j.Status == JOB_STATUS_SUCCESS || j.Status == JOB_STATUS_ERROR || j.Status == JOB_STATUS_CANCELED
+}
 
-	taskRecurer := func() {
-		go task.function()
-		task.timer.Reset(task.Interval)
+func IsValidJobType(jobType string) bool {
+	for _, t := range JOB_TYPES {
+		if t == jobType {
+			return true
+		}
 	}
 
-	task.timer = time.AfterFunc(interval, taskRecurer)
-
-	addTask(task)
+	return false
+}
 
-	return task
+func (j *Job) ToJson() string {
+	if b, err := json.Marshal(j); err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
 }
 
-func (task *ScheduledTask) Cancel() {
-	task.timer.Stop()
-	removeTaskByName(task.Name)
+func JobFromJson(data io.Reader) *Job {
+	var job Job
+	if err := json.NewDecoder(data).Decode(&job); err == nil {
+		return &job
+	} else {
+		return nil
+	}
 }
 
-// Executes the task immediatly. A recurring task will be run regularally after interval.
-func (task *ScheduledTask) Execute() {
-	task.function()
-	task.timer.Reset(task.Interval)
+func JobsToJson(jobs []*Job) string {
+	if b, err := json.Marshal(jobs); err != nil {
+		return "[]"
+	} else {
+		return string(b)
+	}
 }
 
-func (task *ScheduledTask) String() string {
-	return fmt.Sprintf(
-		"%s\nInterval: %s\nRecurring: %t\n",
-		task.Name,
-		task.Interval.String(),
-		task.Recurring,
-	)
+func JobsFromJson(data io.Reader) []*Job {
+	var jobs []*Job
+	if err := json.NewDecoder(data).Decode(&jobs); err == nil {
+		return jobs
+	} else {
+		return nil
+	}
 }
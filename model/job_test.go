@@ -1,188 +1,80 @@
-// Copyright (c) 2016 Mattermost, Inc. All Rights Reserved.
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
 // See License.txt for license information.
 
 package model
 
 import (
+	"strings"
 	"testing"
-	"time"
 )
 
-func TestCreateTask(t *testing.T) {
-	TASK_NAME := "Test Task"
-	TASK_TIME := time.Second * 3
-
-	testValue := 0
-	testFunc := func() {
-		testValue = 1
-	}
-
-	task := CreateTask(TASK_NAME, testFunc, TASK_TIME)
-	if testValue != 0 {
-		t.Fatal("Unexpected execuition of task")
+func TestJobJson(t *testing.T) {
+	job := Job{
+		Id:       NewId(),
+		Type:     JOB_TYPE_DATA_RETENTION,
+		CreateAt: GetMillis(),
+		Status:   JOB_STATUS_PENDING,
+		Data:     StringMap{"key": "value"},
 	}
 
-	time.Sleep(TASK_TIME + time.Second)
-
-	if testValue != 1 {
-		t.Fatal("Task did not execute")
+	if rjob := JobFromJson(strings.NewReader(job.ToJson())); rjob == nil || rjob.Id != job.Id || rjob.Data["key"] != "value" {
+		t.Fatal("job should have round tripped through json")
 	}
 
-	if task.Name != TASK_NAME {
-		t.Fatal("Bad name")
-	}
-
-	if task.Interval != TASK_TIME {
-		t.Fatal("Bad interval")
-	}
-
-	if task.Recurring != false {
-		t.Fatal("should not reccur")
+	if rjobs := JobsFromJson(strings.NewReader(JobsToJson([]*Job{&job}))); len(rjobs) != 1 || rjobs[0].Id != job.Id {
+		t.Fatal("jobs should have round tripped through json")
 	}
 }
 
-func TestCreateRecurringTask(t *testing.T) {
-	TASK_NAME := "Test Recurring Task"
-	TASK_TIME := time.Second * 3
-
-	testValue := 0
-	testFunc := func() {
-		testValue += 1
-	}
-
-	task := CreateRecurringTask(TASK_NAME, testFunc, TASK_TIME)
-	if testValue != 0 {
-		t.Fatal("Unexpected execuition of task")
-	}
-
-	time.Sleep(TASK_TIME + time.Second)
+func TestJobPreSave(t *testing.T) {
+	job := Job{Type: JOB_TYPE_DATA_RETENTION}
+	job.PreSave()
 
-	if testValue != 1 {
-		t.Fatal("Task did not execute")
+	if len(job.Id) != 26 || job.CreateAt == 0 || job.Status != JOB_STATUS_PENDING || job.Data == nil {
+		t.Fatal("job should have been initialized")
 	}
-
-	time.Sleep(TASK_TIME)
-
-	if testValue != 2 {
-		t.Fatal("Task did not re-execute")
-	}
-
-	if task.Name != TASK_NAME {
-		t.Fatal("Bad name")
-	}
-
-	if task.Interval != TASK_TIME {
-		t.Fatal("Bad interval")
-	}
-
-	if task.Recurring != true {
-		t.Fatal("should reccur")
-	}
-
-	task.Cancel()
 }
 
-func TestCancelTask(t *testing.T) {
-	TASK_NAME := "Test Task"
-	TASK_TIME := time.Second * 3
+func TestJobIsValid(t *testing.T) {
+	job := Job{Type: JOB_TYPE_DATA_RETENTION}
+	job.PreSave()
 
-	testValue := 0
-	testFunc := func() {
-		testValue = 1
+	if err := job.IsValid(); err != nil {
+		t.Fatal(err)
 	}
 
-	task := CreateTask(TASK_NAME, testFunc, TASK_TIME)
-	if testValue != 0 {
-		t.Fatal("Unexpected execuition of task")
+	job.Type = "junk"
+	if err := job.IsValid(); err == nil {
+		t.Fatal("type should be invalid")
 	}
-	task.Cancel()
-
-	time.Sleep(TASK_TIME + time.Second)
 
-	if testValue != 0 {
-		t.Fatal("Unexpected execuition of task")
+	job.Type = JOB_TYPE_DATA_RETENTION
+	job.Status = "junk"
+	if err := job.IsValid(); err == nil {
+		t.Fatal("status should be invalid")
 	}
-}
 
-func TestGetAllTasks(t *testing.T) {
-	doNothing := func() {}
-
-	CreateTask("Task1", doNothing, time.Hour)
-	CreateTask("Task2", doNothing, time.Second)
-	CreateRecurringTask("Task3", doNothing, time.Second)
-	task4 := CreateRecurringTask("Task4", doNothing, time.Second)
-
-	task4.Cancel()
-
-	time.Sleep(time.Second * 3)
-
-	tasks := *GetAllTasks()
-	if len(tasks) != 2 {
-		t.Fatal("Wrong number of tasks got: ", len(tasks))
-	}
-	for _, task := range tasks {
-		if task.Name != "Task1" && task.Name != "Task3" {
-			t.Fatal("Wrong tasks")
-		}
-	}
-}
-
-func TestExecuteTask(t *testing.T) {
-	TASK_NAME := "Test Task"
-	TASK_TIME := time.Second * 5
-
-	testValue := 0
-	testFunc := func() {
-		testValue += 1
-	}
-
-	task := CreateTask(TASK_NAME, testFunc, TASK_TIME)
-	if testValue != 0 {
-		t.Fatal("Unexpected execuition of task")
-	}
-
-	task.Execute()
-
-	if testValue != 1 {
-		t.Fatal("Task did not execute")
+	job.Status = JOB_STATUS_IN_PROGRESS
+	job.Progress = 101
+	if err := job.IsValid(); err == nil {
+		t.Fatal("progress should be invalid")
 	}
 
-	time.Sleep(TASK_TIME + time.Second)
-
-	if testValue != 2 {
-		t.Fatal("Task re-executed")
+	job.Progress = 50
+	job.Id = "junk"
+	if err := job.IsValid(); err == nil {
+		t.Fatal("id should be invalid")
 	}
 }
 
-func TestExecuteTaskRecurring(t *testing.T) {
-	TASK_NAME := "Test Recurring Task"
-	TASK_TIME := time.Second * 5
-
-	testValue := 0
-	testFunc := func() {
-		testValue += 1
-	}
-
-	task := CreateRecurringTask(TASK_NAME, testFunc, TASK_TIME)
-	if testValue != 0 {
-		t.Fatal("Unexpected execuition of task")
+func TestJobIsFinished(t *testing.T) {
+	job := Job{Status: JOB_STATUS_IN_PROGRESS}
+	if job.IsFinished() {
+		t.Fatal("in progress job shouldn't be finished")
 	}
 
-	time.Sleep(time.Second * 3)
-
-	task.Execute()
-	if testValue != 1 {
-		t.Fatal("Task did not execute")
-	}
-
-	time.Sleep(time.Second * 3)
-	if testValue != 1 {
-		t.Fatal("Task should not have executed before 5 seconds")
-	}
-
-	time.Sleep(time.Second * 3)
-
-	if testValue != 2 {
-		t.Fatal("Task did not re-execute after forced execution")
+	job.Status = JOB_STATUS_CANCELED
+	if !job.IsFinished() {
+		t.Fatal("canceled job should be finished")
 	}
 }
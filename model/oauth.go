@@ -29,6 +29,7 @@ type OAuthApp struct {
 	CallbackUrls StringArray `json:"callback_urls"`
 	Homepage     string      `json:"homepage"`
 	IsTrusted    bool        `json:"is_trusted"`
+	BotUserId    string      `json:"bot_user_id"`
 }
 
 // IsValid validates the app and returns an error if it isn't configured
@@ -83,6 +84,10 @@ func (a *OAuthApp) IsValid() *AppError {
 		}
 	}
 
+	if len(a.BotUserId) != 0 && len(a.BotUserId) != 26 {
+		return NewLocAppError("OAuthApp.IsValid", "model.oauth.is_valid.bot_user_id.app_error", nil, "app_id="+a.Id)
+	}
+
 	return nil
 }
 
@@ -12,21 +12,49 @@ import (
 	"strings"
 )
 
+const (
+	OUTGOING_WEBHOOK_EVENT_POSTED              = "posted"
+	OUTGOING_WEBHOOK_EVENT_POST_EDITED         = "post_edited"
+	OUTGOING_WEBHOOK_EVENT_POST_DELETED        = "post_deleted"
+	OUTGOING_WEBHOOK_EVENT_REACTION_ADDED      = "reaction_added"
+	OUTGOING_WEBHOOK_EVENT_USER_JOINED_CHANNEL = "user_joined_channel"
+	OUTGOING_WEBHOOK_EVENT_USER_LEFT_CHANNEL   = "user_left_channel"
+	OUTGOING_WEBHOOK_EVENT_CHANNEL_CREATED     = "channel_created"
+
+	// OUTGOING_WEBHOOK_PAYLOAD_VERSION is increased whenever fields are added to or changed in the payload so that
+	// integrations can tell which fields to expect. Version 2 added the event, version, root_id and emoji_name fields.
+	OUTGOING_WEBHOOK_PAYLOAD_VERSION = 2
+)
+
+var outgoingWebhookEvents = []string{
+	OUTGOING_WEBHOOK_EVENT_POSTED,
+	OUTGOING_WEBHOOK_EVENT_POST_EDITED,
+	OUTGOING_WEBHOOK_EVENT_POST_DELETED,
+	OUTGOING_WEBHOOK_EVENT_REACTION_ADDED,
+	OUTGOING_WEBHOOK_EVENT_USER_JOINED_CHANNEL,
+	OUTGOING_WEBHOOK_EVENT_USER_LEFT_CHANNEL,
+	OUTGOING_WEBHOOK_EVENT_CHANNEL_CREATED,
+}
+
 type OutgoingWebhook struct {
-	Id           string      `json:"id"`
-	Token        string      `json:"token"`
-	CreateAt     int64       `json:"create_at"`
-	UpdateAt     int64       `json:"update_at"`
-	DeleteAt     int64       `json:"delete_at"`
-	CreatorId    string      `json:"creator_id"`
-	ChannelId    string      `json:"channel_id"`
-	TeamId       string      `json:"team_id"`
-	TriggerWords StringArray `json:"trigger_words"`
-	TriggerWhen  int         `json:"trigger_when"`
-	CallbackURLs StringArray `json:"callback_urls"`
-	DisplayName  string      `json:"display_name"`
-	Description  string      `json:"description"`
-	ContentType  string      `json:"content_type"`
+	Id                     string      `json:"id"`
+	Token                  string      `json:"token"`
+	CreateAt               int64       `json:"create_at"`
+	UpdateAt               int64       `json:"update_at"`
+	DeleteAt               int64       `json:"delete_at"`
+	CreatorId              string      `json:"creator_id"`
+	ChannelId              string      `json:"channel_id"`
+	TeamId                 string      `json:"team_id"`
+	TriggerWords           StringArray `json:"trigger_words"`
+	TriggerWhen            int         `json:"trigger_when"`
+	CallbackURLs           StringArray `json:"callback_urls"`
+	DisplayName            string      `json:"display_name"`
+	Description            string      `json:"description"`
+	ContentType            string      `json:"content_type"`
+	BotUserId              string      `json:"bot_user_id"`
+	SigningSecret          string      `json:"signing_secret"`
+	IncludePrivateChannels bool        `json:"include_private_channels"`
+	Events                 StringArray `json:"events"` // an empty list means only OUTGOING_WEBHOOK_EVENT_POSTED
 }
 
 type OutgoingWebhookPayload struct {
@@ -41,6 +69,10 @@ type OutgoingWebhookPayload struct {
 	PostId      string `json:"post_id"`
 	Text        string `json:"text"`
 	TriggerWord string `json:"trigger_word"`
+	Event       string `json:"event"`
+	Version     int    `json:"version"`
+	RootId      string `json:"root_id,omitempty"`
+	EmojiName   string `json:"emoji_name,omitempty"`
 }
 
 func (o *OutgoingWebhookPayload) ToJSON() string {
@@ -65,6 +97,14 @@ func (o *OutgoingWebhookPayload) ToFormValues() string {
 	v.Set("post_id", o.PostId)
 	v.Set("text", o.Text)
 	v.Set("trigger_word", o.TriggerWord)
+	v.Set("event", o.Event)
+	v.Set("version", strconv.Itoa(o.Version))
+	if o.RootId != "" {
+		v.Set("root_id", o.RootId)
+	}
+	if o.EmojiName != "" {
+		v.Set("emoji_name", o.EmojiName)
+	}
 
 	return v.Encode()
 }
@@ -177,6 +217,20 @@ func (o *OutgoingWebhook) IsValid() *AppError {
 		return NewLocAppError("OutgoingWebhook.IsValid", "model.outgoing_hook.is_valid.content_type.app_error", nil, "")
 	}
 
+	if len(o.BotUserId) != 0 && len(o.BotUserId) != 26 {
+		return NewLocAppError("OutgoingWebhook.IsValid", "model.outgoing_hook.is_valid.bot_user_id.app_error", nil, "")
+	}
+
+	if len(fmt.Sprintf("%s", o.Events)) > 1024 {
+		return NewLocAppError("OutgoingWebhook.IsValid", "model.outgoing_hook.is_valid.events.app_error", nil, "")
+	}
+
+	for _, event := range o.Events {
+		if !IsValidOutgoingWebhookEvent(event) {
+			return NewLocAppError("OutgoingWebhook.IsValid", "model.outgoing_hook.is_valid.events.app_error", nil, "event="+event)
+		}
+	}
+
 	return nil
 }
 
@@ -189,6 +243,10 @@ func (o *OutgoingWebhook) PreSave() {
 		o.Token = NewId()
 	}
 
+	if o.SigningSecret == "" {
+		o.SigningSecret = NewSigningSecret()
+	}
+
 	o.CreateAt = GetMillis()
 	o.UpdateAt = o.CreateAt
 }
@@ -224,3 +282,29 @@ func (o *OutgoingWebhook) TriggerWordStartsWith(word string) bool {
 
 	return false
 }
+
+// HasEvent returns true if the hook should be triggered by the given event. Hooks that don't list any events are
+// only triggered by new posts, as they were before events were added.
+func (o *OutgoingWebhook) HasEvent(event string) bool {
+	if len(o.Events) == 0 {
+		return event == OUTGOING_WEBHOOK_EVENT_POSTED
+	}
+
+	for _, e := range o.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsValidOutgoingWebhookEvent(event string) bool {
+	for _, e := range outgoingWebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
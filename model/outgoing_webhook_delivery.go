@@ -0,0 +1,123 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING = "pending"
+	OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS = "success"
+	OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED  = "failed"
+
+	OUTGOING_WEBHOOK_DELIVERY_PAYLOAD_MAX_LENGTH = 65535
+	OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH   = 1024
+)
+
+// OutgoingWebhookDelivery records the delivery of an outgoing webhook's payload to one of its callback URLs. The
+// payload is kept so that the delivery can be sent again.
+type OutgoingWebhookDelivery struct {
+	Id          string `json:"id"`
+	HookId      string `json:"hook_id"`
+	ChannelId   string `json:"channel_id"`
+	PostId      string `json:"post_id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Payload     string `json:"payload"`
+	Status      string `json:"status"`
+	Attempts    int    `json:"attempts"`
+	StatusCode  int    `json:"status_code"`
+	Latency     int64  `json:"latency"` // in milliseconds, for the last attempt
+	Error       string `json:"error"`
+	CreateAt    int64  `json:"create_at"`
+	UpdateAt    int64  `json:"update_at"`
+}
+
+func (o *OutgoingWebhookDelivery) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Status == "" {
+		o.Status = OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *OutgoingWebhookDelivery) PreUpdate() {
+	o.UpdateAt = GetMillis()
+
+	if len(o.Error) > OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH {
+		o.Error = o.Error[:OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH]
+	}
+}
+
+func (o *OutgoingWebhookDelivery) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.HookId) != 26 {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.hook_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.channel_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.URL) == 0 || len(o.URL) > 1024 || !IsValidHttpUrl(o.URL) {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.url.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Payload) > OUTGOING_WEBHOOK_DELIVERY_PAYLOAD_MAX_LENGTH {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.payload.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	switch o.Status {
+	case OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING, OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS, OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED:
+	default:
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.status.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 || o.UpdateAt == 0 {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *OutgoingWebhookDelivery) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func OutgoingWebhookDeliveryFromJson(data io.Reader) *OutgoingWebhookDelivery {
+	var o *OutgoingWebhookDelivery
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func OutgoingWebhookDeliveryListToJson(l []*OutgoingWebhookDelivery) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return "[]"
+	} else {
+		return string(b)
+	}
+}
+
+func OutgoingWebhookDeliveryListFromJson(data io.Reader) []*OutgoingWebhookDelivery {
+	var l []*OutgoingWebhookDelivery
+	json.NewDecoder(data).Decode(&l)
+	return l
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutgoingWebhookDeliveryIsValid(t *testing.T) {
+	o := &OutgoingWebhookDelivery{
+		HookId:      NewId(),
+		ChannelId:   NewId(),
+		PostId:      NewId(),
+		URL:         "http://example.com/hook",
+		ContentType: "application/json",
+		Payload:     "{}",
+	}
+	o.PreSave()
+
+	if o.Status != OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING {
+		t.Fatal("should default to pending")
+	}
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.URL = "ftp://example.com"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a non-http url")
+	}
+	o.URL = "http://example.com/hook"
+
+	o.Status = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with an unknown status")
+	}
+	o.Status = OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED
+
+	o.Payload = strings.Repeat("a", OUTGOING_WEBHOOK_DELIVERY_PAYLOAD_MAX_LENGTH+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a payload that's too long")
+	}
+	o.Payload = "{}"
+
+	o.HookId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a bad hook id")
+	}
+}
+
+func TestOutgoingWebhookDeliveryPreUpdate(t *testing.T) {
+	o := &OutgoingWebhookDelivery{Error: strings.Repeat("a", OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH+1)}
+	o.PreUpdate()
+
+	if len(o.Error) != OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH {
+		t.Fatal("should have truncated the error")
+	}
+
+	if o.UpdateAt == 0 {
+		t.Fatal("should have set update at")
+	}
+}
+
+func TestOutgoingWebhookDeliveryJson(t *testing.T) {
+	o := &OutgoingWebhookDelivery{Id: NewId(), HookId: NewId(), StatusCode: 200}
+	ro := OutgoingWebhookDeliveryFromJson(strings.NewReader(o.ToJson()))
+
+	if ro.Id != o.Id || ro.HookId != o.HookId || ro.StatusCode != o.StatusCode {
+		t.Fatal("ids didn't match")
+	}
+
+	l := OutgoingWebhookDeliveryListFromJson(strings.NewReader(OutgoingWebhookDeliveryListToJson([]*OutgoingWebhookDelivery{o})))
+	if len(l) != 1 || l[0].Id != o.Id {
+		t.Fatal("list didn't match")
+	}
+}
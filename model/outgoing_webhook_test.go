@@ -121,6 +121,16 @@ func TestOutgoingWebhookIsValid(t *testing.T) {
 	if err := o.IsValid(); err != nil {
 		t.Fatal(err)
 	}
+
+	o.Events = StringArray{OUTGOING_WEBHOOK_EVENT_POSTED, "unknown"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Events = StringArray{OUTGOING_WEBHOOK_EVENT_POST_EDITED, OUTGOING_WEBHOOK_EVENT_CHANNEL_CREATED}
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func TestOutgoingWebhookPayloadToFormValues(t *testing.T) {
@@ -136,6 +146,9 @@ func TestOutgoingWebhookPayloadToFormValues(t *testing.T) {
 		PostId:      "PostId",
 		Text:        "Text",
 		TriggerWord: "TriggerWord",
+		Event:       OUTGOING_WEBHOOK_EVENT_POSTED,
+		Version:     OUTGOING_WEBHOOK_PAYLOAD_VERSION,
+		RootId:      "RootId",
 	}
 	v := url.Values{}
 	v.Set("token", "Token")
@@ -149,6 +162,9 @@ func TestOutgoingWebhookPayloadToFormValues(t *testing.T) {
 	v.Set("post_id", "PostId")
 	v.Set("text", "Text")
 	v.Set("trigger_word", "TriggerWord")
+	v.Set("event", "posted")
+	v.Set("version", "2")
+	v.Set("root_id", "RootId")
 	if got, want := p.ToFormValues(), v.Encode(); !reflect.DeepEqual(got, want) {
 		t.Fatalf("Got %+v, wanted %+v", got, want)
 	}
@@ -174,3 +190,21 @@ func TestOutgoingWebhookTriggerWordStartsWith(t *testing.T) {
 		t.Fatal("Should return false")
 	}
 }
+
+func TestOutgoingWebhookHasEvent(t *testing.T) {
+	o := OutgoingWebhook{Id: NewId()}
+	if !o.HasEvent(OUTGOING_WEBHOOK_EVENT_POSTED) {
+		t.Fatal("hooks without events should be triggered by new posts")
+	}
+	if o.HasEvent(OUTGOING_WEBHOOK_EVENT_POST_EDITED) {
+		t.Fatal("hooks without events should only be triggered by new posts")
+	}
+
+	o.Events = StringArray{OUTGOING_WEBHOOK_EVENT_POST_EDITED}
+	if o.HasEvent(OUTGOING_WEBHOOK_EVENT_POSTED) {
+		t.Fatal("should return false")
+	}
+	if !o.HasEvent(OUTGOING_WEBHOOK_EVENT_POST_EDITED) {
+		t.Fatal("should return true")
+	}
+}
@@ -74,6 +74,11 @@ func PostFromJson(data io.Reader) *Post {
 	}
 }
 
+// Attachments returns the Slack-style attachments on the post, if it has any.
+func (o *Post) Attachments() []*SlackAttachment {
+	return SlackAttachmentsFromInterface(o.Props["attachments"])
+}
+
 func (o *Post) Etag() string {
 	return Etag(o.Id, o.UpdateAt)
 }
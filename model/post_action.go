@@ -0,0 +1,138 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type PostActionOptions struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// PostActionIntegration is where the server sends a request when an action is clicked. The context is passed back to
+// the integration as is, so it can be used to tell which action was clicked. It's visible to anybody that can read
+// the post, so it shouldn't contain secrets.
+type PostActionIntegration struct {
+	URL     string          `json:"url,omitempty"`
+	Context StringInterface `json:"context,omitempty"`
+}
+
+type PostActionIntegrationRequest struct {
+	UserId    string          `json:"user_id"`
+	ChannelId string          `json:"channel_id"`
+	TeamId    string          `json:"team_id"`
+	PostId    string          `json:"post_id"`
+	Context   StringInterface `json:"context,omitempty"`
+}
+
+type PostActionIntegrationResponse struct {
+	Update        *Post  `json:"update"`
+	EphemeralText string `json:"ephemeral_text"`
+}
+
+func (r *PostActionIntegrationRequest) ToJson() string {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostActionIntegrationRequestFromJson(data io.Reader) *PostActionIntegrationRequest {
+	decoder := json.NewDecoder(data)
+	var o PostActionIntegrationRequest
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (r *PostActionIntegrationResponse) ToJson() string {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostActionIntegrationResponseFromJson(data io.Reader) *PostActionIntegrationResponse {
+	decoder := json.NewDecoder(data)
+	var o PostActionIntegrationResponse
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// GenerateActionIds gives each of the post's attachment actions a new id, so that a click can be traced back to it.
+// Any ids sent with the post are replaced so that they're always generated by the server.
+func (o *Post) GenerateActionIds() {
+	attachments := o.Attachments()
+	if len(attachments) == 0 {
+		return
+	}
+
+	for _, attachment := range attachments {
+		if attachment == nil {
+			continue
+		}
+
+		for _, action := range attachment.Actions {
+			if action != nil {
+				action.Id = NewId()
+			}
+		}
+	}
+
+	o.AddProp("attachments", attachments)
+}
+
+// StripActionIntegrations removes the integrations from the post's attachment actions. Posts made by users can't
+// have working actions, since clicking one would make the server send a request to a URL of the user's choosing.
+func (o *Post) StripActionIntegrations() {
+	attachments := o.Attachments()
+	if len(attachments) == 0 {
+		return
+	}
+
+	for _, attachment := range attachments {
+		if attachment == nil {
+			continue
+		}
+
+		for _, action := range attachment.Actions {
+			if action != nil {
+				action.Integration = nil
+			}
+		}
+	}
+
+	o.AddProp("attachments", attachments)
+}
+
+// GetAction returns the attachment action with the given id, or nil if the post doesn't have one.
+func (o *Post) GetAction(id string) *SlackAttachmentAction {
+	for _, attachment := range o.Attachments() {
+		if attachment == nil {
+			continue
+		}
+
+		for _, action := range attachment.Actions {
+			if action != nil && action.Id == id {
+				return action
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostActionIntegrationResponseJson(t *testing.T) {
+	o := PostActionIntegrationResponse{Update: &Post{Id: NewId(), Message: "update"}, EphemeralText: "text"}
+	ro := PostActionIntegrationResponseFromJson(strings.NewReader(o.ToJson()))
+
+	if ro.Update.Id != o.Update.Id || ro.Update.Message != o.Update.Message || ro.EphemeralText != o.EphemeralText {
+		t.Fatal("response didn't match")
+	}
+}
+
+func TestPostGenerateActionIds(t *testing.T) {
+	post := &Post{}
+	post.GenerateActionIds()
+	if post.Props != nil {
+		t.Fatal("shouldn't add props to a post without attachments")
+	}
+
+	existingId := NewId()
+	post.AddProp("attachments", []*SlackAttachment{
+		{
+			Text: "text",
+			Actions: []*SlackAttachmentAction{
+				{Name: "one"},
+				{Id: existingId, Name: "two"},
+			},
+		},
+	})
+
+	// actions are generated after the post has been through JSON too
+	post = PostFromJson(strings.NewReader(post.ToJson()))
+	post.GenerateActionIds()
+
+	actions := post.Attachments()[0].Actions
+	if len(actions[0].Id) != 26 {
+		t.Fatal("should've generated an id")
+	}
+
+	if len(actions[1].Id) != 26 || actions[1].Id == existingId {
+		t.Fatal("should've replaced the id sent with the post")
+	}
+
+	if action := post.GetAction(actions[0].Id); action == nil || action.Name != "one" {
+		t.Fatal("should've found the action")
+	}
+
+	if action := post.GetAction(NewId()); action != nil {
+		t.Fatal("shouldn't have found an action")
+	}
+}
+
+func TestPostStripActionIntegrations(t *testing.T) {
+	post := &Post{}
+	post.StripActionIntegrations()
+	if post.Props != nil {
+		t.Fatal("shouldn't add props to a post without attachments")
+	}
+
+	post.AddProp("attachments", []*SlackAttachment{
+		{
+			Text: "text",
+			Actions: []*SlackAttachmentAction{
+				{Name: "one", Integration: &PostActionIntegration{URL: "http://localhost:8065"}},
+				nil,
+			},
+		},
+	})
+
+	post = PostFromJson(strings.NewReader(post.ToJson()))
+	post.StripActionIntegrations()
+
+	if actions := post.Attachments()[0].Actions; actions[0].Integration != nil || actions[0].Name != "one" {
+		t.Fatal("should have only removed the integration")
+	}
+}
@@ -38,6 +38,7 @@ const (
 	PREFERENCE_CATEGORY_NOTIFICATIONS = "notifications"
 	PREFERENCE_NAME_EMAIL_INTERVAL    = "email_interval"
 	PREFERENCE_DEFAULT_EMAIL_INTERVAL = "30" // default to match the interval of the "immediate" setting (ie 30 seconds)
+	PREFERENCE_NAME_QUIET_HOURS       = "quiet_hours"
 )
 
 type Preference struct {
@@ -91,6 +92,14 @@ func (o *Preference) IsValid() *AppError {
 		}
 	}
 
+	if o.Category == PREFERENCE_CATEGORY_NOTIFICATIONS && o.Name == PREFERENCE_NAME_QUIET_HOURS {
+		if quietHours := QuietHoursFromJson(strings.NewReader(o.Value)); quietHours == nil {
+			return NewLocAppError("Preference.IsValid", "model.preference.is_valid.quiet_hours.app_error", nil, "value="+o.Value)
+		} else if err := quietHours.IsValid(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
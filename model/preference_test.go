@@ -64,6 +64,17 @@ func TestPreferenceIsValid(t *testing.T) {
 	if err := preference.IsValid(); err != nil {
 		t.Fatal(err)
 	}
+
+	preference.Category = PREFERENCE_CATEGORY_NOTIFICATIONS
+	preference.Name = PREFERENCE_NAME_QUIET_HOURS
+	if err := preference.IsValid(); err == nil {
+		t.Fatal("should have failed with invalid quiet hours")
+	}
+
+	preference.Value = `{"start": "22:00", "end": "07:00", "timezone": "UTC"}`
+	if err := preference.IsValid(); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func TestPreferencePreUpdate(t *testing.T) {
@@ -36,6 +36,8 @@ type PushNotification struct {
 	ChannelId        string `json:"channel_id"`
 	ChannelName      string `json:"channel_name"`
 	Type             string `json:"type"`
+	PostId           string `json:"post_id"`
+	IsIdLoaded       bool   `json:"is_id_loaded"` // the contents have been left out and must be fetched using the post id
 }
 
 func (me *PushNotification) ToJson() string {
@@ -0,0 +1,119 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+const QUIET_HOURS_TIME_FORMAT = "15:04"
+
+// QuietHours is the daily period during which a user is put into do not disturb. It's stored as the value of the
+// user's quiet_hours notification preference.
+type QuietHours struct {
+	Start    string `json:"start"`          // the time of day that quiet hours start, like "22:00"
+	End      string `json:"end"`            // the time of day that quiet hours end, which is the next day if it's before the start
+	Timezone string `json:"timezone"`       // the name of the user's timezone, like "Europe/Berlin", or UTC if empty
+	Days     []int  `json:"days,omitempty"` // the days of the week that quiet hours start on, from 0 for Sunday, or every day if empty
+}
+
+func (o *QuietHours) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func QuietHoursFromJson(data io.Reader) *QuietHours {
+	var o *QuietHours
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func (o *QuietHours) IsValid() *AppError {
+	start, err := time.Parse(QUIET_HOURS_TIME_FORMAT, o.Start)
+	if err != nil {
+		return NewAppError("QuietHours.IsValid", "model.quiet_hours.is_valid.start.app_error", nil, "start="+o.Start, http.StatusBadRequest)
+	}
+
+	end, err := time.Parse(QUIET_HOURS_TIME_FORMAT, o.End)
+	if err != nil {
+		return NewAppError("QuietHours.IsValid", "model.quiet_hours.is_valid.end.app_error", nil, "end="+o.End, http.StatusBadRequest)
+	}
+
+	if start.Equal(end) {
+		return NewAppError("QuietHours.IsValid", "model.quiet_hours.is_valid.end.app_error", nil, "end="+o.End, http.StatusBadRequest)
+	}
+
+	if _, err := loadLocation(o.Timezone); err != nil {
+		return NewAppError("QuietHours.IsValid", "model.quiet_hours.is_valid.timezone.app_error", nil, "timezone="+o.Timezone, http.StatusBadRequest)
+	}
+
+	for _, day := range o.Days {
+		if day < int(time.Sunday) || day > int(time.Saturday) {
+			return NewAppError("QuietHours.IsValid", "model.quiet_hours.is_valid.days.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+func (o *QuietHours) startsOn(day time.Weekday) bool {
+	if len(o.Days) == 0 {
+		return true
+	}
+
+	for _, d := range o.Days {
+		if d == int(day) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StartBetween checks whether quiet hours started after one time and up to another, both in milliseconds, which
+// should be less than a day apart. If they did, it returns when those quiet hours end.
+func (o *QuietHours) StartBetween(after, until int64) (int64, bool) {
+	location, err := loadLocation(o.Timezone)
+	if err != nil {
+		return 0, false
+	}
+
+	startTime, err := time.Parse(QUIET_HOURS_TIME_FORMAT, o.Start)
+	if err != nil {
+		return 0, false
+	}
+
+	endTime, err := time.Parse(QUIET_HOURS_TIME_FORMAT, o.End)
+	if err != nil {
+		return 0, false
+	}
+
+	now := time.Unix(0, until*int64(time.Millisecond)).In(location)
+
+	// quiet hours that started up to a day ago may have started on the previous day
+	for _, day := range []time.Time{now, now.AddDate(0, 0, -1)} {
+		start := time.Date(day.Year(), day.Month(), day.Day(), startTime.Hour(), startTime.Minute(), 0, 0, location)
+		startMillis := start.UnixNano() / int64(time.Millisecond)
+
+		if startMillis <= after || startMillis > until || !o.startsOn(start.Weekday()) {
+			continue
+		}
+
+		end := time.Date(day.Year(), day.Month(), day.Day(), endTime.Hour(), endTime.Minute(), 0, 0, location)
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+
+		return end.UnixNano() / int64(time.Millisecond), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuietHoursJson(t *testing.T) {
+	quietHours := QuietHours{Start: "22:00", End: "07:00", Timezone: "Europe/Berlin", Days: []int{1, 2}}
+	result := QuietHoursFromJson(strings.NewReader(quietHours.ToJson()))
+
+	if result.Start != quietHours.Start || result.End != quietHours.End || result.Timezone != quietHours.Timezone || len(result.Days) != 2 {
+		t.Fatal("quiet hours should have matched")
+	}
+
+	if QuietHoursFromJson(strings.NewReader("junk")) != nil {
+		t.Fatal("should not have parsed")
+	}
+}
+
+func TestQuietHoursIsValid(t *testing.T) {
+	quietHours := QuietHours{Start: "22:00", End: "07:00"}
+	if err := quietHours.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	quietHours.Start = "10pm"
+	if err := quietHours.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	quietHours.Start = "22:00"
+	quietHours.End = "25:00"
+	if err := quietHours.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	quietHours.End = "22:00"
+	if err := quietHours.IsValid(); err == nil {
+		t.Fatal("should be invalid when starting and ending at the same time")
+	}
+
+	quietHours.End = "07:00"
+	quietHours.Timezone = "Nowhere/Junk"
+	if err := quietHours.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	quietHours.Timezone = "America/Toronto"
+	quietHours.Days = []int{0, 6}
+	if err := quietHours.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	quietHours.Days = []int{7}
+	if err := quietHours.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestQuietHoursStartBetween(t *testing.T) {
+	millis := func(tm time.Time) int64 {
+		return tm.UnixNano() / int64(time.Millisecond)
+	}
+
+	// Wednesday
+	start := time.Date(2017, time.March, 1, 22, 0, 0, 0, time.UTC)
+
+	quietHours := QuietHours{Start: "22:00", End: "07:00"}
+	if end, ok := quietHours.StartBetween(millis(start.Add(-time.Minute)), millis(start)); !ok {
+		t.Fatal("should have started")
+	} else if end != millis(time.Date(2017, time.March, 2, 7, 0, 0, 0, time.UTC)) {
+		t.Fatal("should end the next morning")
+	}
+
+	if _, ok := quietHours.StartBetween(millis(start), millis(start.Add(time.Minute))); ok {
+		t.Fatal("should only start once")
+	}
+
+	if _, ok := quietHours.StartBetween(millis(start.Add(-2*time.Minute)), millis(start.Add(-time.Minute))); ok {
+		t.Fatal("shouldn't have started yet")
+	}
+
+	// after midnight, quiet hours that started the day before are found
+	if _, ok := quietHours.StartBetween(millis(start.Add(-time.Minute)), millis(start.Add(3*time.Hour))); !ok {
+		t.Fatal("should have started the day before")
+	}
+
+	quietHours = QuietHours{Start: "13:00", End: "14:30"}
+	if end, ok := quietHours.StartBetween(millis(start.Add(-10*time.Hour)), millis(start.Add(-8*time.Hour))); !ok {
+		t.Fatal("should have started")
+	} else if end != millis(time.Date(2017, time.March, 1, 14, 30, 0, 0, time.UTC)) {
+		t.Fatal("should end the same day")
+	}
+
+	quietHours = QuietHours{Start: "22:00", End: "07:00", Days: []int{int(time.Thursday)}}
+	if _, ok := quietHours.StartBetween(millis(start.Add(-time.Minute)), millis(start)); ok {
+		t.Fatal("shouldn't start on a Wednesday")
+	}
+
+	if _, ok := quietHours.StartBetween(millis(start.Add(24*time.Hour-time.Minute)), millis(start.Add(24*time.Hour))); !ok {
+		t.Fatal("should start on a Thursday")
+	}
+
+	// 22:00 in Toronto is 03:00 UTC the next day
+	quietHours = QuietHours{Start: "22:00", End: "07:00", Timezone: "America/Toronto"}
+	if _, ok := quietHours.StartBetween(millis(start.Add(-time.Minute)), millis(start)); ok {
+		t.Fatal("shouldn't have started yet in Toronto")
+	}
+
+	if end, ok := quietHours.StartBetween(millis(start.Add(5*time.Hour-time.Minute)), millis(start.Add(5*time.Hour))); !ok {
+		t.Fatal("should have started in Toronto")
+	} else if end != millis(time.Date(2017, time.March, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Fatal("should end in the morning in Toronto")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HEADER_REQUEST_SIGNATURE  = "X-Mattermost-Signature"
+	HEADER_REQUEST_TIMESTAMP  = "X-Mattermost-Request-Timestamp"
+	REQUEST_SIGNATURE_VERSION = "v1"
+	REQUEST_SIGNATURE_MAX_AGE = 5 * time.Minute
+	SIGNING_SECRET_LENGTH     = 32
+)
+
+// NewSigningSecret generates the secret shared with an integration to sign the requests sent to it.
+func NewSigningSecret() string {
+	return NewRandomString(SIGNING_SECRET_LENGTH)
+}
+
+// ComputeRequestSignature returns the signature of a request body sent at the given time, in seconds since the
+// epoch. It's an HMAC-SHA256 of "v1:<timestamp>:<body>", hex encoded and prefixed with "v1=".
+func ComputeRequestSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(REQUEST_SIGNATURE_VERSION + ":" + strconv.FormatInt(timestamp, 10) + ":"))
+	mac.Write(body)
+
+	return REQUEST_SIGNATURE_VERSION + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest sets the timestamp and signature headers of a request to an integration. The body must be the same
+// bytes that the request sends.
+func SignRequest(req *http.Request, secret string, body []byte) {
+	timestamp := time.Now().Unix()
+
+	req.Header.Set(HEADER_REQUEST_TIMESTAMP, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HEADER_REQUEST_SIGNATURE, ComputeRequestSignature(secret, timestamp, body))
+}
+
+// VerifyRequestSignature checks that a request received by an integration was signed with its secret within the
+// last few minutes. It reads the whole body and returns it, and also replaces the request's body so that it can
+// still be parsed afterwards.
+func VerifyRequestSignature(r *http.Request, secret string) ([]byte, *AppError) {
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, NewAppError("VerifyRequestSignature", "model.request_signature.read_body.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if err := VerifySignature(secret, r.Header.Get(HEADER_REQUEST_SIGNATURE), r.Header.Get(HEADER_REQUEST_TIMESTAMP), body, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// VerifySignature checks a signature and timestamp, as sent in the request headers, against a body. Signatures
+// more than REQUEST_SIGNATURE_MAX_AGE away from now are rejected so that captured requests can't be replayed.
+func VerifySignature(secret, signature, timestamp string, body []byte, now time.Time) *AppError {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return NewAppError("VerifySignature", "model.request_signature.timestamp.app_error", nil, "timestamp="+timestamp, http.StatusUnauthorized)
+	}
+
+	if age := now.Sub(time.Unix(seconds, 0)); age > REQUEST_SIGNATURE_MAX_AGE || age < -REQUEST_SIGNATURE_MAX_AGE {
+		return NewAppError("VerifySignature", "model.request_signature.expired.app_error", nil, "timestamp="+timestamp, http.StatusUnauthorized)
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(ComputeRequestSignature(secret, seconds, body))) {
+		return NewAppError("VerifySignature", "model.request_signature.invalid.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := NewSigningSecret()
+	body := []byte("token=abc&text=hello")
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := ComputeRequestSignature(secret, now.Unix(), body)
+
+	if !strings.HasPrefix(signature, REQUEST_SIGNATURE_VERSION+"=") {
+		t.Fatal("signature should be prefixed with its version")
+	}
+
+	if err := VerifySignature(secret, signature, timestamp, body, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifySignature(NewSigningSecret(), signature, timestamp, body, now); err == nil {
+		t.Fatal("should fail with a different secret")
+	}
+
+	if err := VerifySignature(secret, signature, timestamp, []byte("token=abc&text=goodbye"), now); err == nil {
+		t.Fatal("should fail with a different body")
+	}
+
+	if err := VerifySignature(secret, signature, "junk", body, now); err == nil {
+		t.Fatal("should fail with a bad timestamp")
+	}
+
+	if err := VerifySignature(secret, signature, timestamp, body, now.Add(REQUEST_SIGNATURE_MAX_AGE+time.Minute)); err == nil {
+		t.Fatal("should fail once the signature is too old")
+	}
+
+	if err := VerifySignature(secret, "", timestamp, body, now); err == nil {
+		t.Fatal("should fail without a signature")
+	}
+}
+
+func TestSignRequest(t *testing.T) {
+	secret := NewSigningSecret()
+	body := "text=hello"
+
+	req, _ := http.NewRequest("POST", "http://example.com", strings.NewReader(body))
+	SignRequest(req, secret, []byte(body))
+
+	if received, err := VerifyRequestSignature(req, secret); err != nil {
+		t.Fatal(err)
+	} else if string(received) != body {
+		t.Fatal("should have returned the body")
+	}
+
+	if b, _ := ioutil.ReadAll(req.Body); string(b) != body {
+		t.Fatal("should have replaced the request's body")
+	}
+
+	req, _ = http.NewRequest("POST", "http://example.com", strings.NewReader(body))
+	if _, err := VerifyRequestSignature(req, secret); err == nil {
+		t.Fatal("should fail for an unsigned request")
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	SCHEDULED_POST_RECURRENCE_NONE     = ""
+	SCHEDULED_POST_RECURRENCE_DAILY    = "daily"
+	SCHEDULED_POST_RECURRENCE_WEEKDAYS = "weekdays"
+	SCHEDULED_POST_RECURRENCE_WEEKLY   = "weekly"
+	SCHEDULED_POST_RECURRENCE_MONTHLY  = "monthly"
+)
+
+// ScheduledPost is a message that a user has asked to be posted later, either once or on a recurring schedule.
+type ScheduledPost struct {
+	Id          string `json:"id"`
+	CreateAt    int64  `json:"create_at"`
+	UpdateAt    int64  `json:"update_at"`
+	UserId      string `json:"user_id"`
+	ChannelId   string `json:"channel_id"`
+	RootId      string `json:"root_id"`
+	Message     string `json:"message"`
+	ScheduledAt int64  `json:"scheduled_at"` // the next time that the message will be posted
+	Recurrence  string `json:"recurrence"`
+	Timezone    string `json:"timezone"`     // the name of the timezone that recurrences are calculated in, which recurring posts must have
+	DayOfMonth  int    `json:"day_of_month"` // the day of the month that a monthly post is sent on, or the last day of shorter months
+}
+
+func (o *ScheduledPost) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ScheduledPostFromJson(data io.Reader) *ScheduledPost {
+	var o *ScheduledPost
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func ScheduledPostListToJson(l []*ScheduledPost) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return "[]"
+	} else {
+		return string(b)
+	}
+}
+
+func ScheduledPostListFromJson(data io.Reader) []*ScheduledPost {
+	var l []*ScheduledPost
+	json.NewDecoder(data).Decode(&l)
+	return l
+}
+
+func (o *ScheduledPost) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+
+	if o.DayOfMonth == 0 {
+		o.DayOfMonth = o.scheduledTime().Day()
+	}
+}
+
+func (o *ScheduledPost) PreUpdate() {
+	o.UpdateAt = GetMillis()
+
+	if o.DayOfMonth == 0 {
+		o.DayOfMonth = o.scheduledTime().Day()
+	}
+}
+
+func (o *ScheduledPost) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 || o.UpdateAt == 0 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.channel_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !(len(o.RootId) == 26 || len(o.RootId) == 0) {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.root_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Message) == 0 || utf8.RuneCountInString(o.Message) > POST_MESSAGE_MAX_RUNES {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.message.app_error", map[string]interface{}{"Max": POST_MESSAGE_MAX_RUNES}, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.ScheduledAt <= 0 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.scheduled_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidScheduledPostRecurrence(o.Recurrence) {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.recurrence.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.Recurrence != SCHEDULED_POST_RECURRENCE_NONE && o.Timezone == "" {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.timezone_required.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if _, err := time.LoadLocation(o.Timezone); err != nil {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.timezone.app_error", nil, "id="+o.Id+", timezone="+o.Timezone, http.StatusBadRequest)
+	}
+
+	if o.DayOfMonth < 1 || o.DayOfMonth > 31 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.day_of_month.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func IsValidScheduledPostRecurrence(recurrence string) bool {
+	switch recurrence {
+	case SCHEDULED_POST_RECURRENCE_NONE,
+		SCHEDULED_POST_RECURRENCE_DAILY,
+		SCHEDULED_POST_RECURRENCE_WEEKDAYS,
+		SCHEDULED_POST_RECURRENCE_WEEKLY,
+		SCHEDULED_POST_RECURRENCE_MONTHLY:
+		return true
+	}
+
+	return false
+}
+
+// NextScheduledAt returns the first time after now that a recurring post should be sent again, or 0 if it doesn't
+// recur. Times that were missed, such as while the server was down, are skipped rather than sent all at once.
+// Recurrences are calculated in the post's timezone so that it's sent at the same local time across daylight saving
+// changes, and monthly posts stay on their day of the month, falling back to the last day of shorter months.
+func (o *ScheduledPost) NextScheduledAt(now int64) int64 {
+	if o.Recurrence == SCHEDULED_POST_RECURRENCE_NONE {
+		return 0
+	}
+
+	dayOfMonth := o.DayOfMonth
+	if dayOfMonth == 0 {
+		dayOfMonth = o.scheduledTime().Day()
+	}
+
+	next := o.scheduledTime()
+	for next.UnixNano()/int64(time.Millisecond) <= now {
+		switch o.Recurrence {
+		case SCHEDULED_POST_RECURRENCE_DAILY:
+			next = next.AddDate(0, 0, 1)
+		case SCHEDULED_POST_RECURRENCE_WEEKDAYS:
+			next = next.AddDate(0, 0, 1)
+			for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+				next = next.AddDate(0, 0, 1)
+			}
+		case SCHEDULED_POST_RECURRENCE_WEEKLY:
+			next = next.AddDate(0, 0, 7)
+		default:
+			year, month, _ := next.Date()
+			month++
+
+			day := dayOfMonth
+			if daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day(); day > daysInMonth {
+				day = daysInMonth
+			}
+
+			next = time.Date(year, month, day, next.Hour(), next.Minute(), next.Second(), next.Nanosecond(), next.Location())
+		}
+	}
+
+	return next.UnixNano() / int64(time.Millisecond)
+}
+
+// scheduledTime returns the time that the post is next scheduled for in its timezone.
+func (o *ScheduledPost) scheduledTime() time.Time {
+	location, err := time.LoadLocation(o.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	return time.Unix(0, o.ScheduledAt*int64(time.Millisecond)).In(location)
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduledPostJson(t *testing.T) {
+	o := ScheduledPost{Id: NewId(), Message: "hello", Recurrence: SCHEDULED_POST_RECURRENCE_DAILY}
+	ro := ScheduledPostFromJson(strings.NewReader(o.ToJson()))
+
+	if o.Id != ro.Id || o.Message != ro.Message || o.Recurrence != ro.Recurrence {
+		t.Fatal("scheduled posts do not match")
+	}
+
+	l := ScheduledPostListFromJson(strings.NewReader(ScheduledPostListToJson([]*ScheduledPost{&o})))
+	if len(l) != 1 || l[0].Id != o.Id {
+		t.Fatal("lists do not match")
+	}
+}
+
+func TestScheduledPostIsValid(t *testing.T) {
+	o := ScheduledPost{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	o.ChannelId = NewId()
+	o.Message = "hello"
+	o.ScheduledAt = GetMillis()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.RootId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.RootId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Message = strings.Repeat("a", POST_MESSAGE_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Message = "hello"
+	o.Recurrence = "hourly"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Recurrence = SCHEDULED_POST_RECURRENCE_WEEKDAYS
+	if err := o.IsValid(); err == nil {
+		t.Fatal("a recurring post without a timezone should be invalid")
+	}
+
+	o.Timezone = "UTC"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Timezone = "Not/AZone"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Timezone = "America/Toronto"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.DayOfMonth = 32
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.DayOfMonth = 31
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.ScheduledAt = 0
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestScheduledPostNextScheduledAt(t *testing.T) {
+	millis := func(year int, month time.Month, day, hour int) int64 {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+	}
+
+	// a Friday
+	friday := millis(2017, time.June, 30, 9)
+
+	o := ScheduledPost{ScheduledAt: friday}
+	if next := o.NextScheduledAt(friday); next != 0 {
+		t.Fatal("a post that doesn't recur shouldn't have a next time")
+	}
+
+	o.Recurrence = SCHEDULED_POST_RECURRENCE_DAILY
+	if next := o.NextScheduledAt(friday); next != millis(2017, time.July, 1, 9) {
+		t.Fatal("should be the next day", next)
+	}
+
+	o.Recurrence = SCHEDULED_POST_RECURRENCE_WEEKDAYS
+	if next := o.NextScheduledAt(friday); next != millis(2017, time.July, 3, 9) {
+		t.Fatal("should skip the weekend", next)
+	}
+
+	o.Recurrence = SCHEDULED_POST_RECURRENCE_WEEKLY
+	if next := o.NextScheduledAt(friday); next != millis(2017, time.July, 7, 9) {
+		t.Fatal("should be the next week", next)
+	}
+
+	o.Recurrence = SCHEDULED_POST_RECURRENCE_MONTHLY
+	if next := o.NextScheduledAt(friday); next != millis(2017, time.July, 30, 9) {
+		t.Fatal("should be the next month", next)
+	}
+
+	// times missed while the server was down are skipped
+	o.Recurrence = SCHEDULED_POST_RECURRENCE_DAILY
+	if next := o.NextScheduledAt(millis(2017, time.July, 4, 12)); next != millis(2017, time.July, 5, 9) {
+		t.Fatal("should have skipped the missed days", next)
+	}
+
+	// monthly posts stay on their day of the month instead of drifting after shorter months
+	o = ScheduledPost{ScheduledAt: millis(2017, time.January, 31, 9), Recurrence: SCHEDULED_POST_RECURRENCE_MONTHLY}
+	o.PreSave()
+	if next := o.NextScheduledAt(millis(2017, time.January, 31, 9)); next != millis(2017, time.February, 28, 9) {
+		t.Fatal("should be the last day of February", next)
+	}
+
+	o.ScheduledAt = millis(2017, time.February, 28, 9)
+	if next := o.NextScheduledAt(millis(2017, time.February, 28, 9)); next != millis(2017, time.March, 31, 9) {
+		t.Fatal("should be back on the 31st in March", next)
+	}
+
+	if next := o.NextScheduledAt(millis(2017, time.April, 1, 9)); next != millis(2017, time.April, 30, 9) {
+		t.Fatal("should be the last day of April", next)
+	}
+}
+
+func TestScheduledPostNextScheduledAtTimezone(t *testing.T) {
+	location, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Skip("timezone data isn't available")
+	}
+
+	millis := func(year int, month time.Month, day, hour int) int64 {
+		return time.Date(year, month, day, hour, 0, 0, 0, location).UnixNano() / int64(time.Millisecond)
+	}
+
+	// the day before daylight saving time starts
+	saturday := millis(2017, time.March, 11, 9)
+
+	o := ScheduledPost{ScheduledAt: saturday, Recurrence: SCHEDULED_POST_RECURRENCE_DAILY, Timezone: "America/Toronto"}
+	if next := o.NextScheduledAt(saturday); next != millis(2017, time.March, 12, 9) {
+		t.Fatal("should be at the same local time after the clocks change", next)
+	}
+
+	// 9pm on Friday in Toronto is already Saturday in UTC
+	friday := millis(2017, time.March, 17, 21)
+
+	o = ScheduledPost{ScheduledAt: millis(2017, time.March, 16, 21), Recurrence: SCHEDULED_POST_RECURRENCE_WEEKDAYS, Timezone: "America/Toronto"}
+	if next := o.NextScheduledAt(millis(2017, time.March, 16, 21)); next != friday {
+		t.Fatal("should be on Friday in the post's timezone", next)
+	}
+
+	if next := o.NextScheduledAt(friday); next != millis(2017, time.March, 20, 21) {
+		t.Fatal("should skip the weekend in the post's timezone", next)
+	}
+}
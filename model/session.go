@@ -15,6 +15,10 @@ const (
 	SESSION_PROP_PLATFORM = "platform"
 	SESSION_PROP_OS       = "os"
 	SESSION_PROP_BROWSER  = "browser"
+
+	SESSION_PROP_TYPE                 = "type"
+	SESSION_PROP_USER_ACCESS_TOKEN_ID = "user_access_token_id"
+	SESSION_TYPE_USER_ACCESS_TOKEN    = "UserAccessToken"
 )
 
 type Session struct {
@@ -56,7 +60,10 @@ func (me *Session) PreSave() {
 		me.Id = NewId()
 	}
 
-	me.Token = NewId()
+	// sessions created from a user access token use the access token as their own
+	if me.Token == "" {
+		me.Token = NewId()
+	}
 
 	me.CreateAt = GetMillis()
 	me.LastActivityAt = me.CreateAt
@@ -114,6 +121,10 @@ func (me *Session) IsMobileApp() bool {
 	return len(me.DeviceId) > 0
 }
 
+func (me *Session) IsUserAccessToken() bool {
+	return me.Props != nil && me.Props[SESSION_PROP_TYPE] == SESSION_TYPE_USER_ACCESS_TOKEN
+}
+
 func (me *Session) GetUserRoles() []string {
 	return strings.Fields(me.Roles)
 }
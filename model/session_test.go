@@ -33,3 +33,22 @@ func TestSessionJson(t *testing.T) {
 
 	session.SetExpireInDays(10)
 }
+
+func TestSessionUserAccessToken(t *testing.T) {
+	session := Session{Token: NewId()}
+	token := session.Token
+	session.PreSave()
+
+	if session.Token != token {
+		t.Fatal("should've kept the existing token")
+	}
+
+	if session.IsUserAccessToken() {
+		t.Fatal("shouldn't be a user access token session")
+	}
+
+	session.AddProp(SESSION_PROP_TYPE, SESSION_TYPE_USER_ACCESS_TOKEN)
+	if !session.IsUserAccessToken() {
+		t.Fatal("should be a user access token session")
+	}
+}
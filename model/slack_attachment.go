@@ -0,0 +1,226 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"unicode/utf8"
+)
+
+const (
+	SLACK_ATTACHMENTS_MAX                 = 100
+	SLACK_ATTACHMENT_FIELDS_MAX           = 100
+	SLACK_ATTACHMENT_ACTIONS_MAX          = 25
+	SLACK_ATTACHMENT_TEXT_MAX_RUNES       = POST_MESSAGE_MAX_RUNES
+	SLACK_ATTACHMENT_TITLE_MAX_RUNES      = 1024
+	SLACK_ATTACHMENT_URL_MAX_LENGTH       = 1024
+	SLACK_ATTACHMENT_COLOR_MAX_LENGTH     = 32
+	SLACK_ATTACHMENT_ACTION_TYPE_BUTTON   = "button"
+	SLACK_ATTACHMENT_ACTION_TYPE_SELECT   = "select"
+	SLACK_ATTACHMENT_ACTION_ID_MAX_LENGTH = 26
+)
+
+// action ids are used in URLs, so they're restricted to the characters allowed there by the API's routes
+var validSlackAttachmentActionId = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// SlackAttachment is a message attachment in the format used by Slack, as documented at
+// https://api.slack.com/docs/message-attachments
+type SlackAttachment struct {
+	Id         int64                    `json:"id"`
+	Fallback   string                   `json:"fallback"`
+	Color      string                   `json:"color"`
+	Pretext    string                   `json:"pretext"`
+	AuthorName string                   `json:"author_name"`
+	AuthorLink string                   `json:"author_link"`
+	AuthorIcon string                   `json:"author_icon"`
+	Title      string                   `json:"title"`
+	TitleLink  string                   `json:"title_link"`
+	Text       string                   `json:"text"`
+	Fields     []*SlackAttachmentField  `json:"fields"`
+	ImageURL   string                   `json:"image_url"`
+	ThumbURL   string                   `json:"thumb_url"`
+	Footer     string                   `json:"footer"`
+	FooterIcon string                   `json:"footer_icon"`
+	Timestamp  interface{}              `json:"ts"` // Slack sends this as either a number or a string
+	Actions    []*SlackAttachmentAction `json:"actions,omitempty"`
+}
+
+type SlackAttachmentField struct {
+	Title string      `json:"title"`
+	Value interface{} `json:"value"` // a string, number or boolean
+	Short bool        `json:"short"`
+}
+
+// SlackAttachmentAction is a button or select menu on an attachment. When a user clicks it, the server sends the
+// integration's context to its URL.
+type SlackAttachmentAction struct {
+	Id          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Type        string                 `json:"type"`
+	Options     []*PostActionOptions   `json:"options,omitempty"`
+	Integration *PostActionIntegration `json:"integration,omitempty"`
+}
+
+func (a *SlackAttachment) IsValid() *AppError {
+	for _, text := range []string{a.Fallback, a.Pretext, a.Text} {
+		if utf8.RuneCountInString(text) > SLACK_ATTACHMENT_TEXT_MAX_RUNES {
+			return NewAppError("SlackAttachment.IsValid", "model.slack_attachment.is_valid.text.app_error", map[string]interface{}{"Max": SLACK_ATTACHMENT_TEXT_MAX_RUNES}, "", http.StatusBadRequest)
+		}
+	}
+
+	for _, title := range []string{a.AuthorName, a.Title, a.Footer} {
+		if utf8.RuneCountInString(title) > SLACK_ATTACHMENT_TITLE_MAX_RUNES {
+			return NewAppError("SlackAttachment.IsValid", "model.slack_attachment.is_valid.title.app_error", map[string]interface{}{"Max": SLACK_ATTACHMENT_TITLE_MAX_RUNES}, "", http.StatusBadRequest)
+		}
+	}
+
+	for _, url := range []string{a.AuthorLink, a.AuthorIcon, a.TitleLink, a.ImageURL, a.ThumbURL, a.FooterIcon} {
+		if len(url) > SLACK_ATTACHMENT_URL_MAX_LENGTH || (len(url) > 0 && !IsValidHttpUrl(url)) {
+			return NewAppError("SlackAttachment.IsValid", "model.slack_attachment.is_valid.url.app_error", nil, "url="+url, http.StatusBadRequest)
+		}
+	}
+
+	if len(a.Color) > SLACK_ATTACHMENT_COLOR_MAX_LENGTH {
+		return NewAppError("SlackAttachment.IsValid", "model.slack_attachment.is_valid.color.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	switch a.Timestamp.(type) {
+	case nil, string, float64:
+	default:
+		return NewAppError("SlackAttachment.IsValid", "model.slack_attachment.is_valid.timestamp.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(a.Fields) > SLACK_ATTACHMENT_FIELDS_MAX {
+		return NewAppError("SlackAttachment.IsValid", "model.slack_attachment.is_valid.fields.app_error", map[string]interface{}{"Max": SLACK_ATTACHMENT_FIELDS_MAX}, "", http.StatusBadRequest)
+	}
+
+	for _, field := range a.Fields {
+		if field == nil {
+			return NewAppError("SlackAttachment.IsValid", "model.slack_attachment.is_valid.field.app_error", nil, "", http.StatusBadRequest)
+		} else if err := field.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	if len(a.Actions) > SLACK_ATTACHMENT_ACTIONS_MAX {
+		return NewAppError("SlackAttachment.IsValid", "model.slack_attachment.is_valid.actions.app_error", map[string]interface{}{"Max": SLACK_ATTACHMENT_ACTIONS_MAX}, "", http.StatusBadRequest)
+	}
+
+	for _, action := range a.Actions {
+		if action == nil {
+			return NewAppError("SlackAttachment.IsValid", "model.slack_attachment.is_valid.action.app_error", nil, "", http.StatusBadRequest)
+		} else if err := action.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *SlackAttachmentField) IsValid() *AppError {
+	if utf8.RuneCountInString(f.Title) > SLACK_ATTACHMENT_TITLE_MAX_RUNES {
+		return NewAppError("SlackAttachmentField.IsValid", "model.slack_attachment.is_valid.field_title.app_error", map[string]interface{}{"Max": SLACK_ATTACHMENT_TITLE_MAX_RUNES}, "", http.StatusBadRequest)
+	}
+
+	switch value := f.Value.(type) {
+	case nil, float64, bool:
+	case string:
+		if utf8.RuneCountInString(value) > SLACK_ATTACHMENT_TEXT_MAX_RUNES {
+			return NewAppError("SlackAttachmentField.IsValid", "model.slack_attachment.is_valid.field_value_length.app_error", map[string]interface{}{"Max": SLACK_ATTACHMENT_TEXT_MAX_RUNES}, "", http.StatusBadRequest)
+		}
+	default:
+		return NewAppError("SlackAttachmentField.IsValid", "model.slack_attachment.is_valid.field_value.app_error", nil, fmt.Sprintf("type=%T", value), http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (a *SlackAttachmentAction) IsValid() *AppError {
+	if len(a.Id) > SLACK_ATTACHMENT_ACTION_ID_MAX_LENGTH || (len(a.Id) > 0 && !validSlackAttachmentActionId.MatchString(a.Id)) {
+		return NewAppError("SlackAttachmentAction.IsValid", "model.slack_attachment.is_valid.action_id.app_error", nil, "id="+a.Id, http.StatusBadRequest)
+	}
+
+	if len(a.Name) == 0 || utf8.RuneCountInString(a.Name) > SLACK_ATTACHMENT_TITLE_MAX_RUNES {
+		return NewAppError("SlackAttachmentAction.IsValid", "model.slack_attachment.is_valid.action_name.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	switch a.Type {
+	case "", SLACK_ATTACHMENT_ACTION_TYPE_BUTTON:
+	case SLACK_ATTACHMENT_ACTION_TYPE_SELECT:
+		if len(a.Options) == 0 {
+			return NewAppError("SlackAttachmentAction.IsValid", "model.slack_attachment.is_valid.action_options.app_error", nil, "", http.StatusBadRequest)
+		}
+	default:
+		return NewAppError("SlackAttachmentAction.IsValid", "model.slack_attachment.is_valid.action_type.app_error", nil, "type="+a.Type, http.StatusBadRequest)
+	}
+
+	for _, option := range a.Options {
+		if option == nil || len(option.Text) == 0 || len(option.Value) == 0 {
+			return NewAppError("SlackAttachmentAction.IsValid", "model.slack_attachment.is_valid.action_option.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	if a.Integration == nil || !IsValidHttpUrl(a.Integration.URL) || len(a.Integration.URL) > SLACK_ATTACHMENT_URL_MAX_LENGTH {
+		return NewAppError("SlackAttachmentAction.IsValid", "model.slack_attachment.is_valid.action_integration.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// HasOption returns true if value is one of the options of a select menu.
+func (a *SlackAttachmentAction) HasOption(value string) bool {
+	for _, option := range a.Options {
+		if option.Value == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateSlackAttachments checks each of a list of attachments received from an integration.
+func ValidateSlackAttachments(attachments []*SlackAttachment) *AppError {
+	if len(attachments) > SLACK_ATTACHMENTS_MAX {
+		return NewAppError("ValidateSlackAttachments", "model.slack_attachment.is_valid.count.app_error", map[string]interface{}{"Max": SLACK_ATTACHMENTS_MAX}, "", http.StatusBadRequest)
+	}
+
+	for i, attachment := range attachments {
+		if attachment == nil {
+			return NewAppError("ValidateSlackAttachments", "model.slack_attachment.is_valid.attachment.app_error", nil, fmt.Sprintf("index=%v", i), http.StatusBadRequest)
+		}
+
+		if err := attachment.IsValid(); err != nil {
+			err.DetailedError = fmt.Sprintf("index=%v %v", i, err.DetailedError)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SlackAttachmentsFromInterface converts the attachments stored in a post's props into typed attachments. They are
+// only typed until the post has been through JSON, after which they're a []interface{} of maps.
+func SlackAttachmentsFromInterface(value interface{}) []*SlackAttachment {
+	switch attachments := value.(type) {
+	case []*SlackAttachment:
+		return attachments
+	case nil:
+		return nil
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	var attachments []*SlackAttachment
+	if err := json.Unmarshal(b, &attachments); err != nil {
+		return nil
+	}
+
+	return attachments
+}
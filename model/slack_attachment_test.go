@@ -0,0 +1,165 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlackAttachmentIsValid(t *testing.T) {
+	attachment := &SlackAttachment{
+		Fallback:  "fallback",
+		Color:     "#36a64f",
+		Title:     "title",
+		TitleLink: "https://example.com",
+		Text:      "text",
+		ImageURL:  "http://example.com/image.png",
+		Timestamp: "1500000000",
+		Fields: []*SlackAttachmentField{
+			{Title: "string", Value: "value", Short: true},
+			{Title: "number", Value: float64(1)},
+			{Title: "empty"},
+		},
+		Actions: []*SlackAttachmentAction{
+			{Name: "Approve", Type: SLACK_ATTACHMENT_ACTION_TYPE_BUTTON, Integration: &PostActionIntegration{URL: "https://example.com/action"}},
+		},
+	}
+
+	if err := attachment.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	attachment.Text = strings.Repeat("a", SLACK_ATTACHMENT_TEXT_MAX_RUNES+1)
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with text that's too long")
+	}
+	attachment.Text = "text"
+
+	attachment.Title = strings.Repeat("a", SLACK_ATTACHMENT_TITLE_MAX_RUNES+1)
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with a title that's too long")
+	}
+	attachment.Title = "title"
+
+	attachment.TitleLink = "javascript:alert(1)"
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with a non-http link")
+	}
+	attachment.TitleLink = ""
+
+	attachment.Timestamp = map[string]interface{}{}
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with a timestamp that isn't a string or number")
+	}
+	attachment.Timestamp = float64(1500000000)
+
+	attachment.Fields = append(attachment.Fields, &SlackAttachmentField{Title: "object", Value: map[string]interface{}{}})
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with a field value that's an object")
+	}
+	attachment.Fields = attachment.Fields[:len(attachment.Fields)-1]
+
+	attachment.Fields = append(attachment.Fields, nil)
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with a missing field")
+	}
+	attachment.Fields = attachment.Fields[:len(attachment.Fields)-1]
+
+	attachment.Actions[0].Type = "junk"
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with an unknown action type")
+	}
+	attachment.Actions[0].Type = ""
+
+	attachment.Actions[0].Name = ""
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with an unnamed action")
+	}
+	attachment.Actions[0].Name = "Approve"
+
+	attachment.Actions[0].Id = "not-alphanumeric"
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with a bad action id")
+	}
+	attachment.Actions[0].Id = NewId()
+
+	attachment.Actions[0].Integration = nil
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid without an integration")
+	}
+	attachment.Actions[0].Integration = &PostActionIntegration{URL: "ftp://example.com"}
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with a non-http integration url")
+	}
+	attachment.Actions[0].Integration = &PostActionIntegration{URL: "https://example.com/action"}
+
+	attachment.Actions[0].Type = SLACK_ATTACHMENT_ACTION_TYPE_SELECT
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with a select that has no options")
+	}
+	attachment.Actions[0].Options = []*PostActionOptions{{Text: "One", Value: ""}}
+	if err := attachment.IsValid(); err == nil {
+		t.Fatal("should be invalid with an option that has no value")
+	}
+	attachment.Actions[0].Options = []*PostActionOptions{{Text: "One", Value: "1"}, {Text: "Two", Value: "2"}}
+	if err := attachment.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !attachment.Actions[0].HasOption("2") || attachment.Actions[0].HasOption("3") {
+		t.Fatal("HasOption didn't match the options")
+	}
+
+	if err := attachment.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateSlackAttachments(t *testing.T) {
+	if err := ValidateSlackAttachments(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateSlackAttachments([]*SlackAttachment{{Text: "text"}, nil}); err == nil {
+		t.Fatal("should be invalid with a missing attachment")
+	}
+
+	attachments := make([]*SlackAttachment, SLACK_ATTACHMENTS_MAX+1)
+	for i := range attachments {
+		attachments[i] = &SlackAttachment{Text: "text"}
+	}
+
+	if err := ValidateSlackAttachments(attachments); err == nil {
+		t.Fatal("should be invalid with too many attachments")
+	}
+
+	if err := ValidateSlackAttachments(attachments[:SLACK_ATTACHMENTS_MAX]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSlackAttachmentsFromInterface(t *testing.T) {
+	attachments := []*SlackAttachment{{Text: "text", Fields: []*SlackAttachmentField{{Title: "title", Value: "value"}}}}
+
+	if result := SlackAttachmentsFromInterface(attachments); len(result) != 1 || result[0] != attachments[0] {
+		t.Fatal("typed attachments should be returned as is")
+	}
+
+	// once a post has been stored, its attachments are decoded as generic JSON
+	post := &Post{Props: StringInterface{"attachments": attachments}}
+	post = PostFromJson(strings.NewReader(post.ToJson()))
+
+	if result := post.Attachments(); len(result) != 1 || result[0].Text != "text" || result[0].Fields[0].Value != "value" {
+		t.Fatal("attachments weren't converted correctly")
+	}
+
+	if result := SlackAttachmentsFromInterface("junk"); result != nil {
+		t.Fatal("should return nil for invalid attachments")
+	}
+
+	if result := (&Post{}).Attachments(); result != nil {
+		t.Fatal("should return nil for a post without attachments")
+	}
+}
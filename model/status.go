@@ -12,6 +12,7 @@ const (
 	STATUS_OFFLINE         = "offline"
 	STATUS_AWAY            = "away"
 	STATUS_ONLINE          = "online"
+	STATUS_DND             = "dnd"
 	STATUS_CACHE_SIZE      = SESSION_CACHE_SIZE
 	STATUS_CHANNEL_TIMEOUT = 20000  // 20 seconds
 	STATUS_MIN_UPDATE_TIME = 120000 // 2 minutes
@@ -23,6 +24,8 @@ type Status struct {
 	Manual         bool   `json:"manual"`
 	LastActivityAt int64  `json:"last_activity_at"`
 	ActiveChannel  string `json:"active_channel" db:"-"`
+	DNDEndTime     int64  `json:"dnd_end_time"`          // when do not disturb ends, or 0 if it lasts until it's turned off
+	PrevStatus     string `json:"prev_status,omitempty"` // the manually set status to return to when do not disturb ends
 }
 
 func (o *Status) ToJson() string {
@@ -11,6 +11,7 @@ import (
 type SuggestCommand struct {
 	Suggestion  string `json:"suggestion"`
 	Description string `json:"description"`
+	Hint        string `json:"hint,omitempty"`
 }
 
 func (o *SuggestCommand) ToJson() string {
@@ -32,3 +33,18 @@ func SuggestCommandFromJson(data io.Reader) *SuggestCommand {
 		return nil
 	}
 }
+
+func SuggestCommandListToJson(l []*SuggestCommand) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return "[]"
+	} else {
+		return string(b)
+	}
+}
+
+func SuggestCommandListFromJson(data io.Reader) []*SuggestCommand {
+	var l []*SuggestCommand
+	json.NewDecoder(data).Decode(&l)
+	return l
+}
@@ -40,31 +40,35 @@ const (
 )
 
 type User struct {
-	Id                 string    `json:"id"`
-	CreateAt           int64     `json:"create_at,omitempty"`
-	UpdateAt           int64     `json:"update_at,omitempty"`
-	DeleteAt           int64     `json:"delete_at"`
-	Username           string    `json:"username"`
-	Password           string    `json:"password,omitempty"`
-	AuthData           *string   `json:"auth_data,omitempty"`
-	AuthService        string    `json:"auth_service"`
-	Email              string    `json:"email"`
-	EmailVerified      bool      `json:"email_verified,omitempty"`
-	Nickname           string    `json:"nickname"`
-	FirstName          string    `json:"first_name"`
-	LastName           string    `json:"last_name"`
-	Position           string    `json:"position"`
-	Roles              string    `json:"roles"`
-	AllowMarketing     bool      `json:"allow_marketing,omitempty"`
-	Props              StringMap `json:"props,omitempty"`
-	NotifyProps        StringMap `json:"notify_props,omitempty"`
-	LastPasswordUpdate int64     `json:"last_password_update,omitempty"`
-	LastPictureUpdate  int64     `json:"last_picture_update,omitempty"`
-	FailedAttempts     int       `json:"failed_attempts,omitempty"`
-	Locale             string    `json:"locale"`
-	MfaActive          bool      `json:"mfa_active,omitempty"`
-	MfaSecret          string    `json:"mfa_secret,omitempty"`
-	LastActivityAt     int64     `db:"-" json:"last_activity_at,omitempty"`
+	Id                    string        `json:"id"`
+	CreateAt              int64         `json:"create_at,omitempty"`
+	UpdateAt              int64         `json:"update_at,omitempty"`
+	DeleteAt              int64         `json:"delete_at"`
+	Username              string        `json:"username"`
+	Password              string        `json:"password,omitempty"`
+	AuthData              *string       `json:"auth_data,omitempty"`
+	AuthService           string        `json:"auth_service"`
+	Email                 string        `json:"email"`
+	EmailVerified         bool          `json:"email_verified,omitempty"`
+	Nickname              string        `json:"nickname"`
+	FirstName             string        `json:"first_name"`
+	LastName              string        `json:"last_name"`
+	Position              string        `json:"position"`
+	Roles                 string        `json:"roles"`
+	AllowMarketing        bool          `json:"allow_marketing,omitempty"`
+	Props                 StringMap     `json:"props,omitempty"`
+	NotifyProps           StringMap     `json:"notify_props,omitempty"`
+	LastPasswordUpdate    int64         `json:"last_password_update,omitempty"`
+	LastPictureUpdate     int64         `json:"last_picture_update,omitempty"`
+	FailedAttempts        int           `json:"failed_attempts,omitempty"`
+	Locale                string        `json:"locale"`
+	MfaActive             bool          `json:"mfa_active,omitempty"`
+	MfaSecret             string        `json:"mfa_secret,omitempty"`
+	LastActivityAt        int64         `db:"-" json:"last_activity_at,omitempty"`
+	IsBot                 bool          `json:"is_bot,omitempty"`
+	BotOwnerId            string        `json:"bot_owner_id,omitempty"`
+	CustomStatus          *CustomStatus `json:"custom_status,omitempty"`
+	CustomStatusExpiresAt int64         `json:"-"` // copied from the custom status so that expired ones can be found
 }
 
 type UserPatch struct {
@@ -131,6 +135,16 @@ func (u *User) IsValid() *AppError {
 		return NewAppError("User.IsValid", "model.user.is_valid.auth_data_pwd.app_error", nil, "user_id="+u.Id, http.StatusBadRequest)
 	}
 
+	if (u.IsBot && len(u.BotOwnerId) != 26) || (!u.IsBot && len(u.BotOwnerId) != 0) {
+		return NewAppError("User.IsValid", "model.user.is_valid.bot_owner_id.app_error", nil, "user_id="+u.Id, http.StatusBadRequest)
+	}
+
+	if u.CustomStatus != nil {
+		if err := u.CustomStatus.IsValid(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,91 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	USER_ACCESS_TOKEN_DESCRIPTION_MAX_RUNES = 255
+)
+
+// UserAccessToken is a long-lived token that lets a script or bot use the API as the user that created it. The
+// token itself is only returned when it's created.
+type UserAccessToken struct {
+	Id          string `json:"id"`
+	Token       string `json:"token,omitempty"`
+	UserId      string `json:"user_id"`
+	Description string `json:"description"`
+	CreateAt    int64  `json:"create_at"`
+}
+
+func (t *UserAccessToken) PreSave() {
+	if t.Id == "" {
+		t.Id = NewId()
+	}
+
+	t.CreateAt = GetMillis()
+}
+
+func (t *UserAccessToken) IsValid() *AppError {
+	if len(t.Id) != 26 {
+		return NewAppError("UserAccessToken.IsValid", "model.user_access_token.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(t.Token) != 26 {
+		return NewAppError("UserAccessToken.IsValid", "model.user_access_token.is_valid.token.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(t.UserId) != 26 {
+		return NewAppError("UserAccessToken.IsValid", "model.user_access_token.is_valid.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if t.Description == "" || utf8.RuneCountInString(t.Description) > USER_ACCESS_TOKEN_DESCRIPTION_MAX_RUNES {
+		return NewAppError("UserAccessToken.IsValid", "model.user_access_token.is_valid.description.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if t.CreateAt == 0 {
+		return NewAppError("UserAccessToken.IsValid", "model.user_access_token.is_valid.create_at.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (t *UserAccessToken) Sanitize() {
+	t.Token = ""
+}
+
+func (t *UserAccessToken) ToJson() string {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func UserAccessTokenFromJson(data io.Reader) *UserAccessToken {
+	var t *UserAccessToken
+	json.NewDecoder(data).Decode(&t)
+	return t
+}
+
+func UserAccessTokenListToJson(t []*UserAccessToken) string {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return "[]"
+	} else {
+		return string(b)
+	}
+}
+
+func UserAccessTokenListFromJson(data io.Reader) []*UserAccessToken {
+	var t []*UserAccessToken
+	json.NewDecoder(data).Decode(&t)
+	return t
+}
@@ -139,6 +139,33 @@ func TestUserIsValid(t *testing.T) {
 	if err := user.IsValid(); err == nil {
 		t.Fatal(err)
 	}
+
+	user.Position = ""
+	user.IsBot = true
+	if err := user.IsValid(); err == nil {
+		t.Fatal("bots should require an owner")
+	}
+
+	user.BotOwnerId = NewId()
+	if err := user.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	user.IsBot = false
+	if err := user.IsValid(); err == nil {
+		t.Fatal("only bots should have an owner")
+	}
+
+	user.BotOwnerId = ""
+	user.CustomStatus = &CustomStatus{Text: "In a meeting"}
+	if err := user.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	user.CustomStatus = &CustomStatus{}
+	if err := user.IsValid(); err == nil {
+		t.Fatal("should validate the custom status")
+	}
 }
 
 func TestUserGetFullName(t *testing.T) {
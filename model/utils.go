@@ -15,6 +15,7 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	goi18n "github.com/nicksnyder/go-i18n/i18n"
@@ -466,3 +467,29 @@ func IsValidWebsocketUrl(rawUrl string) bool {
 
 	return true
 }
+
+var locationCache = map[string]*time.Location{}
+var locationCacheMutex sync.RWMutex
+
+// loadLocation is time.LoadLocation but remembers the locations that it has loaded, since they're read from disk and
+// the same few are loaded over and over by jobs that run every minute.
+func loadLocation(name string) (*time.Location, error) {
+	locationCacheMutex.RLock()
+	location, ok := locationCache[name]
+	locationCacheMutex.RUnlock()
+
+	if ok {
+		return location, nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	locationCacheMutex.Lock()
+	locationCache[name] = location
+	locationCacheMutex.Unlock()
+
+	return location, nil
+}
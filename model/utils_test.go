@@ -6,6 +6,7 @@ package model
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewId(t *testing.T) {
@@ -137,3 +138,17 @@ func TestParseHashtags(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadLocation(t *testing.T) {
+	if location, err := loadLocation(""); err != nil || location != time.UTC {
+		t.Fatal("should've loaded UTC")
+	}
+
+	if _, err := loadLocation("Not/AZone"); err == nil {
+		t.Fatal("should've failed to load an unknown timezone")
+	}
+
+	if _, err := loadLocation("Not/AZone"); err == nil {
+		t.Fatal("shouldn't have cached an unknown timezone")
+	}
+}
@@ -5,6 +5,8 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
+
 	"github.com/gorilla/websocket"
 )
 
@@ -18,6 +20,8 @@ type WebSocketClient struct {
 	Conn            *websocket.Conn // The WebSocket connection
 	AuthToken       string          // The token used to open the WebSocket
 	Sequence        int64           // The ever-incrementing sequence attached to each WebSocket action
+	ConnectionId    string          // The id the server gave the connection, used to resume it after reconnecting
+	EventSequence   int64           // The sequence number of the last event received on the connection
 	EventChannel    chan *WebSocketEvent
 	ResponseChannel chan *WebSocketResponse
 	ListenError     *AppError
@@ -37,6 +41,8 @@ func NewWebSocketClient(url, authToken string) (*WebSocketClient, *AppError) {
 		conn,
 		authToken,
 		1,
+		"",
+		0,
 		make(chan *WebSocketEvent, 100),
 		make(chan *WebSocketResponse, 100),
 		nil,
@@ -47,9 +53,16 @@ func NewWebSocketClient(url, authToken string) (*WebSocketClient, *AppError) {
 	return client, nil
 }
 
+// Connect opens the WebSocket again after it was closed. If the server has given the client a connection id, the
+// server is asked to resume that connection and send any events that were missed while disconnected.
 func (wsc *WebSocketClient) Connect() *AppError {
+	url := wsc.ApiUrl + "/users/websocket"
+	if wsc.ConnectionId != "" {
+		url += fmt.Sprintf("?%v=%v&%v=%v", WEBSOCKET_PARAM_CONNECTION_ID, wsc.ConnectionId, WEBSOCKET_PARAM_SEQUENCE_NUMBER, wsc.EventSequence)
+	}
+
 	var err error
-	wsc.Conn, _, err = websocket.DefaultDialer.Dial(wsc.ApiUrl+"/users/websocket", nil)
+	wsc.Conn, _, err = websocket.DefaultDialer.Dial(url, nil)
 	if err != nil {
 		return NewLocAppError("NewWebSocketClient", "model.websocket_client.connect_fail.app_error", nil, err.Error())
 	}
@@ -87,6 +100,15 @@ func (wsc *WebSocketClient) Listen() {
 
 			var event WebSocketEvent
 			if err := json.Unmarshal(rawMsg, &event); err == nil && event.IsValid() {
+				if event.Event == WEBSOCKET_EVENT_HELLO {
+					if connectionId, ok := event.Data[WEBSOCKET_PARAM_CONNECTION_ID].(string); ok && connectionId != wsc.ConnectionId {
+						wsc.ConnectionId = connectionId
+						wsc.EventSequence = 0
+					}
+				} else if event.Seq > 0 {
+					wsc.EventSequence = event.Seq
+				}
+
 				wsc.EventChannel <- &event
 				continue
 			}
@@ -6,6 +6,7 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"strconv"
 )
 
 const (
@@ -31,6 +32,12 @@ const (
 	WEBSOCKET_AUTHENTICATION_CHALLENGE = "authentication_challenge"
 	WEBSOCKET_EVENT_REACTION_ADDED     = "reaction_added"
 	WEBSOCKET_EVENT_REACTION_REMOVED   = "reaction_removed"
+	WEBSOCKET_EVENT_RESYNC_REQUIRED    = "resync_required"
+)
+
+const (
+	WEBSOCKET_PARAM_CONNECTION_ID   = "connection_id"
+	WEBSOCKET_PARAM_SEQUENCE_NUMBER = "sequence_number"
 )
 
 type WebSocketMessage interface {
@@ -52,6 +59,7 @@ type WebSocketEvent struct {
 	Event          string                 `json:"event"`
 	Data           map[string]interface{} `json:"data"`
 	Broadcast      *WebsocketBroadcast    `json:"broadcast"`
+	Seq            int64                  `json:"seq,omitempty"` // numbers the events sent on a connection, starting at 1
 	PreComputeJson []byte                 `json:"-"`
 }
 
@@ -81,6 +89,22 @@ func (o *WebSocketEvent) DoPreComputeJson() {
 	}
 }
 
+// SetSequence returns a copy of the event numbered for a connection. Since the same event is sent on many
+// connections, the copy's JSON is spliced from the precomputed JSON of the unnumbered event instead of being
+// marshalled again.
+func (o *WebSocketEvent) SetSequence(seq int64) *WebSocketEvent {
+	copy := *o
+	copy.Seq = seq
+
+	if o.Seq == 0 && len(o.PreComputeJson) > 2 {
+		copy.PreComputeJson = append([]byte(`{"seq":`+strconv.FormatInt(seq, 10)+`,`), o.PreComputeJson[1:]...)
+	} else {
+		copy.DoPreComputeJson()
+	}
+
+	return &copy
+}
+
 func (o *WebSocketEvent) GetPreComputeJson() []byte {
 	return o.PreComputeJson
 }
@@ -54,3 +54,27 @@ func TestWebSocketResponse(t *testing.T) {
 		t.Fatal("Ids do not match")
 	}
 }
+
+func TestWebSocketEventSetSequence(t *testing.T) {
+	m := NewWebSocketEvent("some_event", NewId(), NewId(), NewId(), nil)
+	m.Add("RootId", NewId())
+	m.DoPreComputeJson()
+
+	numbered := m.SetSequence(42)
+	if m.Seq != 0 {
+		t.Fatal("should not have numbered the original event")
+	}
+
+	result := WebSocketEventFromJson(strings.NewReader(string(numbered.GetPreComputeJson())))
+	if result == nil || result.Seq != 42 {
+		t.Fatal("should have numbered the event")
+	}
+
+	if result.Event != m.Event || result.Data["RootId"] != m.Data["RootId"] || result.Broadcast.TeamId != m.Broadcast.TeamId {
+		t.Fatal("should have kept the rest of the event")
+	}
+
+	if result := WebSocketEventFromJson(strings.NewReader(numbered.SetSequence(43).ToJson())); result.Seq != 43 {
+		t.Fatal("should have renumbered the event")
+	}
+}
@@ -645,6 +645,37 @@ func (s SqlChannelStore) GetTeamChannels(teamId string) StoreChannel {
 	return storeChannel
 }
 
+func (s SqlChannelStore) GetDirectChannelsForExport(afterId string, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		data := &model.ChannelList{}
+		if _, err := s.GetReplica().Select(data,
+			`SELECT
+				*
+			FROM
+				Channels
+			WHERE
+				Type IN ('D', 'G')
+				AND DeleteAt = 0
+				AND Id > :AfterId
+			ORDER BY Id
+			LIMIT :Limit`,
+			map[string]interface{}{"AfterId": afterId, "Limit": limit}); err != nil {
+			result.Err = model.NewLocAppError("SqlChannelStore.GetDirectChannelsForExport", "store.sql_channel.get_direct_channels_for_export.app_error", nil, err.Error())
+		} else {
+			result.Data = data
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlChannelStore) GetByName(teamId string, name string, allowFromCache bool) StoreChannel {
 	return s.getByName(teamId, name, false, allowFromCache)
 }
@@ -1621,3 +1621,44 @@ func TestChannelStoreGetPinnedPosts(t *testing.T) {
 		t.Fatal("wasn't supposed to return posts")
 	}
 }
+
+func TestChannelStoreGetDirectChannelsForExport(t *testing.T) {
+	Setup()
+
+	u1 := Must(store.User().Save(&model.User{Email: model.NewId(), Nickname: model.NewId()})).(*model.User)
+	u2 := Must(store.User().Save(&model.User{Email: model.NewId(), Nickname: model.NewId()})).(*model.User)
+
+	direct := Must(store.Channel().CreateDirectChannel(u1.Id, u2.Id)).(*model.Channel)
+
+	open := Must(store.Channel().Save(&model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "Name",
+		Name:        "a" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	})).(*model.Channel)
+
+	foundDirect := false
+	afterId := ""
+	for {
+		channels := *Must(store.Channel().GetDirectChannelsForExport(afterId, 100)).(*model.ChannelList)
+		if len(channels) == 0 {
+			break
+		}
+
+		for _, channel := range channels {
+			if channel.Id <= afterId {
+				t.Fatal("channels should be returned in order of id")
+			} else if channel.Id == open.Id {
+				t.Fatal("shouldn't have returned an open channel")
+			} else if channel.Id == direct.Id {
+				foundDirect = true
+			}
+
+			afterId = channel.Id
+		}
+	}
+
+	if !foundDirect {
+		t.Fatal("should've returned the direct channel")
+	}
+}
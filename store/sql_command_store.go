@@ -27,8 +27,11 @@ func NewSqlCommandStore(sqlStore *SqlStore) CommandStore {
 		tableo.ColMap("IconURL").SetMaxSize(1024)
 		tableo.ColMap("AutoCompleteDesc").SetMaxSize(1024)
 		tableo.ColMap("AutoCompleteHint").SetMaxSize(1024)
+		tableo.ColMap("AutocompleteData").SetMaxSize(model.COMMAND_AUTOCOMPLETE_DATA_MAX_LENGTH)
 		tableo.ColMap("DisplayName").SetMaxSize(64)
 		tableo.ColMap("Description").SetMaxSize(128)
+		tableo.ColMap("BotUserId").SetMaxSize(26)
+		tableo.ColMap("SigningSecret").SetMaxSize(128)
 	}
 
 	return s
@@ -0,0 +1,65 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestCommandWebhookStore(t *testing.T) {
+	Setup()
+
+	h1 := &model.CommandWebhook{
+		CommandId: model.NewId(),
+		UserId:    model.NewId(),
+		ChannelId: model.NewId(),
+	}
+
+	if r := <-store.CommandWebhook().Save(h1); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	if r := <-store.CommandWebhook().Get(h1.Id); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if received := r.Data.(*model.CommandWebhook); received.CommandId != h1.CommandId {
+		t.Fatal("received the wrong hook")
+	}
+
+	if r := <-store.CommandWebhook().Get(model.NewId()); r.Err == nil || r.Err.StatusCode != http.StatusNotFound {
+		t.Fatal("should have failed to get a missing hook")
+	}
+
+	for i := 0; i < 2; i++ {
+		if r := <-store.CommandWebhook().TryUse(h1.Id, 2); r.Err != nil {
+			t.Fatal(r.Err)
+		}
+	}
+
+	if r := <-store.CommandWebhook().TryUse(h1.Id, 2); r.Err == nil {
+		t.Fatal("should have failed once the hook was used up")
+	}
+
+	h2 := &model.CommandWebhook{
+		CreateAt:  model.GetMillis() - model.COMMAND_WEBHOOK_LIFETIME - 1000,
+		CommandId: model.NewId(),
+		UserId:    model.NewId(),
+		ChannelId: model.NewId(),
+	}
+	Must(store.CommandWebhook().Save(h2))
+
+	if r := <-store.CommandWebhook().Cleanup(); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	if r := <-store.CommandWebhook().Get(h1.Id); r.Err != nil {
+		t.Fatal("shouldn't have deleted a hook that hasn't expired")
+	}
+
+	if r := <-store.CommandWebhook().Get(h2.Id); r.Err == nil {
+		t.Fatal("should have deleted the expired hook")
+	}
+}
@@ -257,3 +257,37 @@ func (fs SqlFileInfoStore) DeleteForPost(postId string) StoreChannel {
 
 	return storeChannel
 }
+
+func (fs SqlFileInfoStore) PermanentDeleteForPosts(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(postIds) == 0 {
+			result.Data = []*model.FileInfo{}
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		props := map[string]interface{}{}
+		idQuery := buildInQuery("PostId", postIds, props)
+
+		var infos []*model.FileInfo
+		if _, err := fs.GetMaster().Select(&infos, "SELECT * FROM FileInfo WHERE PostId IN ("+idQuery+")", props); err != nil {
+			result.Err = model.NewLocAppError("SqlFileInfoStore.PermanentDeleteForPosts",
+				"store.sql_file_info.permanent_delete_for_posts.get_file_infos.app_error", nil, err.Error())
+		} else if _, err := fs.GetMaster().Exec("DELETE FROM FileInfo WHERE PostId IN ("+idQuery+")", props); err != nil {
+			result.Err = model.NewLocAppError("SqlFileInfoStore.PermanentDeleteForPosts",
+				"store.sql_file_info.permanent_delete_for_posts.delete_file_infos.app_error", nil, err.Error())
+		} else {
+			result.Data = infos
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -218,3 +218,47 @@ func TestFileInfoDeleteForPost(t *testing.T) {
 		t.Fatal("shouldn't have returned any file infos")
 	}
 }
+
+func TestFileInfoPermanentDeleteForPosts(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	postId1 := model.NewId()
+	postId2 := model.NewId()
+	postId3 := model.NewId()
+
+	infos := []*model.FileInfo{
+		{
+			PostId:    postId1,
+			CreatorId: userId,
+			Path:      "file.txt",
+		},
+		{
+			PostId:    postId2,
+			CreatorId: userId,
+			Path:      "file.txt",
+			DeleteAt:  123,
+		},
+		{
+			PostId:    postId3,
+			CreatorId: userId,
+			Path:      "file.txt",
+		},
+	}
+
+	for i, info := range infos {
+		infos[i] = Must(store.FileInfo().Save(info)).(*model.FileInfo)
+	}
+
+	if deleted := Must(store.FileInfo().PermanentDeleteForPosts([]string{postId1, postId2})).([]*model.FileInfo); len(deleted) != 2 {
+		t.Fatal("should've returned both deleted file infos, including the soft deleted one")
+	}
+
+	if result := <-store.FileInfo().Get(infos[0].Id); result.Err == nil {
+		t.Fatal("file info should've been deleted")
+	}
+
+	if result := <-store.FileInfo().Get(infos[2].Id); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+}
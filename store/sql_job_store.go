@@ -0,0 +1,426 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+type SqlJobStore struct {
+	*SqlStore
+}
+
+func NewSqlJobStore(sqlStore *SqlStore) JobStore {
+	s := &SqlJobStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Job{}, "Jobs").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("Type").SetMaxSize(32)
+		table.ColMap("Status").SetMaxSize(32)
+		table.ColMap("LastError").SetMaxSize(1024)
+		table.ColMap("Data").SetMaxSize(1024)
+	}
+
+	return s
+}
+
+func (jss SqlJobStore) CreateIndexesIfNotExists() {
+	jss.CreateIndexIfNotExists("idx_jobs_type", "Jobs", "Type")
+	jss.CreateIndexIfNotExists("idx_jobs_status", "Jobs", "Status")
+}
+
+func (jss SqlJobStore) Save(job *model.Job) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		job.PreSave()
+		if result.Err = job.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := jss.GetMaster().Insert(job); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.Save", "store.sql_job.save.app_error", nil, "id="+job.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = job
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// SaveIfNoneActive saves the job only if there's no other job of its type that's unfinished or that was created after
+// the given time, so that servers sharing the database can't both schedule the same job. The existing jobs are locked
+// while checking, and on Postgres the transaction is serializable since a concurrent insert wouldn't otherwise be seen.
+// The result is true if the job was saved.
+func (jss SqlJobStore) SaveIfNoneActive(job *model.Job, createdAfter int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		job.PreSave()
+		if result.Err = job.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if transaction, err := jss.GetMaster().Begin(); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.SaveIfNoneActive", "store.sql_job.save.app_error", nil, "id="+job.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if saved, err := saveJobIfNoneActive(transaction, job, createdAfter); err != nil {
+			transaction.Rollback()
+
+			result.Err = model.NewAppError("SqlJobStore.SaveIfNoneActive", "store.sql_job.save.app_error", nil, "id="+job.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if err := transaction.Commit(); err != nil {
+			// don't need to rollback here since the transaction is already closed
+			result.Err = model.NewAppError("SqlJobStore.SaveIfNoneActive", "store.sql_job.save.app_error", nil, "id="+job.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = saved
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func saveJobIfNoneActive(transaction *gorp.Transaction, job *model.Job, createdAfter int64) (bool, error) {
+	if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
+		if _, err := transaction.Exec("SET TRANSACTION ISOLATION LEVEL SERIALIZABLE"); err != nil {
+			return false, err
+		}
+	}
+
+	var activeIds []string
+	if _, err := transaction.Select(&activeIds,
+		`SELECT
+			Id
+		FROM
+			Jobs
+		WHERE
+			Type = :Type
+			AND (Status IN (:Pending, :InProgress, :CancelRequested) OR CreateAt > :CreatedAfter)
+		FOR UPDATE`,
+		map[string]interface{}{
+			"Type":            job.Type,
+			"Pending":         model.JOB_STATUS_PENDING,
+			"InProgress":      model.JOB_STATUS_IN_PROGRESS,
+			"CancelRequested": model.JOB_STATUS_CANCEL_REQUESTED,
+			"CreatedAfter":    createdAfter,
+		}); err != nil {
+		return false, err
+	} else if len(activeIds) > 0 {
+		return false, nil
+	}
+
+	if err := transaction.Insert(job); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// UpdateOptimistically saves the job only if its status in the database still matches currentStatus. The result
+// is true if the job was updated.
+func (jss SqlJobStore) UpdateOptimistically(job *model.Job, currentStatus string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if result.Err = job.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		job.LastActivityAt = model.GetMillis()
+
+		if sqlResult, err := jss.GetMaster().Exec(
+			`UPDATE
+				Jobs
+			SET
+				LastActivityAt = :LastActivityAt,
+				Status = :Status,
+				Progress = :Progress,
+				LastError = :LastError,
+				Data = :Data
+			WHERE
+				Id = :Id
+				AND Status = :CurrentStatus`,
+			map[string]interface{}{
+				"Id":             job.Id,
+				"CurrentStatus":  currentStatus,
+				"LastActivityAt": job.LastActivityAt,
+				"Status":         job.Status,
+				"Progress":       job.Progress,
+				"LastError":      job.LastError,
+				"Data":           model.MapToJson(job.Data),
+			}); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.UpdateOptimistically", "store.sql_job.update.app_error", nil, "id="+job.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.UpdateOptimistically", "store.sql_job.update.app_error", nil, "id="+job.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (jss SqlJobStore) UpdateStatus(id string, status string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := jss.GetMaster().Exec(
+			"UPDATE Jobs SET Status = :Status, LastActivityAt = :LastActivityAt WHERE Id = :Id",
+			map[string]interface{}{"Id": id, "Status": status, "LastActivityAt": model.GetMillis()}); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.UpdateStatus", "store.sql_job.update.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = status
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UpdateStatusOptimistically moves the job from currentStatus to newStatus. The result is true if the job was
+// still in currentStatus and has been updated, which lets a server safely claim a job that other servers can see.
+func (jss SqlJobStore) UpdateStatusOptimistically(id string, currentStatus string, newStatus string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		now := model.GetMillis()
+		query := "UPDATE Jobs SET Status = :NewStatus, LastActivityAt = :LastActivityAt"
+		if newStatus == model.JOB_STATUS_IN_PROGRESS {
+			query += ", StartAt = :LastActivityAt"
+		}
+		query += " WHERE Id = :Id AND Status = :CurrentStatus"
+
+		if sqlResult, err := jss.GetMaster().Exec(query,
+			map[string]interface{}{"Id": id, "CurrentStatus": currentStatus, "NewStatus": newStatus, "LastActivityAt": now}); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.UpdateStatusOptimistically", "store.sql_job.update.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.UpdateStatusOptimistically", "store.sql_job.update.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UpdateLastActivityAt records that the server running a job is still working on it.
+func (jss SqlJobStore) UpdateLastActivityAt(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := jss.GetMaster().Exec(
+			"UPDATE Jobs SET LastActivityAt = :LastActivityAt WHERE Id = :Id",
+			map[string]interface{}{"Id": id, "LastActivityAt": model.GetMillis()}); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.UpdateLastActivityAt", "store.sql_job.update.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = id
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (jss SqlJobStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var job model.Job
+		if err := jss.GetReplica().SelectOne(&job, "SELECT * FROM Jobs WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlJobStore.Get", "store.sql_job.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlJobStore.Get", "store.sql_job.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &job
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (jss SqlJobStore) GetAllPage(offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var jobs []*model.Job
+		if _, err := jss.GetReplica().Select(&jobs,
+			"SELECT * FROM Jobs ORDER BY CreateAt DESC LIMIT :Limit OFFSET :Offset",
+			map[string]interface{}{"Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.GetAllPage", "store.sql_job.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = jobs
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (jss SqlJobStore) GetAllByTypePage(jobType string, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var jobs []*model.Job
+		if _, err := jss.GetReplica().Select(&jobs,
+			"SELECT * FROM Jobs WHERE Type = :Type ORDER BY CreateAt DESC LIMIT :Limit OFFSET :Offset",
+			map[string]interface{}{"Type": jobType, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.GetAllByTypePage", "store.sql_job.get_all.app_error", nil, "type="+jobType+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = jobs
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (jss SqlJobStore) GetAllByStatus(status string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var jobs []*model.Job
+		if _, err := jss.GetMaster().Select(&jobs,
+			"SELECT * FROM Jobs WHERE Status = :Status ORDER BY Priority DESC, CreateAt ASC",
+			map[string]interface{}{"Status": status}); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.GetAllByStatus", "store.sql_job.get_all.app_error", nil, "status="+status+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = jobs
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetNewestJobByType returns the most recently created job of the given type, or nil if there are none.
+func (jss SqlJobStore) GetNewestJobByType(jobType string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var jobs []*model.Job
+		if _, err := jss.GetMaster().Select(&jobs,
+			"SELECT * FROM Jobs WHERE Type = :Type ORDER BY CreateAt DESC LIMIT 1",
+			map[string]interface{}{"Type": jobType}); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.GetNewestJobByType", "store.sql_job.get_newest_job_by_type.app_error", nil, "type="+jobType+", "+err.Error(), http.StatusInternalServerError)
+		} else if len(jobs) == 0 {
+			result.Data = (*model.Job)(nil)
+		} else {
+			result.Data = jobs[0]
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (jss SqlJobStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := jss.GetMaster().Exec("DELETE FROM Jobs WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.Delete", "store.sql_job.delete.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = id
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// PermanentDeleteFinishedByTypeBefore deletes the finished jobs of a type that were created before the given time.
+func (jss SqlJobStore) PermanentDeleteFinishedByTypeBefore(jobType string, before int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := jss.GetMaster().Exec(
+			"DELETE FROM Jobs WHERE Type = :Type AND CreateAt < :Before AND Status IN (:Success, :Error, :Canceled)",
+			map[string]interface{}{
+				"Type":     jobType,
+				"Before":   before,
+				"Success":  model.JOB_STATUS_SUCCESS,
+				"Error":    model.JOB_STATUS_ERROR,
+				"Canceled": model.JOB_STATUS_CANCELED,
+			}); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.PermanentDeleteFinishedByTypeBefore", "store.sql_job.delete.app_error", nil, "type="+jobType+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlJobStore.PermanentDeleteFinishedByTypeBefore", "store.sql_job.delete.app_error", nil, "type="+jobType+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = rows
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestJobSaveGet(t *testing.T) {
+	Setup()
+
+	job := &model.Job{
+		Type: model.JOB_TYPE_DATA_RETENTION,
+		Data: model.StringMap{
+			"Processed":     "0",
+			"Total":         "12345",
+			"LastProcessed": "abcd",
+		},
+	}
+
+	if result := <-store.Job().Save(job); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	defer func() {
+		<-store.Job().Delete(job.Id)
+	}()
+
+	if result := <-store.Job().Get(job.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if received := result.Data.(*model.Job); received.Id != job.Id {
+		t.Fatal("received incorrect job after save")
+	} else if received.Data["Total"] != "12345" || received.Status != model.JOB_STATUS_PENDING {
+		t.Fatal("data and status should have been saved")
+	}
+
+	if result := <-store.Job().Get(model.NewId()); result.Err == nil {
+		t.Fatal("should've failed to get a missing job")
+	}
+}
+
+func TestJobGetAllByTypePage(t *testing.T) {
+	Setup()
+
+	jobType := model.JOB_TYPE_DATA_RETENTION
+
+	jobs := []*model.Job{
+		{Type: jobType},
+		{Type: jobType},
+	}
+
+	for _, job := range jobs {
+		Must(store.Job().Save(job))
+		defer store.Job().Delete(job.Id)
+	}
+
+	if received := Must(store.Job().GetAllByTypePage(jobType, 0, 1)).([]*model.Job); len(received) != 1 {
+		t.Fatal("received wrong number of jobs")
+	} else if received[0].CreateAt < jobs[0].CreateAt {
+		t.Fatal("should've received newest job first")
+	}
+
+	if received := Must(store.Job().GetAllPage(0, 2)).([]*model.Job); len(received) != 2 {
+		t.Fatal("received wrong number of jobs")
+	}
+}
+
+func TestJobGetAllByStatus(t *testing.T) {
+	Setup()
+
+	job := &model.Job{Type: model.JOB_TYPE_DATA_RETENTION}
+	Must(store.Job().Save(job))
+	defer store.Job().Delete(job.Id)
+
+	found := false
+	for _, received := range Must(store.Job().GetAllByStatus(model.JOB_STATUS_PENDING)).([]*model.Job) {
+		if received.Id == job.Id {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should've received the pending job")
+	}
+
+	if newest := Must(store.Job().GetNewestJobByType(model.JOB_TYPE_DATA_RETENTION)).(*model.Job); newest == nil {
+		t.Fatal("should've received the newest job")
+	}
+}
+
+func TestJobSaveIfNoneActive(t *testing.T) {
+	Setup()
+
+	job := &model.Job{Type: model.JOB_TYPE_DATA_RETENTION}
+	Must(store.Job().Save(job))
+	defer store.Job().Delete(job.Id)
+
+	job2 := &model.Job{Type: model.JOB_TYPE_DATA_RETENTION}
+	if saved := Must(store.Job().SaveIfNoneActive(job2, model.GetMillis()+1)).(bool); saved {
+		store.Job().Delete(job2.Id)
+		t.Fatal("shouldn't have saved a job while another is pending")
+	}
+
+	Must(store.Job().UpdateStatus(job.Id, model.JOB_STATUS_SUCCESS))
+
+	job3 := &model.Job{Type: model.JOB_TYPE_DATA_RETENTION}
+	if saved := Must(store.Job().SaveIfNoneActive(job3, job.CreateAt-1)).(bool); saved {
+		store.Job().Delete(job3.Id)
+		t.Fatal("shouldn't have saved a job while another was created too recently")
+	}
+
+	job4 := &model.Job{Type: model.JOB_TYPE_DATA_RETENTION}
+	if saved := Must(store.Job().SaveIfNoneActive(job4, model.GetMillis()+1)).(bool); !saved {
+		t.Fatal("should have saved the job")
+	}
+	defer store.Job().Delete(job4.Id)
+
+	if received := Must(store.Job().Get(job4.Id)).(*model.Job); received.Status != model.JOB_STATUS_PENDING {
+		t.Fatal("should have saved the job as pending")
+	}
+}
+
+func TestJobUpdateOptimistically(t *testing.T) {
+	Setup()
+
+	job := &model.Job{Type: model.JOB_TYPE_DATA_RETENTION}
+	Must(store.Job().Save(job))
+	defer store.Job().Delete(job.Id)
+
+	job.Status = model.JOB_STATUS_IN_PROGRESS
+	job.Progress = 50
+	job.Data["key"] = "value"
+
+	if updated := Must(store.Job().UpdateOptimistically(job, model.JOB_STATUS_SUCCESS)).(bool); updated {
+		t.Fatal("shouldn't have updated a job with a different status")
+	}
+
+	if updated := Must(store.Job().UpdateOptimistically(job, model.JOB_STATUS_PENDING)).(bool); !updated {
+		t.Fatal("should have updated the job")
+	}
+
+	if received := Must(store.Job().Get(job.Id)).(*model.Job); received.Status != model.JOB_STATUS_IN_PROGRESS || received.Progress != 50 || received.Data["key"] != "value" {
+		t.Fatal("job wasn't updated")
+	}
+}
+
+func TestJobUpdateStatus(t *testing.T) {
+	Setup()
+
+	job := &model.Job{Type: model.JOB_TYPE_DATA_RETENTION}
+	Must(store.Job().Save(job))
+	defer store.Job().Delete(job.Id)
+
+	if claimed := Must(store.Job().UpdateStatusOptimistically(job.Id, model.JOB_STATUS_PENDING, model.JOB_STATUS_IN_PROGRESS)).(bool); !claimed {
+		t.Fatal("should've claimed the pending job")
+	}
+
+	if claimed := Must(store.Job().UpdateStatusOptimistically(job.Id, model.JOB_STATUS_PENDING, model.JOB_STATUS_IN_PROGRESS)).(bool); claimed {
+		t.Fatal("shouldn't be able to claim the job twice")
+	}
+
+	if received := Must(store.Job().Get(job.Id)).(*model.Job); received.Status != model.JOB_STATUS_IN_PROGRESS || received.StartAt == 0 {
+		t.Fatal("job should've been started")
+	}
+
+	Must(store.Job().UpdateStatus(job.Id, model.JOB_STATUS_SUCCESS))
+
+	if received := Must(store.Job().Get(job.Id)).(*model.Job); received.Status != model.JOB_STATUS_SUCCESS {
+		t.Fatal("status should've been updated")
+	}
+}
+
+func TestJobPermanentDeleteFinishedByTypeBefore(t *testing.T) {
+	Setup()
+
+	finishedJob := &model.Job{Type: model.JOB_TYPE_DATA_RETENTION, Status: model.JOB_STATUS_SUCCESS}
+	Must(store.Job().Save(finishedJob))
+	defer store.Job().Delete(finishedJob.Id)
+
+	pendingJob := &model.Job{Type: model.JOB_TYPE_DATA_RETENTION}
+	Must(store.Job().Save(pendingJob))
+	defer store.Job().Delete(pendingJob.Id)
+
+	Must(store.Job().PermanentDeleteFinishedByTypeBefore(model.JOB_TYPE_DATA_RETENTION, finishedJob.CreateAt))
+
+	if result := <-store.Job().Get(finishedJob.Id); result.Err != nil {
+		t.Fatal("shouldn't have deleted a job created at the cutoff")
+	}
+
+	Must(store.Job().PermanentDeleteFinishedByTypeBefore(model.JOB_TYPE_DATA_RETENTION, model.GetMillis()+1))
+
+	if result := <-store.Job().Get(finishedJob.Id); result.Err == nil {
+		t.Fatal("should have deleted the finished job")
+	}
+
+	if result := <-store.Job().Get(pendingJob.Id); result.Err != nil {
+		t.Fatal("shouldn't have deleted a job that hasn't finished")
+	}
+}
@@ -26,6 +26,7 @@ func NewSqlOAuthStore(sqlStore *SqlStore) OAuthStore {
 		table.ColMap("Description").SetMaxSize(512)
 		table.ColMap("CallbackUrls").SetMaxSize(1024)
 		table.ColMap("Homepage").SetMaxSize(256)
+		table.ColMap("BotUserId").SetMaxSize(26)
 		table.ColMap("IconURL").SetMaxSize(512)
 
 		tableAuth := db.AddTableWithName(model.AuthData{}, "OAuthAuthData").SetKeys(false, "Code")
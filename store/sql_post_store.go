@@ -476,6 +476,152 @@ func (s SqlPostStore) PermanentDeleteByChannel(channelId string) StoreChannel {
 	return storeChannel
 }
 
+// GetPostsForDataRetention reads from the master since the posts that it returns are deleted before it's called again,
+// and a replica could still return posts that were already deleted.
+func (s SqlPostStore) GetPostsForDataRetention(policy *model.DataRetentionPolicy, endTime int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"EndTime": endTime, "Limit": limit}
+
+		scopeQuery := ""
+		switch policy.Scope {
+		case model.DATA_RETENTION_SCOPE_CHANNEL:
+			scopeQuery = "Posts.ChannelId = :ScopeId"
+			props["ScopeId"] = policy.ScopeId
+		case model.DATA_RETENTION_SCOPE_TEAM:
+			scopeQuery = "Channels.TeamId = :ScopeId AND Channels.Type IN ('" + model.CHANNEL_OPEN + "', '" + model.CHANNEL_PRIVATE + "')"
+			props["ScopeId"] = policy.ScopeId
+		case model.DATA_RETENTION_SCOPE_DIRECT:
+			scopeQuery = "Channels.Type IN ('" + model.CHANNEL_DIRECT + "', '" + model.CHANNEL_GROUP + "')"
+		default:
+			scopeQuery = "Channels.Type IN ('" + model.CHANNEL_OPEN + "', '" + model.CHANNEL_PRIVATE + "')"
+		}
+
+		if len(policy.ExcludeChannelIds) > 0 {
+			scopeQuery += " AND Posts.ChannelId NOT IN (" + buildInQuery("ExcludeChannelId", policy.ExcludeChannelIds, props) + ")"
+		}
+
+		if len(policy.ExcludeTeamIds) > 0 {
+			scopeQuery += " AND Channels.TeamId NOT IN (" + buildInQuery("ExcludeTeamId", policy.ExcludeTeamIds, props) + ")"
+		}
+
+		var posts []*model.Post
+		if _, err := s.GetMaster().Select(&posts,
+			`SELECT
+				Posts.*
+			FROM
+				Posts
+			INNER JOIN
+				Channels ON Posts.ChannelId = Channels.Id
+			WHERE
+				Posts.CreateAt < :EndTime
+				AND `+scopeQuery+`
+			ORDER BY Posts.CreateAt
+			LIMIT :Limit`, props); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.GetPostsForDataRetention", "store.sql_post.get_posts_for_data_retention.app_error", nil, "scope="+policy.Scope+", scope_id="+policy.ScopeId+", "+err.Error())
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostStore) PermanentDeleteByIds(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(postIds) > 0 {
+			props := map[string]interface{}{}
+			idQuery := buildInQuery("PostId", postIds, props)
+
+			if sqlResult, err := s.GetMaster().Exec("DELETE FROM Posts WHERE Id IN ("+idQuery+")", props); err != nil {
+				result.Err = model.NewLocAppError("SqlPostStore.PermanentDeleteByIds", "store.sql_post.permanent_delete_by_ids.app_error", nil, err.Error())
+			} else {
+				rowsAffected, _ := sqlResult.RowsAffected()
+				result.Data = rowsAffected
+			}
+		} else {
+			result.Data = int64(0)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetPostsForExport returns the channel's posts that haven't been deleted, ordered by creation time. Each page
+// starts after the post identified by afterCreateAt and afterId so that large channels can be read in batches.
+func (s SqlPostStore) GetPostsForExport(channelId string, afterCreateAt int64, afterId string, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.Post
+		if _, err := s.GetReplica().Select(&posts,
+			`SELECT
+				*
+			FROM
+				Posts
+			WHERE
+				ChannelId = :ChannelId
+				AND DeleteAt = 0
+				AND (CreateAt > :CreateAt OR (CreateAt = :CreateAt AND Id > :Id))
+			ORDER BY CreateAt, Id
+			LIMIT :Limit`,
+			map[string]interface{}{"ChannelId": channelId, "CreateAt": afterCreateAt, "Id": afterId, "Limit": limit}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.GetPostsForExport", "store.sql_post.get_posts_for_export.app_error", nil, "channel_id="+channelId+", "+err.Error())
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostStore) GetRepliesForExport(rootId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.Post
+		if _, err := s.GetReplica().Select(&posts,
+			`SELECT
+				*
+			FROM
+				Posts
+			WHERE
+				RootId = :RootId
+				AND DeleteAt = 0
+			ORDER BY CreateAt, Id`,
+			map[string]interface{}{"RootId": rootId}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.GetRepliesForExport", "store.sql_post.get_replies_for_export.app_error", nil, "root_id="+rootId+", "+err.Error())
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlPostStore) GetPosts(channelId string, offset int, limit int, allowFromCache bool) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
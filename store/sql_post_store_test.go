@@ -1265,3 +1265,188 @@ func TestPostStoreOverwrite(t *testing.T) {
 		t.Fatal("Failed to set FileIds")
 	}
 }
+
+func TestPostStoreGetPostsForDataRetention(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+
+	c1 := &model.Channel{}
+	c1.TeamId = teamId
+	c1.DisplayName = "Channel1"
+	c1.Name = "a" + model.NewId() + "b"
+	c1.Type = model.CHANNEL_OPEN
+	c1 = Must(store.Channel().Save(c1)).(*model.Channel)
+
+	c2 := &model.Channel{}
+	c2.TeamId = teamId
+	c2.DisplayName = "Channel2"
+	c2.Name = "a" + model.NewId() + "b"
+	c2.Type = model.CHANNEL_PRIVATE
+	c2 = Must(store.Channel().Save(c2)).(*model.Channel)
+
+	o1 := &model.Post{}
+	o1.ChannelId = c1.Id
+	o1.UserId = model.NewId()
+	o1.Message = "a" + model.NewId() + "b"
+	o1.CreateAt = 1000
+	o1 = Must(store.Post().Save(o1)).(*model.Post)
+
+	o2 := &model.Post{}
+	o2.ChannelId = c1.Id
+	o2.UserId = model.NewId()
+	o2.Message = "a" + model.NewId() + "b"
+	o2 = Must(store.Post().Save(o2)).(*model.Post)
+
+	o3 := &model.Post{}
+	o3.ChannelId = c2.Id
+	o3.UserId = model.NewId()
+	o3.Message = "a" + model.NewId() + "b"
+	o3.CreateAt = 2000
+	o3 = Must(store.Post().Save(o3)).(*model.Post)
+
+	endTime := o2.CreateAt - 1
+
+	channelPolicy := &model.DataRetentionPolicy{Scope: model.DATA_RETENTION_SCOPE_CHANNEL, ScopeId: c1.Id}
+	if posts := Must(store.Post().GetPostsForDataRetention(channelPolicy, endTime, 100)).([]*model.Post); len(posts) != 1 || posts[0].Id != o1.Id {
+		t.Fatal("should've returned only the old post in the channel")
+	}
+
+	teamPolicy := &model.DataRetentionPolicy{Scope: model.DATA_RETENTION_SCOPE_TEAM, ScopeId: teamId}
+	if posts := Must(store.Post().GetPostsForDataRetention(teamPolicy, endTime, 100)).([]*model.Post); len(posts) != 2 {
+		t.Fatal("should've returned the old posts in both channels")
+	}
+
+	if posts := Must(store.Post().GetPostsForDataRetention(teamPolicy, endTime, 1)).([]*model.Post); len(posts) != 1 || posts[0].Id != o1.Id {
+		t.Fatal("should've returned the oldest post only")
+	}
+
+	teamPolicy.ExcludeChannelIds = []string{c1.Id}
+	if posts := Must(store.Post().GetPostsForDataRetention(teamPolicy, endTime, 100)).([]*model.Post); len(posts) != 1 || posts[0].Id != o3.Id {
+		t.Fatal("should've excluded the overridden channel")
+	}
+
+	directPolicy := &model.DataRetentionPolicy{Scope: model.DATA_RETENTION_SCOPE_DIRECT}
+	for _, post := range Must(store.Post().GetPostsForDataRetention(directPolicy, endTime, 1000)).([]*model.Post) {
+		if post.ChannelId == c1.Id || post.ChannelId == c2.Id {
+			t.Fatal("shouldn't have returned posts from team channels")
+		}
+	}
+}
+
+func TestPostStorePermanentDeleteByIds(t *testing.T) {
+	Setup()
+
+	o1 := &model.Post{}
+	o1.ChannelId = model.NewId()
+	o1.UserId = model.NewId()
+	o1.Message = "a" + model.NewId() + "b"
+	o1 = Must(store.Post().Save(o1)).(*model.Post)
+
+	o2 := &model.Post{}
+	o2.ChannelId = o1.ChannelId
+	o2.UserId = model.NewId()
+	o2.Message = "a" + model.NewId() + "b"
+	o2 = Must(store.Post().Save(o2)).(*model.Post)
+
+	if deleted := Must(store.Post().PermanentDeleteByIds([]string{o1.Id})).(int64); deleted != 1 {
+		t.Fatal("should've deleted one post")
+	}
+
+	if r := <-store.Post().Get(o1.Id); r.Err == nil {
+		t.Fatal("Deleted id should have failed")
+	}
+
+	if r := <-store.Post().Get(o2.Id); r.Err != nil {
+		t.Fatal("Deleted id shouldn't have failed")
+	}
+
+	if deleted := Must(store.Post().PermanentDeleteByIds([]string{})).(int64); deleted != 0 {
+		t.Fatal("shouldn't have deleted anything")
+	}
+}
+
+func TestPostStoreGetPostsForExport(t *testing.T) {
+	Setup()
+
+	channelId := model.NewId()
+
+	o1 := &model.Post{}
+	o1.ChannelId = channelId
+	o1.UserId = model.NewId()
+	o1.Message = "a" + model.NewId() + "b"
+	o1.CreateAt = 1000
+	o1 = Must(store.Post().Save(o1)).(*model.Post)
+
+	o2 := &model.Post{}
+	o2.ChannelId = channelId
+	o2.UserId = model.NewId()
+	o2.Message = "a" + model.NewId() + "b"
+	o2.CreateAt = 1000
+	o2 = Must(store.Post().Save(o2)).(*model.Post)
+
+	o3 := &model.Post{}
+	o3.ChannelId = channelId
+	o3.UserId = model.NewId()
+	o3.Message = "a" + model.NewId() + "b"
+	o3.CreateAt = 2000
+	o3 = Must(store.Post().Save(o3)).(*model.Post)
+	Must(store.Post().Delete(o3.Id, model.GetMillis()))
+
+	first, second := o1, o2
+	if second.Id < first.Id {
+		first, second = second, first
+	}
+
+	if posts := Must(store.Post().GetPostsForExport(channelId, 0, "", 1)).([]*model.Post); len(posts) != 1 || posts[0].Id != first.Id {
+		t.Fatal("should've returned the first post")
+	}
+
+	if posts := Must(store.Post().GetPostsForExport(channelId, first.CreateAt, first.Id, 10)).([]*model.Post); len(posts) != 1 || posts[0].Id != second.Id {
+		t.Fatal("should've returned the post after the first one, skipping the deleted post")
+	}
+}
+
+func TestPostStoreGetRepliesForExport(t *testing.T) {
+	Setup()
+
+	root := Must(store.Post().Save(&model.Post{
+		ChannelId: model.NewId(),
+		UserId:    model.NewId(),
+		Message:   "a" + model.NewId() + "b",
+	})).(*model.Post)
+
+	r1 := Must(store.Post().Save(&model.Post{
+		ChannelId: root.ChannelId,
+		UserId:    model.NewId(),
+		Message:   "a" + model.NewId() + "b",
+		RootId:    root.Id,
+		ParentId:  root.Id,
+		CreateAt:  root.CreateAt + 2,
+	})).(*model.Post)
+
+	r2 := Must(store.Post().Save(&model.Post{
+		ChannelId: root.ChannelId,
+		UserId:    model.NewId(),
+		Message:   "a" + model.NewId() + "b",
+		RootId:    root.Id,
+		ParentId:  root.Id,
+		CreateAt:  root.CreateAt + 1,
+	})).(*model.Post)
+
+	r3 := Must(store.Post().Save(&model.Post{
+		ChannelId: root.ChannelId,
+		UserId:    model.NewId(),
+		Message:   "a" + model.NewId() + "b",
+		RootId:    root.Id,
+		ParentId:  root.Id,
+		CreateAt:  root.CreateAt + 3,
+	})).(*model.Post)
+	Must(store.Post().Delete(r3.Id, model.GetMillis()))
+
+	if replies := Must(store.Post().GetRepliesForExport(root.Id)).([]*model.Post); len(replies) != 2 {
+		t.Fatal("should've returned the replies that haven't been deleted")
+	} else if replies[0].Id != r2.Id || replies[1].Id != r1.Id {
+		t.Fatal("should've returned the replies in the order they were created")
+	}
+}
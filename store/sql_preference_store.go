@@ -234,6 +234,35 @@ func (s SqlPreferenceStore) GetCategory(userId string, category string) StoreCha
 	return storeChannel
 }
 
+// GetCategoryAndName gets the preference with the given category and name for every user that has set it.
+func (s SqlPreferenceStore) GetCategoryAndName(category string, name string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var preferences model.Preferences
+
+		if _, err := s.GetReplica().Select(&preferences,
+			`SELECT
+				*
+			FROM
+				Preferences
+			WHERE
+				Category = :Category
+				AND Name = :Name`, map[string]interface{}{"Category": category, "Name": name}); err != nil {
+			result.Err = model.NewLocAppError("SqlPreferenceStore.GetCategoryAndName", "store.sql_preference.get_category_and_name.app_error", nil, err.Error())
+		} else {
+			result.Data = preferences
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlPreferenceStore) GetAll(userId string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -193,6 +193,43 @@ func TestPreferenceGetAll(t *testing.T) {
 	}
 }
 
+func TestPreferenceGetCategoryAndName(t *testing.T) {
+	Setup()
+
+	category := model.PREFERENCE_CATEGORY_NOTIFICATIONS
+	name := model.NewId()
+
+	preferences := model.Preferences{
+		{
+			UserId:   model.NewId(),
+			Category: category,
+			Name:     name,
+		},
+		// same category/name, different user
+		{
+			UserId:   model.NewId(),
+			Category: category,
+			Name:     name,
+		},
+		// same category, different name
+		{
+			UserId:   model.NewId(),
+			Category: category,
+			Name:     model.NewId(),
+		},
+	}
+
+	Must(store.Preference().Save(&preferences))
+
+	if result := <-store.Preference().GetCategoryAndName(category, name); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if data := result.Data.(model.Preferences); len(data) != 2 {
+		t.Fatal("got the wrong number of preferences")
+	} else if (data[0] != preferences[0] || data[1] != preferences[1]) && (data[0] != preferences[1] || data[1] != preferences[0]) {
+		t.Fatal("got incorrect preferences")
+	}
+}
+
 func TestPreferenceDeleteByUser(t *testing.T) {
 	Setup()
 
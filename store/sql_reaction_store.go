@@ -269,3 +269,31 @@ func (s SqlReactionStore) DeleteAllWithEmojiName(emojiName string) StoreChannel
 
 	return storeChannel
 }
+
+func (s SqlReactionStore) PermanentDeleteForPosts(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(postIds) > 0 {
+			props := map[string]interface{}{}
+			idQuery := buildInQuery("PostId", postIds, props)
+
+			if sqlResult, err := s.GetMaster().Exec("DELETE FROM Reactions WHERE PostId IN ("+idQuery+")", props); err != nil {
+				result.Err = model.NewLocAppError("SqlReactionStore.PermanentDeleteForPosts",
+					"store.sql_reaction.permanent_delete_for_posts.app_error", nil, err.Error())
+			} else {
+				rowsAffected, _ := sqlResult.RowsAffected()
+				result.Data = rowsAffected
+			}
+		} else {
+			result.Data = int64(0)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -294,3 +294,50 @@ func TestReactionDeleteAllWithEmojiName(t *testing.T) {
 		t.Fatal("post shouldn't have reactions any more")
 	}
 }
+
+func TestReactionPermanentDeleteForPosts(t *testing.T) {
+	Setup()
+
+	post := Must(store.Post().Save(&model.Post{
+		ChannelId: model.NewId(),
+		UserId:    model.NewId(),
+	})).(*model.Post)
+	post2 := Must(store.Post().Save(&model.Post{
+		ChannelId: model.NewId(),
+		UserId:    model.NewId(),
+	})).(*model.Post)
+
+	reactions := []*model.Reaction{
+		{
+			UserId:    model.NewId(),
+			PostId:    post.Id,
+			EmojiName: "smile",
+		},
+		{
+			UserId:    model.NewId(),
+			PostId:    post.Id,
+			EmojiName: "sad",
+		},
+		{
+			UserId:    model.NewId(),
+			PostId:    post2.Id,
+			EmojiName: "smile",
+		},
+	}
+
+	for _, reaction := range reactions {
+		Must(store.Reaction().Save(reaction))
+	}
+
+	if deleted := Must(store.Reaction().PermanentDeleteForPosts([]string{post.Id})).(int64); deleted != 2 {
+		t.Fatal("should've deleted both reactions on the post")
+	}
+
+	if returned := Must(store.Reaction().GetForPost(post.Id, false)).([]*model.Reaction); len(returned) != 0 {
+		t.Fatal("shouldn't have returned any reactions")
+	}
+
+	if returned := Must(store.Reaction().GetForPost(post2.Id, false)).([]*model.Reaction); len(returned) != 1 {
+		t.Fatal("should've kept the reaction on the other post")
+	}
+}
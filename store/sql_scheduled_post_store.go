@@ -0,0 +1,233 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlScheduledPostStore struct {
+	*SqlStore
+}
+
+func NewSqlScheduledPostStore(sqlStore *SqlStore) ScheduledPostStore {
+	s := &SqlScheduledPostStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ScheduledPost{}, "ScheduledPosts").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("RootId").SetMaxSize(26)
+		table.ColMap("Message").SetMaxSize(model.POST_MESSAGE_MAX_RUNES)
+		table.ColMap("Recurrence").SetMaxSize(32)
+		table.ColMap("Timezone").SetMaxSize(64)
+	}
+
+	return s
+}
+
+func (s SqlScheduledPostStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_scheduled_posts_user_id", "ScheduledPosts", "UserId")
+	s.CreateIndexIfNotExists("idx_scheduled_posts_scheduled_at", "ScheduledPosts", "ScheduledAt")
+}
+
+func (s SqlScheduledPostStore) Save(scheduledPost *model.ScheduledPost) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(scheduledPost.Id) > 0 {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Save", "store.sql_scheduled_post.save.existing.app_error", nil, "id="+scheduledPost.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		scheduledPost.PreSave()
+		if result.Err = scheduledPost.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(scheduledPost); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Save", "store.sql_scheduled_post.save.app_error", nil, "id="+scheduledPost.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = scheduledPost
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var scheduledPost model.ScheduledPost
+
+		if err := s.GetReplica().SelectOne(&scheduledPost, "SELECT * FROM ScheduledPosts WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlScheduledPostStore.Get", "store.sql_scheduled_post.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlScheduledPostStore.Get", "store.sql_scheduled_post.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &scheduledPost
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) GetForUser(userId string, offset, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var scheduledPosts []*model.ScheduledPost
+
+		if _, err := s.GetReplica().Select(&scheduledPosts, "SELECT * FROM ScheduledPosts WHERE UserId = :UserId ORDER BY ScheduledAt ASC LIMIT :Limit OFFSET :Offset", map[string]interface{}{"UserId": userId, "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.GetForUser", "store.sql_scheduled_post.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = scheduledPosts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetDue gets the posts that were scheduled to be sent at or before the given time, earliest first.
+func (s SqlScheduledPostStore) GetDue(time int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var scheduledPosts []*model.ScheduledPost
+
+		if _, err := s.GetMaster().Select(&scheduledPosts, "SELECT * FROM ScheduledPosts WHERE ScheduledAt <= :Time ORDER BY ScheduledAt ASC LIMIT :Limit", map[string]interface{}{"Time": time, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.GetDue", "store.sql_scheduled_post.get_due.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = scheduledPosts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) Update(scheduledPost *model.ScheduledPost) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		scheduledPost.PreUpdate()
+		if result.Err = scheduledPost.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(scheduledPost); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Update", "store.sql_scheduled_post.update.app_error", nil, "id="+scheduledPost.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count == 0 {
+			// it was sent or cancelled in the meantime
+			result.Err = model.NewAppError("SqlScheduledPostStore.Update", "store.sql_scheduled_post.get.app_error", nil, "id="+scheduledPost.Id, http.StatusNotFound)
+		} else {
+			result.Data = scheduledPost
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Claim takes responsibility for sending a post that is due by moving it on to the given time, but only if it's still
+// scheduled for the time it was read with, so that when several servers find the same post due, exactly one of them
+// gets true back and sends it.
+func (s SqlScheduledPostStore) Claim(scheduledPost *model.ScheduledPost, nextScheduledAt int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		params := map[string]interface{}{
+			"Id":              scheduledPost.Id,
+			"ScheduledAt":     scheduledPost.ScheduledAt,
+			"NextScheduledAt": nextScheduledAt,
+			"UpdateAt":        model.GetMillis(),
+		}
+
+		if sqlResult, err := s.GetMaster().Exec("UPDATE ScheduledPosts SET ScheduledAt = :NextScheduledAt, UpdateAt = :UpdateAt WHERE Id = :Id AND ScheduledAt = :ScheduledAt", params); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Claim", "store.sql_scheduled_post.claim.app_error", nil, "id="+scheduledPost.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			rows, _ := sqlResult.RowsAffected()
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ScheduledPosts WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Delete", "store.sql_scheduled_post.delete.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = id
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ScheduledPosts WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.PermanentDeleteByUser", "store.sql_scheduled_post.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestScheduledPostStore(t *testing.T) {
+	Setup()
+
+	now := model.GetMillis()
+
+	sp1 := &model.ScheduledPost{
+		UserId:      model.NewId(),
+		ChannelId:   model.NewId(),
+		Message:     "once",
+		ScheduledAt: now - 1000,
+	}
+	sp1 = (<-store.ScheduledPost().Save(sp1)).Data.(*model.ScheduledPost)
+
+	sp2 := &model.ScheduledPost{
+		UserId:      sp1.UserId,
+		ChannelId:   model.NewId(),
+		Message:     "every day",
+		ScheduledAt: now - 500,
+		Recurrence:  model.SCHEDULED_POST_RECURRENCE_DAILY,
+		Timezone:    "UTC",
+	}
+	sp2 = (<-store.ScheduledPost().Save(sp2)).Data.(*model.ScheduledPost)
+
+	sp3 := &model.ScheduledPost{
+		UserId:      model.NewId(),
+		ChannelId:   model.NewId(),
+		Message:     "later",
+		ScheduledAt: now + 60*60*1000,
+	}
+	sp3 = (<-store.ScheduledPost().Save(sp3)).Data.(*model.ScheduledPost)
+
+	if r := <-store.ScheduledPost().Save(sp3); r.Err == nil {
+		t.Fatal("shouldn't be able to save an existing scheduled post")
+	}
+
+	if r := <-store.ScheduledPost().Get(sp1.Id); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if r.Data.(*model.ScheduledPost).Message != sp1.Message {
+		t.Fatal("got the wrong scheduled post")
+	}
+
+	if r := <-store.ScheduledPost().GetForUser(sp1.UserId, 0, 10); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if received := r.Data.([]*model.ScheduledPost); len(received) != 2 || received[0].Id != sp1.Id || received[1].Id != sp2.Id {
+		t.Fatal("should have gotten the user's scheduled posts in order")
+	}
+
+	if r := <-store.ScheduledPost().GetDue(now, 1000); r.Err != nil {
+		t.Fatal(r.Err)
+	} else {
+		for _, sp := range r.Data.([]*model.ScheduledPost) {
+			if sp.Id == sp3.Id {
+				t.Fatal("shouldn't have gotten a post that isn't due")
+			}
+		}
+	}
+
+	next := sp2.NextScheduledAt(now)
+	if r := <-store.ScheduledPost().Claim(sp2, next); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if !r.Data.(bool) {
+		t.Fatal("should have claimed the recurring post")
+	}
+
+	if r := <-store.ScheduledPost().Claim(sp2, next); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if r.Data.(bool) {
+		t.Fatal("shouldn't have claimed the recurring post twice")
+	}
+
+	if r := <-store.ScheduledPost().Get(sp2.Id); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if r.Data.(*model.ScheduledPost).ScheduledAt != next {
+		t.Fatal("should have moved the recurring post to its next time")
+	}
+
+	if r := <-store.ScheduledPost().Claim(sp1, now+1000); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if !r.Data.(bool) {
+		t.Fatal("should have claimed the post")
+	}
+
+	if r := <-store.ScheduledPost().Get(sp1.Id); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if r.Data.(*model.ScheduledPost).ScheduledAt != now+1000 {
+		t.Fatal("should have moved the post to the given time")
+	}
+
+	if r := <-store.ScheduledPost().Delete(sp1.Id); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	sp3.Message = "updated"
+	if r := <-store.ScheduledPost().Update(sp3); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	if r := <-store.ScheduledPost().Get(sp3.Id); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if r.Data.(*model.ScheduledPost).Message != "updated" {
+		t.Fatal("should have updated the message")
+	}
+
+	if r := <-store.ScheduledPost().Update(sp1); r.Err == nil {
+		t.Fatal("shouldn't be able to update a post that was sent")
+	}
+
+	if r := <-store.ScheduledPost().Delete(sp3.Id); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	if r := <-store.ScheduledPost().Get(sp3.Id); r.Err == nil {
+		t.Fatal("should have deleted the scheduled post")
+	}
+
+	if r := <-store.ScheduledPost().PermanentDeleteByUser(sp2.UserId); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	if r := <-store.ScheduledPost().GetForUser(sp2.UserId, 0, 10); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if len(r.Data.([]*model.ScheduledPost)) != 0 {
+		t.Fatal("should have deleted the user's scheduled posts")
+	}
+}
@@ -26,6 +26,7 @@ func NewSqlStatusStore(sqlStore *SqlStore) StatusStore {
 		table.ColMap("UserId").SetMaxSize(26)
 		table.ColMap("Status").SetMaxSize(32)
 		table.ColMap("ActiveChannel").SetMaxSize(26)
+		table.ColMap("PrevStatus").SetMaxSize(32)
 	}
 
 	return s
@@ -122,6 +123,60 @@ func (s SqlStatusStore) GetByIds(userIds []string) StoreChannel {
 	return storeChannel
 }
 
+// GetExpiredDND gets the statuses of the users whose do not disturb ended at or before the given time. It reads from
+// the master so that do not disturb statuses that were just changed aren't seen as expired.
+func (s SqlStatusStore) GetExpiredDND(time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var statuses []*model.Status
+		if _, err := s.GetMaster().Select(&statuses,
+			`SELECT
+				*
+			FROM
+				Status
+			WHERE
+				Status = :DND
+				AND DNDEndTime > 0
+				AND DNDEndTime <= :Time`, map[string]interface{}{"DND": model.STATUS_DND, "Time": time}); err != nil {
+			result.Err = model.NewLocAppError("SqlStatusStore.GetExpiredDND", "store.sql_status.get_expired_dnd.app_error", nil, err.Error())
+		} else {
+			result.Data = statuses
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// EndExpiredDND changes a user's status from do not disturb to the given one, but only if they're still in the do not
+// disturb that ends at the given time, so that a status that the user changed in the meantime is left alone. It
+// returns true if the status was changed.
+func (s SqlStatusStore) EndExpiredDND(userId string, dndEndTime int64, status string, manual bool) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("UPDATE Status SET Status = :Status, Manual = :Manual, DNDEndTime = 0, PrevStatus = '' WHERE UserId = :UserId AND Status = :DND AND DNDEndTime = :DNDEndTime",
+			map[string]interface{}{"Status": status, "Manual": manual, "UserId": userId, "DND": model.STATUS_DND, "DNDEndTime": dndEndTime}); err != nil {
+			result.Err = model.NewLocAppError("SqlStatusStore.EndExpiredDND", "store.sql_status.end_expired_dnd.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			rows, _ := sqlResult.RowsAffected()
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlStatusStore) GetOnlineAway() StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
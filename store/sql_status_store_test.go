@@ -102,3 +102,68 @@ func TestActiveUserCount(t *testing.T) {
 		}
 	}
 }
+
+func TestStatusGetExpiredDND(t *testing.T) {
+	Setup()
+
+	now := model.GetMillis()
+
+	expired := &model.Status{UserId: model.NewId(), Status: model.STATUS_DND, Manual: true, DNDEndTime: now - 1000}
+	Must(store.Status().SaveOrUpdate(expired))
+
+	unexpired := &model.Status{UserId: model.NewId(), Status: model.STATUS_DND, Manual: true, DNDEndTime: now + 60000}
+	Must(store.Status().SaveOrUpdate(unexpired))
+
+	indefinite := &model.Status{UserId: model.NewId(), Status: model.STATUS_DND, Manual: true, DNDEndTime: 0}
+	Must(store.Status().SaveOrUpdate(indefinite))
+
+	if result := <-store.Status().GetExpiredDND(now); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := false
+		for _, status := range result.Data.([]*model.Status) {
+			if status.UserId == unexpired.UserId || status.UserId == indefinite.UserId {
+				t.Fatal("should only have gotten expired statuses")
+			} else if status.UserId == expired.UserId {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatal("should have gotten the expired status")
+		}
+	}
+}
+
+func TestStatusEndExpiredDND(t *testing.T) {
+	Setup()
+
+	now := model.GetMillis()
+
+	status := &model.Status{UserId: model.NewId(), Status: model.STATUS_DND, Manual: true, DNDEndTime: now, PrevStatus: model.STATUS_AWAY}
+	Must(store.Status().SaveOrUpdate(status))
+
+	if result := <-store.Status().EndExpiredDND(status.UserId, now-1, model.STATUS_AWAY, true); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(bool) {
+		t.Fatal("shouldn't have ended a do not disturb that has changed")
+	}
+
+	if result := <-store.Status().EndExpiredDND(status.UserId, now, model.STATUS_AWAY, true); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if !result.Data.(bool) {
+		t.Fatal("should have ended the do not disturb")
+	}
+
+	if result := <-store.Status().Get(status.UserId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if updated := result.Data.(*model.Status); updated.Status != model.STATUS_AWAY || !updated.Manual || updated.DNDEndTime != 0 || updated.PrevStatus != "" {
+		t.Fatal("should have returned to the previous status")
+	}
+
+	if result := <-store.Status().EndExpiredDND(status.UserId, now, model.STATUS_AWAY, true); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(bool) {
+		t.Fatal("shouldn't have ended a do not disturb twice")
+	}
+}
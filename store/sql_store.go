@@ -18,6 +18,7 @@ import (
 	"io"
 	sqltrace "log"
 	"os"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -65,28 +66,32 @@ const (
 )
 
 type SqlStore struct {
-	master        *gorp.DbMap
-	replicas      []*gorp.DbMap
-	team          TeamStore
-	channel       ChannelStore
-	post          PostStore
-	user          UserStore
-	audit         AuditStore
-	compliance    ComplianceStore
-	session       SessionStore
-	oauth         OAuthStore
-	system        SystemStore
-	webhook       WebhookStore
-	command       CommandStore
-	preference    PreferenceStore
-	license       LicenseStore
-	recovery      PasswordRecoveryStore
-	emoji         EmojiStore
-	status        StatusStore
-	fileInfo      FileInfoStore
-	reaction      ReactionStore
-	SchemaVersion string
-	rrCounter     int64
+	master          *gorp.DbMap
+	replicas        []*gorp.DbMap
+	team            TeamStore
+	channel         ChannelStore
+	post            PostStore
+	user            UserStore
+	audit           AuditStore
+	compliance      ComplianceStore
+	session         SessionStore
+	oauth           OAuthStore
+	system          SystemStore
+	webhook         WebhookStore
+	command         CommandStore
+	preference      PreferenceStore
+	license         LicenseStore
+	recovery        PasswordRecoveryStore
+	emoji           EmojiStore
+	status          StatusStore
+	fileInfo        FileInfoStore
+	reaction        ReactionStore
+	job             JobStore
+	userAccessToken UserAccessTokenStore
+	commandWebhook  CommandWebhookStore
+	scheduledPost   ScheduledPostStore
+	SchemaVersion   string
+	rrCounter       int64
 }
 
 func initConnection() *SqlStore {
@@ -136,6 +141,10 @@ func NewSqlStore() Store {
 	sqlStore.status = NewSqlStatusStore(sqlStore)
 	sqlStore.fileInfo = NewSqlFileInfoStore(sqlStore)
 	sqlStore.reaction = NewSqlReactionStore(sqlStore)
+	sqlStore.job = NewSqlJobStore(sqlStore)
+	sqlStore.userAccessToken = NewSqlUserAccessTokenStore(sqlStore)
+	sqlStore.commandWebhook = NewSqlCommandWebhookStore(sqlStore)
+	sqlStore.scheduledPost = NewSqlScheduledPostStore(sqlStore)
 
 	err := sqlStore.master.CreateTablesIfNotExists()
 	if err != nil {
@@ -164,6 +173,10 @@ func NewSqlStore() Store {
 	sqlStore.status.(*SqlStatusStore).CreateIndexesIfNotExists()
 	sqlStore.fileInfo.(*SqlFileInfoStore).CreateIndexesIfNotExists()
 	sqlStore.reaction.(*SqlReactionStore).CreateIndexesIfNotExists()
+	sqlStore.job.(*SqlJobStore).CreateIndexesIfNotExists()
+	sqlStore.userAccessToken.(*SqlUserAccessTokenStore).CreateIndexesIfNotExists()
+	sqlStore.commandWebhook.(*SqlCommandWebhookStore).CreateIndexesIfNotExists()
+	sqlStore.scheduledPost.(*SqlScheduledPostStore).CreateIndexesIfNotExists()
 
 	sqlStore.preference.(*SqlPreferenceStore).DeleteUnusedFeatures()
 
@@ -591,6 +604,23 @@ func IsUniqueConstraintError(err string, indexName []string) bool {
 	return unique && field
 }
 
+// buildInQuery adds a named parameter to props for each value and returns the comma separated
+// placeholders for use in an IN clause.
+func buildInQuery(prefix string, values []string, props map[string]interface{}) string {
+	query := ""
+
+	for index, value := range values {
+		if len(query) > 0 {
+			query += ", "
+		}
+
+		props[prefix+strconv.Itoa(index)] = value
+		query += ":" + prefix + strconv.Itoa(index)
+	}
+
+	return query
+}
+
 func (ss *SqlStore) GetMaster() *gorp.DbMap {
 	return ss.master
 }
@@ -687,6 +717,22 @@ func (ss *SqlStore) Reaction() ReactionStore {
 	return ss.reaction
 }
 
+func (ss *SqlStore) Job() JobStore {
+	return ss.job
+}
+
+func (ss *SqlStore) UserAccessToken() UserAccessTokenStore {
+	return ss.userAccessToken
+}
+
+func (ss *SqlStore) CommandWebhook() CommandWebhookStore {
+	return ss.commandWebhook
+}
+
+func (ss *SqlStore) ScheduledPost() ScheduledPostStore {
+	return ss.scheduledPost
+}
+
 func (ss *SqlStore) DropAllTables() {
 	ss.master.TruncateTables()
 }
@@ -704,6 +750,16 @@ func (me mattermConverter) ToDb(val interface{}) (interface{}, error) {
 		return encrypt([]byte(utils.Cfg.SqlSettings.AtRestEncryptKey), model.MapToJson(t))
 	case model.StringInterface:
 		return model.StringInterfaceToJson(t), nil
+	case *model.CommandAutocompleteData:
+		if t == nil {
+			return "", nil
+		}
+		return t.ToJson(), nil
+	case *model.CustomStatus:
+		if t == nil {
+			return "", nil
+		}
+		return t.ToJson(), nil
 	}
 
 	return val, nil
@@ -757,6 +813,34 @@ func (me mattermConverter) FromDb(target interface{}) (gorp.CustomScanner, bool)
 			return json.Unmarshal(b, target)
 		}
 		return gorp.CustomScanner{Holder: new(string), Target: target, Binder: binder}, true
+	case **model.CommandAutocompleteData:
+		binder := func(holder, target interface{}) error {
+			s, ok := holder.(*string)
+			if !ok {
+				return errors.New(utils.T("store.sql.convert_command_autocomplete_data"))
+			}
+			// commands without any autocomplete data are stored as an empty string
+			if *s == "" {
+				return nil
+			}
+			b := []byte(*s)
+			return json.Unmarshal(b, target)
+		}
+		return gorp.CustomScanner{Holder: new(string), Target: target, Binder: binder}, true
+	case **model.CustomStatus:
+		binder := func(holder, target interface{}) error {
+			s, ok := holder.(*string)
+			if !ok {
+				return errors.New(utils.T("store.sql.convert_custom_status"))
+			}
+			// users without a custom status are stored with an empty string
+			if *s == "" {
+				return nil
+			}
+			b := []byte(*s)
+			return json.Unmarshal(b, target)
+		}
+		return gorp.CustomScanner{Holder: new(string), Target: target, Binder: binder}, true
 	}
 
 	return gorp.CustomScanner{}, false
@@ -250,6 +250,61 @@ func UpgradeDatabaseToVersion38(sqlStore *SqlStore) {
 	// Add the IsPinned column to posts.
 	sqlStore.CreateColumnIfNotExists("Posts", "IsPinned", "boolean", "boolean", "0")
 
+	// Add the columns used by bot accounts and the integrations that post as them.
+	sqlStore.CreateColumnIfNotExists("Users", "IsBot", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("Users", "BotOwnerId", "varchar(26)", "varchar(26)", "")
+	sqlStore.CreateColumnIfNotExists("IncomingWebhooks", "BotUserId", "varchar(26)", "varchar(26)", "")
+	sqlStore.CreateColumnIfNotExists("OutgoingWebhooks", "BotUserId", "varchar(26)", "varchar(26)", "")
+	sqlStore.CreateColumnIfNotExists("Commands", "BotUserId", "varchar(26)", "varchar(26)", "")
+	sqlStore.CreateColumnIfNotExists("OAuthApps", "BotUserId", "varchar(26)", "varchar(26)", "")
+
+	// Add the secrets used to sign the requests sent to outgoing webhooks and slash commands, and give one to each
+	// existing integration so that all of their requests are signed.
+	sqlStore.CreateColumnIfNotExists("OutgoingWebhooks", "SigningSecret", "varchar(128)", "varchar(128)", "")
+	sqlStore.CreateColumnIfNotExists("Commands", "SigningSecret", "varchar(128)", "varchar(128)", "")
+	backfillSigningSecrets(sqlStore, "OutgoingWebhooks")
+	backfillSigningSecrets(sqlStore, "Commands")
+
+	// Add the columns that let outgoing webhooks be triggered from private channels and by events other than new posts.
+	sqlStore.CreateColumnIfNotExists("OutgoingWebhooks", "IncludePrivateChannels", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("OutgoingWebhooks", "Events", "varchar(1024)", "varchar(1024)", "")
+
+	// Add the columns that let incoming webhooks be locked to their channel and rate limited.
+	sqlStore.CreateColumnIfNotExists("IncomingWebhooks", "ChannelLocked", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("IncomingWebhooks", "RateLimit", "int", "integer", "0")
+
+	// Add the column holding the subcommands and arguments of slash commands.
+	sqlStore.CreateColumnIfNotExists("Commands", "AutocompleteData", "varchar(4000)", "varchar(4000)", "")
+
+	// Add the column holding the templates that let incoming webhooks accept payloads in other formats.
+	sqlStore.CreateColumnIfNotExists("IncomingWebhooks", "MessageTemplate", "varchar(4000)", "varchar(4000)", "")
+
+	// Add the columns that let users set do not disturb for a while.
+	sqlStore.CreateColumnIfNotExists("Status", "DNDEndTime", "bigint", "bigint", "0")
+	sqlStore.CreateColumnIfNotExists("Status", "PrevStatus", "varchar(32)", "varchar(32)", "")
+
+	// Add the columns holding users' custom statuses.
+	sqlStore.CreateColumnIfNotExists("Users", "CustomStatus", "varchar(1024)", "varchar(1024)", "")
+	sqlStore.CreateColumnIfNotExists("Users", "CustomStatusExpiresAt", "bigint", "bigint", "0")
+
 	// saveSchemaVersion(sqlStore, VERSION_3_8_0)
 	// }
 }
+
+// backfillSigningSecrets generates a signing secret for each integration in the given table that doesn't have one.
+// Each one needs its own random secret, so they can't be set with a single UPDATE.
+func backfillSigningSecrets(sqlStore *SqlStore, table string) {
+	var ids []string
+	if _, err := sqlStore.GetMaster().Select(&ids, "SELECT Id FROM "+table+" WHERE SigningSecret = ''"); err != nil {
+		l4g.Critical(utils.T("store.sql_upgrade.backfill_signing_secrets.critical"), table, err)
+		return
+	}
+
+	for _, id := range ids {
+		if _, err := sqlStore.GetMaster().Exec("UPDATE "+table+" SET SigningSecret = :SigningSecret WHERE Id = :Id AND SigningSecret = ''",
+			map[string]interface{}{"SigningSecret": model.NewSigningSecret(), "Id": id}); err != nil {
+			l4g.Critical(utils.T("store.sql_upgrade.backfill_signing_secrets.critical"), table, err)
+			return
+		}
+	}
+}
@@ -69,6 +69,8 @@ func NewSqlUserStore(sqlStore *SqlStore) UserStore {
 		table.ColMap("Locale").SetMaxSize(5)
 		table.ColMap("MfaSecret").SetMaxSize(128)
 		table.ColMap("Position").SetMaxSize(64)
+		table.ColMap("BotOwnerId").SetMaxSize(26)
+		table.ColMap("CustomStatus").SetMaxSize(model.CUSTOM_STATUS_MAX_LENGTH)
 	}
 
 	return us
@@ -79,6 +81,7 @@ func (us SqlUserStore) CreateIndexesIfNotExists() {
 	us.CreateIndexIfNotExists("idx_users_update_at", "Users", "UpdateAt")
 	us.CreateIndexIfNotExists("idx_users_create_at", "Users", "CreateAt")
 	us.CreateIndexIfNotExists("idx_users_delete_at", "Users", "DeleteAt")
+	us.CreateIndexIfNotExists("idx_users_custom_status_expires_at", "Users", "CustomStatusExpiresAt")
 
 	us.CreateFullTextIndexIfNotExists("idx_users_all_txt", "Users", USER_SEARCH_TYPE_ALL)
 	us.CreateFullTextIndexIfNotExists("idx_users_all_no_full_name_txt", "Users", USER_SEARCH_TYPE_ALL_NO_FULL_NAME)
@@ -107,6 +110,12 @@ func (us SqlUserStore) Save(user *model.User) StoreChannel {
 			return
 		}
 
+		// keep the column used to find expired custom statuses in sync with the custom status
+		user.CustomStatusExpiresAt = 0
+		if user.CustomStatus != nil {
+			user.CustomStatusExpiresAt = user.CustomStatus.ExpiresAt
+		}
+
 		if err := us.GetMaster().Insert(user); err != nil {
 			if IsUniqueConstraintError(err.Error(), []string{"Email", "users_email_key", "idx_users_email_unique"}) {
 				result.Err = model.NewAppError("SqlUserStore.Save", "store.sql_user.save.email_exists.app_error", nil, "user_id="+user.Id+", "+err.Error(), http.StatusBadRequest)
@@ -156,10 +165,14 @@ func (us SqlUserStore) Update(user *model.User, trustedUpdateData bool) StoreCha
 			user.FailedAttempts = oldUser.FailedAttempts
 			user.MfaSecret = oldUser.MfaSecret
 			user.MfaActive = oldUser.MfaActive
+			user.IsBot = oldUser.IsBot
+			user.CustomStatus = oldUser.CustomStatus
+			user.CustomStatusExpiresAt = oldUser.CustomStatusExpiresAt
 
 			if !trustedUpdateData {
 				user.Roles = oldUser.Roles
 				user.DeleteAt = oldUser.DeleteAt
+				user.BotOwnerId = oldUser.BotOwnerId
 			}
 
 			if user.IsOAuthUser() {
@@ -225,6 +238,80 @@ func (us SqlUserStore) UpdateLastPictureUpdate(userId string) StoreChannel {
 	return storeChannel
 }
 
+// UpdateCustomStatus sets or, if it's nil, clears a user's custom status.
+func (us SqlUserStore) UpdateCustomStatus(userId string, customStatus *model.CustomStatus) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		value := ""
+		expiresAt := int64(0)
+		if customStatus != nil {
+			value = customStatus.ToJson()
+			expiresAt = customStatus.ExpiresAt
+		}
+
+		if _, err := us.GetMaster().Exec("UPDATE Users SET CustomStatus = :CustomStatus, CustomStatusExpiresAt = :ExpiresAt, UpdateAt = :Time WHERE Id = :UserId",
+			map[string]interface{}{"CustomStatus": value, "ExpiresAt": expiresAt, "Time": model.GetMillis(), "UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlUserStore.UpdateCustomStatus", "store.sql_user.update_custom_status.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			result.Data = userId
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// ClearExpiredCustomStatus clears a user's custom status, but only if it's still the one that expires at the given
+// time and that time has passed, so that a custom status that the user changed in the meantime is left alone. It
+// returns true if the custom status was cleared.
+func (us SqlUserStore) ClearExpiredCustomStatus(userId string, expiresAt int64, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := us.GetMaster().Exec("UPDATE Users SET CustomStatus = '', CustomStatusExpiresAt = 0, UpdateAt = :UpdateAt WHERE Id = :UserId AND CustomStatusExpiresAt = :ExpiresAt AND CustomStatusExpiresAt <= :Time",
+			map[string]interface{}{"UpdateAt": model.GetMillis(), "UserId": userId, "ExpiresAt": expiresAt, "Time": time}); err != nil {
+			result.Err = model.NewLocAppError("SqlUserStore.ClearExpiredCustomStatus", "store.sql_user.clear_expired_custom_status.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			rows, _ := sqlResult.RowsAffected()
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetWithExpiredCustomStatus gets the users whose custom status expired at or before the given time. It reads from
+// the master so that custom statuses that were just changed aren't seen as expired.
+func (us SqlUserStore) GetWithExpiredCustomStatus(time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var users []*model.User
+		if _, err := us.GetMaster().Select(&users, "SELECT * FROM Users WHERE CustomStatusExpiresAt > 0 AND CustomStatusExpiresAt <= :Time", map[string]interface{}{"Time": time}); err != nil {
+			result.Err = model.NewLocAppError("SqlUserStore.GetWithExpiredCustomStatus", "store.sql_user.get_with_expired_custom_status.app_error", nil, err.Error())
+		} else {
+			result.Data = users
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (us SqlUserStore) UpdateUpdateAt(userId string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -527,6 +614,46 @@ func (us SqlUserStore) GetAllProfiles(offset int, limit int) StoreChannel {
 	return storeChannel
 }
 
+// GetBots returns a page of bot accounts sorted by username. If ownerId is set, only the bots owned by that user are
+// returned.
+func (us SqlUserStore) GetBots(ownerId string, includeDeleted bool, offset int, limit int) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		query := "SELECT * FROM Users WHERE IsBot = true"
+		if ownerId != "" {
+			query += " AND BotOwnerId = :OwnerId"
+		}
+		if !includeDeleted {
+			query += " AND DeleteAt = 0"
+		}
+		query += " ORDER BY Username ASC LIMIT :Limit OFFSET :Offset"
+
+		var users []*model.User
+
+		if _, err := us.GetReplica().Select(&users, query, map[string]interface{}{"OwnerId": ownerId, "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlUserStore.GetBots", "store.sql_user.get_bots.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+
+			for _, u := range users {
+				u.Password = ""
+				u.AuthData = new(string)
+				*u.AuthData = ""
+			}
+
+			result.Data = users
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlUserStore) GetEtagForProfiles(teamId string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -1129,9 +1256,9 @@ func (us SqlUserStore) AnalyticsUniqueUserCount(teamId string) StoreChannel {
 
 		query := ""
 		if len(teamId) > 0 {
-			query = "SELECT COUNT(DISTINCT Users.Email) From Users, TeamMembers WHERE TeamMembers.TeamId = :TeamId AND Users.Id = TeamMembers.UserId AND TeamMembers.DeleteAt = 0 AND Users.DeleteAt = 0"
+			query = "SELECT COUNT(DISTINCT Users.Email) From Users, TeamMembers WHERE TeamMembers.TeamId = :TeamId AND Users.Id = TeamMembers.UserId AND TeamMembers.DeleteAt = 0 AND Users.DeleteAt = 0 AND Users.IsBot = false"
 		} else {
-			query = "SELECT COUNT(DISTINCT Email) FROM Users WHERE DeleteAt = 0"
+			query = "SELECT COUNT(DISTINCT Email) FROM Users WHERE DeleteAt = 0 AND IsBot = false"
 		}
 
 		v, err := us.GetReplica().SelectInt(query, map[string]interface{}{"TeamId": teamId})
@@ -156,6 +156,112 @@ func TestUserStoreUpdateUpdateAt(t *testing.T) {
 
 }
 
+func TestUserStoreUpdateCustomStatus(t *testing.T) {
+	Setup()
+
+	u1 := &model.User{}
+	u1.Email = model.NewId()
+	Must(store.User().Save(u1))
+	Must(store.Team().SaveMember(&model.TeamMember{TeamId: model.NewId(), UserId: u1.Id}))
+
+	u2 := &model.User{}
+	u2.Email = model.NewId()
+	Must(store.User().Save(u2))
+	Must(store.Team().SaveMember(&model.TeamMember{TeamId: model.NewId(), UserId: u2.Id}))
+
+	now := model.GetMillis()
+
+	if err := (<-store.User().UpdateCustomStatus(u1.Id, &model.CustomStatus{Emoji: "calendar", Text: "In a meeting", ExpiresAt: now})).Err; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (<-store.User().UpdateCustomStatus(u2.Id, &model.CustomStatus{Text: "Working from home"})).Err; err != nil {
+		t.Fatal(err)
+	}
+
+	if r1 := <-store.User().Get(u1.Id); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if customStatus := r1.Data.(*model.User).CustomStatus; customStatus == nil || customStatus.Emoji != "calendar" || customStatus.Text != "In a meeting" {
+		t.Fatal("custom status not updated correctly")
+	}
+
+	if r2 := <-store.User().GetWithExpiredCustomStatus(now - 1); r2.Err != nil {
+		t.Fatal(r2.Err)
+	} else {
+		for _, user := range r2.Data.([]*model.User) {
+			if user.Id == u1.Id || user.Id == u2.Id {
+				t.Fatal("shouldn't have returned a user whose custom status hasn't expired")
+			}
+		}
+	}
+
+	if r3 := <-store.User().GetWithExpiredCustomStatus(now); r3.Err != nil {
+		t.Fatal(r3.Err)
+	} else {
+		found := false
+		for _, user := range r3.Data.([]*model.User) {
+			if user.Id == u1.Id {
+				found = true
+			} else if user.Id == u2.Id {
+				t.Fatal("shouldn't have returned a user whose custom status doesn't expire")
+			}
+		}
+
+		if !found {
+			t.Fatal("should have returned the user whose custom status expired")
+		}
+	}
+
+	if err := (<-store.User().UpdateCustomStatus(u1.Id, nil)).Err; err != nil {
+		t.Fatal(err)
+	}
+
+	if r4 := <-store.User().Get(u1.Id); r4.Err != nil {
+		t.Fatal(r4.Err)
+	} else if r4.Data.(*model.User).CustomStatus != nil {
+		t.Fatal("custom status not cleared")
+	}
+}
+
+func TestUserStoreClearExpiredCustomStatus(t *testing.T) {
+	Setup()
+
+	now := model.GetMillis()
+
+	u1 := &model.User{}
+	u1.Email = model.NewId()
+	u1.CustomStatus = &model.CustomStatus{Text: "In a meeting", ExpiresAt: now}
+	Must(store.User().Save(u1))
+
+	if u1.CustomStatusExpiresAt != now {
+		t.Fatal("should have copied when the custom status expires")
+	}
+
+	if r1 := <-store.User().ClearExpiredCustomStatus(u1.Id, now, now-1); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if r1.Data.(bool) {
+		t.Fatal("shouldn't have cleared a custom status that hasn't expired")
+	}
+
+	if r2 := <-store.User().ClearExpiredCustomStatus(u1.Id, now-1, now); r2.Err != nil {
+		t.Fatal(r2.Err)
+	} else if r2.Data.(bool) {
+		t.Fatal("shouldn't have cleared a custom status that has changed")
+	}
+
+	if r3 := <-store.User().ClearExpiredCustomStatus(u1.Id, now, now); r3.Err != nil {
+		t.Fatal(r3.Err)
+	} else if !r3.Data.(bool) {
+		t.Fatal("should have cleared the expired custom status")
+	}
+
+	if r4 := <-store.User().Get(u1.Id); r4.Err != nil {
+		t.Fatal(r4.Err)
+	} else if user := r4.Data.(*model.User); user.CustomStatus != nil || user.CustomStatusExpiresAt != 0 {
+		t.Fatal("custom status not cleared")
+	}
+}
+
 func TestUserStoreUpdateFailedPasswordAttempts(t *testing.T) {
 	Setup()
 
@@ -251,6 +357,57 @@ func TestUserStoreGetAllProfiles(t *testing.T) {
 	}
 }
 
+func TestUserStoreGetBots(t *testing.T) {
+	Setup()
+
+	ownerId := model.NewId()
+
+	u1 := &model.User{}
+	u1.Email = model.NewId()
+	Must(store.User().Save(u1))
+
+	b1 := &model.User{IsBot: true, BotOwnerId: ownerId}
+	b1.Email = model.NewId()
+	Must(store.User().Save(b1))
+
+	b2 := &model.User{IsBot: true, BotOwnerId: model.NewId()}
+	b2.Email = model.NewId()
+	Must(store.User().Save(b2))
+
+	b3 := &model.User{IsBot: true, BotOwnerId: ownerId, DeleteAt: model.GetMillis()}
+	b3.Email = model.NewId()
+	Must(store.User().Save(b3))
+
+	if r1 := <-store.User().GetBots("", true, 0, 1000); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else {
+		users := r1.Data.([]*model.User)
+		found := map[string]bool{}
+		for _, u := range users {
+			if !u.IsBot {
+				t.Fatal("should only return bots")
+			}
+			found[u.Id] = true
+		}
+
+		if !found[b1.Id] || !found[b2.Id] || !found[b3.Id] {
+			t.Fatal("should have returned every bot")
+		}
+	}
+
+	if r2 := <-store.User().GetBots(ownerId, false, 0, 100); r2.Err != nil {
+		t.Fatal(r2.Err)
+	} else if users := r2.Data.([]*model.User); len(users) != 1 || users[0].Id != b1.Id {
+		t.Fatal("should only return the active bot for the owner")
+	}
+
+	if r3 := <-store.User().GetBots(ownerId, true, 0, 100); r3.Err != nil {
+		t.Fatal(r3.Err)
+	} else if users := r3.Data.([]*model.User); len(users) != 2 {
+		t.Fatal("should return the deleted bot for the owner too")
+	}
+}
+
 func TestUserStoreGetProfiles(t *testing.T) {
 	Setup()
 
@@ -39,6 +39,8 @@ func NewSqlWebhookStore(sqlStore *SqlStore) WebhookStore {
 		table.ColMap("TeamId").SetMaxSize(26)
 		table.ColMap("DisplayName").SetMaxSize(64)
 		table.ColMap("Description").SetMaxSize(128)
+		table.ColMap("BotUserId").SetMaxSize(26)
+		table.ColMap("MessageTemplate").SetMaxSize(model.INCOMING_WEBHOOK_MESSAGE_TEMPLATE_MAX_LENGTH)
 
 		tableo := db.AddTableWithName(model.OutgoingWebhook{}, "OutgoingWebhooks").SetKeys(false, "Id")
 		tableo.ColMap("Id").SetMaxSize(26)
@@ -52,6 +54,20 @@ func NewSqlWebhookStore(sqlStore *SqlStore) WebhookStore {
 		tableo.ColMap("Description").SetMaxSize(128)
 		tableo.ColMap("ContentType").SetMaxSize(128)
 		tableo.ColMap("TriggerWhen").SetMaxSize(1)
+		tableo.ColMap("BotUserId").SetMaxSize(26)
+		tableo.ColMap("SigningSecret").SetMaxSize(128)
+		tableo.ColMap("Events").SetMaxSize(1024)
+
+		tabled := db.AddTableWithName(model.OutgoingWebhookDelivery{}, "OutgoingWebhookDeliveries").SetKeys(false, "Id")
+		tabled.ColMap("Id").SetMaxSize(26)
+		tabled.ColMap("HookId").SetMaxSize(26)
+		tabled.ColMap("ChannelId").SetMaxSize(26)
+		tabled.ColMap("PostId").SetMaxSize(26)
+		tabled.ColMap("URL").SetMaxSize(1024)
+		tabled.ColMap("ContentType").SetMaxSize(128)
+		tabled.ColMap("Payload").SetMaxSize(model.OUTGOING_WEBHOOK_DELIVERY_PAYLOAD_MAX_LENGTH)
+		tabled.ColMap("Status").SetMaxSize(32)
+		tabled.ColMap("Error").SetMaxSize(model.OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH)
 	}
 
 	return s
@@ -69,6 +85,10 @@ func (s SqlWebhookStore) CreateIndexesIfNotExists() {
 	s.CreateIndexIfNotExists("idx_outgoing_webhook_update_at", "OutgoingWebhooks", "UpdateAt")
 	s.CreateIndexIfNotExists("idx_outgoing_webhook_create_at", "OutgoingWebhooks", "CreateAt")
 	s.CreateIndexIfNotExists("idx_outgoing_webhook_delete_at", "OutgoingWebhooks", "DeleteAt")
+
+	s.CreateIndexIfNotExists("idx_outgoing_webhook_deliveries_hook_id", "OutgoingWebhookDeliveries", "HookId")
+	s.CreateIndexIfNotExists("idx_outgoing_webhook_deliveries_create_at", "OutgoingWebhookDeliveries", "CreateAt")
+	s.CreateIndexIfNotExists("idx_outgoing_webhook_deliveries_update_at", "OutgoingWebhookDeliveries", "UpdateAt")
 }
 
 func (s SqlWebhookStore) InvalidateWebhookCache(webhookId string) {
@@ -406,6 +426,39 @@ func (s SqlWebhookStore) GetOutgoingByTeam(teamId string, offset, limit int) Sto
 	return storeChannel
 }
 
+// GetOutgoingForDirectChannel gets the hooks that can be triggered from a direct or group message channel, which
+// don't belong to a team. These are the hooks that include private channels and aren't limited to one channel, and
+// that were created by a member of the channel.
+func (s SqlWebhookStore) GetOutgoingForDirectChannel(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var webhooks []*model.OutgoingWebhook
+
+		if _, err := s.GetReplica().Select(&webhooks,
+			`SELECT
+				*
+			FROM
+				OutgoingWebhooks
+			WHERE
+				IncludePrivateChannels = true
+				AND ChannelId = ''
+				AND DeleteAt = 0
+				AND CreatorId IN (SELECT UserId FROM ChannelMembers WHERE ChannelId = :ChannelId)`, map[string]interface{}{"ChannelId": channelId}); err != nil {
+			result.Err = model.NewLocAppError("SqlWebhookStore.GetOutgoingForDirectChannel", "store.sql_webhooks.get_outgoing_for_direct_channel.app_error", nil, "channelId="+channelId+", err="+err.Error())
+		}
+
+		result.Data = webhooks
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlWebhookStore) DeleteOutgoing(webhookId string, time int64) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -463,6 +516,175 @@ func (s SqlWebhookStore) UpdateOutgoing(hook *model.OutgoingWebhook) StoreChanne
 	return storeChannel
 }
 
+func (s SqlWebhookStore) SaveOutgoingDelivery(delivery *model.OutgoingWebhookDelivery) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		delivery.PreSave()
+
+		if result.Err = delivery.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(delivery); err != nil {
+			result.Err = model.NewAppError("SqlWebhookStore.SaveOutgoingDelivery", "store.sql_webhooks.save_outgoing_delivery.app_error", nil, "hook_id="+delivery.HookId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = delivery
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlWebhookStore) UpdateOutgoingDelivery(delivery *model.OutgoingWebhookDelivery) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		delivery.PreUpdate()
+
+		if result.Err = delivery.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := s.GetMaster().Update(delivery); err != nil {
+			result.Err = model.NewAppError("SqlWebhookStore.UpdateOutgoingDelivery", "store.sql_webhooks.update_outgoing_delivery.app_error", nil, "id="+delivery.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = delivery
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlWebhookStore) GetOutgoingDelivery(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var delivery model.OutgoingWebhookDelivery
+
+		if err := s.GetReplica().SelectOne(&delivery, "SELECT * FROM OutgoingWebhookDeliveries WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlWebhookStore.GetOutgoingDelivery", "store.sql_webhooks.get_outgoing_delivery.app_error", nil, "id="+id+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlWebhookStore.GetOutgoingDelivery", "store.sql_webhooks.get_outgoing_delivery.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &delivery
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlWebhookStore) GetOutgoingDeliveriesForHook(hookId string, offset, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var deliveries []*model.OutgoingWebhookDelivery
+
+		if _, err := s.GetReplica().Select(&deliveries, "SELECT * FROM OutgoingWebhookDeliveries WHERE HookId = :HookId ORDER BY CreateAt DESC LIMIT :Limit OFFSET :Offset", map[string]interface{}{"HookId": hookId, "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlWebhookStore.GetOutgoingDeliveriesForHook", "store.sql_webhooks.get_outgoing_deliveries.app_error", nil, "hook_id="+hookId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = deliveries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetStaleOutgoingDeliveries gets the pending deliveries that haven't been updated since the given time, oldest first.
+func (s SqlWebhookStore) GetStaleOutgoingDeliveries(before int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var deliveries []*model.OutgoingWebhookDelivery
+
+		if _, err := s.GetMaster().Select(&deliveries, "SELECT * FROM OutgoingWebhookDeliveries WHERE Status = :Status AND UpdateAt < :Before ORDER BY UpdateAt ASC LIMIT :Limit",
+			map[string]interface{}{"Status": model.OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING, "Before": before, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlWebhookStore.GetStaleOutgoingDeliveries", "store.sql_webhooks.get_stale_outgoing_deliveries.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = deliveries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// ClaimOutgoingDelivery takes responsibility for a stale pending delivery by updating it, but only if it hasn't been
+// updated since it was read, so that when several servers find the same delivery, exactly one of them gets true back.
+func (s SqlWebhookStore) ClaimOutgoingDelivery(delivery *model.OutgoingWebhookDelivery) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		updateAt := model.GetMillis()
+
+		if sqlResult, err := s.GetMaster().Exec("UPDATE OutgoingWebhookDeliveries SET UpdateAt = :NewUpdateAt WHERE Id = :Id AND Status = :Status AND UpdateAt = :UpdateAt",
+			map[string]interface{}{"NewUpdateAt": updateAt, "Id": delivery.Id, "Status": model.OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING, "UpdateAt": delivery.UpdateAt}); err != nil {
+			result.Err = model.NewAppError("SqlWebhookStore.ClaimOutgoingDelivery", "store.sql_webhooks.claim_outgoing_delivery.app_error", nil, "id="+delivery.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, _ := sqlResult.RowsAffected(); rows == 1 {
+			delivery.UpdateAt = updateAt
+			result.Data = true
+		} else {
+			result.Data = false
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlWebhookStore) PermanentDeleteOutgoingDeliveriesBefore(time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("DELETE FROM OutgoingWebhookDeliveries WHERE CreateAt < :Time", map[string]interface{}{"Time": time}); err != nil {
+			result.Err = model.NewAppError("SqlWebhookStore.PermanentDeleteOutgoingDeliveriesBefore", "store.sql_webhooks.permanent_delete_outgoing_deliveries.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data, _ = sqlResult.RowsAffected()
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlWebhookStore) AnalyticsIncomingCount(teamId string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -5,6 +5,7 @@ package store
 
 import (
 	"testing"
+	"time"
 
 	"net/http"
 
@@ -349,6 +350,45 @@ func TestWebhookStoreGetOutgoingByTeam(t *testing.T) {
 	}
 }
 
+func TestWebhookStoreGetOutgoingForDirectChannel(t *testing.T) {
+	Setup()
+
+	channelId := model.NewId()
+	member := &model.ChannelMember{ChannelId: channelId, UserId: model.NewId(), NotifyProps: model.GetDefaultChannelNotifyProps()}
+	Must(store.Channel().SaveMember(member))
+
+	o1 := &model.OutgoingWebhook{}
+	o1.CreatorId = member.UserId
+	o1.TeamId = model.NewId()
+	o1.TriggerWords = []string{"trigger"}
+	o1.CallbackURLs = []string{"http://nowhere.com/"}
+	o1.IncludePrivateChannels = true
+	o1 = (<-store.Webhook().SaveOutgoing(o1)).Data.(*model.OutgoingWebhook)
+
+	// doesn't include private channels
+	o2 := &model.OutgoingWebhook{}
+	o2.CreatorId = member.UserId
+	o2.TeamId = model.NewId()
+	o2.TriggerWords = []string{"trigger"}
+	o2.CallbackURLs = []string{"http://nowhere.com/"}
+	Must(store.Webhook().SaveOutgoing(o2))
+
+	// created by someone who isn't a member
+	o3 := &model.OutgoingWebhook{}
+	o3.CreatorId = model.NewId()
+	o3.TeamId = model.NewId()
+	o3.TriggerWords = []string{"trigger"}
+	o3.CallbackURLs = []string{"http://nowhere.com/"}
+	o3.IncludePrivateChannels = true
+	Must(store.Webhook().SaveOutgoing(o3))
+
+	if result := <-store.Webhook().GetOutgoingForDirectChannel(channelId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if hooks := result.Data.([]*model.OutgoingWebhook); len(hooks) != 1 || hooks[0].Id != o1.Id {
+		t.Fatal("should've only returned the hook created by the channel member")
+	}
+}
+
 func TestWebhookStoreDeleteOutgoing(t *testing.T) {
 	Setup()
 
@@ -463,3 +503,111 @@ func TestWebhookStoreCountOutgoing(t *testing.T) {
 		}
 	}
 }
+
+func TestWebhookStoreOutgoingDeliveries(t *testing.T) {
+	Setup()
+
+	hookId := model.NewId()
+
+	d1 := &model.OutgoingWebhookDelivery{
+		HookId:      hookId,
+		ChannelId:   model.NewId(),
+		URL:         "http://nowhere.com/",
+		ContentType: "application/json",
+		Payload:     "{}",
+	}
+	if r := <-store.Webhook().SaveOutgoingDelivery(d1); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	d2 := &model.OutgoingWebhookDelivery{HookId: hookId, ChannelId: d1.ChannelId, URL: d1.URL}
+	Must(store.Webhook().SaveOutgoingDelivery(d2))
+
+	d1.Status = model.OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS
+	d1.StatusCode = 200
+	d1.Attempts = 1
+	if r := <-store.Webhook().UpdateOutgoingDelivery(d1); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	if r := <-store.Webhook().GetOutgoingDelivery(d1.Id); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if received := r.Data.(*model.OutgoingWebhookDelivery); received.Status != model.OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS || received.StatusCode != 200 {
+		t.Fatal("delivery wasn't updated")
+	}
+
+	if r := <-store.Webhook().GetOutgoingDelivery(model.NewId()); r.Err == nil || r.Err.StatusCode != 404 {
+		t.Fatal("should have failed to find a missing delivery")
+	}
+
+	if r := <-store.Webhook().GetOutgoingDeliveriesForHook(hookId, 0, 10); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if received := r.Data.([]*model.OutgoingWebhookDelivery); len(received) != 2 || received[0].Id != d2.Id {
+		t.Fatal("should've returned the newest delivery first")
+	}
+
+	if r := <-store.Webhook().PermanentDeleteOutgoingDeliveriesBefore(d2.CreateAt); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	if r := <-store.Webhook().GetOutgoingDeliveriesForHook(hookId, 0, 10); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if received := r.Data.([]*model.OutgoingWebhookDelivery); len(received) != 1 || received[0].Id != d2.Id {
+		t.Fatal("should've deleted the older delivery")
+	}
+}
+
+func TestWebhookStoreStaleOutgoingDeliveries(t *testing.T) {
+	Setup()
+
+	d1 := &model.OutgoingWebhookDelivery{HookId: model.NewId(), ChannelId: model.NewId(), URL: "http://nowhere.com/"}
+	Must(store.Webhook().SaveOutgoingDelivery(d1))
+
+	d2 := &model.OutgoingWebhookDelivery{HookId: d1.HookId, ChannelId: d1.ChannelId, URL: d1.URL, Status: model.OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS}
+	Must(store.Webhook().SaveOutgoingDelivery(d2))
+
+	if r := <-store.Webhook().GetStaleOutgoingDeliveries(d1.UpdateAt, 1000); r.Err != nil {
+		t.Fatal(r.Err)
+	} else {
+		for _, delivery := range r.Data.([]*model.OutgoingWebhookDelivery) {
+			if delivery.Id == d1.Id || delivery.Id == d2.Id {
+				t.Fatal("shouldn't have returned a delivery that was updated since")
+			}
+		}
+	}
+
+	if r := <-store.Webhook().GetStaleOutgoingDeliveries(d2.UpdateAt+1, 1000); r.Err != nil {
+		t.Fatal(r.Err)
+	} else {
+		found := false
+		for _, delivery := range r.Data.([]*model.OutgoingWebhookDelivery) {
+			if delivery.Id == d1.Id {
+				found = true
+			} else if delivery.Id == d2.Id {
+				t.Fatal("shouldn't have returned a delivery that isn't pending")
+			}
+		}
+
+		if !found {
+			t.Fatal("should have returned the stale pending delivery")
+		}
+	}
+
+	stale := *d1
+
+	time.Sleep(10 * time.Millisecond)
+
+	if r := <-store.Webhook().ClaimOutgoingDelivery(d1); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if !r.Data.(bool) {
+		t.Fatal("should have claimed the delivery")
+	}
+
+	if r := <-store.Webhook().ClaimOutgoingDelivery(&stale); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if r.Data.(bool) {
+		t.Fatal("shouldn't have claimed a delivery that was already claimed")
+	}
+}
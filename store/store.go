@@ -47,6 +47,10 @@ type Store interface {
 	Status() StatusStore
 	FileInfo() FileInfoStore
 	Reaction() ReactionStore
+	Job() JobStore
+	UserAccessToken() UserAccessTokenStore
+	CommandWebhook() CommandWebhookStore
+	ScheduledPost() ScheduledPostStore
 	MarkSystemRanUnitTests()
 	Close()
 	DropAllTables()
@@ -105,6 +109,7 @@ type ChannelStore interface {
 	GetPublicChannelsForTeam(teamId string, offset int, limit int) StoreChannel
 	GetChannelCounts(teamId string, userId string) StoreChannel
 	GetTeamChannels(teamId string) StoreChannel
+	GetDirectChannelsForExport(afterId string, limit int) StoreChannel
 	GetAll(teamId string) StoreChannel
 	GetForPost(postId string) StoreChannel
 	SaveMember(member *model.ChannelMember) StoreChannel
@@ -144,6 +149,10 @@ type PostStore interface {
 	Delete(postId string, time int64) StoreChannel
 	PermanentDeleteByUser(userId string) StoreChannel
 	PermanentDeleteByChannel(channelId string) StoreChannel
+	PermanentDeleteByIds(postIds []string) StoreChannel
+	GetPostsForDataRetention(policy *model.DataRetentionPolicy, endTime int64, limit int) StoreChannel
+	GetPostsForExport(channelId string, afterCreateAt int64, afterId string, limit int) StoreChannel
+	GetRepliesForExport(rootId string) StoreChannel
 	GetPosts(channelId string, offset int, limit int, allowFromCache bool) StoreChannel
 	GetFlaggedPosts(userId string, offset int, limit int) StoreChannel
 	GetPostsBefore(channelId string, postId string, numPosts int, offset int) StoreChannel
@@ -163,6 +172,9 @@ type UserStore interface {
 	Save(user *model.User) StoreChannel
 	Update(user *model.User, allowRoleUpdate bool) StoreChannel
 	UpdateLastPictureUpdate(userId string) StoreChannel
+	UpdateCustomStatus(userId string, customStatus *model.CustomStatus) StoreChannel
+	ClearExpiredCustomStatus(userId string, expiresAt int64, time int64) StoreChannel
+	GetWithExpiredCustomStatus(time int64) StoreChannel
 	UpdateUpdateAt(userId string) StoreChannel
 	UpdatePassword(userId, newPassword string) StoreChannel
 	UpdateAuthData(userId string, service string, authData *string, email string, resetMfa bool) StoreChannel
@@ -177,6 +189,7 @@ type UserStore interface {
 	GetProfilesNotInChannel(teamId string, channelId string, offset int, limit int) StoreChannel
 	GetProfilesByUsernames(usernames []string, teamId string) StoreChannel
 	GetAllProfiles(offset int, limit int) StoreChannel
+	GetBots(ownerId string, includeDeleted bool, offset int, limit int) StoreChannel
 	GetProfiles(teamId string, offset int, limit int) StoreChannel
 	GetProfileByIds(userId []string, allowFromCache bool) StoreChannel
 	InvalidatProfileCacheForUser(userId string)
@@ -276,10 +289,19 @@ type WebhookStore interface {
 	GetOutgoingList(offset, limit int) StoreChannel
 	GetOutgoingByChannel(channelId string, offset, limit int) StoreChannel
 	GetOutgoingByTeam(teamId string, offset, limit int) StoreChannel
+	GetOutgoingForDirectChannel(channelId string) StoreChannel
 	DeleteOutgoing(webhookId string, time int64) StoreChannel
 	PermanentDeleteOutgoingByUser(userId string) StoreChannel
 	UpdateOutgoing(hook *model.OutgoingWebhook) StoreChannel
 
+	SaveOutgoingDelivery(delivery *model.OutgoingWebhookDelivery) StoreChannel
+	UpdateOutgoingDelivery(delivery *model.OutgoingWebhookDelivery) StoreChannel
+	GetOutgoingDelivery(id string) StoreChannel
+	GetOutgoingDeliveriesForHook(hookId string, offset, limit int) StoreChannel
+	GetStaleOutgoingDeliveries(before int64, limit int) StoreChannel
+	ClaimOutgoingDelivery(delivery *model.OutgoingWebhookDelivery) StoreChannel
+	PermanentDeleteOutgoingDeliveriesBefore(time int64) StoreChannel
+
 	AnalyticsIncomingCount(teamId string) StoreChannel
 	AnalyticsOutgoingCount(teamId string) StoreChannel
 	InvalidateWebhookCache(webhook string)
@@ -300,6 +322,7 @@ type PreferenceStore interface {
 	Get(userId string, category string, name string) StoreChannel
 	GetCategory(userId string, category string) StoreChannel
 	GetAll(userId string) StoreChannel
+	GetCategoryAndName(category string, name string) StoreChannel
 	Delete(userId, category, name string) StoreChannel
 	DeleteCategory(userId string, category string) StoreChannel
 	DeleteCategoryAndName(category string, name string) StoreChannel
@@ -337,6 +360,8 @@ type StatusStore interface {
 	ResetAll() StoreChannel
 	GetTotalActiveUsersCount() StoreChannel
 	UpdateLastActivityAt(userId string, lastActivityAt int64) StoreChannel
+	GetExpiredDND(time int64) StoreChannel
+	EndExpiredDND(userId string, dndEndTime int64, status string, manual bool) StoreChannel
 }
 
 type FileInfoStore interface {
@@ -347,6 +372,7 @@ type FileInfoStore interface {
 	InvalidateFileInfosForPostCache(postId string)
 	AttachToPost(fileId string, postId string) StoreChannel
 	DeleteForPost(postId string) StoreChannel
+	PermanentDeleteForPosts(postIds []string) StoreChannel
 }
 
 type ReactionStore interface {
@@ -356,4 +382,49 @@ type ReactionStore interface {
 	InvalidateCache()
 	GetForPost(postId string, allowFromCache bool) StoreChannel
 	DeleteAllWithEmojiName(emojiName string) StoreChannel
+	PermanentDeleteForPosts(postIds []string) StoreChannel
+}
+
+type JobStore interface {
+	Save(job *model.Job) StoreChannel
+	SaveIfNoneActive(job *model.Job, createdAfter int64) StoreChannel
+	UpdateOptimistically(job *model.Job, currentStatus string) StoreChannel
+	UpdateStatus(id string, status string) StoreChannel
+	UpdateStatusOptimistically(id string, currentStatus string, newStatus string) StoreChannel
+	UpdateLastActivityAt(id string) StoreChannel
+	Get(id string) StoreChannel
+	GetAllPage(offset int, limit int) StoreChannel
+	GetAllByTypePage(jobType string, offset int, limit int) StoreChannel
+	GetAllByStatus(status string) StoreChannel
+	GetNewestJobByType(jobType string) StoreChannel
+	Delete(id string) StoreChannel
+	PermanentDeleteFinishedByTypeBefore(jobType string, before int64) StoreChannel
+}
+
+type UserAccessTokenStore interface {
+	Save(token *model.UserAccessToken) StoreChannel
+	Delete(tokenId string) StoreChannel
+	DeleteAllForUser(userId string) StoreChannel
+	Get(tokenId string) StoreChannel
+	GetAll(offset int, limit int) StoreChannel
+	GetByToken(tokenString string) StoreChannel
+	GetByUser(userId string, offset int, limit int) StoreChannel
+}
+
+type CommandWebhookStore interface {
+	Save(webhook *model.CommandWebhook) StoreChannel
+	Get(id string) StoreChannel
+	TryUse(id string, limit int) StoreChannel
+	Cleanup() StoreChannel
+}
+
+type ScheduledPostStore interface {
+	Save(scheduledPost *model.ScheduledPost) StoreChannel
+	Get(id string) StoreChannel
+	GetForUser(userId string, offset, limit int) StoreChannel
+	GetDue(time int64, limit int) StoreChannel
+	Update(scheduledPost *model.ScheduledPost) StoreChannel
+	Claim(scheduledPost *model.ScheduledPost, nextScheduledAt int64) StoreChannel
+	Delete(id string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
 }
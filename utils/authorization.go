@@ -150,6 +150,8 @@ func SetDefaultRolesBasedOnConfig() {
 		model.ROLE_SYSTEM_USER.Permissions = append(
 			model.ROLE_SYSTEM_USER.Permissions,
 			model.PERMISSION_MANAGE_OAUTH.Id,
+			model.PERMISSION_CREATE_BOT.Id,
+			model.PERMISSION_MANAGE_BOTS.Id,
 		)
 	}
 
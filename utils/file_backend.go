@@ -0,0 +1,38 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"io"
+
+	"github.com/mattermost/platform/model"
+)
+
+// FileBackend is implemented by each of the supported file storage drivers. All paths are
+// relative to the root of the configured storage location.
+type FileBackend interface {
+	TestConnection() *model.AppError
+
+	Reader(path string) (io.ReadCloser, *model.AppError)
+	ReadFile(path string) ([]byte, *model.AppError)
+	WriteFile(fr io.Reader, path string) (int64, *model.AppError)
+	MoveFile(oldPath, newPath string) *model.AppError
+	RemoveFile(path string) *model.AppError
+
+	ListDirectory(path string) (*[]string, *model.AppError)
+	RemoveDirectory(path string) *model.AppError
+}
+
+func NewFileBackend(settings *model.FileSettings) (FileBackend, *model.AppError) {
+	switch settings.DriverName {
+	case model.IMAGE_DRIVER_S3:
+		return NewS3FileBackend(settings)
+	case model.IMAGE_DRIVER_LOCAL:
+		return &LocalFileBackend{
+			directory: settings.Directory,
+		}, nil
+	}
+
+	return nil, model.NewLocAppError("NewFileBackend", "api.file.no_driver.app_error", nil, "driver="+settings.DriverName)
+}
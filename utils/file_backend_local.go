@@ -0,0 +1,110 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	TEST_FILE_PATH = "/testfile"
+)
+
+type LocalFileBackend struct {
+	directory string
+}
+
+func (b *LocalFileBackend) TestConnection() *model.AppError {
+	f := []byte("testingwrite")
+	if _, err := b.WriteFile(bytes.NewReader(f), TEST_FILE_PATH); err != nil {
+		return model.NewLocAppError("TestFileConnection", "api.file.test_connection.local.app_error", nil, err.Error())
+	}
+	os.Remove(filepath.Join(b.directory, TEST_FILE_PATH))
+	return nil
+}
+
+func (b *LocalFileBackend) Reader(path string) (io.ReadCloser, *model.AppError) {
+	if f, err := os.Open(filepath.Join(b.directory, path)); err != nil {
+		return nil, model.NewLocAppError("Reader", "api.file.reader.reading_local.app_error", nil, err.Error())
+	} else {
+		return f, nil
+	}
+}
+
+func (b *LocalFileBackend) ReadFile(path string) ([]byte, *model.AppError) {
+	if f, err := ioutil.ReadFile(filepath.Join(b.directory, path)); err != nil {
+		return nil, model.NewLocAppError("ReadFile", "api.file.read_file.reading_local.app_error", nil, err.Error())
+	} else {
+		return f, nil
+	}
+}
+
+func (b *LocalFileBackend) WriteFile(fr io.Reader, path string) (int64, *model.AppError) {
+	return writeFileLocally(fr, filepath.Join(b.directory, path))
+}
+
+func writeFileLocally(fr io.Reader, path string) (int64, *model.AppError) {
+	if err := os.MkdirAll(filepath.Dir(path), 0774); err != nil {
+		directory, _ := filepath.Abs(filepath.Dir(path))
+		return 0, model.NewLocAppError("WriteFile", "api.file.write_file_locally.create_dir.app_error", nil, "directory="+directory+", err="+err.Error())
+	}
+
+	fw, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, model.NewLocAppError("WriteFile", "api.file.write_file_locally.writing.app_error", nil, err.Error())
+	}
+	defer fw.Close()
+
+	written, err := io.Copy(fw, fr)
+	if err != nil {
+		return written, model.NewLocAppError("WriteFile", "api.file.write_file_locally.writing.app_error", nil, err.Error())
+	}
+
+	return written, nil
+}
+
+func (b *LocalFileBackend) MoveFile(oldPath, newPath string) *model.AppError {
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(b.directory, newPath)), 0774); err != nil {
+		return model.NewLocAppError("moveFile", "api.file.move_file.rename.app_error", nil, err.Error())
+	}
+
+	if err := os.Rename(filepath.Join(b.directory, oldPath), filepath.Join(b.directory, newPath)); err != nil {
+		return model.NewLocAppError("moveFile", "api.file.move_file.rename.app_error", nil, err.Error())
+	}
+
+	return nil
+}
+
+func (b *LocalFileBackend) RemoveFile(path string) *model.AppError {
+	if err := os.Remove(filepath.Join(b.directory, path)); err != nil {
+		return model.NewLocAppError("RemoveFile", "api.file.remove_file.local.app_error", nil, err.Error())
+	}
+	return nil
+}
+
+func (b *LocalFileBackend) ListDirectory(dir string) (*[]string, *model.AppError) {
+	var paths []string
+	if fileInfos, err := ioutil.ReadDir(filepath.Join(b.directory, dir)); err != nil {
+		return nil, model.NewLocAppError("ListDirectory", "api.file.list_directory.local.app_error", nil, err.Error())
+	} else {
+		for _, fileInfo := range fileInfos {
+			paths = append(paths, path.Join(dir, fileInfo.Name()))
+		}
+	}
+	return &paths, nil
+}
+
+func (b *LocalFileBackend) RemoveDirectory(path string) *model.AppError {
+	if err := os.RemoveAll(filepath.Join(b.directory, path)); err != nil {
+		return model.NewLocAppError("RemoveDirectory", "api.file.remove_directory.local.app_error", nil, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	s3 "github.com/minio/minio-go"
+
+	"github.com/mattermost/platform/model"
+)
+
+type S3FileBackend struct {
+	client *s3.Client
+	bucket string
+	region string
+}
+
+func NewS3FileBackend(settings *model.FileSettings) (*S3FileBackend, *model.AppError) {
+	secure := settings.AmazonS3SSL == nil || *settings.AmazonS3SSL // Secure by default.
+
+	client, err := s3.New(settings.AmazonS3Endpoint, settings.AmazonS3AccessKeyId, settings.AmazonS3SecretAccessKey, secure)
+	if err != nil {
+		return nil, model.NewLocAppError("NewS3FileBackend", "api.file.s3.new_client.app_error", nil, err.Error())
+	}
+
+	return &S3FileBackend{
+		client: client,
+		bucket: settings.AmazonS3Bucket,
+		region: settings.AmazonS3Region,
+	}, nil
+}
+
+func (b *S3FileBackend) TestConnection() *model.AppError {
+	exists, err := b.client.BucketExists(b.bucket)
+	if err != nil {
+		return model.NewLocAppError("TestFileConnection", "api.file.test_connection.s3.connection.app_error", nil, err.Error())
+	}
+
+	if !exists {
+		l4g.Warn(T("api.file.test_connection.s3.bucket_does_not_exist.warn"), b.bucket)
+		if err := b.client.MakeBucket(b.bucket, b.region); err != nil {
+			return model.NewLocAppError("TestFileConnection", "api.file.test_connection.s3.bucket_create.app_error", nil, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (b *S3FileBackend) Reader(path string) (io.ReadCloser, *model.AppError) {
+	minioObject, err := b.client.GetObject(b.bucket, path)
+	if err != nil {
+		return nil, model.NewLocAppError("Reader", "api.file.reader.s3.app_error", nil, err.Error())
+	}
+
+	// GetObject is lazy so stat the object to report a missing file here rather than on the first read
+	if _, err := minioObject.Stat(); err != nil {
+		minioObject.Close()
+		return nil, model.NewLocAppError("Reader", "api.file.reader.s3.app_error", nil, err.Error())
+	}
+
+	return minioObject, nil
+}
+
+func (b *S3FileBackend) ReadFile(path string) ([]byte, *model.AppError) {
+	minioObject, err := b.client.GetObject(b.bucket, path)
+	if err != nil {
+		return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
+	}
+	defer minioObject.Close()
+
+	if f, err := ioutil.ReadAll(minioObject); err != nil {
+		return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
+	} else {
+		return f, nil
+	}
+}
+
+func (b *S3FileBackend) WriteFile(fr io.Reader, path string) (int64, *model.AppError) {
+	var contentType string
+	if ext := filepath.Ext(path); model.IsFileExtImage(ext) {
+		contentType = model.GetImageMimeType(ext)
+	} else {
+		contentType = "binary/octet-stream"
+	}
+
+	written, err := b.client.PutObject(b.bucket, path, fr, contentType)
+	if err != nil {
+		return written, model.NewLocAppError("WriteFile", "api.file.write_file.s3.app_error", nil, err.Error())
+	}
+
+	return written, nil
+}
+
+func (b *S3FileBackend) MoveFile(oldPath, newPath string) *model.AppError {
+	copyConds := s3.NewCopyConditions()
+	if err := b.client.CopyObject(b.bucket, newPath, "/"+path.Join(b.bucket, oldPath), copyConds); err != nil {
+		return model.NewLocAppError("moveFile", "api.file.move_file.copy_within_s3.app_error", nil, err.Error())
+	}
+
+	if err := b.client.RemoveObject(b.bucket, oldPath); err != nil {
+		return model.NewLocAppError("moveFile", "api.file.move_file.delete_from_s3.app_error", nil, err.Error())
+	}
+
+	return nil
+}
+
+func (b *S3FileBackend) RemoveFile(path string) *model.AppError {
+	if err := b.client.RemoveObject(b.bucket, path); err != nil {
+		return model.NewLocAppError("RemoveFile", "api.file.remove_file.s3.app_error", nil, err.Error())
+	}
+
+	return nil
+}
+
+func (b *S3FileBackend) ListDirectory(dir string) (*[]string, *model.AppError) {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	var paths []string
+	for object := range b.client.ListObjects(b.bucket, s3DirectoryPrefix(dir), false, doneCh) {
+		if object.Err != nil {
+			return nil, model.NewLocAppError("ListDirectory", "api.file.list_directory.s3.app_error", nil, object.Err.Error())
+		}
+		paths = append(paths, strings.TrimSuffix(object.Key, "/"))
+	}
+
+	return &paths, nil
+}
+
+func (b *S3FileBackend) RemoveDirectory(dir string) *model.AppError {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	objectsCh := make(chan string)
+	go func() {
+		defer close(objectsCh)
+		for object := range b.client.ListObjects(b.bucket, s3DirectoryPrefix(dir), true, doneCh) {
+			if object.Err != nil {
+				return
+			}
+			select {
+			case objectsCh <- object.Key:
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+
+	for err := range b.client.RemoveObjects(b.bucket, objectsCh) {
+		if err.Err != nil {
+			return model.NewLocAppError("RemoveDirectory", "api.file.remove_directory.s3.app_error", nil, err.Err.Error())
+		}
+	}
+
+	return nil
+}
+
+// s3DirectoryPrefix makes sure that a listing of "teams/abc" doesn't also return the contents of "teams/abcd".
+func s3DirectoryPrefix(dir string) string {
+	dir = strings.Trim(dir, "/")
+	if dir == "" {
+		return ""
+	}
+	return dir + "/"
+}
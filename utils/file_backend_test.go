@@ -0,0 +1,134 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestLocalFileBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	backend, appErr := NewFileBackend(&model.FileSettings{
+		DriverName: model.IMAGE_DRIVER_LOCAL,
+		Directory:  dir,
+	})
+	if appErr != nil {
+		t.Fatal(appErr)
+	}
+
+	testFileBackend(t, backend)
+}
+
+func TestS3FileBackend(t *testing.T) {
+	TranslationsPreInit()
+	secure := false
+	backend, appErr := NewFileBackend(&model.FileSettings{
+		DriverName:              model.IMAGE_DRIVER_S3,
+		AmazonS3AccessKeyId:     "minioaccesskey",
+		AmazonS3SecretAccessKey: "miniosecretkey",
+		AmazonS3Bucket:          "mattermost-test",
+		AmazonS3Region:          "us-east-1",
+		AmazonS3Endpoint:        "localhost:9001",
+		AmazonS3SSL:             &secure,
+	})
+	if appErr != nil {
+		t.Fatal(appErr)
+	}
+
+	if err := backend.TestConnection(); err != nil {
+		t.Skip("minio is not running, skipping S3 file backend tests, err=" + err.Error())
+	}
+
+	testFileBackend(t, backend)
+}
+
+func TestNewFileBackendInvalidDriver(t *testing.T) {
+	if _, err := NewFileBackend(&model.FileSettings{DriverName: "invalid"}); err == nil {
+		t.Fatal("should have failed with an invalid driver")
+	}
+}
+
+func testFileBackend(t *testing.T, backend FileBackend) {
+	if err := backend.TestConnection(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := "tests/" + model.NewId()
+	defer backend.RemoveDirectory(dir)
+
+	path := dir + "/file.txt"
+	data := []byte("some test data")
+
+	if written, err := backend.WriteFile(bytes.NewReader(data), path); err != nil {
+		t.Fatal(err)
+	} else if written != int64(len(data)) {
+		t.Fatal("wrote the wrong number of bytes")
+	}
+
+	if read, err := backend.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(read, data) {
+		t.Fatal("read the wrong data")
+	}
+
+	if reader, err := backend.Reader(path); err != nil {
+		t.Fatal(err)
+	} else {
+		read, _ := ioutil.ReadAll(reader)
+		reader.Close()
+
+		if !bytes.Equal(read, data) {
+			t.Fatal("streamed the wrong data")
+		}
+	}
+
+	if _, err := backend.Reader(dir + "/missing.txt"); err == nil {
+		t.Fatal("should have failed to open a missing file")
+	}
+
+	newPath := dir + "/moved/file.txt"
+	if err := backend.MoveFile(path, newPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.ReadFile(path); err == nil {
+		t.Fatal("file should have been moved")
+	}
+
+	if paths, err := backend.ListDirectory(dir); err != nil {
+		t.Fatal(err)
+	} else if len(*paths) != 1 || (*paths)[0] != dir+"/moved" {
+		t.Fatal("listed the wrong paths", *paths)
+	}
+
+	if err := backend.RemoveFile(newPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.ReadFile(newPath); err == nil {
+		t.Fatal("file should have been removed")
+	}
+
+	if _, err := backend.WriteFile(bytes.NewReader(data), path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.RemoveDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.ReadFile(path); err == nil {
+		t.Fatal("directory should have been removed")
+	}
+}